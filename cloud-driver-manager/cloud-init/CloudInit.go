@@ -0,0 +1,259 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is PoC of Cloud-Init of Cloud Driver Manager.
+// The cloud-config documents are rendered from typed inputs for the UserData of VMReqInfo,
+// cloud-init runs them on AWS, OpenStack and Azure in the same way.
+
+package cloudinit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"gopkg.in/yaml.v3"
+)
+
+// Header is the first line of cloud-config user data.
+const Header = "#cloud-config"
+
+// SudoAll is the sudo rule of a user allowed to run all commands without password.
+const SudoAll = "ALL=(ALL) NOPASSWD:ALL"
+
+// ex) 0644, 0755
+var permissionsPattern = regexp.MustCompile(`^0[0-7]{3}$`)
+
+// User is a user created by cloud-init.
+type User struct {
+	Name              string   `yaml:"name"`
+	Groups            []string `yaml:"groups,omitempty"` // ex) ["docker", "wheel"]
+	Sudo              string   `yaml:"sudo,omitempty"`   // ex) SudoAll
+	Shell             string   `yaml:"shell,omitempty"`  // ex) /bin/bash
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+}
+
+// File is a file written by cloud-init before the packages are installed.
+type File struct {
+	Path        string `yaml:"path"`
+	Content     string `yaml:"content"`
+	Permissions string `yaml:"permissions,omitempty"` // ex) 0644
+	Owner       string `yaml:"owner,omitempty"`       // ex) root:root
+	Append      bool   `yaml:"append,omitempty"`
+}
+
+// CloudConfig is a cloud-config document or a fragment of it.
+// Users are added to the default user of the image(ex: ubuntu, ec2-user), SSHAuthorizedKeys are keys of the default user.
+type CloudConfig struct {
+	Hostname          string
+	Users             []User
+	SSHAuthorizedKeys []string
+	PackageUpdate     bool
+	PackageUpgrade    bool
+	Packages          []string
+	WriteFiles        []File
+	BootCmd           []string // shell commands run at every boot, before the network.
+	RunCmd            []string // shell commands run once at the first boot, after the packages and files.
+}
+
+// document is the YAML of CloudConfig, "default" in users keeps the default user of the image.
+type document struct {
+	Hostname          string        `yaml:"hostname,omitempty"`
+	Users             []interface{} `yaml:"users,omitempty"`
+	SSHAuthorizedKeys []string      `yaml:"ssh_authorized_keys,omitempty"`
+	PackageUpdate     bool          `yaml:"package_update,omitempty"`
+	PackageUpgrade    bool          `yaml:"package_upgrade,omitempty"`
+	Packages          []string      `yaml:"packages,omitempty"`
+	WriteFiles        []File        `yaml:"write_files,omitempty"`
+	BootCmd           []string      `yaml:"bootcmd,omitempty"`
+	RunCmd            []string      `yaml:"runcmd,omitempty"`
+}
+
+// getPublicKey returns the public key of a key pair.
+// AWS does not return the public key of a key pair, so it must be kept by the caller.
+func getPublicKey(keyPairInfo irs.KeyPairInfo) (string, error) {
+	publicKey := strings.TrimSpace(keyPairInfo.PublicKey)
+	if publicKey == "" {
+		return "", errors.New("no public key of key pair " + keyPairInfo.Name)
+	}
+	return publicKey, nil
+}
+
+// AddKeyPair adds the public key of a key pair to the authorized keys of the user.
+func (user *User) AddKeyPair(keyPairInfo irs.KeyPairInfo) error {
+	publicKey, err := getPublicKey(keyPairInfo)
+	if err != nil {
+		return err
+	}
+	user.SSHAuthorizedKeys = appendUnique(user.SSHAuthorizedKeys, publicKey)
+	return nil
+}
+
+// AddKeyPair adds the public key of a key pair to the authorized keys of the default user.
+func (config *CloudConfig) AddKeyPair(keyPairInfo irs.KeyPairInfo) error {
+	publicKey, err := getPublicKey(keyPairInfo)
+	if err != nil {
+		return err
+	}
+	config.SSHAuthorizedKeys = appendUnique(config.SSHAuthorizedKeys, publicKey)
+	return nil
+}
+
+// Merge merges fragments in order into a CloudConfig.
+//   - Hostname: the last one not empty.
+//   - Users: the users of the same name are merged, their groups and keys are added and the others of the last one are kept.
+//   - SSHAuthorizedKeys, Packages: added without duplicates.
+//   - PackageUpdate, PackageUpgrade: true if any fragment is true.
+//   - WriteFiles: the last file of the same path is kept.
+//   - BootCmd, RunCmd: added in order.
+func Merge(fragments ...CloudConfig) CloudConfig {
+	var config CloudConfig
+	for _, fragment := range fragments {
+		if fragment.Hostname != "" {
+			config.Hostname = fragment.Hostname
+		}
+		for _, user := range fragment.Users {
+			config.Users = mergeUser(config.Users, user)
+		}
+		for _, key := range fragment.SSHAuthorizedKeys {
+			config.SSHAuthorizedKeys = appendUnique(config.SSHAuthorizedKeys, key)
+		}
+		config.PackageUpdate = config.PackageUpdate || fragment.PackageUpdate
+		config.PackageUpgrade = config.PackageUpgrade || fragment.PackageUpgrade
+		for _, pkg := range fragment.Packages {
+			config.Packages = appendUnique(config.Packages, pkg)
+		}
+		for _, file := range fragment.WriteFiles {
+			config.WriteFiles = mergeFile(config.WriteFiles, file)
+		}
+		config.BootCmd = append(config.BootCmd, fragment.BootCmd...)
+		config.RunCmd = append(config.RunCmd, fragment.RunCmd...)
+	}
+	return config
+}
+
+func mergeUser(users []User, user User) []User {
+	for i, u := range users {
+		if u.Name != user.Name {
+			continue
+		}
+		for _, group := range user.Groups {
+			u.Groups = appendUnique(u.Groups, group)
+		}
+		for _, key := range user.SSHAuthorizedKeys {
+			u.SSHAuthorizedKeys = appendUnique(u.SSHAuthorizedKeys, key)
+		}
+		if user.Sudo != "" {
+			u.Sudo = user.Sudo
+		}
+		if user.Shell != "" {
+			u.Shell = user.Shell
+		}
+		users[i] = u
+		return users
+	}
+	// copy the lists not to share them with the fragment.
+	user.Groups = append([]string(nil), user.Groups...)
+	user.SSHAuthorizedKeys = append([]string(nil), user.SSHAuthorizedKeys...)
+	return append(users, user)
+}
+
+func mergeFile(files []File, file File) []File {
+	for i, f := range files {
+		if f.Path == file.Path {
+			files[i] = file
+			return files
+		}
+	}
+	return append(files, file)
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// Check checks the users and files of a CloudConfig.
+func (config CloudConfig) Check() error {
+	names := map[string]bool{}
+	for _, user := range config.Users {
+		if user.Name == "" {
+			return errors.New("the name of a user is required")
+		}
+		if user.Name == "default" {
+			return errors.New("the default user can not be added, use SSHAuthorizedKeys of CloudConfig for it")
+		}
+		if names[user.Name] {
+			return errors.New("duplicated user " + user.Name + ", merge the fragments with Merge")
+		}
+		names[user.Name] = true
+	}
+
+	paths := map[string]bool{}
+	for _, file := range config.WriteFiles {
+		if !strings.HasPrefix(file.Path, "/") {
+			return errors.New(fmt.Sprintf("the path of a file must be absolute: \"%s\"", file.Path))
+		}
+		if paths[file.Path] {
+			return errors.New("duplicated file " + file.Path + ", merge the fragments with Merge")
+		}
+		paths[file.Path] = true
+		if file.Permissions != "" && !permissionsPattern.MatchString(file.Permissions) {
+			return errors.New(fmt.Sprintf("invalid permissions of file %s: %s, ex) 0644", file.Path, file.Permissions))
+		}
+	}
+	return nil
+}
+
+// Render renders a CloudConfig into cloud-config user data for the UserData of VMReqInfo.
+// The size limit of each cloud is checked by the driver.
+func Render(config CloudConfig) (string, error) {
+	if err := config.Check(); err != nil {
+		return "", err
+	}
+
+	doc := document{
+		Hostname:          config.Hostname,
+		SSHAuthorizedKeys: config.SSHAuthorizedKeys,
+		PackageUpdate:     config.PackageUpdate,
+		PackageUpgrade:    config.PackageUpgrade,
+		Packages:          config.Packages,
+		WriteFiles:        config.WriteFiles,
+		BootCmd:           config.BootCmd,
+		RunCmd:            config.RunCmd,
+	}
+	if len(config.Users) != 0 {
+		// without "default", cloud-init does not create the default user of the image.
+		doc.Users = append(doc.Users, "default")
+		for _, user := range config.Users {
+			doc.Users = append(doc.Users, user)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(Header + "\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderFragments merges fragments and renders them. ex) RenderFragments(base, docker, app)
+func RenderFragments(fragments ...CloudConfig) (string, error) {
+	return Render(Merge(fragments...))
+}
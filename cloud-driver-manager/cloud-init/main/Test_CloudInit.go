@@ -0,0 +1,143 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a test of the Cloud-Init of Cloud Driver Manager.
+// The fragments are merged and rendered, and the cloud-config user data is compared with the expected one.
+//
+//      $ go run Test_CloudInit.go
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	cloudinit "github.com/cloud-barista/poc-cb-spider/cloud-driver-manager/cloud-init"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// the user data of RenderFragments(base, docker, app) with the key pair.
+const expectedUserData = `#cloud-config
+hostname: cb-vm
+users:
+  - default
+  - name: cb-user
+    groups:
+      - docker
+      - wheel
+    sudo: ALL=(ALL) NOPASSWD:ALL
+    shell: /bin/bash
+ssh_authorized_keys:
+  - ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC cb-key
+package_update: true
+packages:
+  - curl
+  - docker.io
+write_files:
+  - path: /etc/motd
+    content: |
+      cb-spider
+    permissions: "0644"
+    owner: root:root
+runcmd:
+  - echo base
+  - systemctl enable --now docker
+`
+
+func check(name string, err error) bool {
+	if err != nil {
+		fmt.Printf("[FAIL] %s: %v\n", name, err)
+		return false
+	}
+	fmt.Printf("[OK] %s\n", name)
+	return true
+}
+
+// checkError checks Render rejects a config with an error containing want.
+func checkError(config cloudinit.CloudConfig, want string) error {
+	_, err := cloudinit.Render(config)
+	if err == nil {
+		return errors.New("no error, want: " + want)
+	}
+	if !strings.Contains(err.Error(), want) {
+		return errors.New(fmt.Sprintf("error %q, want: %s", err, want))
+	}
+	return nil
+}
+
+func main() {
+	ok := true
+
+	base := cloudinit.CloudConfig{
+		Hostname:      "cb-vm",
+		PackageUpdate: true,
+		Packages:      []string{"curl"},
+		RunCmd:        []string{"echo base"},
+	}
+	err := base.AddKeyPair(irs.KeyPairInfo{Name: "cb-key", PublicKey: "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC cb-key\n"})
+	ok = check("AddKeyPair", err) && ok
+
+	docker := cloudinit.CloudConfig{
+		Users: []cloudinit.User{
+			{Name: "cb-user", Groups: []string{"docker"}, Sudo: cloudinit.SudoAll, Shell: "/bin/bash"},
+		},
+		Packages: []string{"docker.io", "curl"},
+		WriteFiles: []cloudinit.File{
+			{Path: "/etc/motd", Content: "docker\n"},
+		},
+		RunCmd: []string{"systemctl enable --now docker"},
+	}
+	app := cloudinit.CloudConfig{
+		Users: []cloudinit.User{
+			{Name: "cb-user", Groups: []string{"wheel", "docker"}},
+		},
+		WriteFiles: []cloudinit.File{
+			{Path: "/etc/motd", Content: "cb-spider\n", Permissions: "0644", Owner: "root:root"},
+		},
+	}
+
+	// Merge and Render: the user and the file of the same name are merged, the packages are not duplicated.
+	userData, err := cloudinit.RenderFragments(base, docker, app)
+	if err == nil && userData != expectedUserData {
+		err = errors.New(fmt.Sprintf("\n--- want\n%s--- got\n%s", expectedUserData, userData))
+	}
+	ok = check("RenderFragments", err) && ok
+
+	// the fragments are not changed by Merge.
+	err = nil
+	if len(docker.Users[0].Groups) != 1 || docker.WriteFiles[0].Content != "docker\n" {
+		err = errors.New(fmt.Sprintf("the fragment is changed: %v", docker))
+	}
+	ok = check("Merge without changing the fragments", err) && ok
+
+	// the user data of a VM.
+	err = irs.CheckUserData(userData, 16*1024, false)
+	ok = check("CheckUserData", err) && ok
+
+	// Check
+	err = checkError(cloudinit.CloudConfig{Users: []cloudinit.User{{Name: "default"}}}, "default user")
+	ok = check("Check the default user", err) && ok
+	err = checkError(cloudinit.CloudConfig{Users: []cloudinit.User{{Name: "cb-user"}, {Name: "cb-user"}}}, "duplicated user")
+	ok = check("Check the duplicated users", err) && ok
+	err = checkError(cloudinit.CloudConfig{WriteFiles: []cloudinit.File{{Path: "etc/motd"}}}, "absolute")
+	ok = check("Check the relative path", err) && ok
+	err = checkError(cloudinit.CloudConfig{WriteFiles: []cloudinit.File{{Path: "/etc/motd", Permissions: "644"}}}, "invalid permissions")
+	ok = check("Check the permissions", err) && ok
+	var config cloudinit.CloudConfig
+	err = config.AddKeyPair(irs.KeyPairInfo{Name: "aws-key"})
+	if err == nil {
+		err = errors.New("no error without the public key")
+	} else {
+		err = nil
+	}
+	ok = check("AddKeyPair without the public key", err) && ok
+
+	if !ok {
+		os.Exit(1)
+	}
+}
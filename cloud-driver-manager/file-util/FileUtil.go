@@ -0,0 +1,47 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is common file functions of Cloud Driver Manager(key vault, spec catalogs).
+// A file is replaced with a temporary file in the same directory not to break it on failures.
+
+package fileutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile replaces a file with a temporary file of data in the same directory.
+func WriteFile(path string, data []byte) error {
+	tmpPath, err := WriteTempFile(filepath.Dir(path), data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// WriteTempFile writes data to a new temporary file(.tmp-*) in dir and returns its path.
+// The caller renames it to the target file or removes it.
+func WriteTempFile(dir string, data []byte) (string, error) {
+	tmpFile, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return "", err
+	}
+	return tmpFile.Name(), nil
+}
@@ -0,0 +1,582 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is PoC of Key Vault of Cloud Driver Manager.
+// The private keys of generated key pairs are returned only once by CreateKey,
+// so the key vault keeps them encrypted on local disk to log into the VMs later.
+
+package keyvault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh"
+
+	cblog "github.com/cloud-barista/cb-log"
+	fileutil "github.com/cloud-barista/poc-cb-spider/cloud-driver-manager/file-util"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+const (
+	// env of the passphrase to encrypt the private keys.
+	PassphraseEnv = "CBSPIDER_KEY_VAULT_PASSPHRASE"
+
+	keyFileExt = ".key"
+	saltSize   = 16
+
+	// scrypt parameters recommended for interactive logins(2017).
+	scryptN = 32768
+	scryptR = 8
+	scryptP = 1
+	keySize = 32 // AES-256
+
+	// suffix of the name of the new key pair during RotateKey.
+	rotatingKeySuffix = "-rotating"
+)
+
+var cblogger *logrus.Logger
+
+func init() {
+	// cblog is a global variable.
+	cblogger = cblog.GetLogger("CB-SPIDER KeyVault")
+}
+
+// KeyInfo is the information of a private key in the vault without the key itself.
+type KeyInfo struct {
+	ConnectionName string
+	KeyName        string
+	Fingerprint    string
+	Version        int // increased by RotateKey.
+	CreatedTime    time.Time
+}
+
+// keyVersion is a version of an encrypted private key.
+// The old versions are kept after the rotation for the VMs created with them.
+type keyVersion struct {
+	Version     int
+	Fingerprint string
+	CreatedTime time.Time
+	Salt        []byte
+	Nonce       []byte
+	Ciphertext  []byte
+}
+
+// keyFile is the local file of a key, {vault path}/{connection name}/{key name}.key
+type keyFile struct {
+	ConnectionName string
+	KeyName        string
+	Versions       []keyVersion // the last one is the current.
+}
+
+type KeyVault struct {
+	path       string
+	passphrase []byte
+	mutex      sync.Mutex
+}
+
+// NewKeyVault opens the key vault in path, the private keys are encrypted with AES-GCM by a key derived from passphrase.
+func NewKeyVault(path string, passphrase string) (*KeyVault, error) {
+	if path == "" {
+		return nil, errors.New("no path of the key vault")
+	}
+	if passphrase == "" {
+		return nil, errors.New("no passphrase of the key vault")
+	}
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	return &KeyVault{path: path, passphrase: []byte(passphrase)}, nil
+}
+
+// OpenKeyVault opens the key vault in $CBSPIDER_PATH/key/vault with the passphrase of $CBSPIDER_KEY_VAULT_PASSPHRASE.
+func OpenKeyVault() (*KeyVault, error) {
+	rootPath := os.Getenv("CBSPIDER_PATH")
+	return NewKeyVault(filepath.Join(rootPath, "key", "vault"), os.Getenv(PassphraseEnv))
+}
+
+// CreateKey creates a key pair with keyPairHandler and puts its private key into the vault.
+// The PrivateKey of the result is cleared, GetPrivateKey returns it.
+// If the private key can not be saved, the key pair is deleted because nobody can use it.
+func (vault *KeyVault) CreateKey(connectionName string, keyPairHandler irs.KeyPairHandler, keyPairReqInfo irs.KeyPairReqInfo) (irs.KeyPairInfo, error) {
+	if _, err := vault.getKeyFilePath(connectionName, keyPairReqInfo.Name); err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+
+	keyPairInfo, err := keyPairHandler.CreateKey(keyPairReqInfo)
+	if err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+	// imported key pairs have no private keys.
+	if keyPairInfo.PrivateKey == "" {
+		return keyPairInfo, nil
+	}
+
+	err = vault.PutKey(connectionName, keyPairInfo)
+	if err != nil {
+		if _, deleteErr := keyPairHandler.DeleteKey(keyPairReqInfo.Name); deleteErr != nil {
+			cblogger.Errorf("unable to delete key pair %s without the private key, %v", keyPairReqInfo.Name, deleteErr)
+		}
+		return irs.KeyPairInfo{}, err
+	}
+	keyPairInfo.PrivateKey = ""
+	return keyPairInfo, nil
+}
+
+// DeleteKey deletes a key pair with keyPairHandler and then its private keys in the vault.
+// A key pair which is not in the vault(ex: imported) is deleted only in the cloud.
+func (vault *KeyVault) DeleteKey(connectionName string, keyPairHandler irs.KeyPairHandler, keyName string) (bool, error) {
+	keyFilePath, err := vault.getKeyFilePath(connectionName, keyName)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := keyPairHandler.DeleteKey(keyName)
+	if err != nil {
+		return false, err
+	}
+
+	vault.mutex.Lock()
+	defer vault.mutex.Unlock()
+	if err := os.Remove(keyFilePath); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return result, nil
+}
+
+// RotateKey replaces a key pair with a new one of the same name.
+// The old private keys are kept for the VMs created with them, GetPrivateKeyByFingerprint returns them.
+// The key pairs of the clouds can not be changed, so the new key pair is created as {key name}-rotating
+// and its private key is put into the vault before the old key pair is deleted.
+// Then the new public key is imported with the name of the key, and the temporary key pair is deleted.
+// If the import fails, the new key pair is left as {key name}-rotating in the cloud and in the vault.
+func (vault *KeyVault) RotateKey(connectionName string, keyPairHandler irs.KeyPairHandler, keyName string) (irs.KeyPairInfo, error) {
+	if _, err := vault.GetKey(connectionName, keyName); err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+	oldKeyPairInfo, err := keyPairHandler.GetKey(keyName)
+	if err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+
+	// Create the new key pair with the temporary name, the old key pair is not changed on failures.
+	tmpKeyName := keyName + rotatingKeySuffix
+	keyPairReqInfo := irs.KeyPairReqInfo{
+		Name: tmpKeyName,
+		Tags: oldKeyPairInfo.Tags,
+	}
+	if _, err := vault.CreateKey(connectionName, keyPairHandler, keyPairReqInfo); err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+	privateKey, err := vault.GetPrivateKey(connectionName, tmpKeyName)
+	if err != nil {
+		vault.deleteTempKey(connectionName, keyPairHandler, tmpKeyName)
+		return irs.KeyPairInfo{}, errors.New(fmt.Sprintf("no private key of the new key pair %s, %v", tmpKeyName, err))
+	}
+	publicKey, err := getPublicKey(privateKey)
+	if err != nil {
+		vault.deleteTempKey(connectionName, keyPairHandler, tmpKeyName)
+		return irs.KeyPairInfo{}, err
+	}
+
+	// Replace the old key pair with the new public key.
+	if _, err := keyPairHandler.DeleteKey(keyName); err != nil {
+		vault.deleteTempKey(connectionName, keyPairHandler, tmpKeyName)
+		return irs.KeyPairInfo{}, err
+	}
+	keyPairReqInfo = irs.KeyPairReqInfo{
+		Name:      keyName,
+		Tags:      oldKeyPairInfo.Tags,
+		PublicKey: publicKey,
+	}
+	keyPairInfo, err := keyPairHandler.CreateKey(keyPairReqInfo)
+	if err != nil {
+		return irs.KeyPairInfo{}, errors.New(fmt.Sprintf("unable to import the new key pair %s, it is kept as %s, %v", keyName, tmpKeyName, err))
+	}
+
+	keyPairInfo.PrivateKey = privateKey
+	err = vault.PutKey(connectionName, keyPairInfo)
+	if err != nil {
+		return irs.KeyPairInfo{}, errors.New(fmt.Sprintf("unable to put the new private key of %s, it is kept as %s in the key vault, %v", keyName, tmpKeyName, err))
+	}
+	vault.deleteTempKey(connectionName, keyPairHandler, tmpKeyName)
+
+	keyPairInfo.PrivateKey = ""
+	return keyPairInfo, nil
+}
+
+// deleteTempKey deletes the temporary key pair of RotateKey, the failure is only logged.
+func (vault *KeyVault) deleteTempKey(connectionName string, keyPairHandler irs.KeyPairHandler, tmpKeyName string) {
+	if _, err := vault.DeleteKey(connectionName, keyPairHandler, tmpKeyName); err != nil {
+		cblogger.Errorf("unable to delete the temporary key pair %s, %v", tmpKeyName, err)
+	}
+}
+
+// getPublicKey gets the public key(OpenSSH authorized_keys format) of a PEM encoded private key.
+func getPublicKey(privateKey string) (string, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))), nil
+}
+
+// PutKey puts the private key of keyPairInfo into the vault as the new version of the key.
+func (vault *KeyVault) PutKey(connectionName string, keyPairInfo irs.KeyPairInfo) error {
+	if keyPairInfo.PrivateKey == "" {
+		return errors.New(fmt.Sprintf("no private key of key pair %s", keyPairInfo.Name))
+	}
+
+	vault.mutex.Lock()
+	defer vault.mutex.Unlock()
+
+	file, err := vault.readKeyFile(connectionName, keyPairInfo.Name)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if os.IsNotExist(err) {
+		file = keyFile{ConnectionName: connectionName, KeyName: keyPairInfo.Name}
+	}
+
+	version, err := vault.encrypt([]byte(keyPairInfo.PrivateKey))
+	if err != nil {
+		return err
+	}
+	version.Version = len(file.Versions) + 1
+	version.Fingerprint = keyPairInfo.Fingerprint
+	version.CreatedTime = time.Now()
+	file.Versions = append(file.Versions, version)
+
+	return vault.writeKeyFile(file)
+}
+
+// GetPrivateKey returns the current private key of a key.
+func (vault *KeyVault) GetPrivateKey(connectionName string, keyName string) (string, error) {
+	vault.mutex.Lock()
+	defer vault.mutex.Unlock()
+
+	file, err := vault.getKeyFile(connectionName, keyName)
+	if err != nil {
+		return "", err
+	}
+	return vault.decrypt(file.Versions[len(file.Versions)-1])
+}
+
+// GetPrivateKeyByFingerprint returns the private key of a key version, ex) for the VMs created before RotateKey.
+func (vault *KeyVault) GetPrivateKeyByFingerprint(connectionName string, keyName string, fingerprint string) (string, error) {
+	vault.mutex.Lock()
+	defer vault.mutex.Unlock()
+
+	file, err := vault.getKeyFile(connectionName, keyName)
+	if err != nil {
+		return "", err
+	}
+	for i := len(file.Versions) - 1; i >= 0; i-- {
+		if file.Versions[i].Fingerprint == fingerprint {
+			return vault.decrypt(file.Versions[i])
+		}
+	}
+	return "", errors.New(fmt.Sprintf("no private key of %s with fingerprint %s", keyName, fingerprint))
+}
+
+// GetKey returns the information of the current private key of a key.
+func (vault *KeyVault) GetKey(connectionName string, keyName string) (KeyInfo, error) {
+	vault.mutex.Lock()
+	defer vault.mutex.Unlock()
+
+	file, err := vault.getKeyFile(connectionName, keyName)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return getKeyInfo(file), nil
+}
+
+// ListKey returns the keys of a connection in the order of the names.
+func (vault *KeyVault) ListKey(connectionName string) ([]*KeyInfo, error) {
+	var keyInfoList []*KeyInfo
+
+	dirPath, err := vault.getConnectionPath(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	vault.mutex.Lock()
+	defer vault.mutex.Unlock()
+
+	files, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return keyInfoList, nil
+		}
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != keyFileExt {
+			continue
+		}
+		keyName, err := url.PathUnescape(strings.TrimSuffix(f.Name(), keyFileExt))
+		if err != nil {
+			continue
+		}
+		file, err := vault.getKeyFile(connectionName, keyName)
+		if err != nil {
+			return nil, err
+		}
+		keyInfo := getKeyInfo(file)
+		keyInfoList = append(keyInfoList, &keyInfo)
+	}
+	return keyInfoList, nil
+}
+
+// RemoveKey removes the private keys of a key only in the vault, DeleteKey deletes the key pair too.
+func (vault *KeyVault) RemoveKey(connectionName string, keyName string) error {
+	keyFilePath, err := vault.getKeyFilePath(connectionName, keyName)
+	if err != nil {
+		return err
+	}
+
+	vault.mutex.Lock()
+	defer vault.mutex.Unlock()
+	err = os.Remove(keyFilePath)
+	if os.IsNotExist(err) {
+		return errors.New(fmt.Sprintf("no key %s of connection %s in the key vault", keyName, connectionName))
+	}
+	return err
+}
+
+// ChangePassphrase re-encrypts all the private keys in the vault with a new passphrase.
+// All the new key files are written to temporary files before any key file is replaced,
+// and the replaced key files are restored when a replacement fails.
+func (vault *KeyVault) ChangePassphrase(newPassphrase string) error {
+	if newPassphrase == "" {
+		return errors.New("no passphrase of the key vault")
+	}
+
+	vault.mutex.Lock()
+	defer vault.mutex.Unlock()
+
+	var keyFilePaths []string
+	err := filepath.Walk(vault.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(path) == keyFileExt {
+			keyFilePaths = append(keyFilePaths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// re-encrypt all in memory first, nothing is changed with a wrong passphrase.
+	newVault := KeyVault{path: vault.path, passphrase: []byte(newPassphrase)}
+	var oldDataList, newDataList [][]byte
+	for _, path := range keyFilePaths {
+		oldData, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file, err := parseKeyFile(path, oldData)
+		if err != nil {
+			return err
+		}
+		for j, version := range file.Versions {
+			privateKey, err := vault.decrypt(version)
+			if err != nil {
+				return err
+			}
+			newVersion, err := newVault.encrypt([]byte(privateKey))
+			if err != nil {
+				return err
+			}
+			newVersion.Version = version.Version
+			newVersion.Fingerprint = version.Fingerprint
+			newVersion.CreatedTime = version.CreatedTime
+			file.Versions[j] = newVersion
+		}
+		newData, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return err
+		}
+		oldDataList = append(oldDataList, oldData)
+		newDataList = append(newDataList, newData)
+	}
+
+	// write all the new key files to temporary files, the left ones are removed on failures.
+	var tmpPaths []string
+	defer func() {
+		for _, tmpPath := range tmpPaths {
+			os.Remove(tmpPath)
+		}
+	}()
+	for i, path := range keyFilePaths {
+		tmpPath, err := fileutil.WriteTempFile(filepath.Dir(path), newDataList[i])
+		if err != nil {
+			return err
+		}
+		tmpPaths = append(tmpPaths, tmpPath)
+	}
+
+	// replace the key files, the replaced ones are restored with the old data on failures.
+	for i, path := range keyFilePaths {
+		if err := os.Rename(tmpPaths[i], path); err != nil {
+			for j := 0; j < i; j++ {
+				if restoreErr := fileutil.WriteFile(keyFilePaths[j], oldDataList[j]); restoreErr != nil {
+					cblogger.Errorf("unable to restore key file %s, %v", keyFilePaths[j], restoreErr)
+				}
+			}
+			return err
+		}
+	}
+	vault.passphrase = newVault.passphrase
+	return nil
+}
+
+func getKeyInfo(file keyFile) KeyInfo {
+	current := file.Versions[len(file.Versions)-1]
+	return KeyInfo{
+		ConnectionName: file.ConnectionName,
+		KeyName:        file.KeyName,
+		Fingerprint:    current.Fingerprint,
+		Version:        current.Version,
+		CreatedTime:    current.CreatedTime,
+	}
+}
+
+// getConnectionPath returns the directory of the keys of a connection, the name is escaped not to be a path.
+func (vault *KeyVault) getConnectionPath(connectionName string) (string, error) {
+	if connectionName == "" || connectionName == "." || connectionName == ".." {
+		return "", errors.New(fmt.Sprintf("invalid connection name %q", connectionName))
+	}
+	return filepath.Join(vault.path, url.PathEscape(connectionName)), nil
+}
+
+func (vault *KeyVault) getKeyFilePath(connectionName string, keyName string) (string, error) {
+	dirPath, err := vault.getConnectionPath(connectionName)
+	if err != nil {
+		return "", err
+	}
+	if keyName == "" {
+		return "", errors.New("invalid key name \"\"")
+	}
+	return filepath.Join(dirPath, url.PathEscape(keyName)+keyFileExt), nil
+}
+
+// getKeyFile reads a key file, an error is returned when the key is not in the vault.
+func (vault *KeyVault) getKeyFile(connectionName string, keyName string) (keyFile, error) {
+	file, err := vault.readKeyFile(connectionName, keyName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return keyFile{}, errors.New(fmt.Sprintf("no key %s of connection %s in the key vault", keyName, connectionName))
+		}
+		return keyFile{}, err
+	}
+	if len(file.Versions) == 0 {
+		return keyFile{}, errors.New(fmt.Sprintf("no private key of %s in the key vault", keyName))
+	}
+	return file, nil
+}
+
+func (vault *KeyVault) readKeyFile(connectionName string, keyName string) (keyFile, error) {
+	keyFilePath, err := vault.getKeyFilePath(connectionName, keyName)
+	if err != nil {
+		return keyFile{}, err
+	}
+	return readKeyFile(keyFilePath)
+}
+
+func readKeyFile(keyFilePath string) (keyFile, error) {
+	data, err := ioutil.ReadFile(keyFilePath)
+	if err != nil {
+		return keyFile{}, err
+	}
+	return parseKeyFile(keyFilePath, data)
+}
+
+func parseKeyFile(keyFilePath string, data []byte) (keyFile, error) {
+	var file keyFile
+	err := json.Unmarshal(data, &file)
+	if err != nil {
+		return keyFile{}, errors.New(fmt.Sprintf("invalid key file %s, %v", keyFilePath, err))
+	}
+	return file, nil
+}
+
+// writeKeyFile replaces a key file with a temporary file not to break it on failures.
+func (vault *KeyVault) writeKeyFile(file keyFile) error {
+	keyFilePath, err := vault.getKeyFilePath(file.ConnectionName, file.KeyName)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFilePath), 0700); err != nil {
+		return err
+	}
+	return fileutil.WriteFile(keyFilePath, data)
+}
+
+// encrypt encrypts a private key with AES-GCM, the key is derived from the passphrase and a new salt by scrypt.
+func (vault *KeyVault) encrypt(plaintext []byte) (keyVersion, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return keyVersion{}, err
+	}
+	gcm, err := vault.getCipher(salt)
+	if err != nil {
+		return keyVersion{}, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return keyVersion{}, err
+	}
+	return keyVersion{
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, nil
+}
+
+func (vault *KeyVault) decrypt(version keyVersion) (string, error) {
+	gcm, err := vault.getCipher(version.Salt)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := gcm.Open(nil, version.Nonce, version.Ciphertext, nil)
+	if err != nil {
+		// the error of GCM does not tell the wrong passphrase from the broken file.
+		return "", errors.New("unable to decrypt the private key, wrong passphrase or broken key file")
+	}
+	return string(plaintext), nil
+}
+
+func (vault *KeyVault) getCipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(vault.passphrase, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
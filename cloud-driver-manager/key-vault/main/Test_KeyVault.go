@@ -0,0 +1,209 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a test of the Key Vault with a local key pair handler instead of a cloud.
+// The private keys are kept in a temporary vault which is removed at the end.
+//
+//      $ go run Test_KeyVault.go
+
+package main
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	keyvault "github.com/cloud-barista/poc-cb-spider/cloud-driver-manager/key-vault"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+const connectionName = "test-connection"
+
+// localKeyPairHandler keeps the public keys of the key pairs like a cloud.
+type localKeyPairHandler struct {
+	keyPairs map[string]irs.KeyPairInfo
+}
+
+func (keyPairHandler *localKeyPairHandler) CreateKey(keyPairReqInfo irs.KeyPairReqInfo) (irs.KeyPairInfo, error) {
+	if _, ok := keyPairHandler.keyPairs[keyPairReqInfo.Name]; ok {
+		return irs.KeyPairInfo{}, errors.New("key pair already exist: " + keyPairReqInfo.Name)
+	}
+
+	var privateKey string
+	publicKey := keyPairReqInfo.PublicKey
+	if publicKey == "" {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return irs.KeyPairInfo{}, err
+		}
+		privateKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+		sshPublicKey, err := ssh.NewPublicKey(&rsaKey.PublicKey)
+		if err != nil {
+			return irs.KeyPairInfo{}, err
+		}
+		publicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey)))
+	}
+	sshPublicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+
+	keyPairInfo := irs.KeyPairInfo{
+		Name:        keyPairReqInfo.Name,
+		Id:          keyPairReqInfo.Name,
+		Tags:        keyPairReqInfo.Tags,
+		PublicKey:   publicKey,
+		Fingerprint: fmt.Sprintf("%x", md5.Sum(sshPublicKey.Marshal())),
+	}
+	keyPairHandler.keyPairs[keyPairInfo.Name] = keyPairInfo
+
+	keyPairInfo.PrivateKey = privateKey
+	return keyPairInfo, nil
+}
+
+func (keyPairHandler *localKeyPairHandler) ListKey(listReqInfo irs.ListReqInfo) ([]*irs.KeyPairInfo, irs.ListPageInfo, error) {
+	var keyPairList []*irs.KeyPairInfo
+	for _, keyPairInfo := range keyPairHandler.keyPairs {
+		keyPair := keyPairInfo
+		keyPairList = append(keyPairList, &keyPair)
+	}
+	return keyPairList, irs.ListPageInfo{}, nil
+}
+
+func (keyPairHandler *localKeyPairHandler) GetKey(keyPairID string) (irs.KeyPairInfo, error) {
+	keyPairInfo, ok := keyPairHandler.keyPairs[keyPairID]
+	if !ok {
+		return irs.KeyPairInfo{}, errors.New("no key pair: " + keyPairID)
+	}
+	return keyPairInfo, nil
+}
+
+func (keyPairHandler *localKeyPairHandler) DeleteKey(keyPairID string) (bool, error) {
+	if _, ok := keyPairHandler.keyPairs[keyPairID]; !ok {
+		return false, errors.New("no key pair: " + keyPairID)
+	}
+	delete(keyPairHandler.keyPairs, keyPairID)
+	return true, nil
+}
+
+func check(name string, err error) bool {
+	if err != nil {
+		fmt.Printf("[FAIL] %s: %v\n", name, err)
+		return false
+	}
+	fmt.Printf("[OK] %s\n", name)
+	return true
+}
+
+// checkPrivateKey checks the private key in the vault is of the public key of the key pair.
+func checkPrivateKey(privateKey string, keyPairInfo irs.KeyPairInfo) error {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return err
+	}
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	if publicKey != keyPairInfo.PublicKey {
+		return errors.New("the private key is not of key pair " + keyPairInfo.Name)
+	}
+	return nil
+}
+
+func main() {
+	vaultPath, err := ioutil.TempDir("", "key-vault-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(vaultPath)
+
+	vault, err := keyvault.NewKeyVault(vaultPath, "passphrase-1")
+	if err != nil {
+		panic(err)
+	}
+	keyPairHandler := &localKeyPairHandler{keyPairs: map[string]irs.KeyPairInfo{}}
+	ok := true
+
+	// CreateKey
+	keyPairInfo, err := vault.CreateKey(connectionName, keyPairHandler, irs.KeyPairReqInfo{Name: "cb-key", Tags: map[string]string{"owner": "cb-spider"}})
+	if err == nil && keyPairInfo.PrivateKey != "" {
+		err = errors.New("the private key is returned")
+	}
+	if err == nil {
+		var privateKey string
+		privateKey, err = vault.GetPrivateKey(connectionName, "cb-key")
+		if err == nil {
+			err = checkPrivateKey(privateKey, keyPairHandler.keyPairs["cb-key"])
+		}
+	}
+	ok = check("CreateKey", err) && ok
+	oldFingerprint := keyPairInfo.Fingerprint
+
+	// RotateKey: a new key pair of the same name, the old private key is kept.
+	keyPairInfo, err = vault.RotateKey(connectionName, keyPairHandler, "cb-key")
+	if err == nil && keyPairInfo.Fingerprint == oldFingerprint {
+		err = errors.New("the key pair is not changed")
+	}
+	if err == nil {
+		var privateKey string
+		privateKey, err = vault.GetPrivateKey(connectionName, "cb-key")
+		if err == nil {
+			err = checkPrivateKey(privateKey, keyPairHandler.keyPairs["cb-key"])
+		}
+	}
+	if err == nil {
+		_, err = vault.GetPrivateKeyByFingerprint(connectionName, "cb-key", oldFingerprint)
+	}
+	if err == nil {
+		if _, tmpErr := keyPairHandler.GetKey("cb-key-rotating"); tmpErr == nil {
+			err = errors.New("the temporary key pair is left")
+		}
+	}
+	ok = check("RotateKey", err) && ok
+
+	// ChangePassphrase: the old passphrase can not decrypt the keys.
+	err = vault.ChangePassphrase("passphrase-2")
+	if err == nil {
+		_, err = vault.GetPrivateKeyByFingerprint(connectionName, "cb-key", oldFingerprint)
+	}
+	if err == nil {
+		var oldVault *keyvault.KeyVault
+		oldVault, err = keyvault.NewKeyVault(vaultPath, "passphrase-1")
+		if err == nil {
+			if _, decryptErr := oldVault.GetPrivateKey(connectionName, "cb-key"); decryptErr == nil {
+				err = errors.New("the old passphrase decrypts the key")
+			}
+		}
+	}
+	ok = check("ChangePassphrase", err) && ok
+
+	// ListKey
+	keyInfoList, err := vault.ListKey(connectionName)
+	if err == nil && (len(keyInfoList) != 1 || keyInfoList[0].KeyName != "cb-key" || keyInfoList[0].Version != 2) {
+		err = errors.New(fmt.Sprintf("unexpected keys %v", keyInfoList))
+	}
+	ok = check("ListKey", err) && ok
+
+	// DeleteKey
+	_, err = vault.DeleteKey(connectionName, keyPairHandler, "cb-key")
+	if err == nil {
+		if _, getErr := vault.GetKey(connectionName, "cb-key"); getErr == nil {
+			err = errors.New("the key is left in the vault")
+		}
+	}
+	ok = check("DeleteKey", err) && ok
+
+	if !ok {
+		os.Exit(1)
+	}
+}
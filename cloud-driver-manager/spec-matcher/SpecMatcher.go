@@ -0,0 +1,344 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is PoC of Spec Matcher of Cloud Driver Manager.
+// The spec matcher finds the cheapest VM specs meeting the requirements in each connection,
+// the specs and prices of a connection are cached in a local catalog to match them without the cloud.
+
+package specmatcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	cblog "github.com/cloud-barista/cb-log"
+	fileutil "github.com/cloud-barista/poc-cb-spider/cloud-driver-manager/file-util"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+var cblogger *logrus.Logger
+
+func init() {
+	// cblog is a global variable.
+	cblogger = cblog.GetLogger("CB-SPIDER SpecMatcher")
+}
+
+// DefaultCatalogTTL is the time to refresh a catalog when the cloud can be called.
+const DefaultCatalogTTL = 24 * time.Hour
+
+// SpecRequirement is the requirements of a VM spec, 0 or "": no requirement.
+type SpecRequirement struct {
+	MinVCpuCount int
+	MinMemoryMiB int64
+	MinGpuCount  int
+	Architecture string // ex) x86_64, arm64
+}
+
+// MatchedSpec is a spec matched with the requirements.
+// The specs are ranked by the price in the catalog(see SetPrices), the cheapest first.
+// The spec listing of the clouds has no prices, so the specs without a price are ranked after them
+// by the size over the requirements(SizeScore), the smallest first.
+type MatchedSpec struct {
+	ConnectionName string
+	Rank           int     // 1: the cheapest(or the smallest without prices)
+	Price          float64 // the hourly price in the catalog, 0: unknown
+	SizeScore      float64 // the sum of the excess rates of vCPU, memory and GPU, see getSizeScore.
+	VMSpecInfo     irs.VMSpecInfo
+}
+
+// catalog is the local file of the specs of a connection, {catalog path}/{connection name}.json
+// The prices are set by SetPrices and kept when the specs are refreshed.
+type catalog struct {
+	ConnectionName string
+	UpdatedTime    time.Time
+	Specs          []irs.VMSpecInfo
+	Prices         map[string]float64 `json:",omitempty"` // spec name: hourly price, ex) {"t3.micro": 0.013}
+}
+
+type SpecMatcher struct {
+	path  string
+	ttl   time.Duration
+	mutex sync.Mutex
+}
+
+// NewSpecMatcher keeps the catalogs in path, a catalog older than ttl is refreshed when the cloud can be called.
+func NewSpecMatcher(path string, ttl time.Duration) (*SpecMatcher, error) {
+	if path == "" {
+		return nil, errors.New("no path of the spec catalogs")
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	return &SpecMatcher{path: path, ttl: ttl}, nil
+}
+
+// OpenSpecMatcher keeps the catalogs in $CBSPIDER_PATH/catalog/spec with DefaultCatalogTTL.
+func OpenSpecMatcher() (*SpecMatcher, error) {
+	rootPath := os.Getenv("CBSPIDER_PATH")
+	return NewSpecMatcher(filepath.Join(rootPath, "catalog", "spec"), DefaultCatalogTTL)
+}
+
+// MatchSpecs matches the specs of each connection(connection name: VMSpecHandler) with the requirements.
+// A nil VMSpecHandler means offline, only the catalog of the connection is used.
+// A connection which fails(ex: no catalog and the cloud fails) is skipped, and returned with its error in the failed map.
+func (matcher *SpecMatcher) MatchSpecs(vmSpecHandlers map[string]irs.VMSpecHandler, requirement SpecRequirement) (map[string][]*MatchedSpec, map[string]error) {
+	result := map[string][]*MatchedSpec{}
+	failed := map[string]error{}
+	for connectionName, vmSpecHandler := range vmSpecHandlers {
+		matchedSpecs, err := matcher.MatchSpec(connectionName, vmSpecHandler, requirement)
+		if err != nil {
+			cblogger.Errorf("unable to match the specs of connection %s, %v", connectionName, err)
+			failed[connectionName] = err
+			continue
+		}
+		result[connectionName] = matchedSpecs
+	}
+	return result, failed
+}
+
+// MatchSpec returns the specs of a connection which meet the requirements, ranked from the cheapest.
+func (matcher *SpecMatcher) MatchSpec(connectionName string, vmSpecHandler irs.VMSpecHandler, requirement SpecRequirement) ([]*MatchedSpec, error) {
+	cat, err := matcher.getCatalog(connectionName, vmSpecHandler)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchedSpecs []*MatchedSpec
+	for _, spec := range cat.Specs {
+		score, ok := getSizeScore(spec, requirement)
+		if !ok {
+			continue
+		}
+		matchedSpecs = append(matchedSpecs, &MatchedSpec{
+			ConnectionName: connectionName,
+			Price:          cat.Prices[spec.Name],
+			SizeScore:      score,
+			VMSpecInfo:     spec,
+		})
+	}
+
+	sort.SliceStable(matchedSpecs, func(i, j int) bool {
+		a, b := matchedSpecs[i], matchedSpecs[j]
+		if (a.Price > 0) != (b.Price > 0) {
+			return a.Price > 0
+		}
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		if a.SizeScore != b.SizeScore {
+			return a.SizeScore < b.SizeScore
+		}
+		return a.VMSpecInfo.Name < b.VMSpecInfo.Name
+	})
+	for i, matchedSpec := range matchedSpecs {
+		matchedSpec.Rank = i + 1
+	}
+	return matchedSpecs, nil
+}
+
+// getSizeScore checks a spec with the requirements and returns how much it exceeds them, 0: exactly the requirements.
+// The architecture of a spec can be unknown(ex: Azure VM sizes), then it is matched with a penalty.
+func getSizeScore(spec irs.VMSpecInfo, requirement SpecRequirement) (float64, bool) {
+	if spec.VCpuCount < requirement.MinVCpuCount || spec.MemoryMiB < requirement.MinMemoryMiB || spec.GpuCount < requirement.MinGpuCount {
+		return 0, false
+	}
+
+	score := getExcess(float64(spec.VCpuCount), float64(requirement.MinVCpuCount), 1) +
+		getExcess(float64(spec.MemoryMiB), float64(requirement.MinMemoryMiB), 1024) +
+		getExcess(float64(spec.GpuCount), float64(requirement.MinGpuCount), 1)
+
+	if requirement.Architecture != "" {
+		if len(spec.Architectures) == 0 {
+			score += 1
+		} else if !contains(spec.Architectures, requirement.Architecture) {
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// getExcess returns the excess rate of a resource,
+// or the amount of it in unit(ex: 1024 MiB of memory) when it is not required.
+func getExcess(value float64, min float64, unit float64) float64 {
+	if min <= 0 {
+		return value / unit
+	}
+	return (value - min) / min
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCatalog returns the specs of a connection from the catalog, the catalog is refreshed when it is older than the TTL.
+// If the cloud fails or vmSpecHandler is nil(offline), the existing catalog is used even though it is old.
+func (matcher *SpecMatcher) GetCatalog(connectionName string, vmSpecHandler irs.VMSpecHandler) ([]irs.VMSpecInfo, error) {
+	cat, err := matcher.getCatalog(connectionName, vmSpecHandler)
+	if err != nil {
+		return nil, err
+	}
+	return cat.Specs, nil
+}
+
+func (matcher *SpecMatcher) getCatalog(connectionName string, vmSpecHandler irs.VMSpecHandler) (catalog, error) {
+	cat, err := matcher.readCatalog(connectionName)
+	if err != nil && !os.IsNotExist(err) {
+		return catalog{}, err
+	}
+	// a catalog with the prices only(see SetPrices) has no specs listed.
+	listed := err == nil && !cat.UpdatedTime.IsZero()
+	if listed && (vmSpecHandler == nil || time.Since(cat.UpdatedTime) < matcher.ttl) {
+		return cat, nil
+	}
+	if vmSpecHandler == nil {
+		return catalog{}, errors.New(fmt.Sprintf("no spec catalog of connection %s", connectionName))
+	}
+
+	newCat, refreshErr := matcher.refreshCatalog(connectionName, vmSpecHandler)
+	if refreshErr != nil {
+		if listed {
+			cblogger.Errorf("unable to refresh the spec catalog of connection %s, the old one is used, %v", connectionName, refreshErr)
+			return cat, nil
+		}
+		return catalog{}, refreshErr
+	}
+	return newCat, nil
+}
+
+// RefreshCatalog lists all the specs of a connection and saves them into the catalog, the prices are kept.
+func (matcher *SpecMatcher) RefreshCatalog(connectionName string, vmSpecHandler irs.VMSpecHandler) ([]irs.VMSpecInfo, error) {
+	cat, err := matcher.refreshCatalog(connectionName, vmSpecHandler)
+	if err != nil {
+		return nil, err
+	}
+	return cat.Specs, nil
+}
+
+func (matcher *SpecMatcher) refreshCatalog(connectionName string, vmSpecHandler irs.VMSpecHandler) (catalog, error) {
+	var specs []irs.VMSpecInfo
+
+	listReqInfo := irs.ListReqInfo{}
+	for {
+		list, pageInfo, err := vmSpecHandler.ListVMSpec(listReqInfo)
+		if err != nil {
+			return catalog{}, err
+		}
+		for _, spec := range list {
+			specs = append(specs, *spec)
+		}
+		if pageInfo.NextToken == "" || pageInfo.NextToken == listReqInfo.NextToken {
+			break
+		}
+		listReqInfo.NextToken = pageInfo.NextToken
+	}
+
+	oldCat, err := matcher.readCatalog(connectionName)
+	if err != nil && !os.IsNotExist(err) {
+		cblogger.Errorf("unable to read the prices of connection %s, they are not kept, %v", connectionName, err)
+	}
+	cat := catalog{
+		ConnectionName: connectionName,
+		UpdatedTime:    time.Now(),
+		Specs:          specs,
+		Prices:         oldCat.Prices,
+	}
+	if err := matcher.writeCatalog(cat); err != nil {
+		return catalog{}, err
+	}
+	return cat, nil
+}
+
+// SetPrices sets the hourly prices of the specs of a connection(spec name: price) in the catalog,
+// ex) from the price list of a cloud. The specs without a price are ranked after the others by MatchSpec.
+func (matcher *SpecMatcher) SetPrices(connectionName string, prices map[string]float64) error {
+	for specName, price := range prices {
+		if price <= 0 {
+			return errors.New(fmt.Sprintf("invalid price %v of spec %s", price, specName))
+		}
+	}
+	cat, err := matcher.readCatalog(connectionName)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// without a catalog, the specs are listed at the next match.
+	cat.ConnectionName = connectionName
+	cat.Prices = prices
+	return matcher.writeCatalog(cat)
+}
+
+// DeleteCatalog deletes the catalog of a connection, ex) when the connection is deleted.
+func (matcher *SpecMatcher) DeleteCatalog(connectionName string) error {
+	catalogPath, err := matcher.getCatalogPath(connectionName)
+	if err != nil {
+		return err
+	}
+
+	matcher.mutex.Lock()
+	defer matcher.mutex.Unlock()
+	if err := os.Remove(catalogPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// getCatalogPath returns the catalog file of a connection, the name is escaped not to be a path.
+func (matcher *SpecMatcher) getCatalogPath(connectionName string) (string, error) {
+	if connectionName == "" || connectionName == "." || connectionName == ".." {
+		return "", errors.New(fmt.Sprintf("invalid connection name %q", connectionName))
+	}
+	return filepath.Join(matcher.path, url.PathEscape(connectionName)+".json"), nil
+}
+
+func (matcher *SpecMatcher) readCatalog(connectionName string) (catalog, error) {
+	catalogPath, err := matcher.getCatalogPath(connectionName)
+	if err != nil {
+		return catalog{}, err
+	}
+
+	matcher.mutex.Lock()
+	defer matcher.mutex.Unlock()
+	data, err := ioutil.ReadFile(catalogPath)
+	if err != nil {
+		return catalog{}, err
+	}
+	var cat catalog
+	err = json.Unmarshal(data, &cat)
+	if err != nil {
+		return catalog{}, errors.New(fmt.Sprintf("invalid spec catalog %s, %v", catalogPath, err))
+	}
+	return cat, nil
+}
+
+// writeCatalog replaces a catalog with a temporary file not to break it on failures.
+func (matcher *SpecMatcher) writeCatalog(cat catalog) error {
+	catalogPath, err := matcher.getCatalogPath(cat.ConnectionName)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cat, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	matcher.mutex.Lock()
+	defer matcher.mutex.Unlock()
+	return fileutil.WriteFile(catalogPath, data)
+}
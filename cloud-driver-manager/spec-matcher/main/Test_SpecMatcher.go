@@ -0,0 +1,174 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a test of the Spec Matcher with local spec handlers instead of clouds.
+// The catalogs are kept in a temporary directory which is removed at the end.
+//
+//      $ go run Test_SpecMatcher.go
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	specmatcher "github.com/cloud-barista/poc-cb-spider/cloud-driver-manager/spec-matcher"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// localVMSpecHandler returns the specs in pages of 2 like a cloud, or err.
+type localVMSpecHandler struct {
+	specs []irs.VMSpecInfo
+	err   error
+}
+
+func (vmSpecHandler *localVMSpecHandler) ListVMSpec(listReqInfo irs.ListReqInfo) ([]*irs.VMSpecInfo, irs.ListPageInfo, error) {
+	if vmSpecHandler.err != nil {
+		return nil, irs.ListPageInfo{}, vmSpecHandler.err
+	}
+	listReqInfo.PageSize = 2
+	start, end, nextToken, err := irs.GetPageRange(len(vmSpecHandler.specs), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	var specList []*irs.VMSpecInfo
+	for i := start; i < end; i++ {
+		specList = append(specList, &vmSpecHandler.specs[i])
+	}
+	return specList, irs.ListPageInfo{NextToken: nextToken}, nil
+}
+
+func (vmSpecHandler *localVMSpecHandler) GetVMSpec(specID string) (irs.VMSpecInfo, error) {
+	for _, spec := range vmSpecHandler.specs {
+		if spec.Id == specID {
+			return spec, nil
+		}
+	}
+	return irs.VMSpecInfo{}, errors.New("no spec: " + specID)
+}
+
+func check(name string, err error) bool {
+	if err != nil {
+		fmt.Printf("[FAIL] %s: %v\n", name, err)
+		return false
+	}
+	fmt.Printf("[OK] %s\n", name)
+	return true
+}
+
+// checkRanks checks the names of the matched specs in the order of the ranks.
+func checkRanks(matchedSpecs []*specmatcher.MatchedSpec, names ...string) error {
+	var got []string
+	for i, matchedSpec := range matchedSpecs {
+		if matchedSpec.Rank != i+1 {
+			return errors.New(fmt.Sprintf("rank %d of %s", matchedSpec.Rank, matchedSpec.VMSpecInfo.Name))
+		}
+		got = append(got, matchedSpec.VMSpecInfo.Name)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(names) {
+		return errors.New(fmt.Sprintf("want %v, got %v", names, got))
+	}
+	return nil
+}
+
+func main() {
+	catalogPath, err := ioutil.TempDir("", "spec-catalog-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(catalogPath)
+
+	matcher, err := specmatcher.NewSpecMatcher(catalogPath, time.Hour)
+	if err != nil {
+		panic(err)
+	}
+	awsHandler := &localVMSpecHandler{specs: []irs.VMSpecInfo{
+		{Name: "t3.micro", Id: "t3.micro", VCpuCount: 2, MemoryMiB: 1024, Architectures: []string{"x86_64"}},
+		{Name: "m5.xlarge", Id: "m5.xlarge", VCpuCount: 4, MemoryMiB: 16384, Architectures: []string{"x86_64"}},
+		{Name: "m5.large", Id: "m5.large", VCpuCount: 2, MemoryMiB: 8192, Architectures: []string{"x86_64"}},
+		{Name: "m6g.large", Id: "m6g.large", VCpuCount: 2, MemoryMiB: 8192, Architectures: []string{"arm64"}},
+		{Name: "p3.2xlarge", Id: "p3.2xlarge", VCpuCount: 8, MemoryMiB: 62464, GpuCount: 1, Architectures: []string{"x86_64"}},
+	}}
+	azureHandler := &localVMSpecHandler{specs: []irs.VMSpecInfo{
+		{Name: "Standard_D2s_v3", Id: "Standard_D2s_v3", VCpuCount: 2, MemoryMiB: 8192},
+		{Name: "Standard_B1ls", Id: "Standard_B1ls", VCpuCount: 1, MemoryMiB: 512},
+	}}
+	requirement := specmatcher.SpecRequirement{MinVCpuCount: 2, MinMemoryMiB: 8192, Architecture: "x86_64"}
+	ok := true
+
+	// MatchSpec: the specs over the requirements, the smallest first without prices.
+	matchedSpecs, err := matcher.MatchSpec("aws-config", awsHandler, requirement)
+	if err == nil {
+		err = checkRanks(matchedSpecs, "m5.large", "m5.xlarge", "p3.2xlarge")
+	}
+	ok = check("MatchSpec", err) && ok
+
+	// the unknown architecture(Azure) is matched with a penalty.
+	matchedSpecs, err = matcher.MatchSpec("azure-config", azureHandler, requirement)
+	if err == nil {
+		err = checkRanks(matchedSpecs, "Standard_D2s_v3")
+	}
+	ok = check("MatchSpec without architectures", err) && ok
+
+	// MatchSpecs: the offline connection uses its catalog, the failed connection is skipped and reported.
+	vmSpecHandlers := map[string]irs.VMSpecHandler{
+		"aws-config":       nil,
+		"openstack-config": &localVMSpecHandler{err: errors.New("connection refused")},
+	}
+	result, failed := matcher.MatchSpecs(vmSpecHandlers, requirement)
+	err = checkRanks(result["aws-config"], "m5.large", "m5.xlarge", "p3.2xlarge")
+	if err == nil && (len(failed) != 1 || failed["openstack-config"] == nil) {
+		err = errors.New(fmt.Sprintf("unexpected failed connections %v", failed))
+	}
+	if _, exist := result["openstack-config"]; err == nil && exist {
+		err = errors.New("the failed connection is in the result")
+	}
+	ok = check("MatchSpecs", err) && ok
+
+	// SetPrices: the priced specs first from the cheapest, the others by the size, the prices are kept by RefreshCatalog.
+	err = matcher.SetPrices("aws-config", map[string]float64{"m5.large": 0.096, "m5.xlarge": 0.08, "t3.micro": 0.0104})
+	if err == nil {
+		_, err = matcher.RefreshCatalog("aws-config", awsHandler)
+	}
+	if err == nil {
+		matchedSpecs, err = matcher.MatchSpec("aws-config", awsHandler, requirement)
+	}
+	if err == nil {
+		err = checkRanks(matchedSpecs, "m5.xlarge", "m5.large", "p3.2xlarge")
+	}
+	if err == nil && (matchedSpecs[0].Price != 0.08 || matchedSpecs[2].Price != 0) {
+		err = errors.New(fmt.Sprintf("unexpected prices %v, %v", matchedSpecs[0].Price, matchedSpecs[2].Price))
+	}
+	ok = check("MatchSpec with prices", err) && ok
+
+	// a catalog with the prices only is not used offline.
+	err = matcher.SetPrices("gcp-config", map[string]float64{"e2-small": 0.017})
+	if err == nil {
+		if _, matchErr := matcher.MatchSpec("gcp-config", nil, requirement); matchErr == nil {
+			err = errors.New("the catalog without specs is used")
+		}
+	}
+	ok = check("SetPrices without a catalog", err) && ok
+
+	// GetCatalog: the old catalog is used when the cloud fails.
+	awsHandler.err = errors.New("connection refused")
+	oldMatcher, err := specmatcher.NewSpecMatcher(catalogPath, 0)
+	if err == nil {
+		var specs []irs.VMSpecInfo
+		specs, err = oldMatcher.GetCatalog("aws-config", awsHandler)
+		if err == nil && len(specs) != len(awsHandler.specs) {
+			err = errors.New(fmt.Sprintf("%d specs in the catalog", len(specs)))
+		}
+	}
+	ok = check("GetCatalog with the old catalog", err) && ok
+
+	if !ok {
+		os.Exit(1)
+	}
+}
@@ -17,12 +17,19 @@ import (
 	acon "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/aws/connect"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	icon "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/connect"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+import (
+	"errors"
+	"fmt"
 )
-import "fmt"
 
 type AwsDriver struct {
 }
@@ -45,26 +52,50 @@ func (AwsDriver) GetDriverCapability() idrv.DriverCapabilityInfo {
 	return drvCapabilityInfo
 }
 
-func getVMClient(regionInfo idrv.RegionInfo) (*ec2.EC2, error) {
+// getSession builds an AWS session for the region of the connection.
+// Credentials are taken from the access key(+ session token) of the connection's credential,
+// then from the named profile, then from the default credential chain(env, shared config, instance role).
+// If RoleArn is given, the resolved credentials are used to assume that role.
+func getSession(connectionInfo idrv.ConnectionInfo) (*session.Session, error) {
+	credentialInfo := connectionInfo.CredentialInfo
+	regionInfo := connectionInfo.RegionInfo
+
 	// setup Region
-	fmt.Println("AwsDriver : getVMClient() - Region : [" + regionInfo.Region + "]")
+	fmt.Println("AwsDriver : getSession() - Region : [" + regionInfo.Region + "]")
+
+	opts := session.Options{
+		Config: aws.Config{
+			Region: aws.String(regionInfo.Region),
+		},
+	}
 
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(regionInfo.Region)},
-	)
+	switch {
+	case credentialInfo.ClientId != "" || credentialInfo.ClientSecret != "":
+		if credentialInfo.ClientId == "" || credentialInfo.ClientSecret == "" {
+			return nil, errors.New("AWS access key ID and secret access key must be given together")
+		}
+		opts.Config.Credentials = credentials.NewStaticCredentials(credentialInfo.ClientId, credentialInfo.ClientSecret, credentialInfo.SessionToken)
+	case credentialInfo.SessionToken != "":
+		return nil, errors.New("AWS session token requires access key ID and secret access key")
+	case credentialInfo.Profile != "":
+		opts.Profile = credentialInfo.Profile
+		opts.SharedConfigState = session.SharedConfigEnable
+	}
+
+	sess, err := session.NewSessionWithOptions(opts)
 	if err != nil {
 		fmt.Println("Could not create aws New Session", err)
 		return nil, err
 	}
 
-	// Create EC2 service client
-	svc := ec2.New(sess)
-	if err != nil {
-		fmt.Println("Could not create EC2 service client", err)
-		return nil, err
+	if credentialInfo.RoleArn != "" {
+		fmt.Println("AwsDriver : getSession() - Assume Role : [" + credentialInfo.RoleArn + "]")
+		sess = sess.Copy(&aws.Config{
+			Credentials: stscreds.NewCredentials(sess, credentialInfo.RoleArn),
+		})
 	}
 
-	return svc, nil
+	return sess, nil
 }
 
 func (driver *AwsDriver) ConnectCloud(connectionInfo idrv.ConnectionInfo) (icon.CloudConnection, error) {
@@ -73,25 +104,26 @@ func (driver *AwsDriver) ConnectCloud(connectionInfo idrv.ConnectionInfo) (icon.
 	// 3. create CloudConnection Instance of "connect/TDA_CloudConnection".
 	// 4. return CloudConnection Interface of TDA_CloudConnection.
 
-	// sample code, do not user like this^^
-	//var iConn icon.CloudConnection
-	//VMClient, err := getVMClient(connectionInfo.CredentialInfo)
-	vmClient, err := getVMClient(connectionInfo.RegionInfo)
+	sess, err := getSession(connectionInfo)
 	if err != nil {
 		return nil, err
 	}
 
-	//iConn = acon.AwsCloudConnection{}
+	// one client per service, shared by all handlers of this connection.
 	iConn := acon.AwsCloudConnection{
-		Region:        connectionInfo.RegionInfo,
-		VMClient:      vmClient,
-		KeyPairClient: vmClient,
-
-		VNetworkClient: vmClient,
-		VNicClient:     vmClient,
-		ImageClient:    vmClient,
-		PublicIPClient: vmClient,
-		SecurityClient: vmClient,
+		Region:    connectionInfo.RegionInfo,
+		EC2Client: ec2.New(sess),
+		STSClient: sts.New(sess),
+	}
+
+	// the region(and zone) typed in the connection must be available.
+	regionZoneHandler, err := iConn.CreateRegionZoneHandler()
+	if err != nil {
+		return nil, err
+	}
+	err = irs.ValidateRegionZone(regionZoneHandler, connectionInfo.RegionInfo.Region, connectionInfo.RegionInfo.Zone)
+	if err != nil {
+		return nil, err
 	}
 
 	return &iConn, nil // return type: (icon.CloudConnection, error)
@@ -20,19 +20,16 @@ import (
 
 	//ec2drv "github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
 )
 
 //type AwsCloudConnection struct{}
+// Every EC2 resource handler shares the same EC2Client.
+// STSClient is only used to validate the credential.
 type AwsCloudConnection struct {
-	Region        idrv.RegionInfo
-	KeyPairClient *ec2.EC2
-	VMClient      *ec2.EC2
-
-	VNetworkClient *ec2.EC2
-	VNicClient     *ec2.EC2
-	ImageClient    *ec2.EC2
-	PublicIPClient *ec2.EC2
-	SecurityClient *ec2.EC2
+	Region    idrv.RegionInfo
+	EC2Client *ec2.EC2
+	STSClient *sts.STS
 }
 
 var cblogger *logrus.Logger
@@ -45,7 +42,7 @@ func init() {
 func (cloudConn *AwsCloudConnection) CreateKeyPairHandler() (irs.KeyPairHandler, error) {
 	cblogger.Info("Start CreateKeyPairHandler()")
 
-	keyPairHandler := ars.AwsKeyPairHandler{cloudConn.Region, cloudConn.KeyPairClient}
+	keyPairHandler := ars.AwsKeyPairHandler{cloudConn.Region, cloudConn.EC2Client}
 
 	return &keyPairHandler, nil
 }
@@ -53,11 +50,21 @@ func (cloudConn *AwsCloudConnection) CreateKeyPairHandler() (irs.KeyPairHandler,
 func (cloudConn *AwsCloudConnection) CreateVMHandler() (irs.VMHandler, error) {
 	cblogger.Info("Start CreateVMHandler()")
 
-	vmHandler := ars.AwsVMHandler{cloudConn.Region, cloudConn.VMClient}
+	vmHandler := ars.AwsVMHandler{cloudConn.Region, cloudConn.EC2Client}
 	return &vmHandler, nil
 }
 
+// IsConnected checks the credential of this connection with the STS GetCallerIdentity call.
 func (cloudConn *AwsCloudConnection) IsConnected() (bool, error) {
+	cblogger.Info("Start IsConnected()")
+
+	result, err := cloudConn.STSClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		cblogger.Errorf("Could not validate the AWS credential, %v", err)
+		return false, err
+	}
+
+	cblogger.Infof("Connected - Account : [%s], Arn : [%s]", *result.Account, *result.Arn)
 	return true, nil
 }
 func (cloudConn *AwsCloudConnection) Close() error {
@@ -66,34 +73,76 @@ func (cloudConn *AwsCloudConnection) Close() error {
 
 func (cloudConn *AwsCloudConnection) CreateVNetworkHandler() (irs.VNetworkHandler, error) {
 	cblogger.Info("Start")
-	handler := ars.AwsVNetworkHandler{cloudConn.Region, cloudConn.KeyPairClient}
+	handler := ars.AwsVNetworkHandler{cloudConn.Region, cloudConn.EC2Client}
+
+	return &handler, nil
+}
+
+func (cloudConn *AwsCloudConnection) CreateSubnetHandler() (irs.SubnetHandler, error) {
+	cblogger.Info("Start")
+	handler := ars.AwsSubnetHandler{cloudConn.Region, cloudConn.EC2Client}
 
 	return &handler, nil
 }
 
 func (cloudConn *AwsCloudConnection) CreateImageHandler() (irs.ImageHandler, error) {
 	cblogger.Info("Start")
-	handler := ars.AwsImageHandler{cloudConn.Region, cloudConn.KeyPairClient}
+	handler := ars.AwsImageHandler{cloudConn.Region, cloudConn.EC2Client}
 
 	return &handler, nil
 }
 
 func (cloudConn *AwsCloudConnection) CreateSecurityHandler() (irs.SecurityHandler, error) {
 	cblogger.Info("Start")
-	handler := ars.AwsSecurityHandler{cloudConn.Region, cloudConn.KeyPairClient}
+	handler := ars.AwsSecurityHandler{cloudConn.Region, cloudConn.EC2Client}
 
 	return &handler, nil
 }
 
 func (cloudConn *AwsCloudConnection) CreateVNicHandler() (irs.VNicHandler, error) {
 	cblogger.Info("Start")
-	handler := ars.AwsVNicHandler{cloudConn.Region, cloudConn.KeyPairClient}
+	handler := ars.AwsVNicHandler{cloudConn.Region, cloudConn.EC2Client}
 
 	return &handler, nil
 }
 func (cloudConn *AwsCloudConnection) CreatePublicIPHandler() (irs.PublicIPHandler, error) {
 	cblogger.Info("Start")
-	handler := ars.AwsPublicIPHandler{cloudConn.Region, cloudConn.KeyPairClient}
+	handler := ars.AwsPublicIPHandler{cloudConn.Region, cloudConn.EC2Client}
+
+	return &handler, nil
+}
+
+func (cloudConn *AwsCloudConnection) CreateRouterHandler() (irs.RouterHandler, error) {
+	cblogger.Info("Start")
+	handler := ars.AwsRouterHandler{cloudConn.Region, cloudConn.EC2Client}
+
+	return &handler, nil
+}
+
+func (cloudConn *AwsCloudConnection) CreateVMSpecHandler() (irs.VMSpecHandler, error) {
+	cblogger.Info("Start")
+	handler := ars.AwsVMSpecHandler{cloudConn.Region, cloudConn.EC2Client}
+
+	return &handler, nil
+}
+
+func (cloudConn *AwsCloudConnection) CreateRegionZoneHandler() (irs.RegionZoneHandler, error) {
+	cblogger.Info("Start")
+	handler := ars.AwsRegionZoneHandler{cloudConn.Region, cloudConn.EC2Client}
+
+	return &handler, nil
+}
+
+func (cloudConn *AwsCloudConnection) CreateDiskHandler() (irs.DiskHandler, error) {
+	cblogger.Info("Start")
+	handler := ars.AwsDiskHandler{cloudConn.Region, cloudConn.EC2Client}
+
+	return &handler, nil
+}
+
+func (cloudConn *AwsCloudConnection) CreateSnapshotHandler() (irs.SnapshotHandler, error) {
+	cblogger.Info("Start")
+	handler := ars.AwsSnapshotHandler{cloudConn.Region, cloudConn.EC2Client}
 
 	return &handler, nil
 }
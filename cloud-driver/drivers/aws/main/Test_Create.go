@@ -64,7 +64,7 @@ func createVM() {
 			cblogger.Infof(keyPairName, " 키 페어 생성 실패 : ", err)
 			return
 		} else {
-			cblogger.Infof("[%s] 키 페어 생성 결과 : [%s]", keyPairName, keyPairInfo)
+			cblogger.Infof("[%s] 키 페어 생성 결과 : [%s]", keyPairName, keyPairInfo.Fingerprint) // PrivateKey는 로그에 남기지 않음
 		}
 	} else {
 		cblogger.Infof("[%s] 키 페어 조회 결과 : [%s]", keyPairName, keyPairInfo)
@@ -174,7 +174,10 @@ func handleVM() {
 
 			case 8:
 				cblogger.Debug("Start ListVMStatus ...")
-				vmStatusInfos := vmHandler.ListVMStatus()
+				vmStatusInfos, _, err := vmHandler.ListVMStatus(irs.ListReqInfo{})
+				if err != nil {
+					cblogger.Error(err)
+				}
 				cblogger.Info("리턴 값")
 				cblogger.Info(vmStatusInfos)
 				spew.Dump(vmStatusInfos)
@@ -182,7 +185,10 @@ func handleVM() {
 
 			case 9:
 				cblogger.Debug("Start ListVM ...")
-				vmInfos := vmHandler.ListVM()
+				vmInfos, _, err := vmHandler.ListVM(irs.ListReqInfo{})
+				if err != nil {
+					cblogger.Error(err)
+				}
 				cblogger.Info("=========== VM 목록 ================")
 				spew.Dump(vmInfos)
 				cblogger.Debug("Finish ListVM")
@@ -210,6 +216,9 @@ func setKeyPairHandler() (irs.KeyPairHandler, error) {
 		CredentialInfo: idrv.CredentialInfo{
 			ClientId:     config.Aws.AawsAccessKeyID,
 			ClientSecret: config.Aws.AwsSecretAccessKey,
+			SessionToken: config.Aws.AwsSessionToken,
+			Profile:      config.Aws.Profile,
+			RoleArn:      config.Aws.RoleArn,
 		},
 		RegionInfo: idrv.RegionInfo{
 			Region: config.Aws.Region,
@@ -237,6 +246,9 @@ func setVMHandler() (irs.VMHandler, error) {
 		CredentialInfo: idrv.CredentialInfo{
 			ClientId:     config.Aws.AawsAccessKeyID,
 			ClientSecret: config.Aws.AwsSecretAccessKey,
+			SessionToken: config.Aws.AwsSessionToken,
+			Profile:      config.Aws.Profile,
+			RoleArn:      config.Aws.RoleArn,
 		},
 		RegionInfo: idrv.RegionInfo{
 			Region: config.Aws.Region,
@@ -269,6 +281,9 @@ type Config struct {
 	Aws struct {
 		AawsAccessKeyID    string `yaml:"aws_access_key_id"`
 		AwsSecretAccessKey string `yaml:"aws_secret_access_key"`
+		AwsSessionToken    string `yaml:"aws_session_token"`
+		Profile            string `yaml:"profile"`
+		RoleArn            string `yaml:"role_arn"`
 		Region             string `yaml:"region"`
 
 		ImageID string `yaml:"image_id"`
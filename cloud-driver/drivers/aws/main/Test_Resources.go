@@ -45,11 +45,17 @@ func handlePublicIP() {
 	handler := ResourceHandler.(irs.PublicIPHandler)
 
 	config := readConfigFile()
-	publicIPReqInfo := irs.PublicIPReqInfo{
-		Id: config.Aws.VmID,
+	publicIPReqInfo := irs.PublicIPReqInfo{}
+	publicIP, err := handler.CreatePublicIP(publicIPReqInfo)
+	if err != nil {
+		panic(err)
 	}
-	handler.CreatePublicIP(publicIPReqInfo)
 
+	// EC2에 할당
+	_, err = handler.AssociatePublicIP(publicIP.Id, config.Aws.VmID)
+	if err != nil {
+		panic(err)
+	}
 }
 
 // Test KeyPair
@@ -86,7 +92,7 @@ func handleKeyPair() {
 				return
 
 			case 1:
-				result, err := KeyPairHandler.ListKey()
+				result, _, err := KeyPairHandler.ListKey(irs.ListReqInfo{})
 				if err != nil {
 					cblogger.Infof(" 키 페어 목록 조회 실패 : ", err)
 				} else {
@@ -104,7 +110,7 @@ func handleKeyPair() {
 				if err != nil {
 					cblogger.Infof(keyPairName, " 키 페어 생성 실패 : ", err)
 				} else {
-					cblogger.Infof("[%s] 키 페어 생성 결과 : [%s]", keyPairName, result)
+					cblogger.Infof("[%s] 키 페어 생성 결과 : [%s]", keyPairName, result.Fingerprint) // PrivateKey는 로그에 남기지 않음
 				}
 			case 3:
 				cblogger.Infof("[%s] 키 페어 조회 테스트", keyPairName)
@@ -158,7 +164,7 @@ func handleVNetwork() {
 				return
 
 			case 1:
-				result, err := vNetworkHandler.ListVNetwork()
+				result, _, err := vNetworkHandler.ListVNetwork(irs.ListReqInfo{})
 				if err != nil {
 					cblogger.Infof(" VNetwork 목록 조회 실패 : ", err)
 				} else {
@@ -169,7 +175,13 @@ func handleVNetwork() {
 
 			case 2:
 				cblogger.Infof("[%s] VNetwork 생성 테스트", keyId)
-				vNetworkReqInfo := irs.VNetworkReqInfo{}
+				vNetworkReqInfo := irs.VNetworkReqInfo{
+					Name:            "cb-vnet",
+					AddressPrefixes: []string{"10.0.0.0/16"},
+					Subnets: []irs.SubnetReqInfo{
+						{Name: "cb-subnet", CIDR: "10.0.1.0/24"},
+					},
+				}
 				result, err := vNetworkHandler.CreateVNetwork(vNetworkReqInfo)
 				if err != nil {
 					cblogger.Infof(keyId, " VNetwork 생성 실패 : ", err)
@@ -231,6 +243,9 @@ func getResourceHandler(handlerType string) (interface{}, error) {
 		CredentialInfo: idrv.CredentialInfo{
 			ClientId:     config.Aws.AawsAccessKeyID,
 			ClientSecret: config.Aws.AwsSecretAccessKey,
+			SessionToken: config.Aws.AwsSessionToken,
+			Profile:      config.Aws.Profile,
+			RoleArn:      config.Aws.RoleArn,
 		},
 		RegionInfo: idrv.RegionInfo{
 			Region: config.Aws.Region,
@@ -273,6 +288,9 @@ func setKeyPairHandler() (irs.KeyPairHandler, error) {
 		CredentialInfo: idrv.CredentialInfo{
 			ClientId:     config.Aws.AawsAccessKeyID,
 			ClientSecret: config.Aws.AwsSecretAccessKey,
+			SessionToken: config.Aws.AwsSessionToken,
+			Profile:      config.Aws.Profile,
+			RoleArn:      config.Aws.RoleArn,
 		},
 		RegionInfo: idrv.RegionInfo{
 			Region: config.Aws.Region,
@@ -300,6 +318,9 @@ func setVNetworkHandler() (irs.VNetworkHandler, error) {
 		CredentialInfo: idrv.CredentialInfo{
 			ClientId:     config.Aws.AawsAccessKeyID,
 			ClientSecret: config.Aws.AwsSecretAccessKey,
+			SessionToken: config.Aws.AwsSessionToken,
+			Profile:      config.Aws.Profile,
+			RoleArn:      config.Aws.RoleArn,
 		},
 		RegionInfo: idrv.RegionInfo{
 			Region: config.Aws.Region,
@@ -332,6 +353,9 @@ type Config struct {
 	Aws struct {
 		AawsAccessKeyID    string `yaml:"aws_access_key_id"`
 		AwsSecretAccessKey string `yaml:"aws_secret_access_key"`
+		AwsSessionToken    string `yaml:"aws_session_token"`
+		Profile            string `yaml:"profile"`
+		RoleArn            string `yaml:"role_arn"`
 		Region             string `yaml:"region"`
 
 		ImageID string `yaml:"image_id"`
@@ -0,0 +1,86 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a golden file check of the VMInfo of the AWS Driver.
+// The recorded EC2 responses of testdata/vminfo are replayed by a local server,
+// the results of GetVM and ListVM are compared with testdata/vminfo/*.golden.json.
+//
+//      $ go run Test_VMInfo.go           # check
+//      $ go run Test_VMInfo.go -update   # rewrite the golden files
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	ars "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/aws/resources"
+	"github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/golden"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// replays testdata/vminfo/{Action}.xml or {Action}-{InstanceId}.xml
+func newEc2Server(dataPath string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		name := r.Form.Get("Action")
+		if id := r.Form.Get("InstanceId.1"); id != "" {
+			name += "-" + id
+		}
+		body, err := ioutil.ReadFile(filepath.Join(dataPath, name+".xml"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write(body)
+	}))
+}
+
+func main() {
+	flag.Parse()
+	// StartTime은 서버 위치의 Timezone을 따르므로 UTC로 고정함.
+	time.Local = time.UTC
+
+	dataPath := golden.GetDataPath("aws", "vminfo")
+	server := newEc2Server(dataPath)
+	defer server.Close()
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("ap-northeast-2"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+	}))
+	vmHandler := ars.AwsVMHandler{
+		Region: idrv.RegionInfo{Region: "ap-northeast-2"},
+		Client: ec2.New(sess),
+	}
+
+	ok := golden.Check(dataPath, "GetVM", vmHandler.GetVM("i-0a1b2c3d4e5f60718"))
+
+	vmList, _, err := vmHandler.ListVM(irs.ListReqInfo{})
+	if err != nil {
+		fmt.Printf("[FAIL] ListVM: %v\n", err)
+		ok = false
+	} else {
+		ok = golden.Check(dataPath, "ListVM", vmList) && ok
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,124 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is common functions of AWS resource handlers.
+
+package resources
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// getListFilters converts the name/tag filters of listReqInfo into EC2 filters.
+// nameKey is the filter name of the resource's name. ex) "tag:Name", "key-name", "group-name"
+func getListFilters(listReqInfo irs.ListReqInfo, nameKey string) []*ec2.Filter {
+	var filters []*ec2.Filter
+
+	if listReqInfo.NameFilter != "" {
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String(nameKey),
+			Values: []*string{aws.String(listReqInfo.NameFilter)},
+		})
+	}
+
+	for key, value := range listReqInfo.TagFilter {
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []*string{aws.String(value)},
+		})
+	}
+
+	return filters
+}
+
+// getMaxResults converts PageSize into MaxResults of EC2 Describe* calls.
+// nil(PageSize 0) lets EC2 decide the page size.
+func getMaxResults(pageSize int, min int64, max int64) *int64 {
+	if pageSize <= 0 {
+		return nil
+	}
+
+	maxResults := int64(pageSize)
+	if maxResults < min {
+		maxResults = min
+	}
+	if maxResults > max {
+		maxResults = max
+	}
+	return aws.Int64(maxResults)
+}
+
+// getNextToken converts the continuation token of listReqInfo for EC2 Describe* calls.
+func getNextToken(listReqInfo irs.ListReqInfo) *string {
+	if listReqInfo.NextToken == "" {
+		return nil
+	}
+	return aws.String(listReqInfo.NextToken)
+}
+
+// getNameTag returns the value of the "Name" tag.
+func getNameTag(tags []*ec2.Tag) string {
+	for _, t := range tags {
+		if aws.StringValue(t.Key) == "Name" {
+			return aws.StringValue(t.Value)
+		}
+	}
+	return ""
+}
+
+// getTags converts EC2 tags into the tags of irs.
+// The "Name" tag is the name of the resource, so it is not included.
+func getTags(tags []*ec2.Tag) map[string]string {
+	result := map[string]string{}
+	for _, t := range tags {
+		key := aws.StringValue(t.Key)
+		if key == "Name" {
+			continue
+		}
+		result[key] = aws.StringValue(t.Value)
+	}
+	return result
+}
+
+// getEc2Tags converts the name and tags of a request into EC2 tags.
+// name is kept in the "Name" tag, so "Name" can not be used as a key of tags.
+func getEc2Tags(name string, tags map[string]string) ([]*ec2.Tag, error) {
+	if _, ok := tags["Name"]; ok {
+		return nil, errors.New("tag key \"Name\" is reserved for the name of the resource")
+	}
+
+	var ec2Tags []*ec2.Tag
+	if name != "" {
+		ec2Tags = append(ec2Tags, &ec2.Tag{Key: aws.String("Name"), Value: aws.String(name)})
+	}
+
+	// sorted for the same request every time.
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ec2Tags = append(ec2Tags, &ec2.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	return ec2Tags, nil
+}
+
+// getTime parses a RFC3339 time string of EC2(ex: CreationDate of an image) into the local time.
+// The zero time is returned for an empty or unknown string.
+func getTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.Local()
+}
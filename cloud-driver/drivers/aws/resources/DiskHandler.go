@@ -0,0 +1,249 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// AwsDiskHandler handles EBS volumes.
+type AwsDiskHandler struct {
+	Region idrv.RegionInfo
+	Client *ec2.EC2
+}
+
+// EBS volumes are zonal, the Zone of the connection is used when the Zone of diskReqInfo is not given.
+func (diskHandler *AwsDiskHandler) CreateDisk(diskReqInfo irs.DiskReqInfo) (irs.DiskInfo, error) {
+	cblogger.Info(diskReqInfo)
+	if err := irs.CheckDiskReqInfo(diskReqInfo); err != nil {
+		return irs.DiskInfo{}, err
+	}
+	zone := diskReqInfo.Zone
+	if zone == "" {
+		zone = diskHandler.Region.Zone
+	}
+	if zone == "" {
+		return irs.DiskInfo{}, errors.New("the zone of EBS volume " + diskReqInfo.Name + " is required")
+	}
+
+	ec2Tags, err := getEc2Tags(diskReqInfo.Name, diskReqInfo.Tags)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	input := &ec2.CreateVolumeInput{
+		AvailabilityZone: aws.String(zone),
+		Size:             aws.Int64(int64(diskReqInfo.SizeGiB)),
+	}
+	if diskReqInfo.DiskType != "" {
+		input.VolumeType = aws.String(diskReqInfo.DiskType)
+	}
+	if len(ec2Tags) != 0 {
+		input.TagSpecifications = []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeVolume),
+				Tags:         ec2Tags,
+			},
+		}
+	}
+
+	volume, err := diskHandler.Client.CreateVolume(input)
+	if err != nil {
+		cblogger.Errorf("Unable to create volume %s, %v", diskReqInfo.Name, err)
+		return irs.DiskInfo{}, err
+	}
+
+	err = diskHandler.Client.WaitUntilVolumeAvailable(&ec2.DescribeVolumesInput{VolumeIds: []*string{volume.VolumeId}})
+	if err != nil {
+		cblogger.Errorf("failed to wait until volume %s is available, %v", aws.StringValue(volume.VolumeId), err)
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(aws.StringValue(volume.VolumeId))
+}
+
+func (diskHandler *AwsDiskHandler) ListDisk(listReqInfo irs.ListReqInfo) ([]*irs.DiskInfo, irs.ListPageInfo, error) {
+	cblogger.Info("Start : ", listReqInfo)
+	var diskList []*irs.DiskInfo
+
+	input := &ec2.DescribeVolumesInput{
+		Filters:    getListFilters(listReqInfo, "tag:Name"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 500),
+		NextToken:  getNextToken(listReqInfo),
+	}
+
+	result, err := diskHandler.Client.DescribeVolumes(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get volumes, %v", err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, volume := range result.Volumes {
+		diskInfo := extractVolume(volume)
+		diskList = append(diskList, &diskInfo)
+	}
+
+	return diskList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
+}
+
+func (diskHandler *AwsDiskHandler) GetDisk(diskID string) (irs.DiskInfo, error) {
+	cblogger.Infof("diskID : [%s]", diskID)
+	volume, err := diskHandler.getVolume(diskID)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return extractVolume(volume), nil
+}
+
+func (diskHandler *AwsDiskHandler) getVolume(volumeID string) (*ec2.Volume, error) {
+	result, err := diskHandler.Client.DescribeVolumes(&ec2.DescribeVolumesInput{
+		VolumeIds: []*string{aws.String(volumeID)},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get volume %s, %v", volumeID, err)
+		return nil, err
+	}
+	if len(result.Volumes) == 0 {
+		return nil, errors.New("volume not found: " + volumeID)
+	}
+	return result.Volumes[0], nil
+}
+
+// EBS volumes are resized online(ModifyVolume), the file system in the VM should be extended by the user.
+func (diskHandler *AwsDiskHandler) ResizeDisk(diskID string, sizeGiB int) (irs.DiskInfo, error) {
+	cblogger.Infof("diskID : [%s], sizeGiB : [%d]", diskID, sizeGiB)
+	volume, err := diskHandler.getVolume(diskID)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	if int64(sizeGiB) <= aws.Int64Value(volume.Size) {
+		return irs.DiskInfo{}, errors.New(fmt.Sprintf("volume %s can only grow, current: %d GiB, requested: %d GiB", diskID, aws.Int64Value(volume.Size), sizeGiB))
+	}
+
+	_, err = diskHandler.Client.ModifyVolume(&ec2.ModifyVolumeInput{
+		VolumeId: aws.String(diskID),
+		Size:     aws.Int64(int64(sizeGiB)),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to resize volume %s, %v", diskID, err)
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(diskID)
+}
+
+func (diskHandler *AwsDiskHandler) DeleteDisk(diskID string) (bool, error) {
+	cblogger.Infof("diskID : [%s]", diskID)
+	_, err := diskHandler.Client.DeleteVolume(&ec2.DeleteVolumeInput{
+		VolumeId: aws.String(diskID),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to delete volume %s, %v", diskID, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// The device name of a volume is the first one not used by the instance from /dev/sdf.
+func (diskHandler *AwsDiskHandler) AttachDisk(diskID string, vmID string) (irs.DiskInfo, error) {
+	cblogger.Infof("diskID : [%s], vmID : [%s]", diskID, vmID)
+	result, err := diskHandler.Client.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(vmID)},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get instance %s, %v", vmID, err)
+		return irs.DiskInfo{}, err
+	}
+	if len(result.Reservations) == 0 || len(result.Reservations[0].Instances) == 0 {
+		return irs.DiskInfo{}, errors.New("instance not found: " + vmID)
+	}
+	usedDevices := map[string]bool{}
+	for _, mapping := range result.Reservations[0].Instances[0].BlockDeviceMappings {
+		usedDevices[aws.StringValue(mapping.DeviceName)] = true
+	}
+	device, err := getDeviceName(usedDevices)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	_, err = diskHandler.Client.AttachVolume(&ec2.AttachVolumeInput{
+		Device:     aws.String(device),
+		InstanceId: aws.String(vmID),
+		VolumeId:   aws.String(diskID),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to attach volume %s to %s, %v", diskID, vmID, err)
+		return irs.DiskInfo{}, err
+	}
+
+	err = diskHandler.Client.WaitUntilVolumeInUse(&ec2.DescribeVolumesInput{VolumeIds: []*string{aws.String(diskID)}})
+	if err != nil {
+		cblogger.Errorf("failed to wait until volume %s is in use, %v", diskID, err)
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(diskID)
+}
+
+func (diskHandler *AwsDiskHandler) DetachDisk(diskID string, vmID string) (irs.DiskInfo, error) {
+	cblogger.Infof("diskID : [%s], vmID : [%s]", diskID, vmID)
+	_, err := diskHandler.Client.DetachVolume(&ec2.DetachVolumeInput{
+		InstanceId: aws.String(vmID),
+		VolumeId:   aws.String(diskID),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to detach volume %s from %s, %v", diskID, vmID, err)
+		return irs.DiskInfo{}, err
+	}
+
+	err = diskHandler.Client.WaitUntilVolumeAvailable(&ec2.DescribeVolumesInput{VolumeIds: []*string{aws.String(diskID)}})
+	if err != nil {
+		cblogger.Errorf("failed to wait until volume %s is available, %v", diskID, err)
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(diskID)
+}
+
+// getDeviceName returns the first device name not in usedDevices, /dev/sdf ~ /dev/sdp are recommended for EBS volumes.
+func getDeviceName(usedDevices map[string]bool) (string, error) {
+	for c := 'f'; c <= 'p'; c++ {
+		device := "/dev/sd" + string(c)
+		if !usedDevices[device] && !usedDevices["/dev/xvd"+string(c)] {
+			return device, nil
+		}
+	}
+	return "", errors.New("no device name left for EBS volumes(/dev/sdf ~ /dev/sdp)")
+}
+
+// extractVolume maps an EBS volume into irs.DiskInfo.
+func extractVolume(volume *ec2.Volume) irs.DiskInfo {
+	diskInfo := irs.DiskInfo{
+		Name:        getNameTag(volume.Tags),
+		Id:          aws.StringValue(volume.VolumeId),
+		Tags:        getTags(volume.Tags),
+		SizeGiB:     int(aws.Int64Value(volume.Size)),
+		DiskType:    aws.StringValue(volume.VolumeType),
+		Zone:        aws.StringValue(volume.AvailabilityZone),
+		Status:      aws.StringValue(volume.State),
+		CreatedTime: aws.TimeValue(volume.CreateTime),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Encrypted", Value: strconv.FormatBool(aws.BoolValue(volume.Encrypted))},
+			{Key: "Iops", Value: strconv.FormatInt(aws.Int64Value(volume.Iops), 10)},
+			{Key: "SnapshotId", Value: aws.StringValue(volume.SnapshotId)},
+		},
+	}
+	if len(volume.Attachments) != 0 {
+		diskInfo.VMId = aws.StringValue(volume.Attachments[0].InstanceId)
+		diskInfo.Device = aws.StringValue(volume.Attachments[0].Device)
+	}
+	return diskInfo
+}
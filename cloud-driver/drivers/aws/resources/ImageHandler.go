@@ -11,6 +11,11 @@
 package resources
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
@@ -21,17 +26,190 @@ type AwsImageHandler struct {
 	Client *ec2.EC2
 }
 
+// imageRootDeviceName is the root device of the images registered from snapshots.
+const imageRootDeviceName = "/dev/xvda"
+
+// CreateImage registers a HVM image(ENA enabled) with the root volume from the snapshot(SnapshotId) of a root disk.
+// The architecture is the one of the request or of the instance of the source volume of the snapshot.
+// The snapshot is waited to be completed before the registration.
 func (imageHandler *AwsImageHandler) CreateImage(imageReqInfo irs.ImageReqInfo) (irs.ImageInfo, error) {
+	cblogger.Info(imageReqInfo)
+	if imageReqInfo.SnapshotId == "" {
+		return irs.ImageInfo{}, errors.New("the snapshot of image " + imageReqInfo.Name + " is required")
+	}
+	// RegisterImage has no tag specifications, so the tags are checked before the registration.
+	ec2Tags, err := getEc2Tags(imageReqInfo.Name, imageReqInfo.Tags)
+	if err != nil {
+		return irs.ImageInfo{}, err
+	}
+
+	err = imageHandler.Client.WaitUntilSnapshotCompleted(&ec2.DescribeSnapshotsInput{
+		SnapshotIds: []*string{aws.String(imageReqInfo.SnapshotId)},
+	})
+	if err != nil {
+		cblogger.Errorf("failed to wait until snapshot %s is completed, %v", imageReqInfo.SnapshotId, err)
+		return irs.ImageInfo{}, err
+	}
+
+	architecture := imageReqInfo.Architecture
+	if architecture == "" {
+		architecture, err = imageHandler.getSnapshotArchitecture(imageReqInfo.SnapshotId)
+		if err != nil {
+			return irs.ImageInfo{}, err
+		}
+	}
+
+	result, err := imageHandler.Client.RegisterImage(&ec2.RegisterImageInput{
+		Name:               aws.String(imageReqInfo.Name),
+		Architecture:       aws.String(architecture),
+		VirtualizationType: aws.String("hvm"),
+		EnaSupport:         aws.Bool(true),
+		RootDeviceName:     aws.String(imageRootDeviceName),
+		BlockDeviceMappings: []*ec2.BlockDeviceMapping{
+			{
+				DeviceName: aws.String(imageRootDeviceName),
+				Ebs: &ec2.EbsBlockDevice{
+					SnapshotId:          aws.String(imageReqInfo.SnapshotId),
+					DeleteOnTermination: aws.Bool(true),
+				},
+			},
+		},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to register image %s, %v", imageReqInfo.Name, err)
+		return irs.ImageInfo{}, err
+	}
+	imageID := aws.StringValue(result.ImageId)
 
-	return irs.ImageInfo{}, nil
+	if len(ec2Tags) != 0 {
+		_, err = imageHandler.Client.CreateTags(&ec2.CreateTagsInput{
+			Resources: []*string{result.ImageId},
+			Tags:      ec2Tags,
+		})
+		if err != nil {
+			cblogger.Errorf("Unable to tag image %s, %v", imageID, err)
+			// 태그가 없는 이미지가 남지 않도록 등록을 취소 함.
+			if _, deregisterErr := imageHandler.Client.DeregisterImage(&ec2.DeregisterImageInput{ImageId: result.ImageId}); deregisterErr != nil {
+				cblogger.Errorf("Unable to deregister image %s, %v", imageID, deregisterErr)
+			}
+			return irs.ImageInfo{}, err
+		}
+	}
+
+	return imageHandler.GetImage(imageID)
 }
 
-func (imageHandler *AwsImageHandler) ListImage() ([]*irs.ImageInfo, error) {
-	return nil, nil
+// getSnapshotArchitecture returns the architecture of the instance the source volume of a snapshot is attached to.
+func (imageHandler *AwsImageHandler) getSnapshotArchitecture(snapshotID string) (string, error) {
+	snapshots, err := imageHandler.Client.DescribeSnapshots(&ec2.DescribeSnapshotsInput{
+		SnapshotIds: []*string{aws.String(snapshotID)},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get snapshot %s, %v", snapshotID, err)
+		return "", err
+	}
+	if len(snapshots.Snapshots) == 0 {
+		return "", errors.New("snapshot not found: " + snapshotID)
+	}
+	volumeID := aws.StringValue(snapshots.Snapshots[0].VolumeId)
+	unknownErr := errors.New(fmt.Sprintf("the architecture of snapshot %s is unknown, the Architecture of the image is required", snapshotID))
+
+	// the source volume may be deleted or detached.
+	volumes, err := imageHandler.Client.DescribeVolumes(&ec2.DescribeVolumesInput{
+		Filters: []*ec2.Filter{{Name: aws.String("volume-id"), Values: []*string{aws.String(volumeID)}}},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get volume %s, %v", volumeID, err)
+		return "", err
+	}
+	if len(volumes.Volumes) == 0 || len(volumes.Volumes[0].Attachments) == 0 {
+		return "", unknownErr
+	}
+	instanceID := volumes.Volumes[0].Attachments[0].InstanceId
+
+	result, err := imageHandler.Client.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: []*string{instanceID},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get instance %s, %v", aws.StringValue(instanceID), err)
+		return "", err
+	}
+	if len(result.Reservations) == 0 || len(result.Reservations[0].Instances) == 0 || result.Reservations[0].Instances[0].Architecture == nil {
+		return "", unknownErr
+	}
+	return aws.StringValue(result.Reservations[0].Instances[0].Architecture), nil
+}
+
+func (imageHandler *AwsImageHandler) ListImage(listReqInfo irs.ListReqInfo) ([]*irs.ImageInfo, irs.ListPageInfo, error) {
+	cblogger.Info("Start : ", listReqInfo)
+	var imageList []*irs.ImageInfo
+
+	input := &ec2.DescribeImagesInput{
+		Filters:    getListFilters(listReqInfo, "name"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 1, 1000),
+		NextToken:  getNextToken(listReqInfo),
+	}
+
+	result, err := imageHandler.Client.DescribeImages(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get images, %v", err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, image := range result.Images {
+		imageInfo := extractImage(image)
+		imageList = append(imageList, &imageInfo)
+	}
+
+	return imageList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
 }
 
 func (imageHandler *AwsImageHandler) GetImage(imageID string) (irs.ImageInfo, error) {
-	return irs.ImageInfo{}, nil
+	cblogger.Infof("imageID : [%s]", imageID)
+	input := &ec2.DescribeImagesInput{
+		ImageIds: []*string{aws.String(imageID)},
+	}
+
+	result, err := imageHandler.Client.DescribeImages(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get image %s, %v", imageID, err)
+		return irs.ImageInfo{}, err
+	}
+	if len(result.Images) == 0 {
+		return irs.ImageInfo{}, errors.New("image not found: " + imageID)
+	}
+
+	return extractImage(result.Images[0]), nil
+}
+
+// extractImage maps an EC2 image into irs.ImageInfo.
+func extractImage(image *ec2.Image) irs.ImageInfo {
+	// Platform is only set for Windows images.
+	osType := "Linux"
+	if aws.StringValue(image.Platform) == ec2.PlatformValuesWindows {
+		osType = "Windows"
+	}
+
+	imageInfo := irs.ImageInfo{
+		Id:          aws.StringValue(image.ImageId),
+		Name:        aws.StringValue(image.Name),
+		Tags:        getTags(image.Tags),
+		OSType:      osType,
+		Status:      aws.StringValue(image.State),
+		CreatedTime: getTime(aws.StringValue(image.CreationDate)),
+		Owner:       aws.StringValue(image.OwnerId),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Architecture", Value: aws.StringValue(image.Architecture)},
+			{Key: "VirtualizationType", Value: aws.StringValue(image.VirtualizationType)},
+			{Key: "RootDeviceType", Value: aws.StringValue(image.RootDeviceType)},
+			{Key: "RootDeviceName", Value: aws.StringValue(image.RootDeviceName)},
+			{Key: "Public", Value: strconv.FormatBool(aws.BoolValue(image.Public))},
+		},
+	}
+	if image.Description != nil {
+		imageInfo.KeyValueList = append(imageInfo.KeyValueList, irs.KeyValue{Key: "Description", Value: *image.Description})
+	}
+	return imageInfo
 }
 
 func (imageHandler *AwsImageHandler) DeleteImage(imageID string) (bool, error) {
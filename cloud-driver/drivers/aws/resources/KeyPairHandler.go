@@ -1,12 +1,16 @@
 package resources
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
-	"github.com/davecgh/go-spew/spew"
+	"golang.org/x/crypto/ssh"
 )
 
 type AwsKeyPairHandler struct {
@@ -14,23 +18,15 @@ type AwsKeyPairHandler struct {
 	Client *ec2.EC2
 }
 
-// @TODO: KeyPairInfo 리소스 프로퍼티 정의 필요
-type KeyPairInfo struct {
-	Name        string
-	Fingerprint string
-	KeyMaterial string //RSA PRIVATE KEY
-}
-
-func (keyPairHandler *AwsKeyPairHandler) ListKey() ([]*irs.KeyPairInfo, error) {
+// DescribeKeyPairs has no native paging, so pages are cut from the filtered result.
+func (keyPairHandler *AwsKeyPairHandler) ListKey(listReqInfo irs.ListReqInfo) ([]*irs.KeyPairInfo, irs.ListPageInfo, error) {
 	cblogger.Debug("Start ListKey()")
 	var keyPairList []*irs.KeyPairInfo
 	//spew.Dump(keyPairHandler)
 	cblogger.Info(keyPairHandler)
 
 	input := &ec2.DescribeKeyPairsInput{
-		KeyNames: []*string{
-			nil,
-		},
+		Filters: getListFilters(listReqInfo, "key-name"),
 	}
 
 	//  Returns a list of key pairs
@@ -38,31 +34,55 @@ func (keyPairHandler *AwsKeyPairHandler) ListKey() ([]*irs.KeyPairInfo, error) {
 	cblogger.Info(result)
 	if err != nil {
 		cblogger.Errorf("Unable to get key pairs, %v", err)
-		return keyPairList, err
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	start, end, nextToken, err := irs.GetPageRange(len(result.KeyPairs), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
 	}
 
 	cblogger.Debugf("Key Pairs:")
-	for _, pair := range result.KeyPairs {
+	for _, pair := range result.KeyPairs[start:end] {
 		cblogger.Debugf("%s: %s\n", *pair.KeyName, *pair.KeyFingerprint)
-		keyPairInfo := new(irs.KeyPairInfo)
-		keyPairInfo.Name = *pair.KeyName
-		keyPairInfo.Id = *pair.KeyFingerprint
-
-		keyPairList = append(keyPairList, keyPairInfo)
+		keyPairInfo := extractKeyPair(pair)
+		keyPairList = append(keyPairList, &keyPairInfo)
 	}
 
 	cblogger.Info(keyPairList)
-	spew.Dump(keyPairList)
-	return keyPairList, nil
+	return keyPairList, irs.ListPageInfo{NextToken: nextToken}, nil
 }
 
+// CreateKey imports KeyPairReqInfo.PublicKey, or creates a new key pair of which the private key is returned only once.
 func (keyPairHandler *AwsKeyPairHandler) CreateKey(keyPairReqInfo irs.KeyPairReqInfo) (irs.KeyPairInfo, error) {
-	cblogger.Infof("Start CreateKey(%s)", keyPairReqInfo)
+	cblogger.Infof("Start CreateKey(%s)", keyPairReqInfo.Name)
+
+	// KeyName is the name of a key pair, so the "Name" tag is not needed.
+	ec2Tags, err := getEc2Tags("", keyPairReqInfo.Tags)
+	if err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+	var tagSpecs []*ec2.TagSpecification
+	if len(ec2Tags) != 0 {
+		tagSpecs = []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeKeyPair),
+				Tags:         ec2Tags,
+			},
+		}
+	}
+
+	if keyPairReqInfo.PublicKey != "" {
+		return keyPairHandler.importKey(keyPairReqInfo, tagSpecs)
+	}
+
+	input := &ec2.CreateKeyPairInput{
+		KeyName:           aws.String(keyPairReqInfo.Name),
+		TagSpecifications: tagSpecs,
+	}
 
 	// Creates a new  key pair with the given name
-	result, err := keyPairHandler.Client.CreateKeyPair(&ec2.CreateKeyPairInput{
-		KeyName: aws.String(keyPairReqInfo.Name),
-	})
+	result, err := keyPairHandler.Client.CreateKeyPair(input)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidKeyPair.Duplicate" {
 			cblogger.Errorf("Keypair %q already exists.", keyPairReqInfo.Name)
@@ -72,22 +92,68 @@ func (keyPairHandler *AwsKeyPairHandler) CreateKey(keyPairReqInfo irs.KeyPairReq
 		return irs.KeyPairInfo{}, err
 	}
 
-	cblogger.Infof("Created key pair %q %s\n%s\n", *result.KeyName, *result.KeyFingerprint, *result.KeyMaterial)
-	spew.Dump(result)
+	// KeyMaterial is the private key, it must not be logged.
+	cblogger.Infof("Created key pair %q %s", *result.KeyName, *result.KeyFingerprint)
+	privateKey := aws.StringValue(result.KeyMaterial)
+	publicKey, err := getPublicKey(privateKey)
+	if err != nil {
+		// the key pair is usable with the private key, so only the public key is left empty.
+		cblogger.Errorf("Unable to get the public key of key pair: %s, %v.", keyPairReqInfo.Name, err)
+	}
 	keyPairInfo := irs.KeyPairInfo{
-		Name: *result.KeyName,
-		Id:   *result.KeyFingerprint,
+		Name:        *result.KeyName,
+		Id:          *result.KeyFingerprint,
+		Tags:        keyPairReqInfo.Tags,
+		PublicKey:   publicKey,
+		PrivateKey:  privateKey,
+		Fingerprint: *result.KeyFingerprint,
+		KeyValueList: []irs.KeyValue{
+			{Key: "KeyPairId", Value: aws.StringValue(result.KeyPairId)},
+		},
 	}
 
 	return keyPairInfo, nil
 }
 
-func Test() KeyPairInfo {
-	keyPairInfo := KeyPairInfo{
-		Name:        "키이름",
-		Fingerprint: "핑거프린트",
+// importKey imports the public key of keyPairReqInfo.
+// The fingerprint of an imported key pair is the MD5 of the public key, not the SHA1 of the private key.
+func (keyPairHandler *AwsKeyPairHandler) importKey(keyPairReqInfo irs.KeyPairReqInfo, tagSpecs []*ec2.TagSpecification) (irs.KeyPairInfo, error) {
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(keyPairReqInfo.PublicKey)); err != nil {
+		return irs.KeyPairInfo{}, errors.New(fmt.Sprintf("invalid public key of key pair %s, %v", keyPairReqInfo.Name, err))
 	}
-	return keyPairInfo
+
+	input := &ec2.ImportKeyPairInput{
+		KeyName:           aws.String(keyPairReqInfo.Name),
+		PublicKeyMaterial: []byte(keyPairReqInfo.PublicKey),
+		TagSpecifications: tagSpecs,
+	}
+	result, err := keyPairHandler.Client.ImportKeyPair(input)
+	if err != nil {
+		cblogger.Errorf("Unable to import key pair: %s, %v.", keyPairReqInfo.Name, err)
+		return irs.KeyPairInfo{}, err
+	}
+
+	cblogger.Infof("Imported key pair %q %s", *result.KeyName, *result.KeyFingerprint)
+	keyPairInfo := irs.KeyPairInfo{
+		Name:        *result.KeyName,
+		Id:          *result.KeyFingerprint,
+		Tags:        keyPairReqInfo.Tags,
+		PublicKey:   keyPairReqInfo.PublicKey,
+		Fingerprint: *result.KeyFingerprint,
+		KeyValueList: []irs.KeyValue{
+			{Key: "KeyPairId", Value: aws.StringValue(result.KeyPairId)},
+		},
+	}
+	return keyPairInfo, nil
+}
+
+// getPublicKey gets the public key(OpenSSH authorized_keys format) of a PEM encoded private key.
+func getPublicKey(privateKey string) (string, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))), nil
 }
 
 //혼선을 피하기 위해 keyPairID 대신 keyPairName으로 변경 함.
@@ -126,20 +192,24 @@ func (keyPairHandler *AwsKeyPairHandler) GetKey(keyPairName string) (irs.KeyPair
 	cblogger.Info("KeyName : ", *result.KeyPairs[0].KeyName)
 	cblogger.Info("Fingerprint : ", *result.KeyPairs[0].KeyFingerprint)
 
-	keyPairInfo := irs.KeyPairInfo{
-		Name: *result.KeyPairs[0].KeyName,
-		Id:   *result.KeyPairs[0].KeyFingerprint,
-	}
-
-	/*
-		keyPairInfo := KeyPairInfo{
-			Name:        *result.KeyPairs[0].KeyName,
-			Fingerprint: *result.KeyPairs[0].KeyFingerprint,
-		}
-	*/
+	keyPairInfo := extractKeyPair(result.KeyPairs[0])
 	return keyPairInfo, nil
 }
 
+// extractKeyPair maps an EC2 key pair into irs.KeyPairInfo.
+// The ID of an EC2 key pair is its fingerprint(the name is used to get or delete it).
+func extractKeyPair(pair *ec2.KeyPairInfo) irs.KeyPairInfo {
+	return irs.KeyPairInfo{
+		Name:        aws.StringValue(pair.KeyName),
+		Id:          aws.StringValue(pair.KeyFingerprint),
+		Tags:        getTags(pair.Tags),
+		Fingerprint: aws.StringValue(pair.KeyFingerprint),
+		KeyValueList: []irs.KeyValue{
+			{Key: "KeyPairId", Value: aws.StringValue(pair.KeyPairId)},
+		},
+	}
+}
+
 func (keyPairHandler *AwsKeyPairHandler) DeleteKey(keyPairName string) (bool, error) {
 	cblogger.Infof("DeleteKeyPaid : [%s]", keyPairName)
 	// Delete the key pair by name
@@ -11,6 +11,9 @@
 package resources
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
@@ -23,20 +26,30 @@ type AwsPublicIPHandler struct {
 	Client *ec2.EC2
 }
 
-//@TODO : EC2에 Public를 할당하는 Associate함수 필요 함.
-
 func (publicIpHandler *AwsPublicIPHandler) CreatePublicIP(publicIPReqInfo irs.PublicIPReqInfo) (irs.PublicIPInfo, error) {
 	cblogger.Info("Start : ", publicIPReqInfo)
-	//result, err := vNetworkHandler.Client.DescribeKeyPairs(input)
 
-	//@TODO: 대체해야 함.
-	instanceID := publicIPReqInfo.Id
+	if publicIPReqInfo.Sku != "" {
+		return irs.PublicIPInfo{}, errors.New("the SKU of a public IP is not supported for AWS")
+	}
 
-	// Attempt to allocate the Elastic IP address.
-	allocRes, err := publicIpHandler.Client.AllocateAddress(&ec2.AllocateAddressInput{
+	// EIP의 Name과 Tags는 "Name" 태그와 태그로 설정 함.
+	ec2Tags, err := getEc2Tags(publicIPReqInfo.Name, publicIPReqInfo.Tags)
+	if err != nil {
+		cblogger.Error(err)
+		return irs.PublicIPInfo{}, err
+	}
+
+	input := &ec2.AllocateAddressInput{
 		Domain: aws.String("vpc"), // 범이 : VPC
-	})
+	}
+	// Pool이 없으면 Amazon의 IPv4 주소 Pool에서 할당 됨.
+	if publicIPReqInfo.Pool != "" {
+		input.PublicIpv4Pool = aws.String(publicIPReqInfo.Pool)
+	}
 
+	// Attempt to allocate the Elastic IP address.
+	allocRes, err := publicIpHandler.Client.AllocateAddress(input)
 	if err != nil {
 		cblogger.Errorf("Unable to allocate IP address, %v", err)
 		return irs.PublicIPInfo{}, err
@@ -45,30 +58,160 @@ func (publicIpHandler *AwsPublicIPHandler) CreatePublicIP(publicIPReqInfo irs.Pu
 	spew.Dump(allocRes)
 	cblogger.Infof("EIP 생성 성공 - Public IP : [%s], Allocation Id : [%s]", *allocRes.PublicIp, *allocRes.AllocationId)
 
-	cblogger.Infof("[%s] EC2에 [%s] IP 할당 시작", instanceID, *allocRes.PublicIp)
-	// EC2에 할당.
-	// Associate the new Elastic IP address with an existing EC2 instance.
-	assocRes, err := publicIpHandler.Client.AssociateAddress(&ec2.AssociateAddressInput{
-		AllocationId: allocRes.AllocationId,
-		InstanceId:   aws.String(instanceID),
-	})
-	if err != nil {
-		cblogger.Errorf("Unable to associate IP address with %s, %v", instanceID, err)
-		return irs.PublicIPInfo{}, err
+	if len(ec2Tags) != 0 {
+		_, err = publicIpHandler.Client.CreateTags(&ec2.CreateTagsInput{
+			Resources: []*string{allocRes.AllocationId},
+			Tags:      ec2Tags,
+		})
+		if err != nil {
+			cblogger.Errorf("Could not create tags for EIP %s, %v", *allocRes.AllocationId, err)
+			// 할당된 EIP는 비용이 발생하므로 반환 함.
+			if _, releaseErr := publicIpHandler.Client.ReleaseAddress(&ec2.ReleaseAddressInput{AllocationId: allocRes.AllocationId}); releaseErr != nil {
+				cblogger.Errorf("Unable to release EIP %s, %v", *allocRes.AllocationId, releaseErr)
+			}
+			return irs.PublicIPInfo{}, err
+		}
 	}
-	cblogger.Infof("[%s] EC2에 [%s] IP 할당 완료 - Allocation Id : [%s]", instanceID, *allocRes.PublicIp, *assocRes.AssociationId)
 
-	return irs.PublicIPInfo{}, nil
+	// EC2에 할당은 AssociatePublicIP로 함.
+	publicIPInfo := irs.PublicIPInfo{
+		Id:       *allocRes.AllocationId,
+		Name:     publicIPReqInfo.Name,
+		Tags:     publicIPReqInfo.Tags,
+		PublicIP: *allocRes.PublicIp,
+		Status:   "available",
+		KeyValueList: []irs.KeyValue{
+			{Key: "Domain", Value: aws.StringValue(allocRes.Domain)},
+			{Key: "PublicIpv4Pool", Value: aws.StringValue(allocRes.PublicIpv4Pool)},
+		},
+	}
+	return publicIPInfo, nil
 }
 
-func (publicIpHandler *AwsPublicIPHandler) ListPublicIP() ([]*irs.PublicIPInfo, error) {
-	return nil, nil
+// DescribeAddresses has no native paging, so pages are cut from the filtered result.
+func (publicIpHandler *AwsPublicIPHandler) ListPublicIP(listReqInfo irs.ListReqInfo) ([]*irs.PublicIPInfo, irs.ListPageInfo, error) {
+	cblogger.Info("Start : ", listReqInfo)
+	var publicIPList []*irs.PublicIPInfo
+
+	input := &ec2.DescribeAddressesInput{
+		Filters: getListFilters(listReqInfo, "tag:Name"),
+	}
+
+	result, err := publicIpHandler.Client.DescribeAddresses(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get addresses, %v", err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	start, end, nextToken, err := irs.GetPageRange(len(result.Addresses), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, address := range result.Addresses[start:end] {
+		publicIPInfo := extractAddress(address)
+		publicIPList = append(publicIPList, &publicIPInfo)
+	}
+
+	return publicIPList, irs.ListPageInfo{NextToken: nextToken}, nil
 }
 
 func (publicIpHandler *AwsPublicIPHandler) GetPublicIP(publicIPID string) (irs.PublicIPInfo, error) {
-	return irs.PublicIPInfo{}, nil
+	cblogger.Infof("publicIPID : [%s]", publicIPID)
+	input := &ec2.DescribeAddressesInput{
+		AllocationIds: []*string{aws.String(publicIPID)},
+	}
+
+	result, err := publicIpHandler.Client.DescribeAddresses(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get address %s, %v", publicIPID, err)
+		return irs.PublicIPInfo{}, err
+	}
+	if len(result.Addresses) == 0 {
+		return irs.PublicIPInfo{}, errors.New("public IP not found: " + publicIPID)
+	}
+
+	return extractAddress(result.Addresses[0]), nil
+}
+
+// extractAddress maps an EIP into irs.PublicIPInfo.
+// The ID of an EIP is its allocation ID.
+func extractAddress(address *ec2.Address) irs.PublicIPInfo {
+	status := "available"
+	if address.AssociationId != nil {
+		status = "associated"
+	}
+
+	return irs.PublicIPInfo{
+		Id:        aws.StringValue(address.AllocationId),
+		Name:      getNameTag(address.Tags),
+		Tags:      getTags(address.Tags),
+		PublicIP:  aws.StringValue(address.PublicIp),
+		Status:    status,
+		OwnedVMId: aws.StringValue(address.InstanceId),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Domain", Value: aws.StringValue(address.Domain)},
+			{Key: "AssociationId", Value: aws.StringValue(address.AssociationId)},
+			{Key: "NetworkInterfaceId", Value: aws.StringValue(address.NetworkInterfaceId)},
+			{Key: "PrivateIpAddress", Value: aws.StringValue(address.PrivateIpAddress)},
+		},
+	}
 }
 
 func (publicIpHandler *AwsPublicIPHandler) DeletePublicIP(publicIPID string) (bool, error) {
 	return false, nil
 }
+
+// AssociatePublicIP associates an EIP with an instance(its primary ENI) or an ENI.
+// An EIP associated with another instance is not moved.
+func (publicIpHandler *AwsPublicIPHandler) AssociatePublicIP(publicIPID string, vmOrVNicID string) (bool, error) {
+	cblogger.Infof("publicIPID : [%s], vmOrVNicID : [%s]", publicIPID, vmOrVNicID)
+
+	input := &ec2.AssociateAddressInput{
+		AllocationId: aws.String(publicIPID),
+	}
+	// ENI의 ID는 "eni-"로 시작함.
+	if strings.HasPrefix(vmOrVNicID, "eni-") {
+		input.NetworkInterfaceId = aws.String(vmOrVNicID)
+	} else {
+		input.InstanceId = aws.String(vmOrVNicID)
+	}
+
+	cblogger.Infof("[%s]에 EIP [%s] 할당 시작", vmOrVNicID, publicIPID)
+	assocRes, err := publicIpHandler.Client.AssociateAddress(input)
+	if err != nil {
+		cblogger.Errorf("Unable to associate IP address %s with %s, %v", publicIPID, vmOrVNicID, err)
+		return false, err
+	}
+	cblogger.Infof("[%s]에 EIP [%s] 할당 완료 - Association Id : [%s]", vmOrVNicID, publicIPID, aws.StringValue(assocRes.AssociationId))
+	return true, nil
+}
+
+func (publicIpHandler *AwsPublicIPHandler) DisassociatePublicIP(publicIPID string) (bool, error) {
+	cblogger.Infof("publicIPID : [%s]", publicIPID)
+
+	result, err := publicIpHandler.Client.DescribeAddresses(&ec2.DescribeAddressesInput{
+		AllocationIds: []*string{aws.String(publicIPID)},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get address %s, %v", publicIPID, err)
+		return false, err
+	}
+	if len(result.Addresses) == 0 {
+		return false, errors.New("public IP not found: " + publicIPID)
+	}
+	associationID := result.Addresses[0].AssociationId
+	if associationID == nil {
+		return false, errors.New("public IP is not associated: " + publicIPID)
+	}
+
+	_, err = publicIpHandler.Client.DisassociateAddress(&ec2.DisassociateAddressInput{
+		AssociationId: associationID,
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to disassociate IP address %s, %v", publicIPID, err)
+		return false, err
+	}
+	cblogger.Infof("EIP [%s] 할당 해제 완료 - Association Id : [%s]", publicIPID, *associationID)
+	return true, nil
+}
@@ -0,0 +1,122 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// AwsRegionZoneHandler lists all the regions of the account.
+// The zones of a region are described with a client of that region(same credentials).
+type AwsRegionZoneHandler struct {
+	Region idrv.RegionInfo
+	Client *ec2.EC2
+}
+
+func (regionZoneHandler *AwsRegionZoneHandler) ListRegionZone() ([]*irs.RegionZoneInfo, error) {
+	cblogger.Info("Start ListRegionZone()")
+	var regionZoneList []*irs.RegionZoneInfo
+
+	result, err := regionZoneHandler.Client.DescribeRegions(&ec2.DescribeRegionsInput{
+		AllRegions: aws.Bool(true),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get regions, %v", err)
+		return nil, err
+	}
+
+	for _, region := range result.Regions {
+		regionZoneInfo, err := regionZoneHandler.getRegionZone(region)
+		if err != nil {
+			return nil, err
+		}
+		regionZoneList = append(regionZoneList, &regionZoneInfo)
+	}
+	return regionZoneList, nil
+}
+
+func (regionZoneHandler *AwsRegionZoneHandler) GetRegionZone(regionName string) (irs.RegionZoneInfo, error) {
+	cblogger.Infof("regionName : [%s]", regionName)
+	result, err := regionZoneHandler.Client.DescribeRegions(&ec2.DescribeRegionsInput{
+		AllRegions:  aws.Bool(true),
+		RegionNames: []*string{aws.String(regionName)},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get region %s, %v", regionName, err)
+		return irs.RegionZoneInfo{}, err
+	}
+	if len(result.Regions) == 0 {
+		return irs.RegionZoneInfo{}, errors.New("region not found: " + regionName)
+	}
+
+	return regionZoneHandler.getRegionZone(result.Regions[0])
+}
+
+// getRegionZone describes the zones of a region, the regions not opted in have no zones to describe.
+func (regionZoneHandler *AwsRegionZoneHandler) getRegionZone(region *ec2.Region) (irs.RegionZoneInfo, error) {
+	regionName := aws.StringValue(region.RegionName)
+	optInStatus := aws.StringValue(region.OptInStatus)
+	regionZoneInfo := irs.RegionZoneInfo{
+		Name:   regionName,
+		Status: irs.RegionZoneAvailable,
+		KeyValueList: []irs.KeyValue{
+			{Key: "Endpoint", Value: aws.StringValue(region.Endpoint)},
+			{Key: "OptInStatus", Value: optInStatus},
+		},
+	}
+	if optInStatus == "not-opted-in" {
+		regionZoneInfo.Status = irs.RegionZoneUnavailable
+		return regionZoneInfo, nil
+	}
+
+	client, err := regionZoneHandler.getRegionClient(regionName)
+	if err != nil {
+		return irs.RegionZoneInfo{}, err
+	}
+	result, err := client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{})
+	if err != nil {
+		cblogger.Errorf("Unable to get availability zones of %s, %v", regionName, err)
+		return irs.RegionZoneInfo{}, err
+	}
+	for _, zone := range result.AvailabilityZones {
+		regionZoneInfo.ZoneList = append(regionZoneInfo.ZoneList, extractAvailabilityZone(zone))
+	}
+	return regionZoneInfo, nil
+}
+
+// getRegionClient returns the client of a region with the config(credentials) of the connection's client.
+func (regionZoneHandler *AwsRegionZoneHandler) getRegionClient(regionName string) (*ec2.EC2, error) {
+	if regionName == aws.StringValue(regionZoneHandler.Client.Config.Region) {
+		return regionZoneHandler.Client, nil
+	}
+	sess, err := session.NewSession(regionZoneHandler.Client.Config.Copy(&aws.Config{Region: aws.String(regionName)}))
+	if err != nil {
+		return nil, err
+	}
+	return ec2.New(sess), nil
+}
+
+// extractAvailabilityZone maps an EC2 availability zone into irs.ZoneInfo.
+// The zone states other than "available"(information, impaired, unavailable) are unavailable.
+func extractAvailabilityZone(zone *ec2.AvailabilityZone) irs.ZoneInfo {
+	zoneInfo := irs.ZoneInfo{
+		Name:   aws.StringValue(zone.ZoneName),
+		Status: irs.RegionZoneUnavailable,
+	}
+	if aws.StringValue(zone.State) == ec2.AvailabilityZoneStateAvailable {
+		zoneInfo.Status = irs.RegionZoneAvailable
+	}
+	return zoneInfo
+}
@@ -0,0 +1,505 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// A router of AWS is a route table, and the gateway of it is the internet gateway of its VPC.
+// A VPC has only one internet gateway, so it is shared by the route tables of the VPC.
+type AwsRouterHandler struct {
+	Region idrv.RegionInfo
+	Client *ec2.EC2
+}
+
+// The internet gateway of the VPC is attached(created when the VPC has none) for the routes to the gateway.
+func (routerHandler *AwsRouterHandler) CreateRouter(routerReqInfo irs.RouterReqInfo) (irs.RouterInfo, error) {
+	cblogger.Info(routerReqInfo)
+
+	if routerReqInfo.VNetworkId == "" {
+		return irs.RouterInfo{}, errors.New("the VPC(VNetworkId) of a route table is required by AWS")
+	}
+	useGateway := routerReqInfo.GatewayId != ""
+	for _, route := range routerReqInfo.Routes {
+		if err := irs.CheckRoute(route); err != nil {
+			return irs.RouterInfo{}, err
+		}
+		if route.NextHop == irs.GatewayNextHop {
+			useGateway = true
+		}
+	}
+
+	ec2Tags, err := getEc2Tags(routerReqInfo.Name, routerReqInfo.Tags)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	input := &ec2.CreateRouteTableInput{
+		VpcId: aws.String(routerReqInfo.VNetworkId),
+	}
+	if len(ec2Tags) != 0 {
+		input.TagSpecifications = []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeRouteTable),
+				Tags:         ec2Tags,
+			},
+		}
+	}
+
+	result, err := routerHandler.Client.CreateRouteTable(input)
+	if err != nil {
+		cblogger.Errorf("Unable to create route table %s, %v", routerReqInfo.Name, err)
+		return irs.RouterInfo{}, err
+	}
+	routeTableId := aws.StringValue(result.RouteTable.RouteTableId)
+	cblogger.Infof("Created route table %s [%s]", routerReqInfo.Name, routeTableId)
+
+	err = routerHandler.setupRouteTable(routeTableId, routerReqInfo, useGateway)
+	if err != nil {
+		// the route table without the requested routes and subnets is useless, so it is deleted.
+		if _, deleteErr := routerHandler.DeleteRouter(routeTableId); deleteErr != nil {
+			cblogger.Errorf("Unable to delete route table %s, %v", routeTableId, deleteErr)
+		}
+		return irs.RouterInfo{}, err
+	}
+
+	return routerHandler.GetRouter(routeTableId)
+}
+
+func (routerHandler *AwsRouterHandler) setupRouteTable(routeTableId string, routerReqInfo irs.RouterReqInfo, useGateway bool) error {
+	var gatewayId string
+	if useGateway {
+		var err error
+		gatewayId, err = routerHandler.attachGateway(routerReqInfo.VNetworkId, routerReqInfo.GatewayId)
+		if err != nil {
+			return err
+		}
+	}
+	if err := routerHandler.createRoutes(routeTableId, gatewayId, routerReqInfo.Routes); err != nil {
+		return err
+	}
+	for _, subnetId := range routerReqInfo.SubnetIds {
+		if err := routerHandler.associateSubnet(routeTableId, subnetId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getVpcGateways gets the internet gateways attached to the VPCs, the result is keyed by VPC ID.
+func (routerHandler *AwsRouterHandler) getVpcGateways(vpcIds []*string) (map[string]string, error) {
+	gatewayMap := map[string]string{}
+	if len(vpcIds) == 0 {
+		return gatewayMap, nil
+	}
+
+	input := &ec2.DescribeInternetGatewaysInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("attachment.vpc-id"), Values: vpcIds},
+		},
+	}
+	result, err := routerHandler.Client.DescribeInternetGateways(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get internet gateways, %v", err)
+		return nil, err
+	}
+	for _, gateway := range result.InternetGateways {
+		for _, attachment := range gateway.Attachments {
+			gatewayMap[aws.StringValue(attachment.VpcId)] = aws.StringValue(gateway.InternetGatewayId)
+		}
+	}
+	return gatewayMap, nil
+}
+
+// attachGateway attaches an internet gateway to a VPC and returns the ID of it.
+// The gateway already attached to the VPC is used for "" gatewayId, and a new one is created when the VPC has none.
+func (routerHandler *AwsRouterHandler) attachGateway(vpcId string, gatewayId string) (string, error) {
+	gatewayMap, err := routerHandler.getVpcGateways([]*string{aws.String(vpcId)})
+	if err != nil {
+		return "", err
+	}
+	if attachedId, ok := gatewayMap[vpcId]; ok {
+		if gatewayId != "" && gatewayId != attachedId {
+			return "", errors.New("VPC " + vpcId + " already has internet gateway " + attachedId)
+		}
+		return attachedId, nil
+	}
+
+	if gatewayId == "" {
+		result, err := routerHandler.Client.CreateInternetGateway(&ec2.CreateInternetGatewayInput{})
+		if err != nil {
+			cblogger.Errorf("Unable to create internet gateway, %v", err)
+			return "", err
+		}
+		gatewayId = aws.StringValue(result.InternetGateway.InternetGatewayId)
+		cblogger.Infof("Created internet gateway [%s]", gatewayId)
+	}
+
+	_, err = routerHandler.Client.AttachInternetGateway(&ec2.AttachInternetGatewayInput{
+		InternetGatewayId: aws.String(gatewayId),
+		VpcId:             aws.String(vpcId),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to attach internet gateway %s to %s, %v", gatewayId, vpcId, err)
+		return "", err
+	}
+	return gatewayId, nil
+}
+
+// createRoutes adds routes to a route table, gatewayId is the target of the routes to the gateway.
+// The target of a route is decided by the prefix of the NextHop.
+func (routerHandler *AwsRouterHandler) createRoutes(routeTableId string, gatewayId string, routes []irs.RouteInfo) error {
+	for _, route := range routes {
+		if err := irs.CheckRoute(route); err != nil {
+			return err
+		}
+
+		input := &ec2.CreateRouteInput{
+			RouteTableId: aws.String(routeTableId),
+		}
+		if strings.Contains(route.DestinationCIDR, ":") {
+			input.DestinationIpv6CidrBlock = aws.String(route.DestinationCIDR)
+		} else {
+			input.DestinationCidrBlock = aws.String(route.DestinationCIDR)
+		}
+
+		nextHop := route.NextHop
+		switch {
+		case nextHop == irs.GatewayNextHop:
+			if gatewayId == "" {
+				return errors.New("no internet gateway for the route to " + route.DestinationCIDR)
+			}
+			input.GatewayId = aws.String(gatewayId)
+		case strings.HasPrefix(nextHop, "igw-"), strings.HasPrefix(nextHop, "vgw-"):
+			input.GatewayId = aws.String(nextHop)
+		case strings.HasPrefix(nextHop, "nat-"):
+			input.NatGatewayId = aws.String(nextHop)
+		case strings.HasPrefix(nextHop, "eni-"):
+			input.NetworkInterfaceId = aws.String(nextHop)
+		case strings.HasPrefix(nextHop, "i-"):
+			input.InstanceId = aws.String(nextHop)
+		case strings.HasPrefix(nextHop, "pcx-"):
+			input.VpcPeeringConnectionId = aws.String(nextHop)
+		default:
+			return errors.New("invalid next hop " + nextHop + " of AWS routes: use gateway or the ID of igw-, vgw-, nat-, eni-, i-, pcx-")
+		}
+
+		_, err := routerHandler.Client.CreateRoute(input)
+		if err != nil {
+			cblogger.Errorf("Unable to add the route to %s, %v", route.DestinationCIDR, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (routerHandler *AwsRouterHandler) associateSubnet(routeTableId string, subnetId string) error {
+	_, err := routerHandler.Client.AssociateRouteTable(&ec2.AssociateRouteTableInput{
+		RouteTableId: aws.String(routeTableId),
+		SubnetId:     aws.String(subnetId),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to attach subnet %s to %s, %v", subnetId, routeTableId, err)
+		return err
+	}
+	return nil
+}
+
+// extractRouteTable maps a route table into irs.RouterInfo, gatewayId is the internet gateway of its VPC.
+// Only the routes added by users(origin CreateRoute) are mapped, the routes to gatewayId are GatewayNextHop.
+func extractRouteTable(routeTable *ec2.RouteTable, gatewayId string) irs.RouterInfo {
+	routerInfo := irs.RouterInfo{
+		Id:         aws.StringValue(routeTable.RouteTableId),
+		Name:       getNameTag(routeTable.Tags),
+		Tags:       getTags(routeTable.Tags),
+		VNetworkId: aws.StringValue(routeTable.VpcId),
+		GatewayId:  gatewayId,
+		Owner:      aws.StringValue(routeTable.OwnerId),
+	}
+
+	for _, route := range routeTable.Routes {
+		if aws.StringValue(route.Origin) != ec2.RouteOriginCreateRoute {
+			continue
+		}
+		routeInfo := irs.RouteInfo{DestinationCIDR: aws.StringValue(route.DestinationCidrBlock)}
+		if route.DestinationIpv6CidrBlock != nil {
+			routeInfo.DestinationCIDR = *route.DestinationIpv6CidrBlock
+		}
+		for _, target := range []*string{route.GatewayId, route.NatGatewayId, route.VpcPeeringConnectionId, route.NetworkInterfaceId, route.InstanceId} {
+			if target != nil {
+				routeInfo.NextHop = *target
+				break
+			}
+		}
+		if gatewayId != "" && routeInfo.NextHop == gatewayId {
+			routeInfo.NextHop = irs.GatewayNextHop
+		}
+		routerInfo.Routes = append(routerInfo.Routes, routeInfo)
+	}
+
+	isMain := false
+	for _, association := range routeTable.Associations {
+		if aws.BoolValue(association.Main) {
+			isMain = true
+		}
+		if association.SubnetId != nil {
+			routerInfo.SubnetIds = append(routerInfo.SubnetIds, *association.SubnetId)
+		}
+	}
+	routerInfo.KeyValueList = []irs.KeyValue{
+		{Key: "Main", Value: strconv.FormatBool(isMain)},
+	}
+
+	return routerInfo
+}
+
+func (routerHandler *AwsRouterHandler) ListRouter(listReqInfo irs.ListReqInfo) ([]*irs.RouterInfo, irs.ListPageInfo, error) {
+	cblogger.Info("Start : ", listReqInfo)
+	var routerList []*irs.RouterInfo
+
+	input := &ec2.DescribeRouteTablesInput{
+		Filters:    getListFilters(listReqInfo, "tag:Name"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 100),
+		NextToken:  getNextToken(listReqInfo),
+	}
+
+	result, err := routerHandler.Client.DescribeRouteTables(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get route tables, %v", err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	// gateways of the VPCs in the page are got with one call.
+	var vpcIds []*string
+	for _, routeTable := range result.RouteTables {
+		vpcIds = append(vpcIds, routeTable.VpcId)
+	}
+	gatewayMap, err := routerHandler.getVpcGateways(vpcIds)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, routeTable := range result.RouteTables {
+		routerInfo := extractRouteTable(routeTable, gatewayMap[aws.StringValue(routeTable.VpcId)])
+		routerList = append(routerList, &routerInfo)
+	}
+
+	return routerList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
+}
+
+func (routerHandler *AwsRouterHandler) getRouteTable(routerID string) (*ec2.RouteTable, error) {
+	input := &ec2.DescribeRouteTablesInput{
+		RouteTableIds: []*string{aws.String(routerID)},
+	}
+
+	result, err := routerHandler.Client.DescribeRouteTables(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get route table %s, %v", routerID, err)
+		return nil, err
+	}
+	if len(result.RouteTables) == 0 {
+		return nil, errors.New("route table not found: " + routerID)
+	}
+	return result.RouteTables[0], nil
+}
+
+func (routerHandler *AwsRouterHandler) GetRouter(routerID string) (irs.RouterInfo, error) {
+	cblogger.Infof("routerID : [%s]", routerID)
+
+	routeTable, err := routerHandler.getRouteTable(routerID)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	gatewayMap, err := routerHandler.getVpcGateways([]*string{routeTable.VpcId})
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+
+	return extractRouteTable(routeTable, gatewayMap[aws.StringValue(routeTable.VpcId)]), nil
+}
+
+// The internet gateway is kept in the VPC for the other route tables.
+func (routerHandler *AwsRouterHandler) DeleteRouter(routerID string) (bool, error) {
+	cblogger.Infof("routerID : [%s]", routerID)
+
+	routeTable, err := routerHandler.getRouteTable(routerID)
+	if err != nil {
+		return false, err
+	}
+	for _, association := range routeTable.Associations {
+		if association.SubnetId == nil {
+			continue
+		}
+		_, err := routerHandler.Client.DisassociateRouteTable(&ec2.DisassociateRouteTableInput{
+			AssociationId: association.RouteTableAssociationId,
+		})
+		if err != nil {
+			cblogger.Errorf("Unable to detach subnet %s from %s, %v", aws.StringValue(association.SubnetId), routerID, err)
+			return false, err
+		}
+	}
+
+	_, err = routerHandler.Client.DeleteRouteTable(&ec2.DeleteRouteTableInput{
+		RouteTableId: aws.String(routerID),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to delete route table %s, %v", routerID, err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (routerHandler *AwsRouterHandler) SetGateway(routerID string, gatewayID string) (irs.RouterInfo, error) {
+	cblogger.Infof("routerID : [%s], gatewayID : [%s]", routerID, gatewayID)
+
+	routeTable, err := routerHandler.getRouteTable(routerID)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	_, err = routerHandler.attachGateway(aws.StringValue(routeTable.VpcId), gatewayID)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
+}
+
+// The routes of the route table to the internet gateway are removed and the gateway is detached from the VPC,
+// so the other route tables of the VPC lose the gateway too. The gateway is not deleted.
+func (routerHandler *AwsRouterHandler) RemoveGateway(routerID string) (irs.RouterInfo, error) {
+	cblogger.Infof("routerID : [%s]", routerID)
+
+	routerInfo, err := routerHandler.GetRouter(routerID)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	if routerInfo.GatewayId == "" {
+		return irs.RouterInfo{}, errors.New("no internet gateway of route table " + routerID)
+	}
+
+	for _, route := range routerInfo.Routes {
+		if route.NextHop != irs.GatewayNextHop {
+			continue
+		}
+		if err := routerHandler.deleteRoute(routerID, route.DestinationCIDR); err != nil {
+			return irs.RouterInfo{}, err
+		}
+	}
+
+	_, err = routerHandler.Client.DetachInternetGateway(&ec2.DetachInternetGatewayInput{
+		InternetGatewayId: aws.String(routerInfo.GatewayId),
+		VpcId:             aws.String(routerInfo.VNetworkId),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to detach internet gateway %s, %v", routerInfo.GatewayId, err)
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
+}
+
+func (routerHandler *AwsRouterHandler) AddRoutes(routerID string, routes []irs.RouteInfo) (irs.RouterInfo, error) {
+	cblogger.Infof("routerID : [%s], routes : %v", routerID, routes)
+
+	routerInfo, err := routerHandler.GetRouter(routerID)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	err = routerHandler.createRoutes(routerID, routerInfo.GatewayId, routes)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
+}
+
+// A route of a route table is removed by the destination, there is only one route for a destination.
+func (routerHandler *AwsRouterHandler) RemoveRoutes(routerID string, routes []irs.RouteInfo) (irs.RouterInfo, error) {
+	cblogger.Infof("routerID : [%s], routes : %v", routerID, routes)
+
+	routerInfo, err := routerHandler.GetRouter(routerID)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	destinations := map[string]bool{}
+	for _, route := range routerInfo.Routes {
+		destinations[route.DestinationCIDR] = true
+	}
+
+	for _, route := range routes {
+		if !destinations[route.DestinationCIDR] {
+			return irs.RouterInfo{}, errors.New("no route to " + route.DestinationCIDR + " in route table " + routerID)
+		}
+	}
+	for _, route := range routes {
+		if err := routerHandler.deleteRoute(routerID, route.DestinationCIDR); err != nil {
+			return irs.RouterInfo{}, err
+		}
+	}
+	return routerHandler.GetRouter(routerID)
+}
+
+func (routerHandler *AwsRouterHandler) deleteRoute(routeTableId string, destinationCIDR string) error {
+	input := &ec2.DeleteRouteInput{
+		RouteTableId: aws.String(routeTableId),
+	}
+	if strings.Contains(destinationCIDR, ":") {
+		input.DestinationIpv6CidrBlock = aws.String(destinationCIDR)
+	} else {
+		input.DestinationCidrBlock = aws.String(destinationCIDR)
+	}
+
+	_, err := routerHandler.Client.DeleteRoute(input)
+	if err != nil {
+		cblogger.Errorf("Unable to remove the route to %s, %v", destinationCIDR, err)
+		return err
+	}
+	return nil
+}
+
+func (routerHandler *AwsRouterHandler) AttachSubnet(routerID string, subnetID string) (irs.RouterInfo, error) {
+	cblogger.Infof("routerID : [%s], subnetID : [%s]", routerID, subnetID)
+
+	if err := routerHandler.associateSubnet(routerID, subnetID); err != nil {
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
+}
+
+func (routerHandler *AwsRouterHandler) DetachSubnet(routerID string, subnetID string) (irs.RouterInfo, error) {
+	cblogger.Infof("routerID : [%s], subnetID : [%s]", routerID, subnetID)
+
+	routeTable, err := routerHandler.getRouteTable(routerID)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+
+	var associationId *string
+	for _, association := range routeTable.Associations {
+		if aws.StringValue(association.SubnetId) == subnetID {
+			associationId = association.RouteTableAssociationId
+		}
+	}
+	if associationId == nil {
+		return irs.RouterInfo{}, errors.New("subnet " + subnetID + " is not attached to route table " + routerID)
+	}
+
+	_, err = routerHandler.Client.DisassociateRouteTable(&ec2.DisassociateRouteTableInput{
+		AssociationId: associationId,
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to detach subnet %s from %s, %v", subnetID, routerID, err)
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
+}
@@ -11,6 +11,10 @@
 package resources
 
 import (
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
@@ -21,18 +25,400 @@ type AwsSecurityHandler struct {
 	Client *ec2.EC2
 }
 
+// EC2 has no deny rules and no priorities of rules(all rules are allowed together), so deny rules are rejected and priorities are ignored.
+// EC2 adds the allow-all outbound rule to a new group, it is replaced when outbound rules are requested.
 func (securityHandler *AwsSecurityHandler) CreateSecurity(securityReqInfo irs.SecurityReqInfo) (irs.SecurityInfo, error) {
-	return irs.SecurityInfo{}, nil
+	cblogger.Info("Start : ", securityReqInfo)
+
+	ingress, egress, err := getIpPermissions(securityReqInfo.SecurityRules)
+	if err != nil {
+		cblogger.Error(err)
+		return irs.SecurityInfo{}, err
+	}
+
+	// GroupName is the name of a security group, so the "Name" tag is not needed.
+	ec2Tags, err := getEc2Tags("", securityReqInfo.Tags)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+
+	// Description is required by EC2.
+	description := securityReqInfo.Description
+	if description == "" {
+		description = securityReqInfo.Name
+	}
+	input := &ec2.CreateSecurityGroupInput{
+		GroupName:   aws.String(securityReqInfo.Name),
+		Description: aws.String(description),
+	}
+	if securityReqInfo.VNetworkId != "" {
+		input.VpcId = aws.String(securityReqInfo.VNetworkId)
+	}
+	if len(ec2Tags) != 0 {
+		input.TagSpecifications = []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeSecurityGroup),
+				Tags:         ec2Tags,
+			},
+		}
+	}
+
+	result, err := securityHandler.Client.CreateSecurityGroup(input)
+	if err != nil {
+		cblogger.Errorf("Unable to create security group %s, %v", securityReqInfo.Name, err)
+		return irs.SecurityInfo{}, err
+	}
+	groupId := result.GroupId
+	cblogger.Infof("Created security group %s [%s]", securityReqInfo.Name, *groupId)
+
+	err = securityHandler.authorizeIpPermissions(groupId, ingress, egress, true)
+	if err != nil {
+		// the group without the requested rules is useless, so it is deleted.
+		_, deleteErr := securityHandler.Client.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{GroupId: groupId})
+		if deleteErr != nil {
+			cblogger.Errorf("Unable to delete security group %s, %v", *groupId, deleteErr)
+		}
+		return irs.SecurityInfo{}, err
+	}
+
+	return securityHandler.GetSecurity(*groupId)
+}
+
+// authorizeIpPermissions adds the IP permissions to a security group.
+// replaceDefaultEgress revokes the allow-all outbound rule of a new group before adding outbound rules.
+func (securityHandler *AwsSecurityHandler) authorizeIpPermissions(groupId *string, ingress []*ec2.IpPermission, egress []*ec2.IpPermission, replaceDefaultEgress bool) error {
+	if len(ingress) != 0 {
+		_, err := securityHandler.Client.AuthorizeSecurityGroupIngress(&ec2.AuthorizeSecurityGroupIngressInput{
+			GroupId:       groupId,
+			IpPermissions: ingress,
+		})
+		if err != nil {
+			cblogger.Errorf("Unable to add inbound rules to %s, %v", *groupId, err)
+			return err
+		}
+	}
+
+	if len(egress) == 0 {
+		return nil
+	}
+	if replaceDefaultEgress {
+		_, err := securityHandler.Client.RevokeSecurityGroupEgress(&ec2.RevokeSecurityGroupEgressInput{
+			GroupId: groupId,
+			IpPermissions: []*ec2.IpPermission{
+				{
+					IpProtocol: aws.String("-1"),
+					IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+				},
+			},
+		})
+		if err != nil {
+			cblogger.Errorf("Unable to revoke the default outbound rule of %s, %v", *groupId, err)
+			return err
+		}
+	}
+	_, err := securityHandler.Client.AuthorizeSecurityGroupEgress(&ec2.AuthorizeSecurityGroupEgressInput{
+		GroupId:       groupId,
+		IpPermissions: egress,
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to add outbound rules to %s, %v", *groupId, err)
+		return err
+	}
+	return nil
+}
+
+// getIpPermissions translates rules into the IP permissions of inbound(ingress) and outbound(egress) rules.
+func getIpPermissions(rules []irs.SecurityRuleInfo) ([]*ec2.IpPermission, []*ec2.IpPermission, error) {
+	var ingress, egress []*ec2.IpPermission
+
+	for _, rule := range rules {
+		permission, err := getIpPermission(rule)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rule.Direction == "inbound" {
+			ingress = append(ingress, permission)
+		} else {
+			egress = append(egress, permission)
+		}
+	}
+	return ingress, egress, nil
 }
 
-func (securityHandler *AwsSecurityHandler) ListSecurity() ([]*irs.SecurityInfo, error) {
-	return nil, nil
+// getIpPermission translates a rule into an IP permission.
+// -1 ports of tcp/udp are 0 ~ 65535, -1 type/code of icmp are all types/codes.
+func getIpPermission(rule irs.SecurityRuleInfo) (*ec2.IpPermission, error) {
+	if err := irs.CheckSecurityRule(rule); err != nil {
+		return nil, err
+	}
+	if rule.Action == "deny" {
+		return nil, errors.New("deny rules are not supported by AWS security groups")
+	}
+
+	permission := &ec2.IpPermission{}
+	switch rule.IPProtocol {
+	case "all":
+		permission.IpProtocol = aws.String("-1")
+	case "tcp", "udp":
+		permission.IpProtocol = aws.String(rule.IPProtocol)
+		fromPort, toPort := rule.FromPort, rule.ToPort
+		if fromPort == -1 {
+			fromPort, toPort = 0, 65535
+		}
+		permission.FromPort = aws.Int64(int64(fromPort))
+		permission.ToPort = aws.Int64(int64(toPort))
+	case "icmp":
+		permission.IpProtocol = aws.String(rule.IPProtocol)
+		permission.FromPort = aws.Int64(int64(rule.FromPort))
+		permission.ToPort = aws.Int64(int64(rule.ToPort))
+	}
+
+	switch {
+	case rule.PeerGroup != "":
+		permission.UserIdGroupPairs = []*ec2.UserIdGroupPair{{GroupId: aws.String(rule.PeerGroup)}}
+	case strings.Contains(rule.CIDR, ":"):
+		permission.Ipv6Ranges = []*ec2.Ipv6Range{{CidrIpv6: aws.String(rule.CIDR)}}
+	default:
+		permission.IpRanges = []*ec2.IpRange{{CidrIp: aws.String(rule.CIDR)}}
+	}
+	return permission, nil
+}
+
+func (securityHandler *AwsSecurityHandler) ListSecurity(listReqInfo irs.ListReqInfo) ([]*irs.SecurityInfo, irs.ListPageInfo, error) {
+	cblogger.Info("Start : ", listReqInfo)
+	var securityList []*irs.SecurityInfo
+
+	input := &ec2.DescribeSecurityGroupsInput{
+		Filters:    getListFilters(listReqInfo, "group-name"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 1000),
+		NextToken:  getNextToken(listReqInfo),
+	}
+
+	result, err := securityHandler.Client.DescribeSecurityGroups(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get security groups, %v", err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, group := range result.SecurityGroups {
+		securityInfo := extractSecurityGroup(group)
+		securityList = append(securityList, &securityInfo)
+	}
+
+	return securityList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
 }
 
 func (securityHandler *AwsSecurityHandler) GetSecurity(securityID string) (irs.SecurityInfo, error) {
-	return irs.SecurityInfo{}, nil
+	cblogger.Infof("securityID : [%s]", securityID)
+	input := &ec2.DescribeSecurityGroupsInput{
+		GroupIds: []*string{aws.String(securityID)},
+	}
+
+	result, err := securityHandler.Client.DescribeSecurityGroups(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get security group %s, %v", securityID, err)
+		return irs.SecurityInfo{}, err
+	}
+	if len(result.SecurityGroups) == 0 {
+		return irs.SecurityInfo{}, errors.New("security group not found: " + securityID)
+	}
+
+	return extractSecurityGroup(result.SecurityGroups[0]), nil
+}
+
+// extractSecurityGroup maps an EC2 security group into irs.SecurityInfo.
+func extractSecurityGroup(group *ec2.SecurityGroup) irs.SecurityInfo {
+	var securityRules []irs.SecurityRuleInfo
+	securityRules = append(securityRules, extractIpPermissions("inbound", group.IpPermissions)...)
+	securityRules = append(securityRules, extractIpPermissions("outbound", group.IpPermissionsEgress)...)
+
+	return irs.SecurityInfo{
+		Id:            aws.StringValue(group.GroupId),
+		Name:          aws.StringValue(group.GroupName),
+		Tags:          getTags(group.Tags),
+		Description:   aws.StringValue(group.Description),
+		VNetworkId:    aws.StringValue(group.VpcId),
+		SecurityRules: securityRules,
+		Owner:         aws.StringValue(group.OwnerId),
+	}
+}
+
+// extractIpPermissions maps the IP permissions of a direction into rules.
+// An IP permission has many CIDRs and peer groups, so it is split into a rule for each of them.
+// EC2 rules are always "allow".
+func extractIpPermissions(direction string, permissions []*ec2.IpPermission) []irs.SecurityRuleInfo {
+	var securityRules []irs.SecurityRuleInfo
+
+	for _, permission := range permissions {
+		rule := irs.SecurityRuleInfo{
+			Direction:  direction,
+			IPProtocol: aws.StringValue(permission.IpProtocol),
+			FromPort:   -1,
+			ToPort:     -1,
+			Action:     "allow",
+		}
+		if rule.IPProtocol == "-1" {
+			rule.IPProtocol = "all"
+		}
+		// FromPort and ToPort are not set for all ports(ex: protocol -1).
+		if permission.FromPort != nil {
+			rule.FromPort = int(*permission.FromPort)
+		}
+		if permission.ToPort != nil {
+			rule.ToPort = int(*permission.ToPort)
+		}
+		// all ports of tcp/udp are -1 like the requested rules.
+		if (rule.IPProtocol == "tcp" || rule.IPProtocol == "udp") && rule.FromPort == 0 && rule.ToPort == 65535 {
+			rule.FromPort, rule.ToPort = -1, -1
+		}
+
+		for _, ipRange := range permission.IpRanges {
+			cidrRule := rule
+			cidrRule.CIDR = aws.StringValue(ipRange.CidrIp)
+			securityRules = append(securityRules, cidrRule)
+		}
+		for _, ipv6Range := range permission.Ipv6Ranges {
+			cidrRule := rule
+			cidrRule.CIDR = aws.StringValue(ipv6Range.CidrIpv6)
+			securityRules = append(securityRules, cidrRule)
+		}
+		for _, pair := range permission.UserIdGroupPairs {
+			groupRule := rule
+			groupRule.PeerGroup = aws.StringValue(pair.GroupId)
+			securityRules = append(securityRules, groupRule)
+		}
+	}
+
+	return securityRules
 }
 
 func (securityHandler *AwsSecurityHandler) DeleteSecurity(securityID string) (bool, error) {
+	cblogger.Infof("securityID : [%s]", securityID)
+	_, err := securityHandler.Client.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{
+		GroupId: aws.String(securityID),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to delete security group %s, %v", securityID, err)
+		return false, err
+	}
 	return true, nil
 }
+
+func (securityHandler *AwsSecurityHandler) AddRules(securityID string, securityRules []irs.SecurityRuleInfo) (irs.SecurityInfo, error) {
+	cblogger.Infof("securityID : [%s], rules : %v", securityID, securityRules)
+
+	ingress, egress, err := getIpPermissions(securityRules)
+	if err != nil {
+		cblogger.Error(err)
+		return irs.SecurityInfo{}, err
+	}
+	err = securityHandler.authorizeIpPermissions(aws.String(securityID), ingress, egress, false)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+
+	return securityHandler.GetSecurity(securityID)
+}
+
+// EC2 rules have no Id, so the rules are removed by the same rules.
+func (securityHandler *AwsSecurityHandler) RemoveRules(securityID string, securityRules []irs.SecurityRuleInfo) (irs.SecurityInfo, error) {
+	cblogger.Infof("securityID : [%s], rules : %v", securityID, securityRules)
+
+	securityInfo, err := securityHandler.GetSecurity(securityID)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	rules, err := normalizeRules(securityRules)
+	if err != nil {
+		cblogger.Error(err)
+		return irs.SecurityInfo{}, err
+	}
+	removeRules, err := irs.FindSecurityRules(securityInfo.SecurityRules, rules)
+	if err != nil {
+		cblogger.Error(err)
+		return irs.SecurityInfo{}, err
+	}
+
+	err = securityHandler.revokeRules(securityID, removeRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+
+	return securityHandler.GetSecurity(securityID)
+}
+
+// New rules are added before the old ones are removed, so the allowed traffic is not cut during the sync.
+func (securityHandler *AwsSecurityHandler) SyncRules(securityID string, desiredRules []irs.SecurityRuleInfo) (irs.SecurityInfo, error) {
+	cblogger.Infof("securityID : [%s], rules : %v", securityID, desiredRules)
+
+	securityInfo, err := securityHandler.GetSecurity(securityID)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	rules, err := normalizeRules(desiredRules)
+	if err != nil {
+		cblogger.Error(err)
+		return irs.SecurityInfo{}, err
+	}
+	addRules, removeRules := irs.DiffSecurityRules(securityInfo.SecurityRules, rules)
+	cblogger.Infof("rules to add : %v, rules to remove : %v", addRules, removeRules)
+
+	ingress, egress, err := getIpPermissions(addRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	err = securityHandler.authorizeIpPermissions(aws.String(securityID), ingress, egress, false)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	err = securityHandler.revokeRules(securityID, removeRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+
+	return securityHandler.GetSecurity(securityID)
+}
+
+// revokeRules removes the IP permissions of rules from a security group.
+func (securityHandler *AwsSecurityHandler) revokeRules(securityID string, rules []irs.SecurityRuleInfo) error {
+	ingress, egress, err := getIpPermissions(rules)
+	if err != nil {
+		return err
+	}
+
+	if len(ingress) != 0 {
+		_, err := securityHandler.Client.RevokeSecurityGroupIngress(&ec2.RevokeSecurityGroupIngressInput{
+			GroupId:       aws.String(securityID),
+			IpPermissions: ingress,
+		})
+		if err != nil {
+			cblogger.Errorf("Unable to remove inbound rules from %s, %v", securityID, err)
+			return err
+		}
+	}
+	if len(egress) != 0 {
+		_, err := securityHandler.Client.RevokeSecurityGroupEgress(&ec2.RevokeSecurityGroupEgressInput{
+			GroupId:       aws.String(securityID),
+			IpPermissions: egress,
+		})
+		if err != nil {
+			cblogger.Errorf("Unable to remove outbound rules from %s, %v", securityID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeRules translates rules in the same way as the rules of EC2 are mapped(ex: tcp 0 ~ 65535 is -1),
+// so the requested rules can be compared with the current rules.
+func normalizeRules(rules []irs.SecurityRuleInfo) ([]irs.SecurityRuleInfo, error) {
+	ingress, egress, err := getIpPermissions(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalizedRules []irs.SecurityRuleInfo
+	normalizedRules = append(normalizedRules, extractIpPermissions("inbound", ingress)...)
+	normalizedRules = append(normalizedRules, extractIpPermissions("outbound", egress)...)
+	return normalizedRules, nil
+}
@@ -0,0 +1,238 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// AwsSnapshotHandler handles EBS snapshots owned by the account.
+type AwsSnapshotHandler struct {
+	Region idrv.RegionInfo
+	Client *ec2.EC2
+}
+
+// Snapshots are not waited to be completed, RestoreSnapshot can use pending snapshots.
+func (snapshotHandler *AwsSnapshotHandler) CreateSnapshot(snapshotReqInfo irs.SnapshotReqInfo) ([]*irs.SnapshotInfo, error) {
+	cblogger.Info(snapshotReqInfo)
+	if err := irs.CheckSnapshotReqInfo(snapshotReqInfo); err != nil {
+		return nil, err
+	}
+
+	if snapshotReqInfo.DiskId != "" {
+		snapshotInfo, err := snapshotHandler.createSnapshot(snapshotReqInfo.Name, snapshotReqInfo.Tags, snapshotReqInfo.DiskId)
+		if err != nil {
+			return nil, err
+		}
+		return []*irs.SnapshotInfo{&snapshotInfo}, nil
+	}
+
+	result, err := snapshotHandler.Client.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(snapshotReqInfo.VMId)},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get instance %s, %v", snapshotReqInfo.VMId, err)
+		return nil, err
+	}
+	if len(result.Reservations) == 0 || len(result.Reservations[0].Instances) == 0 {
+		return nil, errors.New("instance not found: " + snapshotReqInfo.VMId)
+	}
+	instance := result.Reservations[0].Instances[0]
+
+	// the root volume first
+	var volumeIDs []string
+	for _, mapping := range instance.BlockDeviceMappings {
+		if mapping.Ebs == nil {
+			continue
+		}
+		if aws.StringValue(mapping.DeviceName) == aws.StringValue(instance.RootDeviceName) {
+			volumeIDs = append([]string{aws.StringValue(mapping.Ebs.VolumeId)}, volumeIDs...)
+		} else {
+			volumeIDs = append(volumeIDs, aws.StringValue(mapping.Ebs.VolumeId))
+		}
+	}
+	if len(volumeIDs) == 0 {
+		return nil, errors.New("no EBS volume of instance " + snapshotReqInfo.VMId)
+	}
+
+	var snapshotList []*irs.SnapshotInfo
+	for i, volumeID := range volumeIDs {
+		name := fmt.Sprintf("%s-%d", snapshotReqInfo.Name, i)
+		snapshotInfo, err := snapshotHandler.createSnapshot(name, snapshotReqInfo.Tags, volumeID)
+		if err != nil {
+			return snapshotList, err
+		}
+		snapshotInfo.VMId = snapshotReqInfo.VMId
+		snapshotInfo.RootDisk = i == 0 && aws.StringValue(instance.RootDeviceType) == ec2.DeviceTypeEbs
+		snapshotList = append(snapshotList, &snapshotInfo)
+	}
+	return snapshotList, nil
+}
+
+func (snapshotHandler *AwsSnapshotHandler) createSnapshot(name string, tags map[string]string, volumeID string) (irs.SnapshotInfo, error) {
+	ec2Tags, err := getEc2Tags(name, tags)
+	if err != nil {
+		return irs.SnapshotInfo{}, err
+	}
+	input := &ec2.CreateSnapshotInput{
+		VolumeId:    aws.String(volumeID),
+		Description: aws.String(name),
+	}
+	if len(ec2Tags) != 0 {
+		input.TagSpecifications = []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeSnapshot),
+				Tags:         ec2Tags,
+			},
+		}
+	}
+
+	snapshot, err := snapshotHandler.Client.CreateSnapshot(input)
+	if err != nil {
+		cblogger.Errorf("Unable to create snapshot %s of volume %s, %v", name, volumeID, err)
+		return irs.SnapshotInfo{}, err
+	}
+	return extractSnapshot(snapshot), nil
+}
+
+func (snapshotHandler *AwsSnapshotHandler) ListSnapshot(listReqInfo irs.ListReqInfo) ([]*irs.SnapshotInfo, irs.ListPageInfo, error) {
+	cblogger.Info("Start : ", listReqInfo)
+	var snapshotList []*irs.SnapshotInfo
+
+	input := &ec2.DescribeSnapshotsInput{
+		OwnerIds:   []*string{aws.String("self")},
+		Filters:    getListFilters(listReqInfo, "tag:Name"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 1000),
+		NextToken:  getNextToken(listReqInfo),
+	}
+
+	result, err := snapshotHandler.Client.DescribeSnapshots(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get snapshots, %v", err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, snapshot := range result.Snapshots {
+		snapshotInfo := extractSnapshot(snapshot)
+		snapshotList = append(snapshotList, &snapshotInfo)
+	}
+
+	return snapshotList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
+}
+
+func (snapshotHandler *AwsSnapshotHandler) GetSnapshot(snapshotID string) (irs.SnapshotInfo, error) {
+	cblogger.Infof("snapshotID : [%s]", snapshotID)
+	snapshot, err := getSnapshot(snapshotHandler.Client, snapshotID)
+	if err != nil {
+		return irs.SnapshotInfo{}, err
+	}
+	return extractSnapshot(snapshot), nil
+}
+
+func getSnapshot(client *ec2.EC2, snapshotID string) (*ec2.Snapshot, error) {
+	result, err := client.DescribeSnapshots(&ec2.DescribeSnapshotsInput{
+		SnapshotIds: []*string{aws.String(snapshotID)},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get snapshot %s, %v", snapshotID, err)
+		return nil, err
+	}
+	if len(result.Snapshots) == 0 {
+		return nil, errors.New("snapshot not found: " + snapshotID)
+	}
+	return result.Snapshots[0], nil
+}
+
+// The snapshots used by images can not be deleted before the images are deregistered.
+func (snapshotHandler *AwsSnapshotHandler) DeleteSnapshot(snapshotID string) (bool, error) {
+	cblogger.Infof("snapshotID : [%s]", snapshotID)
+	_, err := snapshotHandler.Client.DeleteSnapshot(&ec2.DeleteSnapshotInput{
+		SnapshotId: aws.String(snapshotID),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to delete snapshot %s, %v", snapshotID, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// The volume is created in the Zone of diskReqInfo(default: the Zone of the connection).
+func (snapshotHandler *AwsSnapshotHandler) RestoreSnapshot(snapshotID string, diskReqInfo irs.DiskReqInfo) (irs.DiskInfo, error) {
+	cblogger.Infof("snapshotID : [%s]", snapshotID)
+	if diskReqInfo.SizeGiB < 0 {
+		return irs.DiskInfo{}, errors.New(fmt.Sprintf("invalid size of disk %s: %d GiB", diskReqInfo.Name, diskReqInfo.SizeGiB))
+	}
+	zone := diskReqInfo.Zone
+	if zone == "" {
+		zone = snapshotHandler.Region.Zone
+	}
+	if zone == "" {
+		return irs.DiskInfo{}, errors.New("the zone of EBS volume " + diskReqInfo.Name + " is required")
+	}
+
+	ec2Tags, err := getEc2Tags(diskReqInfo.Name, diskReqInfo.Tags)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	input := &ec2.CreateVolumeInput{
+		AvailabilityZone: aws.String(zone),
+		SnapshotId:       aws.String(snapshotID),
+	}
+	if diskReqInfo.SizeGiB > 0 {
+		input.Size = aws.Int64(int64(diskReqInfo.SizeGiB))
+	}
+	if diskReqInfo.DiskType != "" {
+		input.VolumeType = aws.String(diskReqInfo.DiskType)
+	}
+	if len(ec2Tags) != 0 {
+		input.TagSpecifications = []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeVolume),
+				Tags:         ec2Tags,
+			},
+		}
+	}
+
+	volume, err := snapshotHandler.Client.CreateVolume(input)
+	if err != nil {
+		cblogger.Errorf("Unable to restore snapshot %s, %v", snapshotID, err)
+		return irs.DiskInfo{}, err
+	}
+
+	diskHandler := AwsDiskHandler{snapshotHandler.Region, snapshotHandler.Client}
+	err = diskHandler.Client.WaitUntilVolumeAvailable(&ec2.DescribeVolumesInput{VolumeIds: []*string{volume.VolumeId}})
+	if err != nil {
+		cblogger.Errorf("failed to wait until volume %s is available, %v", aws.StringValue(volume.VolumeId), err)
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(aws.StringValue(volume.VolumeId))
+}
+
+// extractSnapshot maps an EBS snapshot into irs.SnapshotInfo.
+func extractSnapshot(snapshot *ec2.Snapshot) irs.SnapshotInfo {
+	return irs.SnapshotInfo{
+		Name:        getNameTag(snapshot.Tags),
+		Id:          aws.StringValue(snapshot.SnapshotId),
+		Tags:        getTags(snapshot.Tags),
+		DiskId:      aws.StringValue(snapshot.VolumeId),
+		SizeGiB:     int(aws.Int64Value(snapshot.VolumeSize)),
+		Status:      aws.StringValue(snapshot.State),
+		CreatedTime: aws.TimeValue(snapshot.StartTime),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Progress", Value: aws.StringValue(snapshot.Progress)},
+			{Key: "Description", Value: aws.StringValue(snapshot.Description)},
+		},
+	}
+}
@@ -0,0 +1,147 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+type AwsSubnetHandler struct {
+	Region idrv.RegionInfo
+	Client *ec2.EC2
+}
+
+// The DNS servers of a VPC are set by DHCP options sets and subnets have no IP pools, so they are rejected.
+func (subnetHandler *AwsSubnetHandler) CreateSubnet(vNetworkID string, subnetReqInfo irs.SubnetReqInfo) (irs.SubnetInfo, error) {
+	cblogger.Infof("vNetworkID : [%s], subnet : %v", vNetworkID, subnetReqInfo)
+
+	if len(subnetReqInfo.DNSServers) != 0 || len(subnetReqInfo.IPPools) != 0 {
+		return irs.SubnetInfo{}, errors.New("DNS servers and IP pools of subnets are not supported by AWS, subnet: " + subnetReqInfo.Name)
+	}
+	// the CIDR in the VPC is checked by EC2.
+	if err := irs.CheckSubnetReqInfo(subnetReqInfo, nil); err != nil {
+		return irs.SubnetInfo{}, err
+	}
+
+	subnet, err := createSubnet(subnetHandler.Client, vNetworkID, subnetReqInfo, nil)
+	if err != nil {
+		return irs.SubnetInfo{}, err
+	}
+	return extractSubnet(subnet), nil
+}
+
+// createSubnet creates a subnet in a VPC, the name of the subnet is kept in the "Name" tag with tags.
+func createSubnet(client *ec2.EC2, vpcId string, subnetReqInfo irs.SubnetReqInfo, tags map[string]string) (*ec2.Subnet, error) {
+	ec2Tags, err := getEc2Tags(subnetReqInfo.Name, tags)
+	if err != nil {
+		return nil, err
+	}
+	input := &ec2.CreateSubnetInput{
+		VpcId:     aws.String(vpcId),
+		CidrBlock: aws.String(subnetReqInfo.CIDR),
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeSubnet),
+				Tags:         ec2Tags,
+			},
+		},
+	}
+	if subnetReqInfo.Zone != "" {
+		input.AvailabilityZone = aws.String(subnetReqInfo.Zone)
+	}
+
+	result, err := client.CreateSubnet(input)
+	if err != nil {
+		cblogger.Errorf("Unable to create subnet %s, %v", subnetReqInfo.Name, err)
+		return nil, err
+	}
+	cblogger.Infof("Created subnet %s [%s]", subnetReqInfo.Name, aws.StringValue(result.Subnet.SubnetId))
+	return result.Subnet, nil
+}
+
+func (subnetHandler *AwsSubnetHandler) ListSubnet(vNetworkID string, listReqInfo irs.ListReqInfo) ([]*irs.SubnetInfo, irs.ListPageInfo, error) {
+	cblogger.Infof("vNetworkID : [%s], list : %v", vNetworkID, listReqInfo)
+	var subnetList []*irs.SubnetInfo
+
+	filters := append(getListFilters(listReqInfo, "tag:Name"), &ec2.Filter{
+		Name:   aws.String("vpc-id"),
+		Values: []*string{aws.String(vNetworkID)},
+	})
+	input := &ec2.DescribeSubnetsInput{
+		Filters:    filters,
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 1000),
+		NextToken:  getNextToken(listReqInfo),
+	}
+
+	result, err := subnetHandler.Client.DescribeSubnets(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get subnets of %s, %v", vNetworkID, err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, subnet := range result.Subnets {
+		subnetInfo := extractSubnet(subnet)
+		subnetList = append(subnetList, &subnetInfo)
+	}
+
+	return subnetList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
+}
+
+func (subnetHandler *AwsSubnetHandler) GetSubnet(subnetID string) (irs.SubnetInfo, error) {
+	cblogger.Infof("subnetID : [%s]", subnetID)
+	input := &ec2.DescribeSubnetsInput{
+		SubnetIds: []*string{aws.String(subnetID)},
+	}
+
+	result, err := subnetHandler.Client.DescribeSubnets(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get subnet %s, %v", subnetID, err)
+		return irs.SubnetInfo{}, err
+	}
+	if len(result.Subnets) == 0 {
+		return irs.SubnetInfo{}, errors.New("subnet not found: " + subnetID)
+	}
+
+	return extractSubnet(result.Subnets[0]), nil
+}
+
+// extractSubnet maps an EC2 subnet into irs.SubnetInfo.
+func extractSubnet(subnet *ec2.Subnet) irs.SubnetInfo {
+	return irs.SubnetInfo{
+		Id:         aws.StringValue(subnet.SubnetId),
+		Name:       getNameTag(subnet.Tags),
+		VNetworkId: aws.StringValue(subnet.VpcId),
+		CIDR:       aws.StringValue(subnet.CidrBlock),
+		Zone:       aws.StringValue(subnet.AvailabilityZone),
+		KeyValueList: []irs.KeyValue{
+			{Key: "State", Value: aws.StringValue(subnet.State)},
+			{Key: "DefaultForAz", Value: strconv.FormatBool(aws.BoolValue(subnet.DefaultForAz))},
+			{Key: "MapPublicIpOnLaunch", Value: strconv.FormatBool(aws.BoolValue(subnet.MapPublicIpOnLaunch))},
+			{Key: "AvailableIpAddressCount", Value: strconv.FormatInt(aws.Int64Value(subnet.AvailableIpAddressCount), 10)},
+		},
+	}
+}
+
+func (subnetHandler *AwsSubnetHandler) DeleteSubnet(subnetID string) (bool, error) {
+	cblogger.Infof("subnetID : [%s]", subnetID)
+	_, err := subnetHandler.Client.DeleteSubnet(&ec2.DeleteSubnetInput{
+		SubnetId: aws.String(subnetID),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to delete subnet %s, %v", subnetID, err)
+		return false, err
+	}
+	return true, nil
+}
@@ -7,15 +7,15 @@
 package resources
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
-	"reflect"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
-	"github.com/davecgh/go-spew/spew"
 	"github.com/sirupsen/logrus"
 
 	cblog "github.com/cloud-barista/cb-log"
@@ -30,6 +30,12 @@ type AwsVMHandler struct {
 
 var cblogger *logrus.Logger
 
+// the limit of the user data of an instance before the base64 encoding.
+const maxUserDataSize = 16 * 1024
+
+// the default limit of the security groups of an ENI.
+const maxSecurityGroupsPerNic = 5
+
 func init() {
 	// cblog is a global variable.
 	cblogger = cblog.GetLogger("AWS VMHandler")
@@ -52,13 +58,13 @@ func Connect(region string) *ec2.EC2 {
 	return svc
 }
 
-// @Todo : SecurityGroupId 배열 처리 방안
+// 여러개의 NIC과 NIC별 보안 그룹 배열은 NetworkInterfaces로 처리 함.
 // 1개의 VM만 생성되도록 수정 (MinCount / MaxCount 이용 안 함)
 //키페어 이름(예:mcloud-barista)은 아래 URL에 나오는 목록 중 "키페어 이름"의 값을 적으면 됨.
 //https://ap-northeast-2.console.aws.amazon.com/ec2/v2/home?region=ap-northeast-2#KeyPairs:sort=keyName
 func (vmHandler *AwsVMHandler) StartVM(vmReqInfo irs.VMReqInfo) (irs.VMInfo, error) {
-	cblogger.Info("Start VMHandler()::StartVM()")
-	spew.Dump(vmReqInfo)
+	// vmReqInfo has the password and the user data, so only the non-secret fields are logged.
+	cblogger.Infof("Start VMHandler()::StartVM() Name: [%s], ImageId: [%s], SpecID: [%s]", vmReqInfo.Name, vmReqInfo.ImageInfo.Id, vmReqInfo.SpecID)
 
 	imageID := vmReqInfo.ImageInfo.Id
 	instanceType := vmReqInfo.SpecID // "t2.micro"
@@ -69,42 +75,77 @@ func (vmHandler *AwsVMHandler) StartVM(vmReqInfo irs.VMReqInfo) (irs.VMInfo, err
 	subnetID := vmReqInfo.VNetworkInfo.Id        // "subnet-cf9ccf83" - 미지정시 기본 VPC의 기본 서브넷이 임의로 이용되며 PublicIP가 할당 됨.
 	baseName := vmReqInfo.Name                   //"mcloud-barista-VMHandlerTest"
 
+	// VM Name과 Tags는 생성 시 함께 설정 함.
+	ec2Tags, err := getEc2Tags(baseName, vmReqInfo.Tags)
+	if err != nil {
+		cblogger.Error(err)
+		return irs.VMInfo{}, err
+	}
+	var tagSpecifications []*ec2.TagSpecification
+	if len(ec2Tags) != 0 {
+		tagSpecifications = []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeInstance),
+				Tags:         ec2Tags,
+			},
+		}
+	}
+
+	// Root Disk 크기와 Data Disk는 BlockDeviceMappings로 생성 시 함께 설정 함.
+	blockDeviceMappings, err := vmHandler.getBlockDeviceMappings(vmReqInfo)
+	if err != nil {
+		cblogger.Error(err)
+		return irs.VMInfo{}, err
+	}
+
+	// User Data는 base64로 인코딩해서 전달 함. (인코딩 전 16KB 제한)
+	var userData *string
+	if vmReqInfo.UserData != "" {
+		err = irs.CheckUserData(vmReqInfo.UserData, maxUserDataSize, false)
+		if err != nil {
+			cblogger.Error(err)
+			return irs.VMInfo{}, err
+		}
+		userData = aws.String(base64.StdEncoding.EncodeToString([]byte(vmReqInfo.UserData)))
+	}
+
 	cblogger.Info("Create EC2 Instance")
 
 	// Specify the details of the instance that you want to create.
-	runResult, err := vmHandler.Client.RunInstances(&ec2.RunInstancesInput{
+	input := &ec2.RunInstancesInput{
 		ImageId:      aws.String(imageID),
 		InstanceType: aws.String(instanceType),
 		MinCount:     minCount,
 		MaxCount:     maxCount,
 		KeyName:      aws.String(keyName),
 
-		SecurityGroupIds: []*string{
+		TagSpecifications:   tagSpecifications,
+		BlockDeviceMappings: blockDeviceMappings,
+		UserData:            userData,
+	}
+
+	// NIC가 여러개 요청되면 NetworkInterfaces로 설정하고, 아니면 기존처럼 Subnet과 보안 그룹을 설정 함.
+	if len(vmReqInfo.NetworkInterfaces) != 0 {
+		networkInterfaces, err := getNetworkInterfaces(vmReqInfo.NetworkInterfaces)
+		if err != nil {
+			cblogger.Error(err)
+			return irs.VMInfo{}, err
+		}
+		input.NetworkInterfaces = networkInterfaces
+	} else {
+		input.SecurityGroupIds = []*string{
 			aws.String(securityGroupID), // set a security group.
-		},
+		}
+		input.SubnetId = aws.String(subnetID) // set a subnet.
+	}
 
-		SubnetId: aws.String(subnetID), // set a subnet.
-	})
+	runResult, err := vmHandler.Client.RunInstances(input)
 	if err != nil {
 		cblogger.Errorf("Could not create instance", err)
 		return irs.VMInfo{}, err
 	}
 
 	cblogger.Info("Created instance", *runResult.Instances[0].InstanceId)
-	// Tag에 VM Name 설정
-	_, errtag := vmHandler.Client.CreateTags(&ec2.CreateTagsInput{
-		Resources: []*string{runResult.Instances[0].InstanceId},
-		Tags: []*ec2.Tag{
-			{
-				Key:   aws.String("Name"),
-				Value: aws.String(baseName),
-			},
-		},
-	})
-	if errtag != nil {
-		cblogger.Error("Could not create tags for instance", runResult.Instances[0].InstanceId, errtag)
-		return irs.VMInfo{}, errtag
-	}
 
 	//빠른 생성을 위해 Running 상태를 대기하지 않고 최소한의 정보만 리턴 함.
 	//Running 상태를 대기 후 Public Ip 등의 정보를 추출하려면 GetVM()을 호출해서 최신 정보를 다시 받아와야 함.
@@ -114,15 +155,106 @@ func (vmHandler *AwsVMHandler) StartVM(vmReqInfo irs.VMReqInfo) (irs.VMInfo, err
 	//WaitForRun(vmHandler.Client, *runResult.Instances[0].InstanceId)
 	//cblogger.Info("EC2 Running 상태 완료 : ", runResult.Instances[0].State.Name)
 
-	vmInfo := ExtractDescribeInstances(runResult)
+	vmInfo := ExtractDescribeInstances(runResult, vmHandler.Region.Region)
 	//속도상 VM 정보를 다시 조회하지 않았기 때문에 Tag 정보가 누락되어서 Name 정보가 설정되어 있지 않음.
 	if vmInfo.Name == "" {
 		vmInfo.Name = baseName
 	}
+	if len(vmInfo.Tags) == 0 {
+		vmInfo.Tags = vmReqInfo.Tags
+	}
 
 	return vmInfo, nil
 }
 
+// getBlockDeviceMappings returns the EBS volumes of the root disk size and the data disks of vmReqInfo.
+// The root device name is read from the image, data disks are mapped from /dev/sdf and deleted with the instance.
+func (vmHandler *AwsVMHandler) getBlockDeviceMappings(vmReqInfo irs.VMReqInfo) ([]*ec2.BlockDeviceMapping, error) {
+	var blockDeviceMappings []*ec2.BlockDeviceMapping
+	usedDevices := map[string]bool{}
+
+	if vmReqInfo.RootDiskSizeGiB > 0 {
+		result, err := vmHandler.Client.DescribeImages(&ec2.DescribeImagesInput{
+			ImageIds: []*string{aws.String(vmReqInfo.ImageInfo.Id)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Images) == 0 {
+			return nil, errors.New("image not found: " + vmReqInfo.ImageInfo.Id)
+		}
+		rootDeviceName := aws.StringValue(result.Images[0].RootDeviceName)
+		usedDevices[rootDeviceName] = true
+		blockDeviceMappings = append(blockDeviceMappings, &ec2.BlockDeviceMapping{
+			DeviceName: aws.String(rootDeviceName),
+			Ebs: &ec2.EbsBlockDevice{
+				VolumeSize:          aws.Int64(int64(vmReqInfo.RootDiskSizeGiB)),
+				DeleteOnTermination: aws.Bool(true),
+			},
+		})
+	}
+
+	for _, diskReqInfo := range vmReqInfo.DataDisks {
+		if err := irs.CheckDiskReqInfo(diskReqInfo); err != nil {
+			return nil, err
+		}
+		if len(diskReqInfo.Tags) != 0 {
+			return nil, errors.New("the tags of data disks at launch are not supported for AWS, create the disk with DiskHandler: " + diskReqInfo.Name)
+		}
+		device, err := getDeviceName(usedDevices)
+		if err != nil {
+			return nil, err
+		}
+		usedDevices[device] = true
+
+		ebs := &ec2.EbsBlockDevice{
+			VolumeSize:          aws.Int64(int64(diskReqInfo.SizeGiB)),
+			DeleteOnTermination: aws.Bool(true),
+		}
+		if diskReqInfo.DiskType != "" {
+			ebs.VolumeType = aws.String(diskReqInfo.DiskType)
+		}
+		blockDeviceMappings = append(blockDeviceMappings, &ec2.BlockDeviceMapping{
+			DeviceName: aws.String(device),
+			Ebs:        ebs,
+		})
+	}
+	return blockDeviceMappings, nil
+}
+
+// getNetworkInterfaces returns the ENIs of the NICs of a VM request, the first one is the primary(eth0).
+// EC2 assigns a public IP only to the primary ENI of an instance with a single ENI.
+func getNetworkInterfaces(nics []irs.VMNicReqInfo) ([]*ec2.InstanceNetworkInterfaceSpecification, error) {
+	if err := irs.CheckVMNicReqInfo(nics, maxSecurityGroupsPerNic); err != nil {
+		return nil, err
+	}
+
+	var networkInterfaces []*ec2.InstanceNetworkInterfaceSpecification
+	for i, nic := range nics {
+		if nic.SubnetId == "" {
+			return nil, errors.New(fmt.Sprintf("the subnet of NIC %d is required", i))
+		}
+		if nic.PublicIP && len(nics) > 1 {
+			return nil, errors.New("a public IP at launch is supported only with a single NIC, associate an Elastic IP after the launch")
+		}
+
+		networkInterface := &ec2.InstanceNetworkInterfaceSpecification{
+			DeviceIndex:         aws.Int64(int64(i)),
+			SubnetId:            aws.String(nic.SubnetId),
+			Groups:              aws.StringSlice(nic.SecurityGroupIds), // 미지정시 VPC의 "default" 보안 그룹이 사용 됨.
+			DeleteOnTermination: aws.Bool(true),
+		}
+		if nic.PrivateIP != "" {
+			networkInterface.PrivateIpAddress = aws.String(nic.PrivateIP)
+		}
+		if nic.PublicIP {
+			networkInterface.AssociatePublicIpAddress = aws.Bool(true)
+		}
+		networkInterfaces = append(networkInterfaces, networkInterface)
+	}
+	return networkInterfaces, nil
+}
+
 //VM이 Running 상태일때까지 대기 함.
 func WaitForRun(svc *ec2.EC2, instanceID string) {
 	cblogger.Infof("EC2 ID : [%s]", instanceID)
@@ -244,8 +376,136 @@ func (vmHandler *AwsVMHandler) TerminateVM(vmID string) {
 	return
 }
 
-//- 보안그룹의 경우 멀티개 설정이 가능한데 현재는 1개만 입력 받음
-// @Todo : SecurityID에 보안그룹 Name을 할당하는게 맞는지 확인 필요
+// 인스턴스 타입은 중지된 상태에서만 변경할 수 있으므로 실행 중이면 중지 후 변경하고 다시 시작 함.
+func (vmHandler *AwsVMHandler) ResizeVM(vmID string, newSpec string) (irs.VMInfo, error) {
+	cblogger.Infof("vmID : [%s], newSpec : [%s]", vmID, newSpec)
+
+	reservation, err := vmHandler.describeInstance(vmID)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	instance := reservation.Instances[0]
+	if aws.StringValue(instance.InstanceType) == newSpec {
+		return ExtractDescribeInstances(reservation, vmHandler.Region.Region), nil
+	}
+
+	// 변경할 인스턴스 타입이 인스턴스의 가용 영역에서 제공되는지 확인 함.
+	zone := aws.StringValue(instance.Placement.AvailabilityZone)
+	offerings, err := vmHandler.Client.DescribeInstanceTypeOfferings(&ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+		Filters: []*ec2.Filter{
+			{Name: aws.String("instance-type"), Values: []*string{aws.String(newSpec)}},
+			{Name: aws.String("location"), Values: []*string{aws.String(zone)}},
+		},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get instance type offerings, %v", err)
+		return irs.VMInfo{}, err
+	}
+	if len(offerings.InstanceTypeOfferings) == 0 {
+		return irs.VMInfo{}, errors.New(fmt.Sprintf("instance type %s is not available in the zone %s of instance %s", newSpec, zone, vmID))
+	}
+
+	instanceIds := []*string{aws.String(vmID)}
+	state := aws.StringValue(instance.State.Name)
+	running := state == ec2.InstanceStateNameRunning || state == ec2.InstanceStateNamePending
+	switch state {
+	case ec2.InstanceStateNameRunning, ec2.InstanceStateNamePending:
+		// pending 상태의 인스턴스는 중지할 수 없으므로 running 상태가 될 때까지 기다림.
+		if state == ec2.InstanceStateNamePending {
+			err = vmHandler.Client.WaitUntilInstanceRunning(&ec2.DescribeInstancesInput{InstanceIds: instanceIds})
+			if err != nil {
+				cblogger.Errorf("failed to wait until pending instance %s is running, %v", vmID, err)
+				return irs.VMInfo{}, errors.New(fmt.Sprintf("instance %s is pending and can not be resized until it is running: %v", vmID, err))
+			}
+		}
+		cblogger.Info("EC2 중지 후 인스턴스 타입 변경")
+		_, err = vmHandler.Client.StopInstances(&ec2.StopInstancesInput{InstanceIds: instanceIds})
+		if err != nil {
+			cblogger.Errorf("Unable to stop instance %s, %v", vmID, err)
+			return irs.VMInfo{}, err
+		}
+	case ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped:
+	default:
+		return irs.VMInfo{}, errors.New(fmt.Sprintf("instance %s can not be resized in state %s", vmID, state))
+	}
+	err = vmHandler.Client.WaitUntilInstanceStopped(&ec2.DescribeInstancesInput{InstanceIds: instanceIds})
+	if err != nil {
+		cblogger.Errorf("failed to wait until instance %s is stopped, %v", vmID, err)
+		return irs.VMInfo{}, err
+	}
+
+	_, err = vmHandler.Client.ModifyInstanceAttribute(&ec2.ModifyInstanceAttributeInput{
+		InstanceId:   aws.String(vmID),
+		InstanceType: &ec2.AttributeValue{Value: aws.String(newSpec)},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to change the instance type of %s to %s, %v", vmID, newSpec, err)
+		// 변경에 실패해도 원래 실행 중이던 인스턴스는 다시 시작 함.
+		if running {
+			vmHandler.ResumeVM(vmID)
+		}
+		return irs.VMInfo{}, err
+	}
+
+	if running {
+		_, err = vmHandler.Client.StartInstances(&ec2.StartInstancesInput{InstanceIds: instanceIds})
+		if err != nil {
+			cblogger.Errorf("Unable to start instance %s, %v", vmID, err)
+			return irs.VMInfo{}, err
+		}
+		err = vmHandler.Client.WaitUntilInstanceRunning(&ec2.DescribeInstancesInput{InstanceIds: instanceIds})
+		if err != nil {
+			cblogger.Errorf("failed to wait until instance %s is running, %v", vmID, err)
+			return irs.VMInfo{}, err
+		}
+	}
+
+	// GetVM은 실패 시 빈 VMInfo를 반환하므로 직접 조회 함.
+	reservation, err = vmHandler.describeInstance(vmID)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	return ExtractDescribeInstances(reservation, vmHandler.Region.Region), nil
+}
+
+// describeInstance는 인스턴스의 Reservation을 조회 함, GetVM과 달리 오류를 반환 함.
+func (vmHandler *AwsVMHandler) describeInstance(vmID string) (*ec2.Reservation, error) {
+	result, err := vmHandler.Client.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(vmID)},
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get instance %s, %v", vmID, err)
+		return nil, err
+	}
+	if len(result.Reservations) == 0 || len(result.Reservations[0].Instances) == 0 {
+		return nil, errors.New("instance not found: " + vmID)
+	}
+	return result.Reservations[0], nil
+}
+
+// 콘솔 출력은 base64로 인코딩되어 있으며 EC2는 마지막 64KB만 보관 함.
+// 인스턴스 시작 직후에는 출력이 아직 없을 수 있음.
+func (vmHandler *AwsVMHandler) GetVMConsoleOutput(vmID string, tailLines int) (string, error) {
+	cblogger.Infof("vmID : [%s]", vmID)
+
+	result, err := vmHandler.Client.GetConsoleOutput(&ec2.GetConsoleOutputInput{
+		InstanceId: aws.String(vmID),
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get the console output of instance %s, %v", vmID, err)
+		return "", err
+	}
+
+	output, err := base64.StdEncoding.DecodeString(aws.StringValue(result.Output))
+	if err != nil {
+		cblogger.Errorf("Unable to decode the console output of instance %s, %v", vmID, err)
+		return "", err
+	}
+	return irs.TailLines(string(output), tailLines), nil
+}
+
+//- 보안그룹은 SecurityGroupIds에 모두 설정되며 SecurityID는 첫번째 보안그룹의 ID 임.
 func (vmHandler *AwsVMHandler) GetVM(vmID string) irs.VMInfo {
 	cblogger.Infof("vmID : [%s]", vmID)
 
@@ -271,14 +531,10 @@ func (vmHandler *AwsVMHandler) GetVM(vmID string) irs.VMInfo {
 
 	cblogger.Info("Success", result)
 
-	/*
-		- 보안그룹의 경우 멀티개 설정이 가능한데 현재는 1개만 입력 받음
-		- SecurityID에 보안그룹 Name을 할당하는게 맞는지 확인 필요
-	*/
 	vmInfo := irs.VMInfo{}
 	for _, i := range result.Reservations {
 		//vmInfo := ExtractDescribeInstances(result.Reservations[0])
-		vmInfo = ExtractDescribeInstances(i)
+		vmInfo = ExtractDescribeInstances(i, vmHandler.Region.Region)
 	}
 
 	cblogger.Info("vmInfo", vmInfo)
@@ -287,140 +543,107 @@ func (vmHandler *AwsVMHandler) GetVM(vmID string) irs.VMInfo {
 }
 
 // DescribeInstances결과에서 EC2 세부 정보 추출
-// VM 생성 시에는 Running 이전 상태의 정보가 넘어오기 때문에
-// 최종 정보 기반으로 리턴 받고 싶으면 GetVM에 통합해야 할 듯.
-func ExtractDescribeInstances(reservation *ec2.Reservation) irs.VMInfo {
-	//cblogger.Info("ExtractDescribeInstances", reservation)
-	cblogger.Debug("Instances[0]", reservation.Instances[0])
-
-	//"stopped" / "terminated" / "running" ...
-	var state string
-	state = *reservation.Instances[0].State.Name
-	cblogger.Info("EC2 상태 : [%s]", state)
-
-	//VM상태와 무관하게 항상 값이 존재하는 항목들만 초기화
+// VM 생성 시에는 Running 이전 상태의 정보가 넘어오기 때문에 Public IP 등 일부 정보가 비어 있음.
+// VM 상태는 GetVMStatus로 조회하며 KeyValueList의 State에도 설정 함.
+// region은 연결의 리전으로, Local Zone(예: us-east-1-bos-1a)의 이름에서는 리전을 구할 수 없음.
+func ExtractDescribeInstances(reservation *ec2.Reservation, region string) irs.VMInfo {
+	instance := reservation.Instances[0]
+	cblogger.Debug("Instances[0]", instance)
+
+	//VM상태와 무관하게 항상 값이 존재하는 항목들
 	vmInfo := irs.VMInfo{
-		Id:             *reservation.Instances[0].InstanceId,
-		ImageID:        *reservation.Instances[0].ImageId,
-		SpecID:         *reservation.Instances[0].InstanceType,
-		KeyPairID:      *reservation.Instances[0].KeyName,
-		GuestUserID:    "",
-		AdditionalInfo: "State:" + *reservation.Instances[0].State.Name,
-	}
+		Name:      getNameTag(instance.Tags), //Name은 Tag의 "Name" 속성에만 저장됨
+		Id:        aws.StringValue(instance.InstanceId),
+		StartTime: aws.TimeValue(instance.LaunchTime),
+		Tags:      getTags(instance.Tags),
+		ImageID:   aws.StringValue(instance.ImageId),
+		SpecID:    aws.StringValue(instance.InstanceType),
+		KeyPairID: aws.StringValue(instance.KeyName),
 
-	//cblogger.Info("=======>타입 : ", reflect.TypeOf(*reservation.Instances[0]))
-	//cblogger.Info("===> PublicIpAddress TypeOf : ", reflect.TypeOf(reservation.Instances[0].PublicIpAddress))
-	//cblogger.Info("===> PublicIpAddress ValueOf : ", reflect.ValueOf(reservation.Instances[0].PublicIpAddress))
+		// 특정 항목(예:EIP)은 VM 상태와 무관하게 동작하며, 중지된 VM 등은 값이 없을 수 있음.
+		VNetworkID:   aws.StringValue(instance.VpcId),
+		SubNetworkID: aws.StringValue(instance.SubnetId),
+		PublicIP:     aws.StringValue(instance.PublicIpAddress),
+		PublicDNS:    aws.StringValue(instance.PublicDnsName),
+		PrivateIP:    aws.StringValue(instance.PrivateIpAddress),
+		PrivateDNS:   aws.StringValue(instance.PrivateDnsName),
 
-	//vmInfo.PublicIP = *reservation.Instances[0].NetworkInterfaces[0].Association.PublicIp
-	//vmInfo.PublicDNS = *reservation.Instances[0].NetworkInterfaces[0].Association.PublicDnsName
-
-	// 특정 항목(예:EIP)은 VM 상태와 무관하게 동작하므로 VM 상태와 무관하게 Nil처리로 모든 필드를 처리 함.
-	if !reflect.ValueOf(reservation.Instances[0].PublicIpAddress).IsNil() {
-		vmInfo.PublicIP = *reservation.Instances[0].PublicIpAddress
+		GuestBootDisk: aws.StringValue(instance.RootDeviceName),
 	}
 
-	if !reflect.ValueOf(reservation.Instances[0].PublicDnsName).IsNil() {
-		vmInfo.PublicDNS = *reservation.Instances[0].PublicDnsName
+	vmInfo.Region = irs.RegionInfo{Region: region}
+	if instance.Placement != nil {
+		vmInfo.Region.Zone = aws.StringValue(instance.Placement.AvailabilityZone)
 	}
 
-	cblogger.Info("===> BlockDeviceMappings ValueOf : ", reflect.ValueOf(reservation.Instances[0].BlockDeviceMappings))
-	if !reflect.ValueOf(reservation.Instances[0].BlockDeviceMappings).IsNil() {
-		if !reflect.ValueOf(reservation.Instances[0].BlockDeviceMappings[0].DeviceName).IsNil() {
-			vmInfo.GuestBlockDisk = *reservation.Instances[0].BlockDeviceMappings[0].DeviceName
-		}
+	for _, group := range instance.SecurityGroups {
+		vmInfo.SecurityGroupIds = append(vmInfo.SecurityGroupIds, aws.StringValue(group.GroupId))
+	}
+	if len(vmInfo.SecurityGroupIds) != 0 {
+		vmInfo.SecurityID = vmInfo.SecurityGroupIds[0]
 	}
 
-	if !reflect.ValueOf(reservation.Instances[0].Placement.AvailabilityZone).IsNil() {
-		vmInfo.Region = irs.RegionInfo{
-			Region: *reservation.Instances[0].Placement.AvailabilityZone,
+	// Primary NIC(eth0)
+	for _, networkInterface := range instance.NetworkInterfaces {
+		if networkInterface.Attachment != nil && aws.Int64Value(networkInterface.Attachment.DeviceIndex) == 0 {
+			vmInfo.VNIC = aws.StringValue(networkInterface.NetworkInterfaceId)
 		}
 	}
 
-	//NetworkInterfaces 배열 값들
-	if !reflect.ValueOf(reservation.Instances[0].NetworkInterfaces).IsNil() {
-		if !reflect.ValueOf(reservation.Instances[0].NetworkInterfaces[0].VpcId).IsNil() {
-			vmInfo.VNetworkID = *reservation.Instances[0].NetworkInterfaces[0].VpcId
+	// Root Disk와 Data Disk(EBS)
+	for _, mapping := range instance.BlockDeviceMappings {
+		if mapping.Ebs == nil {
+			continue
 		}
-
-		if !reflect.ValueOf(reservation.Instances[0].NetworkInterfaces[0].SubnetId).IsNil() {
-			vmInfo.SubNetworkID = *reservation.Instances[0].NetworkInterfaces[0].SubnetId
+		if aws.StringValue(mapping.DeviceName) == vmInfo.GuestBootDisk {
+			vmInfo.RootDiskId = aws.StringValue(mapping.Ebs.VolumeId)
+			continue
 		}
-
-		if !reflect.ValueOf(reservation.Instances[0].NetworkInterfaces[0].Groups).IsNil() {
-			if !reflect.ValueOf(reservation.Instances[0].NetworkInterfaces[0].Groups[0].GroupId).IsNil() {
-				vmInfo.SecurityID = *reservation.Instances[0].NetworkInterfaces[0].Groups[0].GroupId
-			}
+		if vmInfo.GuestBlockDisk == "" {
+			vmInfo.GuestBlockDisk = aws.StringValue(mapping.DeviceName)
 		}
+		vmInfo.DataDiskIds = append(vmInfo.DataDiskIds, aws.StringValue(mapping.Ebs.VolumeId))
 	}
 
-	//SecurityName: *reservation.Instances[0].NetworkInterfaces[0].Groups[0].GroupName,
-	vmInfo.VNIC = "eth0 - 값 위치 확인 필요"
-
-	//vmInfo.PrivateIP = *reservation.Instances[0].NetworkInterfaces[0].PrivateIpAddress	//없는 경우 존재해서 Instances[0].PrivateIpAddress로 대체 - i-0b75cac73c4575386
-	if !reflect.ValueOf(reservation.Instances[0].PrivateIpAddress).IsNil() {
-		vmInfo.PrivateIP = *reservation.Instances[0].PrivateIpAddress
+	if instance.State != nil {
+		vmInfo.KeyValueList = append(vmInfo.KeyValueList, irs.KeyValue{Key: "State", Value: aws.StringValue(instance.State.Name)})
 	}
-
-	//vmInfo.PrivateDNS = *reservation.Instances[0].NetworkInterfaces[0].PrivateDnsName		//없는 경우 존재해서 Instances[0].PrivateDnsName로 대체 - i-0b75cac73c4575386
-	if !reflect.ValueOf(reservation.Instances[0].PrivateDnsName).IsNil() {
-		vmInfo.PrivateDNS = *reservation.Instances[0].PrivateDnsName
-	}
-
-	if !reflect.ValueOf(reservation.Instances[0].RootDeviceName).IsNil() {
-		vmInfo.GuestBootDisk = *reservation.Instances[0].RootDeviceName
-	}
-
-	//Name은 Tag의 "Name" 속성에만 저장됨
-	cblogger.Debug("Name Tag 찾기")
-	for _, t := range reservation.Instances[0].Tags {
-		if *t.Key == "Name" {
-			vmInfo.Name = *t.Value
-			cblogger.Debug("EC2 명칭 : ", vmInfo.Name)
-			break
-		}
+	vmInfo.KeyValueList = append(vmInfo.KeyValueList, irs.KeyValue{Key: "RootDeviceType", Value: aws.StringValue(instance.RootDeviceType)})
+	if instance.Architecture != nil {
+		vmInfo.KeyValueList = append(vmInfo.KeyValueList, irs.KeyValue{Key: "Architecture", Value: aws.StringValue(instance.Architecture)})
 	}
 
 	return vmInfo
 }
 
-func (vmHandler *AwsVMHandler) ListVM() []*irs.VMInfo {
+func (vmHandler *AwsVMHandler) ListVM(listReqInfo irs.ListReqInfo) ([]*irs.VMInfo, irs.ListPageInfo, error) {
 	cblogger.Infof("Start")
 	var vmInfoList []*irs.VMInfo
 
+	// MaxResults can not be used with InstanceIds.
 	input := &ec2.DescribeInstancesInput{
-		InstanceIds: []*string{
-			nil,
-		},
+		Filters:    getListFilters(listReqInfo, "tag:Name"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 1000),
+		NextToken:  getNextToken(listReqInfo),
 	}
 
 	result, err := vmHandler.Client.DescribeInstances(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			default:
-				cblogger.Error(aerr.Error())
-				return vmInfoList
-			}
-		} else {
-			// Print the error, cast err to awserr.Error to get the Code and Message from an error.
-			cblogger.Error(err.Error())
-			return vmInfoList
-		}
-		return vmInfoList
+		cblogger.Error(err.Error())
+		return nil, irs.ListPageInfo{}, err
 	}
 
 	cblogger.Info("Success")
 
 	for _, i := range result.Reservations {
 		for _, vm := range i.Instances {
-			cblogger.Info("[%s] EC2 정보 조회", *vm.InstanceId)
-			vmInfo := vmHandler.GetVM(*vm.InstanceId)
+			cblogger.Infof("[%s] EC2 정보 조회", *vm.InstanceId)
+			vmInfo := ExtractDescribeInstances(&ec2.Reservation{Instances: []*ec2.Instance{vm}}, vmHandler.Region.Region)
 			vmInfoList = append(vmInfoList, &vmInfo)
 		}
 	}
 
-	return vmInfoList
+	return vmInfoList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
 }
 
 //SHUTTING-DOWN / TERMINATED
@@ -464,30 +687,20 @@ func (vmHandler *AwsVMHandler) GetVMStatus(vmID string) irs.VMStatus {
 	return irs.VMStatus("")
 }
 
-func (vmHandler *AwsVMHandler) ListVMStatus() []*irs.VMStatusInfo {
+func (vmHandler *AwsVMHandler) ListVMStatus(listReqInfo irs.ListReqInfo) ([]*irs.VMStatusInfo, irs.ListPageInfo, error) {
 	cblogger.Infof("Start")
 	var vmStatusList []*irs.VMStatusInfo
 
 	input := &ec2.DescribeInstancesInput{
-		InstanceIds: []*string{
-			nil,
-		},
+		Filters:    getListFilters(listReqInfo, "tag:Name"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 1000),
+		NextToken:  getNextToken(listReqInfo),
 	}
 
 	result, err := vmHandler.Client.DescribeInstances(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			default:
-				cblogger.Error(aerr.Error())
-				return vmStatusList
-			}
-		} else {
-			// Print the error, cast err to awserr.Error to get the Code and Message from an error.
-			cblogger.Error(err.Error())
-			return vmStatusList
-		}
-		return vmStatusList
+		cblogger.Error(err.Error())
+		return nil, irs.ListPageInfo{}, err
 	}
 
 	cblogger.Info("Success")
@@ -506,5 +719,5 @@ func (vmHandler *AwsVMHandler) ListVMStatus() []*irs.VMStatusInfo {
 		}
 	}
 
-	return vmStatusList
+	return vmStatusList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
 }
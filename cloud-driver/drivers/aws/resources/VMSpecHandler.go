@@ -0,0 +1,109 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// AwsVMSpecHandler lists the instance types offered in the region.
+type AwsVMSpecHandler struct {
+	Region idrv.RegionInfo
+	Client *ec2.EC2
+}
+
+// Instance types have no tags, so TagFilter is not supported.
+func (vmSpecHandler *AwsVMSpecHandler) ListVMSpec(listReqInfo irs.ListReqInfo) ([]*irs.VMSpecInfo, irs.ListPageInfo, error) {
+	cblogger.Info("Start : ", listReqInfo)
+	if len(listReqInfo.TagFilter) != 0 {
+		return nil, irs.ListPageInfo{}, errors.New("TagFilter is not supported for instance types")
+	}
+	var vmSpecList []*irs.VMSpecInfo
+
+	input := &ec2.DescribeInstanceTypesInput{
+		Filters:    getListFilters(listReqInfo, "instance-type"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 100),
+		NextToken:  getNextToken(listReqInfo),
+	}
+
+	result, err := vmSpecHandler.Client.DescribeInstanceTypes(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get instance types, %v", err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, instanceType := range result.InstanceTypes {
+		vmSpecInfo := extractInstanceType(instanceType)
+		vmSpecList = append(vmSpecList, &vmSpecInfo)
+	}
+
+	return vmSpecList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
+}
+
+func (vmSpecHandler *AwsVMSpecHandler) GetVMSpec(specID string) (irs.VMSpecInfo, error) {
+	cblogger.Infof("specID : [%s]", specID)
+	input := &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(specID)},
+	}
+
+	result, err := vmSpecHandler.Client.DescribeInstanceTypes(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get instance type %s, %v", specID, err)
+		return irs.VMSpecInfo{}, err
+	}
+	if len(result.InstanceTypes) == 0 {
+		return irs.VMSpecInfo{}, errors.New("instance type not found: " + specID)
+	}
+
+	return extractInstanceType(result.InstanceTypes[0]), nil
+}
+
+// extractInstanceType maps an EC2 instance type into irs.VMSpecInfo.
+// The ID of an instance type is its name.
+func extractInstanceType(instanceType *ec2.InstanceTypeInfo) irs.VMSpecInfo {
+	name := aws.StringValue(instanceType.InstanceType)
+	vmSpecInfo := irs.VMSpecInfo{
+		Name: name,
+		Id:   name,
+		KeyValueList: []irs.KeyValue{
+			{Key: "CurrentGeneration", Value: strconv.FormatBool(aws.BoolValue(instanceType.CurrentGeneration))},
+			{Key: "Hypervisor", Value: aws.StringValue(instanceType.Hypervisor)},
+		},
+	}
+
+	if instanceType.VCpuInfo != nil {
+		vmSpecInfo.VCpuCount = int(aws.Int64Value(instanceType.VCpuInfo.DefaultVCpus))
+	}
+	if instanceType.MemoryInfo != nil {
+		vmSpecInfo.MemoryMiB = aws.Int64Value(instanceType.MemoryInfo.SizeInMiB)
+	}
+	if instanceType.GpuInfo != nil {
+		for _, gpu := range instanceType.GpuInfo.Gpus {
+			vmSpecInfo.GpuCount += int(aws.Int64Value(gpu.Count))
+		}
+	}
+	// EBS only instance types have no instance store.
+	if instanceType.InstanceStorageInfo != nil {
+		vmSpecInfo.LocalDiskGiB = aws.Int64Value(instanceType.InstanceStorageInfo.TotalSizeInGB)
+	}
+	if instanceType.NetworkInfo != nil {
+		vmSpecInfo.NetworkPerformance = aws.StringValue(instanceType.NetworkInfo.NetworkPerformance)
+	}
+	if instanceType.ProcessorInfo != nil {
+		vmSpecInfo.Architectures = aws.StringValueSlice(instanceType.ProcessorInfo.SupportedArchitectures)
+	}
+
+	return vmSpecInfo
+}
@@ -12,6 +12,11 @@
 package resources
 
 import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
@@ -22,23 +27,258 @@ type AwsVNetworkHandler struct {
 	Client *ec2.EC2
 }
 
-func (vNetworkHandler *AwsVNetworkHandler) ListVNetwork() ([]*irs.VNetworkInfo, error) {
+func (vNetworkHandler *AwsVNetworkHandler) ListVNetwork(listReqInfo irs.ListReqInfo) ([]*irs.VNetworkInfo, irs.ListPageInfo, error) {
 	cblogger.Debug("Start")
-	return nil, nil
+	var vNetworkList []*irs.VNetworkInfo
+
+	input := &ec2.DescribeVpcsInput{
+		Filters:    getListFilters(listReqInfo, "tag:Name"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 1000),
+		NextToken:  getNextToken(listReqInfo),
+	}
+
+	result, err := vNetworkHandler.Client.DescribeVpcs(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get VPCs, %v", err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	subnetMap, err := vNetworkHandler.getSubnets(result.Vpcs)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, vpc := range result.Vpcs {
+		vNetworkInfo := extractVpc(vpc, subnetMap[aws.StringValue(vpc.VpcId)])
+		vNetworkList = append(vNetworkList, &vNetworkInfo)
+	}
+
+	return vNetworkList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
 }
 
+// The first of the AddressPrefixes is the primary CIDR of the VPC, the others are added as secondary CIDRs.
+// The DNS servers of a VPC are set by DHCP options sets and subnets have no IP pools, so they are rejected.
 func (vNetworkHandler *AwsVNetworkHandler) CreateVNetwork(vNetworkReqInfo irs.VNetworkReqInfo) (irs.VNetworkInfo, error) {
 	cblogger.Info(vNetworkReqInfo)
-	return irs.VNetworkInfo{}, nil
+
+	if len(vNetworkReqInfo.AddressPrefixes) == 0 {
+		return irs.VNetworkInfo{}, errors.New("address prefixes are required by AWS VPCs")
+	}
+	if err := irs.CheckVNetworkReqInfo(vNetworkReqInfo); err != nil {
+		return irs.VNetworkInfo{}, err
+	}
+	for _, subnet := range vNetworkReqInfo.Subnets {
+		if len(subnet.DNSServers) != 0 || len(subnet.IPPools) != 0 {
+			return irs.VNetworkInfo{}, errors.New("DNS servers and IP pools of subnets are not supported by AWS, subnet: " + subnet.Name)
+		}
+	}
+
+	ec2Tags, err := getEc2Tags(vNetworkReqInfo.Name, vNetworkReqInfo.Tags)
+	if err != nil {
+		return irs.VNetworkInfo{}, err
+	}
+	input := &ec2.CreateVpcInput{
+		CidrBlock: aws.String(vNetworkReqInfo.AddressPrefixes[0]),
+	}
+	if len(ec2Tags) != 0 {
+		input.TagSpecifications = []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeVpc),
+				Tags:         ec2Tags,
+			},
+		}
+	}
+
+	result, err := vNetworkHandler.Client.CreateVpc(input)
+	if err != nil {
+		cblogger.Errorf("Unable to create VPC %s, %v", vNetworkReqInfo.Name, err)
+		return irs.VNetworkInfo{}, err
+	}
+	vpcId := aws.StringValue(result.Vpc.VpcId)
+	cblogger.Infof("Created VPC %s [%s]", vNetworkReqInfo.Name, vpcId)
+
+	err = vNetworkHandler.createVpcResources(vpcId, vNetworkReqInfo)
+	if err != nil {
+		// the VPC without the requested subnets is useless, so it is deleted with the created subnets.
+		if _, deleteErr := vNetworkHandler.DeleteVNetwork(vpcId); deleteErr != nil {
+			cblogger.Errorf("Unable to delete VPC %s, %v", vpcId, deleteErr)
+		}
+		return irs.VNetworkInfo{}, err
+	}
+
+	return vNetworkHandler.GetVNetwork(vpcId)
+}
+
+// createVpcResources adds the secondary CIDRs and the subnets of a request to a new VPC.
+func (vNetworkHandler *AwsVNetworkHandler) createVpcResources(vpcId string, vNetworkReqInfo irs.VNetworkReqInfo) error {
+	err := vNetworkHandler.Client.WaitUntilVpcAvailable(&ec2.DescribeVpcsInput{VpcIds: []*string{aws.String(vpcId)}})
+	if err != nil {
+		cblogger.Errorf("VPC %s is not available, %v", vpcId, err)
+		return err
+	}
+
+	for _, prefix := range vNetworkReqInfo.AddressPrefixes[1:] {
+		_, err := vNetworkHandler.Client.AssociateVpcCidrBlock(&ec2.AssociateVpcCidrBlockInput{
+			VpcId:     aws.String(vpcId),
+			CidrBlock: aws.String(prefix),
+		})
+		if err != nil {
+			cblogger.Errorf("Unable to add CIDR %s to VPC %s, %v", prefix, vpcId, err)
+			return err
+		}
+	}
+	if len(vNetworkReqInfo.AddressPrefixes) > 1 {
+		if err := vNetworkHandler.waitCidrBlocksAssociated(vpcId); err != nil {
+			return err
+		}
+	}
+
+	for _, subnet := range vNetworkReqInfo.Subnets {
+		if _, err := createSubnet(vNetworkHandler.Client, vpcId, subnet, vNetworkReqInfo.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitCidrBlocksAssociated waits until the CIDRs of a VPC are associated, there is no waiter of the SDK for it.
+func (vNetworkHandler *AwsVNetworkHandler) waitCidrBlocksAssociated(vpcId string) error {
+	for i := 0; i < 30; i++ {
+		result, err := vNetworkHandler.Client.DescribeVpcs(&ec2.DescribeVpcsInput{VpcIds: []*string{aws.String(vpcId)}})
+		if err != nil {
+			return err
+		}
+		if len(result.Vpcs) == 0 {
+			return errors.New("VPC not found: " + vpcId)
+		}
+
+		associated := true
+		for _, association := range result.Vpcs[0].CidrBlockAssociationSet {
+			if association.CidrBlockState == nil {
+				continue
+			}
+			state := aws.StringValue(association.CidrBlockState.State)
+			if state == ec2.VpcCidrBlockStateCodeFailed {
+				return errors.New("unable to add CIDR " + aws.StringValue(association.CidrBlock) + " to VPC " + vpcId)
+			}
+			if state != ec2.VpcCidrBlockStateCodeAssociated {
+				associated = false
+			}
+		}
+		if associated {
+			return nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return errors.New("timeout of adding CIDRs to VPC " + vpcId)
 }
 
 func (vNetworkHandler *AwsVNetworkHandler) GetVNetwork(vNetworkID string) (irs.VNetworkInfo, error) {
-	cblogger.Info("vNetworkID : [%s]", vNetworkID)
-	//result, err := vNetworkHandler.Client.DescribeKeyPairs(input)
-	return irs.VNetworkInfo{}, nil
+	cblogger.Infof("vNetworkID : [%s]", vNetworkID)
+	input := &ec2.DescribeVpcsInput{
+		VpcIds: []*string{aws.String(vNetworkID)},
+	}
+
+	result, err := vNetworkHandler.Client.DescribeVpcs(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get VPC %s, %v", vNetworkID, err)
+		return irs.VNetworkInfo{}, err
+	}
+	if len(result.Vpcs) == 0 {
+		return irs.VNetworkInfo{}, errors.New("VPC not found: " + vNetworkID)
+	}
+
+	subnetMap, err := vNetworkHandler.getSubnets(result.Vpcs)
+	if err != nil {
+		return irs.VNetworkInfo{}, err
+	}
+	return extractVpc(result.Vpcs[0], subnetMap[vNetworkID]), nil
+}
+
+// getSubnets gets the subnets of the VPCs with one call, the result is keyed by VPC ID.
+func (vNetworkHandler *AwsVNetworkHandler) getSubnets(vpcs []*ec2.Vpc) (map[string][]*ec2.Subnet, error) {
+	subnetMap := map[string][]*ec2.Subnet{}
+	if len(vpcs) == 0 {
+		return subnetMap, nil
+	}
+
+	var vpcIds []*string
+	for _, vpc := range vpcs {
+		vpcIds = append(vpcIds, vpc.VpcId)
+	}
+	input := &ec2.DescribeSubnetsInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("vpc-id"), Values: vpcIds},
+		},
+	}
+
+	err := vNetworkHandler.Client.DescribeSubnetsPages(input, func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+		for _, subnet := range page.Subnets {
+			vpcId := aws.StringValue(subnet.VpcId)
+			subnetMap[vpcId] = append(subnetMap[vpcId], subnet)
+		}
+		return true
+	})
+	if err != nil {
+		cblogger.Errorf("Unable to get subnets, %v", err)
+		return nil, err
+	}
+	return subnetMap, nil
 }
 
+// extractVpc maps a VPC and its subnets into irs.VNetworkInfo.
+func extractVpc(vpc *ec2.Vpc, subnets []*ec2.Subnet) irs.VNetworkInfo {
+	vNetworkInfo := irs.VNetworkInfo{
+		Id:     aws.StringValue(vpc.VpcId),
+		Name:   getNameTag(vpc.Tags),
+		Tags:   getTags(vpc.Tags),
+		Status: aws.StringValue(vpc.State),
+		Owner:  aws.StringValue(vpc.OwnerId),
+		KeyValueList: []irs.KeyValue{
+			{Key: "IsDefault", Value: strconv.FormatBool(aws.BoolValue(vpc.IsDefault))},
+			{Key: "InstanceTenancy", Value: aws.StringValue(vpc.InstanceTenancy)},
+			{Key: "DhcpOptionsId", Value: aws.StringValue(vpc.DhcpOptionsId)},
+		},
+	}
+
+	// the primary CIDR is also in the association set.
+	for _, association := range vpc.CidrBlockAssociationSet {
+		vNetworkInfo.AddressPrefixes = append(vNetworkInfo.AddressPrefixes, aws.StringValue(association.CidrBlock))
+	}
+	if len(vNetworkInfo.AddressPrefixes) == 0 && vpc.CidrBlock != nil {
+		vNetworkInfo.AddressPrefixes = []string{*vpc.CidrBlock}
+	}
+
+	for _, subnet := range subnets {
+		vNetworkInfo.Subnets = append(vNetworkInfo.Subnets, extractSubnet(subnet))
+	}
+	if len(vNetworkInfo.Subnets) != 0 {
+		vNetworkInfo.SubnetId = vNetworkInfo.Subnets[0].Id
+	}
+
+	return vNetworkInfo
+}
+
+// The subnets of a VPC are deleted first, a VPC with subnets can not be deleted.
 func (vNetworkHandler *AwsVNetworkHandler) DeleteVNetwork(vNetworkID string) (bool, error) {
-	cblogger.Info("vNetworkID : [%s]", vNetworkID)
+	cblogger.Infof("vNetworkID : [%s]", vNetworkID)
+
+	subnetMap, err := vNetworkHandler.getSubnets([]*ec2.Vpc{{VpcId: aws.String(vNetworkID)}})
+	if err != nil {
+		return false, err
+	}
+	for _, subnet := range subnetMap[vNetworkID] {
+		_, err := vNetworkHandler.Client.DeleteSubnet(&ec2.DeleteSubnetInput{SubnetId: subnet.SubnetId})
+		if err != nil {
+			cblogger.Errorf("Unable to delete subnet %s, %v", aws.StringValue(subnet.SubnetId), err)
+			return false, err
+		}
+	}
+
+	_, err = vNetworkHandler.Client.DeleteVpc(&ec2.DeleteVpcInput{VpcId: aws.String(vNetworkID)})
+	if err != nil {
+		cblogger.Errorf("Unable to delete VPC %s, %v", vNetworkID, err)
+		return false, err
+	}
 	return true, nil
 }
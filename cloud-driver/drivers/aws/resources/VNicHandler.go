@@ -11,6 +11,9 @@
 package resources
 
 import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
@@ -25,12 +28,77 @@ func (vNicHandler *AwsVNicHandler) CreateVNic(vNicReqInfo irs.VNicReqInfo) (irs.
 	return irs.VNicInfo{}, nil
 }
 
-func (vNicHandler *AwsVNicHandler) ListVNic() ([]*irs.VNicInfo, error) {
-	return nil, nil
+func (vNicHandler *AwsVNicHandler) ListVNic(listReqInfo irs.ListReqInfo) ([]*irs.VNicInfo, irs.ListPageInfo, error) {
+	cblogger.Info("Start : ", listReqInfo)
+	var vNicList []*irs.VNicInfo
+
+	input := &ec2.DescribeNetworkInterfacesInput{
+		Filters:    getListFilters(listReqInfo, "tag:Name"),
+		MaxResults: getMaxResults(listReqInfo.PageSize, 5, 1000),
+		NextToken:  getNextToken(listReqInfo),
+	}
+
+	result, err := vNicHandler.Client.DescribeNetworkInterfaces(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get network interfaces, %v", err)
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	for _, ni := range result.NetworkInterfaces {
+		vNicInfo := extractNetworkInterface(ni)
+		vNicList = append(vNicList, &vNicInfo)
+	}
+
+	return vNicList, irs.ListPageInfo{NextToken: aws.StringValue(result.NextToken)}, nil
 }
 
 func (vNicHandler *AwsVNicHandler) GetVNic(vNicID string) (irs.VNicInfo, error) {
-	return irs.VNicInfo{}, nil
+	cblogger.Infof("vNicID : [%s]", vNicID)
+	input := &ec2.DescribeNetworkInterfacesInput{
+		NetworkInterfaceIds: []*string{aws.String(vNicID)},
+	}
+
+	result, err := vNicHandler.Client.DescribeNetworkInterfaces(input)
+	if err != nil {
+		cblogger.Errorf("Unable to get network interface %s, %v", vNicID, err)
+		return irs.VNicInfo{}, err
+	}
+	if len(result.NetworkInterfaces) == 0 {
+		return irs.VNicInfo{}, errors.New("network interface not found: " + vNicID)
+	}
+
+	return extractNetworkInterface(result.NetworkInterfaces[0]), nil
+}
+
+// extractNetworkInterface maps an ENI into irs.VNicInfo.
+func extractNetworkInterface(ni *ec2.NetworkInterface) irs.VNicInfo {
+	vNicInfo := irs.VNicInfo{
+		Id:         aws.StringValue(ni.NetworkInterfaceId),
+		Name:       getNameTag(ni.TagSet),
+		Tags:       getTags(ni.TagSet),
+		VNetworkId: aws.StringValue(ni.VpcId),
+		SubnetId:   aws.StringValue(ni.SubnetId),
+		MacAddress: aws.StringValue(ni.MacAddress),
+		PrivateIP:  aws.StringValue(ni.PrivateIpAddress),
+		Status:     aws.StringValue(ni.Status),
+		Owner:      aws.StringValue(ni.OwnerId),
+		KeyValueList: []irs.KeyValue{
+			{Key: "InterfaceType", Value: aws.StringValue(ni.InterfaceType)},
+			{Key: "AvailabilityZone", Value: aws.StringValue(ni.AvailabilityZone)},
+			{Key: "Description", Value: aws.StringValue(ni.Description)},
+		},
+	}
+	if ni.Association != nil {
+		vNicInfo.PublicIP = aws.StringValue(ni.Association.PublicIp)
+	}
+	if ni.Attachment != nil {
+		vNicInfo.OwnedVMId = aws.StringValue(ni.Attachment.InstanceId)
+	}
+	for _, group := range ni.Groups {
+		vNicInfo.SecurityGroupIds = append(vNicInfo.SecurityGroupIds, aws.StringValue(group.GroupId))
+	}
+
+	return vNicInfo
 }
 
 func (vNicHandler *AwsVNicHandler) DeleteVNic(vNicID string) (bool, error) {
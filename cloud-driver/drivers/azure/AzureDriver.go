@@ -14,10 +14,15 @@ import (
 	"context"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-06-01/subscriptions"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2018-02-01/storage"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	azcon "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure/connect"
+	azrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure/resources"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	icon "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/connect"
+	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -33,7 +38,7 @@ func (AzureDriver) GetDriverCapability() idrv.DriverCapabilityInfo {
 	drvCapabilityInfo.ImageHandler = false
 	drvCapabilityInfo.VNetworkHandler = false
 	drvCapabilityInfo.SecurityHandler = false
-	drvCapabilityInfo.KeyPairHandler = false
+	drvCapabilityInfo.KeyPairHandler = true
 	drvCapabilityInfo.VNicHandler = false
 	drvCapabilityInfo.PublicIPHandler = false
 	drvCapabilityInfo.VMHandler = true
@@ -75,20 +80,77 @@ func (driver *AzureDriver) ConnectCloud(connectionInfo idrv.ConnectionInfo) (ico
 	if err != nil {
 		return nil, err
 	}
+	Ctx, routeTableClient, err := getRouteTableClient(connectionInfo.CredentialInfo)
+	if err != nil {
+		return nil, err
+	}
+	Ctx, vmSizeClient, err := getVMSizeClient(connectionInfo.CredentialInfo)
+	if err != nil {
+		return nil, err
+	}
+	Ctx, locationClient, err := getLocationClient(connectionInfo.CredentialInfo)
+	if err != nil {
+		return nil, err
+	}
+	Ctx, resourceSkuClient, err := getResourceSkuClient(connectionInfo.CredentialInfo)
+	if err != nil {
+		return nil, err
+	}
+	Ctx, diskClient, err := getDiskClient(connectionInfo.CredentialInfo)
+	if err != nil {
+		return nil, err
+	}
+	Ctx, snapshotClient, err := getSnapshotClient(connectionInfo.CredentialInfo)
+	if err != nil {
+		return nil, err
+	}
+	Ctx, storageAccountClient, err := getStorageAccountClient(connectionInfo.CredentialInfo)
+	if err != nil {
+		return nil, err
+	}
 	iConn := azcon.AzureCloudConnection{
-		Region:              connectionInfo.RegionInfo,
-		Ctx:                 Ctx,
-		VMClient:            VMClient,
-		ImageClient:         imageClient,
-		PublicIPClient:      publicIPClient,
-		SecurityGroupClient: sgClient,
-		VNetClient:          VNetClient,
-		VNicClient:          vNicClient,
-		SubnetClient:        SubnetClient,
+		Region:               connectionInfo.RegionInfo,
+		Ctx:                  Ctx,
+		VMClient:             VMClient,
+		ImageClient:          imageClient,
+		PublicIPClient:       publicIPClient,
+		SecurityGroupClient:  sgClient,
+		VNetClient:           VNetClient,
+		VNicClient:           vNicClient,
+		SubnetClient:         SubnetClient,
+		RouteTableClient:     routeTableClient,
+		VMSizeClient:         vmSizeClient,
+		LocationClient:       locationClient,
+		ResourceSkuClient:    resourceSkuClient,
+		DiskClient:           diskClient,
+		SnapshotClient:       snapshotClient,
+		StorageAccountClient: storageAccountClient,
+		KeyPairPath:          getKeyPairPath(connectionInfo),
+	}
+
+	// the location(and zone) typed in the connection must be available.
+	regionZoneHandler := azrs.AzureRegionZoneHandler{
+		Region:         connectionInfo.RegionInfo,
+		Ctx:            Ctx,
+		Client:         locationClient,
+		SkuClient:      resourceSkuClient,
+		SubscriptionID: VMClient.SubscriptionID,
+	}
+	err = regionZoneHandler.ValidateRegionZone(connectionInfo.RegionInfo.Region, connectionInfo.RegionInfo.Zone)
+	if err != nil {
+		return nil, err
 	}
+
 	return &iConn, nil
 }
 
+// getKeyPairPath returns the local path of the key pairs(Azure has no key pair resources) of a resource group,
+// ex) $CBSPIDER_PATH/key/azure/{subscription ID}/{resource group}
+func getKeyPairPath(connectionInfo idrv.ConnectionInfo) string {
+	rootPath := os.Getenv("CBSPIDER_PATH")
+	return filepath.Join(rootPath, "key", "azure", connectionInfo.CredentialInfo.SubscriptionId, connectionInfo.RegionInfo.ResourceGroup)
+}
+
 func getVMClient(credential idrv.CredentialInfo) (context.Context, *compute.VirtualMachinesClient, error) {
 	/*auth.NewClientCredentialsConfig()
 	  authorizer, err := auth.NewAuthorizerFromFile(azure.PublicCloud.ResourceManagerEndpoint)
@@ -192,4 +254,102 @@ func getSubnetClient(credential idrv.CredentialInfo) (context.Context, *network.
 	return ctx, &subnetClient, nil
 }
 
+func getRouteTableClient(credential idrv.CredentialInfo) (context.Context, *network.RouteTablesClient, error) {
+	config := auth.NewClientCredentialsConfig(credential.ClientId, credential.ClientSecret, credential.TenantId)
+	authorizer, err := config.Authorizer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	routeTableClient := network.NewRouteTablesClient(credential.SubscriptionId)
+	routeTableClient.Authorizer = authorizer
+	ctx, _ := context.WithTimeout(context.Background(), 600*time.Second)
+
+	return ctx, &routeTableClient, nil
+}
+
+func getVMSizeClient(credential idrv.CredentialInfo) (context.Context, *compute.VirtualMachineSizesClient, error) {
+	config := auth.NewClientCredentialsConfig(credential.ClientId, credential.ClientSecret, credential.TenantId)
+	authorizer, err := config.Authorizer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vmSizeClient := compute.NewVirtualMachineSizesClient(credential.SubscriptionId)
+	vmSizeClient.Authorizer = authorizer
+	ctx, _ := context.WithTimeout(context.Background(), 600*time.Second)
+
+	return ctx, &vmSizeClient, nil
+}
+
+func getLocationClient(credential idrv.CredentialInfo) (context.Context, *subscriptions.Client, error) {
+	config := auth.NewClientCredentialsConfig(credential.ClientId, credential.ClientSecret, credential.TenantId)
+	authorizer, err := config.Authorizer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	locationClient := subscriptions.NewClient()
+	locationClient.Authorizer = authorizer
+	ctx, _ := context.WithTimeout(context.Background(), 600*time.Second)
+
+	return ctx, &locationClient, nil
+}
+
+func getResourceSkuClient(credential idrv.CredentialInfo) (context.Context, *compute.ResourceSkusClient, error) {
+	config := auth.NewClientCredentialsConfig(credential.ClientId, credential.ClientSecret, credential.TenantId)
+	authorizer, err := config.Authorizer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resourceSkuClient := compute.NewResourceSkusClient(credential.SubscriptionId)
+	resourceSkuClient.Authorizer = authorizer
+	ctx, _ := context.WithTimeout(context.Background(), 600*time.Second)
+
+	return ctx, &resourceSkuClient, nil
+}
+
+func getDiskClient(credential idrv.CredentialInfo) (context.Context, *compute.DisksClient, error) {
+	config := auth.NewClientCredentialsConfig(credential.ClientId, credential.ClientSecret, credential.TenantId)
+	authorizer, err := config.Authorizer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diskClient := compute.NewDisksClient(credential.SubscriptionId)
+	diskClient.Authorizer = authorizer
+	ctx, _ := context.WithTimeout(context.Background(), 600*time.Second)
+
+	return ctx, &diskClient, nil
+}
+
+func getSnapshotClient(credential idrv.CredentialInfo) (context.Context, *compute.SnapshotsClient, error) {
+	config := auth.NewClientCredentialsConfig(credential.ClientId, credential.ClientSecret, credential.TenantId)
+	authorizer, err := config.Authorizer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshotClient := compute.NewSnapshotsClient(credential.SubscriptionId)
+	snapshotClient.Authorizer = authorizer
+	ctx, _ := context.WithTimeout(context.Background(), 600*time.Second)
+
+	return ctx, &snapshotClient, nil
+}
+
+func getStorageAccountClient(credential idrv.CredentialInfo) (context.Context, *storage.AccountsClient, error) {
+	config := auth.NewClientCredentialsConfig(credential.ClientId, credential.ClientSecret, credential.TenantId)
+	authorizer, err := config.Authorizer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	storageAccountClient := storage.NewAccountsClient(credential.SubscriptionId)
+	storageAccountClient.Authorizer = authorizer
+	ctx, _ := context.WithTimeout(context.Background(), 600*time.Second)
+
+	return ctx, &storageAccountClient, nil
+}
+
 var TestDriver AzureDriver
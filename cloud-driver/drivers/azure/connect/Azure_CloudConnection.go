@@ -15,21 +15,31 @@ import (
 	"fmt"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-06-01/subscriptions"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2018-02-01/storage"
 	azrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure/resources"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 )
 
 type AzureCloudConnection struct {
-	Region              idrv.RegionInfo
-	Ctx                 context.Context
-	VMClient            *compute.VirtualMachinesClient
-	ImageClient         *compute.ImagesClient
-	PublicIPClient      *network.PublicIPAddressesClient
-	SecurityGroupClient *network.SecurityGroupsClient
-	VNetClient          *network.VirtualNetworksClient
-	VNicClient          *network.InterfacesClient
-	SubnetClient        *network.SubnetsClient
+	Region               idrv.RegionInfo
+	Ctx                  context.Context
+	VMClient             *compute.VirtualMachinesClient
+	ImageClient          *compute.ImagesClient
+	PublicIPClient       *network.PublicIPAddressesClient
+	SecurityGroupClient  *network.SecurityGroupsClient
+	VNetClient           *network.VirtualNetworksClient
+	VNicClient           *network.InterfacesClient
+	SubnetClient         *network.SubnetsClient
+	RouteTableClient     *network.RouteTablesClient
+	VMSizeClient         *compute.VirtualMachineSizesClient
+	LocationClient       *subscriptions.Client
+	ResourceSkuClient    *compute.ResourceSkusClient
+	DiskClient           *compute.DisksClient
+	SnapshotClient       *compute.SnapshotsClient
+	StorageAccountClient *storage.AccountsClient
+	KeyPairPath          string
 }
 
 func (cloudConn *AzureCloudConnection) CreateVNetworkHandler() (irs.VNetworkHandler, error) {
@@ -38,9 +48,15 @@ func (cloudConn *AzureCloudConnection) CreateVNetworkHandler() (irs.VNetworkHand
 	return &vNetHandler, nil
 }
 
+func (cloudConn *AzureCloudConnection) CreateSubnetHandler() (irs.SubnetHandler, error) {
+	fmt.Println("Azure Cloud Driver: called CreateSubnetHandler()!")
+	subnetHandler := azrs.AzureSubnetHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.SubnetClient}
+	return &subnetHandler, nil
+}
+
 func (cloudConn *AzureCloudConnection) CreateImageHandler() (irs.ImageHandler, error) {
 	fmt.Println("Azure Cloud Driver: called CreateImageHandler()!")
-	imageHandler := azrs.AzureImageHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.ImageClient}
+	imageHandler := azrs.AzureImageHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.ImageClient, cloudConn.SnapshotClient}
 	return &imageHandler, nil
 }
 
@@ -49,8 +65,10 @@ func (cloudConn *AzureCloudConnection) CreateSecurityHandler() (irs.SecurityHand
 	sgHandler := azrs.AzureSecurityHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.SecurityGroupClient}
 	return &sgHandler, nil
 }
-func (AzureCloudConnection) CreateKeyPairHandler() (irs.KeyPairHandler, error) {
-	return nil, nil
+func (cloudConn *AzureCloudConnection) CreateKeyPairHandler() (irs.KeyPairHandler, error) {
+	fmt.Println("Azure Cloud Driver: called CreateKeyPairHandler()!")
+	keyPairHandler := azrs.AzureKeyPairHandler{cloudConn.Region, cloudConn.KeyPairPath}
+	return &keyPairHandler, nil
 }
 func (cloudConn *AzureCloudConnection) CreateVNicHandler() (irs.VNicHandler, error) {
 	fmt.Println("Azure Cloud Driver: called CreateVNicHandler()!")
@@ -59,13 +77,38 @@ func (cloudConn *AzureCloudConnection) CreateVNicHandler() (irs.VNicHandler, err
 }
 func (cloudConn *AzureCloudConnection) CreatePublicIPHandler() (irs.PublicIPHandler, error) {
 	fmt.Println("Azure Cloud Driver: called CreatePublicIPHandler()!")
-	publicIPHandler := azrs.AzurePublicIPHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.PublicIPClient}
+	publicIPHandler := azrs.AzurePublicIPHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.PublicIPClient, cloudConn.VNicClient, cloudConn.VMClient}
 	return &publicIPHandler, nil
 }
+func (cloudConn *AzureCloudConnection) CreateRouterHandler() (irs.RouterHandler, error) {
+	fmt.Println("Azure Cloud Driver: called CreateRouterHandler()!")
+	routerHandler := azrs.AzureRouterHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.RouteTableClient, cloudConn.SubnetClient}
+	return &routerHandler, nil
+}
+func (cloudConn *AzureCloudConnection) CreateVMSpecHandler() (irs.VMSpecHandler, error) {
+	fmt.Println("Azure Cloud Driver: called CreateVMSpecHandler()!")
+	vmSpecHandler := azrs.AzureVMSpecHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.VMSizeClient}
+	return &vmSpecHandler, nil
+}
+func (cloudConn *AzureCloudConnection) CreateRegionZoneHandler() (irs.RegionZoneHandler, error) {
+	fmt.Println("Azure Cloud Driver: called CreateRegionZoneHandler()!")
+	regionZoneHandler := azrs.AzureRegionZoneHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.LocationClient, cloudConn.ResourceSkuClient, cloudConn.VMClient.SubscriptionID}
+	return &regionZoneHandler, nil
+}
+func (cloudConn *AzureCloudConnection) CreateDiskHandler() (irs.DiskHandler, error) {
+	fmt.Println("Azure Cloud Driver: called CreateDiskHandler()!")
+	diskHandler := azrs.AzureDiskHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.DiskClient, cloudConn.VMClient}
+	return &diskHandler, nil
+}
+func (cloudConn *AzureCloudConnection) CreateSnapshotHandler() (irs.SnapshotHandler, error) {
+	fmt.Println("Azure Cloud Driver: called CreateSnapshotHandler()!")
+	snapshotHandler := azrs.AzureSnapshotHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.SnapshotClient, cloudConn.DiskClient, cloudConn.VMClient}
+	return &snapshotHandler, nil
+}
 
 func (cloudConn *AzureCloudConnection) CreateVMHandler() (irs.VMHandler, error) {
 	fmt.Println("Azure Cloud Driver: called CreateVMHandler()!")
-	vmHandler := azrs.AzureVMHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.VMClient}
+	vmHandler := azrs.AzureVMHandler{cloudConn.Region, cloudConn.Ctx, cloudConn.VMClient, cloudConn.VNicClient, cloudConn.PublicIPClient, cloudConn.SecurityGroupClient, cloudConn.SubnetClient, cloudConn.KeyPairPath, cloudConn.DiskClient, cloudConn.StorageAccountClient}
 	return &vmHandler, nil
 }
 
@@ -13,6 +13,7 @@ package main
 import (
 	"fmt"
 	azdrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure"
+	azrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure/resources"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
@@ -30,20 +31,26 @@ func getVMInfo() {
 	config := readConfigFile()
 
 	// Get VM List
-	vmList := vmHandler.ListVM()
+	vmList, _, err := vmHandler.ListVM(irs.ListReqInfo{})
+	if err != nil {
+		panic(err)
+	}
 	for i, vm := range vmList {
 		fmt.Println("[", i, "] ")
 		spew.Dump(vm)
 	}
 
-	vmId := config.Azure.GroupName + ":" + config.Azure.VMName
+	vmId := config.Azure.VMName
 
 	// Get VM Info
 	vmInfo := vmHandler.GetVM(vmId)
 	spew.Dump(vmInfo)
 
 	// Get VM Status List
-	vmStatusList := vmHandler.ListVMStatus()
+	vmStatusList, _, err := vmHandler.ListVMStatus(irs.ListReqInfo{})
+	if err != nil {
+		panic(err)
+	}
 	for i, vmStatus := range vmStatusList {
 		fmt.Println("[", i, "] ", *vmStatus)
 	}
@@ -74,7 +81,7 @@ func handleVM() {
 			panic(err)
 		}
 
-		vmId := config.Azure.GroupName + ":" + config.Azure.VMName
+		vmId := config.Azure.VMName
 
 		if inputCnt == 1 {
 			switch commandNum {
@@ -108,7 +115,7 @@ func createVM() {
 	}
 	config := readConfigFile()
 
-	vmName := config.Azure.GroupName + ":" + config.Azure.VMName
+	vmName := config.Azure.VMName
 	imageId := config.Azure.Image.Publisher + ":" + config.Azure.Image.Offer + ":" + config.Azure.Image.Sku + ":" + config.Azure.Image.Version
 	vmReqInfo := irs.VMReqInfo{
 		Name: vmName,
@@ -116,9 +123,13 @@ func createVM() {
 			Id: imageId,
 		},
 		SpecID: config.Azure.VMSize,
-		VNetworkInfo: irs.VNetworkInfo{
+		VNicInfo: irs.VNicInfo{
 			Id: config.Azure.Network.ID,
 		},
+		// Test_Create.go에서 생성한 로컬 키 페어
+		KeyPairInfo: irs.KeyPairInfo{
+			Name: keyPairName,
+		},
 		LoginInfo: irs.LoginInfo{
 			AdminUsername: config.Azure.Os.AdminUsername,
 			AdminPassword: config.Azure.Os.AdminPassword,
@@ -155,13 +166,13 @@ Loop:
 			panic(err)
 		}
 
-		imageId := config.Azure.ImageInfo.GroupName + ":" + config.Azure.ImageInfo.Name
+		imageId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.ImageInfo.GroupName, ResourceType: azrs.ImagesType, Name: config.Azure.ImageInfo.Name}.String()
 
 		if inputCnt == 1 {
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListImage() ...")
-				imageHandler.ListImage()
+				imageHandler.ListImage(irs.ListReqInfo{})
 				fmt.Println("Finish ListImage()")
 			case 2:
 				fmt.Println("Start GetImage() ...")
@@ -169,7 +180,7 @@ Loop:
 				fmt.Println("Finish GetImage()")
 			case 3:
 				fmt.Println("Start CreateImage() ...")
-				reqInfo := irs.ImageReqInfo{Id: imageId}
+				reqInfo := irs.ImageReqInfo{Id: imageId, SnapshotId: config.Azure.ImageInfo.SnapshotId}
 				_, err := imageHandler.CreateImage(reqInfo)
 				if err != nil {
 					panic(err)
@@ -209,13 +220,13 @@ Loop:
 			panic(err)
 		}
 		
-		publicIPId := config.Azure.PublicIP.GroupName + ":" + config.Azure.PublicIP.Name
+		publicIPId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.PublicIP.GroupName, ResourceType: azrs.PublicIPAddressesType, Name: config.Azure.PublicIP.Name}.String()
 		
 		if inputCnt == 1 {
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListPublicIP() ...")
-				publicIPHandler.ListPublicIP()
+				publicIPHandler.ListPublicIP(irs.ListReqInfo{})
 				fmt.Println("Finish ListPublicIP()")
 			case 2:
 				fmt.Println("Start GetPublicIP() ...")
@@ -263,13 +274,13 @@ Loop:
 			panic(err)
 		}
 		
-		securityId := config.Azure.Security.GroupName + ":" + config.Azure.Security.Name
+		securityId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.Security.GroupName, ResourceType: azrs.SecurityGroupsType, Name: config.Azure.Security.Name}.String()
 		
 		if inputCnt == 1 {
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListSecurity() ...")
-				securityHandler.ListSecurity()
+				securityHandler.ListSecurity(irs.ListReqInfo{})
 				fmt.Println("Finish ListSecurity()")
 			case 2:
 				fmt.Println("Start GetSecurity() ...")
@@ -277,7 +288,13 @@ Loop:
 				fmt.Println("Finish GetSecurity()")
 			case 3:
 				fmt.Println("Start CreateSecurity() ...")
-				reqInfo := irs.SecurityReqInfo{Id: securityId}
+				reqInfo := irs.SecurityReqInfo{
+					Id: securityId,
+					SecurityRules: []irs.SecurityRuleInfo{
+						{Direction: "inbound", IPProtocol: "tcp", FromPort: 80, ToPort: 80, CIDR: "*", Priority: 300},
+						{Direction: "inbound", IPProtocol: "tcp", FromPort: 22, ToPort: 22, CIDR: "*", Priority: 320},
+					},
+				}
 				_, err := securityHandler.CreateSecurity(reqInfo)
 				if err != nil {
 					panic(err)
@@ -317,13 +334,13 @@ Loop:
 			panic(err)
 		}
 
-		networkId := config.Azure.VNetwork.GroupName + ":" + config.Azure.VNetwork.Name
+		networkId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.VNetwork.GroupName, ResourceType: azrs.VirtualNetworksType, Name: config.Azure.VNetwork.Name}.String()
 
 		if inputCnt == 1 {
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListVNetwork() ...")
-				vNetHandler.ListVNetwork()
+				vNetHandler.ListVNetwork(irs.ListReqInfo{})
 				fmt.Println("Finish ListVNetwork()")
 			case 2:
 				fmt.Println("Start GetVNetwork() ...")
@@ -331,7 +348,13 @@ Loop:
 				fmt.Println("Finish GetVNetwork()")
 			case 3:
 				fmt.Println("Start CreateVNetwork() ...")
-				reqInfo := irs.VNetworkReqInfo{Id: networkId}
+				reqInfo := irs.VNetworkReqInfo{
+					Id:              networkId,
+					AddressPrefixes: []string{"130.0.0.0/8"},
+					Subnets: []irs.SubnetReqInfo{
+						{Name: "default", CIDR: "130.1.0.0/16"},
+					},
+				}
 				_, err := vNetHandler.CreateVNetwork(reqInfo)
 				if err != nil {
 					panic(err)
@@ -372,13 +395,13 @@ Loop:
 			panic(err)
 		}
 		
-		vNicId := config.Azure.VNic.GroupName + ":" + config.Azure.VNic.Name
+		vNicId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.VNic.GroupName, ResourceType: azrs.NetworkInterfacesType, Name: config.Azure.VNic.Name}.String()
 		
 		if inputCnt == 1 {
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListVNic() ...")
-				vNicHandler.ListVNic()
+				vNicHandler.ListVNic(irs.ListReqInfo{})
 				fmt.Println("Finish ListVNic()")
 			case 2:
 				fmt.Println("Start GetVNic() ...")
@@ -623,8 +646,9 @@ type Config struct {
 		ServerId string `yaml:"server_id"`
 		
 		ImageInfo struct {
-			GroupName string `yaml:"group_name"`
-			Name      string `yaml:"name"`
+			GroupName  string `yaml:"group_name"`
+			Name       string `yaml:"name"`
+			SnapshotId string `yaml:"snapshot_id"` // the snapshot of a generalized OS disk
 		} `yaml:"image_info"`
 		
 		PublicIP struct {
@@ -650,6 +674,8 @@ type Config struct {
 	} `yaml:"azure"`
 }
 
+const keyPairName = "mcb-test-key"
+
 func readConfigFile() Config {
 	// Set Environment Value of Project Root Path
 	rootPath := os.Getenv("CBSPIDER_PATH")
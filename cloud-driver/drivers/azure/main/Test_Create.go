@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	azdrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure"
+	azrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure/resources"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
@@ -42,12 +43,19 @@ func testCreateVM() {
 	securityHandler, _ := cloudConnection.CreateSecurityHandler()
 	publicIPHandler, _ := cloudConnection.CreatePublicIPHandler()
 	vNicHandler, _ := cloudConnection.CreateVNicHandler()
+	keyPairHandler, _ := cloudConnection.CreateKeyPairHandler()
 	vmHandler, _ := cloudConnection.CreateVMHandler()
 	
 	// 1. Virtual Network 생성
-	vNetworkId := config.Azure.VNetwork.GroupName + ":" + config.Azure.VNetwork.Name
+	vNetworkId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.VNetwork.GroupName, ResourceType: azrs.VirtualNetworksType, Name: config.Azure.VNetwork.Name}.String()
 	fmt.Println("Start CreateVNetwork() ...")
-	vNetReqInfo := irs.VNetworkReqInfo{Id: vNetworkId}
+	vNetReqInfo := irs.VNetworkReqInfo{
+		Id:              vNetworkId,
+		AddressPrefixes: []string{"130.0.0.0/8"},
+		Subnets: []irs.SubnetReqInfo{
+			{Name: "default", CIDR: "130.1.0.0/16"},
+		},
+	}
 	_, err := vNetworkHandler.CreateVNetwork(vNetReqInfo)
 	if err != nil {
 		panic(err)
@@ -55,9 +63,15 @@ func testCreateVM() {
 	fmt.Println("Finish CreateVNetwork()")
 	
 	// 2. Security Group 생성
-	securityGroupId := config.Azure.Security.GroupName + ":" + config.Azure.Security.Name
+	securityGroupId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.Security.GroupName, ResourceType: azrs.SecurityGroupsType, Name: config.Azure.Security.Name}.String()
 	fmt.Println("Start CreateSecurity() ...")
-	secReqInfo := irs.SecurityReqInfo{Id: securityGroupId}
+	secReqInfo := irs.SecurityReqInfo{
+		Id: securityGroupId,
+		SecurityRules: []irs.SecurityRuleInfo{
+			{Direction: "inbound", IPProtocol: "tcp", FromPort: 80, ToPort: 80, CIDR: "*", Priority: 300},
+			{Direction: "inbound", IPProtocol: "tcp", FromPort: 22, ToPort: 22, CIDR: "*", Priority: 320},
+		},
+	}
 	_, err = securityHandler.CreateSecurity(secReqInfo)
 	if err != nil {
 		panic(err)
@@ -65,7 +79,7 @@ func testCreateVM() {
 	fmt.Println("Finish CreateSecurity()")
 	
 	// 3. Public IP 생성
-	publicIPId := config.Azure.PublicIP.GroupName + ":" + config.Azure.PublicIP.Name
+	publicIPId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.PublicIP.GroupName, ResourceType: azrs.PublicIPAddressesType, Name: config.Azure.PublicIP.Name}.String()
 	fmt.Println("Start CreatePublicIP() ...")
 	publicIPReqInfo := irs.PublicIPReqInfo{Id: publicIPId}
 	_, err = publicIPHandler.CreatePublicIP(publicIPReqInfo)
@@ -75,7 +89,7 @@ func testCreateVM() {
 	fmt.Println("Finish CreatePublicIP()")
 	
 	// 4. Virtual Network Interface 생성
-	vNicId := config.Azure.VNic.GroupName + ":" + config.Azure.VNic.Name
+	vNicId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.VNic.GroupName, ResourceType: azrs.NetworkInterfacesType, Name: config.Azure.VNic.Name}.String()
 	fmt.Println("Start CreateVNic() ...")
 	vNicReqInfo := irs.VNicReqInfo{Id: vNicId}
 	_, err = vNicHandler.CreateVNic(vNicReqInfo)
@@ -84,9 +98,24 @@ func testCreateVM() {
 	}
 	fmt.Println("Finish CreateVNic()")
 	
-	// 5. VM 생성
+	// 5. KeyPair 생성 (mcb-test-key.pub가 있으면 import, 없으면 새로 생성)
+	fmt.Println("Start CreateKey() ...")
+	keyPairReqInfo := irs.KeyPairReqInfo{
+		Name:      keyPairName,
+		PublicKey: readPublicKey(),
+	}
+	keyPair, err := keyPairHandler.CreateKey(keyPairReqInfo)
+	if err != nil {
+		panic(err)
+	}
+	if keyPair.PrivateKey != "" {
+		writePrivateKey(keyPair)
+	}
+	fmt.Println("Finish CreateKey()", keyPair.Fingerprint)
+	
+	// 6. VM 생성
 	fmt.Println("Start Create VM ...")
-	vmName := config.Azure.GroupName + ":" + config.Azure.VMName
+	vmName := config.Azure.VMName
 	imageId := config.Azure.Image.Publisher + ":" + config.Azure.Image.Offer + ":" + config.Azure.Image.Sku + ":" + config.Azure.Image.Version
 	vmReqInfo := irs.VMReqInfo{
 		Name: vmName,
@@ -94,9 +123,12 @@ func testCreateVM() {
 			Id: imageId,
 		},
 		SpecID: config.Azure.VMSize,
-		VNetworkInfo: irs.VNetworkInfo{
+		VNicInfo: irs.VNicInfo{
 			Id: config.Azure.Nic.ID,
 		},
+		KeyPairInfo: irs.KeyPairInfo{
+			Name: keyPair.Name,
+		},
 		LoginInfo: irs.LoginInfo{
 			AdminUsername: config.Azure.Os.AdminUsername,
 			//AdminPassword: config.Azure.Os.AdminPassword,
@@ -171,6 +203,31 @@ type Config struct {
 	} `yaml:"azure"`
 }
 
+const keyPairName = "mcb-test-key"
+
+// read the public key to import, "": a new key pair is generated
+func readPublicKey() string {
+	rootPath := os.Getenv("CBSPIDER_PATH")
+	sshPublicKeyPath := rootPath + "/key/mcb-test-key.pub"
+	if _, err := os.Stat(sshPublicKeyPath); err != nil {
+		return ""
+	}
+	sshBytes, err := ioutil.ReadFile(sshPublicKeyPath)
+	if err != nil {
+		panic(err)
+	}
+	return string(sshBytes)
+}
+
+// the private key is returned only once, so it is saved as $CBSPIDER_PATH/key/{key pair name}.pem
+func writePrivateKey(keyPair irs.KeyPairInfo) {
+	rootPath := os.Getenv("CBSPIDER_PATH")
+	err := ioutil.WriteFile(rootPath+"/key/"+keyPair.Name+".pem", []byte(keyPair.PrivateKey), 0600)
+	if err != nil {
+		panic(err)
+	}
+}
+
 func readConfigFile() Config {
 	// Set Environment Value of Project Root Path
 	rootPath := os.Getenv("CBSPIDER_PATH")
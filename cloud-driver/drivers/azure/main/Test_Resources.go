@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	azdrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure"
+	azrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure/resources"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"gopkg.in/yaml.v3"
@@ -25,7 +26,7 @@ func testImageHandler(config Config) {
 	fmt.Println("4. DeleteImage()")
 	fmt.Println("5. Exit")
 	
-	imageId := config.Azure.ImageInfo.GroupName + ":" + config.Azure.ImageInfo.Name
+	imageId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.ImageInfo.GroupName, ResourceType: azrs.ImagesType, Name: config.Azure.ImageInfo.Name}.String()
 	
 Loop:
 	for {
@@ -39,7 +40,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListImage() ...")
-				imageHandler.ListImage()
+				imageHandler.ListImage(irs.ListReqInfo{})
 				fmt.Println("Finish ListImage()")
 			case 2:
 				fmt.Println("Start GetImage() ...")
@@ -80,7 +81,7 @@ func testPublicIPHanlder(config Config) {
 	fmt.Println("4. DeletePublicIP()")
 	fmt.Println("5. Exit")
 	
-	publicIPId := config.Azure.PublicIP.GroupName + ":" + config.Azure.PublicIP.Name
+	publicIPId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.PublicIP.GroupName, ResourceType: azrs.PublicIPAddressesType, Name: config.Azure.PublicIP.Name}.String()
 
 Loop:
 	for {
@@ -94,7 +95,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListPublicIP() ...")
-				publicIPHandler.ListPublicIP()
+				publicIPHandler.ListPublicIP(irs.ListReqInfo{})
 				fmt.Println("Finish ListPublicIP()")
 			case 2:
 				fmt.Println("Start GetPublicIP() ...")
@@ -135,7 +136,7 @@ func testSecurityHandler(config Config) {
 	fmt.Println("4. DeleteSecurity()")
 	fmt.Println("5. Exit")
 	
-	securityGroupId := config.Azure.Security.GroupName + ":" + config.Azure.Security.Name
+	securityGroupId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.Security.GroupName, ResourceType: azrs.SecurityGroupsType, Name: config.Azure.Security.Name}.String()
 	
 Loop:
 
@@ -150,7 +151,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListSecurity() ...")
-				securityHandler.ListSecurity()
+				securityHandler.ListSecurity(irs.ListReqInfo{})
 				fmt.Println("Finish ListSecurity()")
 			case 2:
 				fmt.Println("Start GetSecurity() ...")
@@ -158,7 +159,13 @@ Loop:
 				fmt.Println("Finish GetSecurity()")
 			case 3:
 				fmt.Println("Start CreateSecurity() ...")
-				reqInfo := irs.SecurityReqInfo{Id: securityGroupId}
+				reqInfo := irs.SecurityReqInfo{
+					Id: securityGroupId,
+					SecurityRules: []irs.SecurityRuleInfo{
+						{Direction: "inbound", IPProtocol: "tcp", FromPort: 80, ToPort: 80, CIDR: "*", Priority: 300},
+						{Direction: "inbound", IPProtocol: "tcp", FromPort: 22, ToPort: 22, CIDR: "*", Priority: 320},
+					},
+				}
 				_, err := securityHandler.CreateSecurity(reqInfo)
 				if err != nil {
 					panic(err)
@@ -191,7 +198,7 @@ func testVNetworkHandler(config Config) {
 	fmt.Println("4. DeleteVNetwork()")
 	fmt.Println("5. Exit")
 
-	vNetworkId := config.Azure.VNetwork.GroupName + ":" + config.Azure.VNetwork.Name
+	vNetworkId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.VNetwork.GroupName, ResourceType: azrs.VirtualNetworksType, Name: config.Azure.VNetwork.Name}.String()
 	
 Loop:
 
@@ -206,7 +213,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListVNetwork() ...")
-				vNetworkHandler.ListVNetwork()
+				vNetworkHandler.ListVNetwork(irs.ListReqInfo{})
 				fmt.Println("Finish ListVNetwork()")
 			case 2:
 				fmt.Println("Start GetVNetwork() ...")
@@ -214,7 +221,13 @@ Loop:
 				fmt.Println("Finish GetVNetwork()")
 			case 3:
 				fmt.Println("Start CreateVNetwork() ...")
-				reqInfo := irs.VNetworkReqInfo{Id: vNetworkId}
+				reqInfo := irs.VNetworkReqInfo{
+					Id:              vNetworkId,
+					AddressPrefixes: []string{"130.0.0.0/8"},
+					Subnets: []irs.SubnetReqInfo{
+						{Name: "default", CIDR: "130.1.0.0/16"},
+					},
+				}
 				_, err := vNetworkHandler.CreateVNetwork(reqInfo)
 				if err != nil {
 					panic(err)
@@ -247,7 +260,7 @@ func testVNicHandler(config Config) {
 	fmt.Println("4. DeleteVNic()")
 	fmt.Println("5. Exit Program")
 	
-	vNicId := config.Azure.VNic.GroupName + ":" + config.Azure.VNic.Name
+	vNicId := azrs.ResourceID{SubscriptionID: config.Azure.SubscriptionID, ResourceGroup: config.Azure.VNic.GroupName, ResourceType: azrs.NetworkInterfacesType, Name: config.Azure.VNic.Name}.String()
 	
 Loop:
 	for {
@@ -261,7 +274,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListVNic() ...")
-				vNicHandler.ListVNic()
+				vNicHandler.ListVNic(irs.ListReqInfo{})
 				fmt.Println("Finish ListVNic()")
 			case 2:
 				fmt.Println("Start GetVNic() ...")
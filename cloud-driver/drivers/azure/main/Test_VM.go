@@ -14,7 +14,7 @@ import (
 // Create Instance
 func createVM(config Config, vmHandler irs.VMHandler) {
 	
-	vmName := config.Azure.GroupName + ":" + config.Azure.VMName
+	vmName := config.Azure.VMName
 	imageId := config.Azure.Image.Publisher + ":" + config.Azure.Image.Offer + ":" + config.Azure.Image.Sku + ":" + config.Azure.Image.Version
 	vmReqInfo := irs.VMReqInfo{
 		Name: vmName,
@@ -22,9 +22,13 @@ func createVM(config Config, vmHandler irs.VMHandler) {
 			Id: imageId,
 		},
 		SpecID: config.Azure.VMSize,
-		VNetworkInfo: irs.VNetworkInfo{
+		VNicInfo: irs.VNicInfo{
 			Id: config.Azure.Nic.ID,
 		},
+		// Test_Create.go에서 생성한 로컬 키 페어
+		KeyPairInfo: irs.KeyPairInfo{
+			Name: keyPairName,
+		},
 		LoginInfo: irs.LoginInfo{
 			AdminUsername: config.Azure.Os.AdminUsername,
 			//AdminPassword: config.Azure.Os.AdminPassword,
@@ -59,7 +63,7 @@ func testVMHandler() {
 	fmt.Println("10. Exit")
 	fmt.Println("==========================================================")
 	
-	vmId := config.Azure.GroupName + ":" + config.Azure.VMName
+	vmId := config.Azure.VMName
 	
 	for {
 		var commandNum int
@@ -72,7 +76,10 @@ func testVMHandler() {
 			switch commandNum {
 			case 1:
 				fmt.Println("Start List VM ...")
-				vmList := vmHandler.ListVM()
+				vmList, _, err := vmHandler.ListVM(irs.ListReqInfo{})
+				if err != nil {
+					panic(err)
+				}
 				for i, vm := range vmList {
 					fmt.Println("[", i, "] ")
 					spew.Dump(vm)
@@ -85,7 +92,10 @@ func testVMHandler() {
 				fmt.Println("Finish Get VM")
 			case 3:
 				fmt.Println("Start List VMStatus ...")
-				vmStatusList := vmHandler.ListVMStatus()
+				vmStatusList, _, err := vmHandler.ListVMStatus(irs.ListReqInfo{})
+				if err != nil {
+					panic(err)
+				}
 				for i, vmStatus := range vmStatusList {
 					fmt.Println("[", i, "] ", *vmStatus)
 				}
@@ -206,6 +216,8 @@ type Config struct {
 	} `yaml:"azure"`
 }
 
+const keyPairName = "mcb-test-key"
+
 func readConfigFile() Config {
 	// Set Environment Value of Project Root Path
 	rootPath := os.Getenv("CBSPIDER_PATH")
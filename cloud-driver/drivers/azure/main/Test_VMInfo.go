@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	azrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/azure/resources"
+	"github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/golden"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Golden file check of the VMInfo of the Azure Driver.
+// The recorded ARM responses of testdata/vminfo are replayed by a local server,
+// the results of GetVM and ListVM are compared with testdata/vminfo/*.golden.json.
+//   $ go run Test_VMInfo.go           # check
+//   $ go run Test_VMInfo.go -update   # rewrite the golden files
+
+// the subscription of the recorded resource IDs
+const testSubscriptionID = "8a2f4c6e-0b1d-4e3f-9a5c-7e9b1d3f5a7c"
+
+// replays testdata/vminfo/{provider path}.json,
+// ex) /subscriptions/{sub}/resourceGroups/{group}/providers/Microsoft.Compute/virtualMachines/{name} -> Microsoft.Compute_virtualMachines_{name}.json
+func newArmServer(dataPath string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pathArr := strings.SplitN(r.URL.Path, "/providers/", 2)
+		if len(pathArr) != 2 {
+			http.Error(w, "not a resource: "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+		name := strings.Replace(strings.Trim(pathArr[1], "/"), "/", "_", -1)
+		body, err := ioutil.ReadFile(filepath.Join(dataPath, name+".json"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+}
+
+func main() {
+	flag.Parse()
+	// StartTime is the local time of the server, so it is fixed to UTC.
+	time.Local = time.UTC
+
+	dataPath := golden.GetDataPath("azure", "vminfo")
+	server := newArmServer(dataPath)
+	defer server.Close()
+
+	// no Authorizer: the requests are not signed.
+	vmClient := compute.NewVirtualMachinesClientWithBaseURI(server.URL, testSubscriptionID)
+	diskClient := compute.NewDisksClientWithBaseURI(server.URL, testSubscriptionID)
+	nicClient := network.NewInterfacesClientWithBaseURI(server.URL, testSubscriptionID)
+	publicIPClient := network.NewPublicIPAddressesClientWithBaseURI(server.URL, testSubscriptionID)
+
+	vmHandler := azrs.AzureVMHandler{
+		Region:         idrv.RegionInfo{Region: "koreacentral", ResourceGroup: "cb-rg"},
+		Ctx:            context.Background(),
+		Client:         &vmClient,
+		NicClient:      &nicClient,
+		PublicIPClient: &publicIPClient,
+		DiskClient:     &diskClient,
+	}
+
+	ok := golden.Check(dataPath, "GetVM", vmHandler.GetVM("cb-vm-01"))
+
+	vmList, _, err := vmHandler.ListVM(irs.ListReqInfo{})
+	if err != nil {
+		fmt.Printf("[FAIL] ListVM: %v\n", err)
+		ok = false
+	} else {
+		ok = golden.Check(dataPath, "ListVM", vmList) && ok
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,104 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is common functions of Azure resource handlers.
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// isNotFound checks the response of a failed Get is 404(the resource does not exist).
+func isNotFound(response autorest.Response) bool {
+	return response.Response != nil && response.StatusCode == http.StatusNotFound
+}
+
+// Azure decides the page size by itself, so ListReqInfo.PageSize is not used.
+// The continuation token is the nextLink of the Azure list result.
+
+// getNextPageRequest prepares the request of the next page like the SDK's listNextResults().
+// The request is sent with the List*Sender() of the client to be authorized,
+// so a nextLink not on the host of the client(baseURI) is rejected not to send the token to other hosts.
+func getNextPageRequest(ctx context.Context, baseURI string, nextLink string) (*http.Request, error) {
+	nextURL, err := url.Parse(nextLink)
+	if err != nil {
+		return nil, errors.New("invalid NextToken: " + nextLink)
+	}
+	baseURL, err := url.Parse(baseURI)
+	if err != nil {
+		return nil, err
+	}
+	if nextURL.Scheme != baseURL.Scheme || !strings.EqualFold(nextURL.Host, baseURL.Host) || nextURL.User != nil {
+		return nil, errors.New("invalid NextToken: not a link of " + baseURL.Scheme + "://" + baseURL.Host)
+	}
+	return autorest.Prepare((&http.Request{}).WithContext(ctx),
+		autorest.AsJSON(),
+		autorest.AsGet(),
+		autorest.WithBaseURL(nextLink))
+}
+
+// getNextToken converts the nextLink of the Azure list result into ListPageInfo.
+func getNextToken(nextLink *string) irs.ListPageInfo {
+	if nextLink == nil {
+		return irs.ListPageInfo{}
+	}
+	return irs.ListPageInfo{NextToken: *nextLink}
+}
+
+// matchListFilter checks the name/tag filters of listReqInfo on the driver side.
+func matchListFilter(listReqInfo irs.ListReqInfo, name *string, tags map[string]*string) bool {
+	if listReqInfo.NameFilter != "" && (name == nil || *name != listReqInfo.NameFilter) {
+		return false
+	}
+	for key, value := range listReqInfo.TagFilter {
+		tagValue, ok := tags[key]
+		if !ok || tagValue == nil || *tagValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// getTags converts the tags of an Azure resource into the tags of irs.
+func getTags(tags map[string]*string) map[string]string {
+	result := map[string]string{}
+	for key, value := range tags {
+		if value != nil {
+			result[key] = *value
+		}
+	}
+	return result
+}
+
+// getAzureTags converts the tags of a request into the tags of an Azure resource.
+func getAzureTags(tags map[string]string) map[string]*string {
+	if len(tags) == 0 {
+		return nil
+	}
+	result := make(map[string]*string, len(tags))
+	for key, value := range tags {
+		v := value
+		result[key] = &v
+	}
+	return result
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,348 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// AzureDiskHandler handles managed disks in the resource group of the connection.
+// Disks are attached to VMs by updating the data disks of the VM.
+type AzureDiskHandler struct {
+	Region   idrv.RegionInfo
+	Ctx      context.Context
+	Client   *compute.DisksClient
+	VMClient *compute.VirtualMachinesClient
+}
+
+// status of managed disks.
+const (
+	DiskUnattached = "Unattached"
+	DiskAttached   = "Attached"
+)
+
+// mappingDiskInfo maps a managed disk into irs.DiskInfo.
+// The LUN of an attached disk is known only by the VM, so Device is empty.
+func mappingDiskInfo(disk compute.Disk) irs.DiskInfo {
+	diskInfo := irs.DiskInfo{
+		Name:   to.String(disk.Name),
+		Id:     to.String(disk.ID),
+		Tags:   getTags(disk.Tags),
+		Status: DiskUnattached,
+	}
+	if disk.Sku != nil {
+		diskInfo.DiskType = string(disk.Sku.Name)
+	}
+	if disk.Zones != nil && len(*disk.Zones) != 0 {
+		diskInfo.Zone = (*disk.Zones)[0]
+	}
+	if disk.ManagedBy != nil {
+		diskInfo.Status = DiskAttached
+		diskInfo.VMId = to.String(disk.ManagedBy)
+	}
+	if disk.DiskProperties != nil {
+		diskInfo.SizeGiB = int(to.Int32(disk.DiskSizeGB))
+		if disk.TimeCreated != nil {
+			diskInfo.CreatedTime = disk.TimeCreated.Local()
+		}
+		diskInfo.KeyValueList = []irs.KeyValue{
+			{Key: "ProvisioningState", Value: to.String(disk.ProvisioningState)},
+			{Key: "OsType", Value: string(disk.OsType)},
+		}
+	}
+	return diskInfo
+}
+
+// The disk is created in the location of the connection, Zone is an availability zone of the location(ex: 1).
+func (diskHandler *AzureDiskHandler) CreateDisk(diskReqInfo irs.DiskReqInfo) (irs.DiskInfo, error) {
+	if err := irs.CheckDiskReqInfo(diskReqInfo); err != nil {
+		return irs.DiskInfo{}, err
+	}
+	diskID, err := ParseResourceID(diskReqInfo.Name, DisksType, diskHandler.Client.SubscriptionID, diskHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	diskOpts := compute.Disk{
+		Location: &diskHandler.Region.Region,
+		Tags:     getAzureTags(diskReqInfo.Tags),
+		DiskProperties: &compute.DiskProperties{
+			CreationData: &compute.CreationData{
+				CreateOption: compute.Empty,
+			},
+			DiskSizeGB: to.Int32Ptr(int32(diskReqInfo.SizeGiB)),
+		},
+	}
+	if diskReqInfo.DiskType != "" {
+		diskOpts.Sku = &compute.DiskSku{Name: compute.DiskStorageAccountTypes(diskReqInfo.DiskType)}
+	}
+	zone := diskReqInfo.Zone
+	if zone == "" {
+		zone = diskHandler.Region.Zone
+	}
+	if zone != "" {
+		diskOpts.Zones = &[]string{zone}
+	}
+
+	future, err := diskHandler.Client.CreateOrUpdate(diskHandler.Ctx, diskID.ResourceGroup, diskID.Name, diskOpts)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	err = future.WaitForCompletionRef(diskHandler.Ctx, diskHandler.Client.Client)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(diskID.String())
+}
+
+// listDiskPage gets a page of disks in the resource group of the connection.
+func (diskHandler *AzureDiskHandler) listDiskPage(listReqInfo irs.ListReqInfo) (compute.DiskList, error) {
+	if listReqInfo.NextToken == "" {
+		diskList, err := diskHandler.Client.ListByResourceGroup(diskHandler.Ctx, diskHandler.Region.ResourceGroup)
+		if err != nil {
+			return compute.DiskList{}, err
+		}
+		return diskList.Response(), nil
+	}
+
+	req, err := getNextPageRequest(diskHandler.Ctx, diskHandler.Client.BaseURI, listReqInfo.NextToken)
+	if err != nil {
+		return compute.DiskList{}, err
+	}
+	resp, err := diskHandler.Client.ListByResourceGroupSender(req)
+	if err != nil {
+		return compute.DiskList{}, err
+	}
+	return diskHandler.Client.ListByResourceGroupResponder(resp)
+}
+
+func (diskHandler *AzureDiskHandler) ListDisk(listReqInfo irs.ListReqInfo) ([]*irs.DiskInfo, irs.ListPageInfo, error) {
+	var diskList []*irs.DiskInfo
+
+	result, err := diskHandler.listDiskPage(listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	if result.Value != nil {
+		for _, disk := range *result.Value {
+			if !matchListFilter(listReqInfo, disk.Name, disk.Tags) {
+				continue
+			}
+			diskInfo := mappingDiskInfo(disk)
+			diskList = append(diskList, &diskInfo)
+		}
+	}
+
+	return diskList, getNextToken(result.NextLink), nil
+}
+
+func (diskHandler *AzureDiskHandler) GetDisk(diskID string) (irs.DiskInfo, error) {
+	diskResourceID, err := ParseResourceID(diskID, DisksType, diskHandler.Client.SubscriptionID, diskHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	disk, err := diskHandler.Client.Get(diskHandler.Ctx, diskResourceID.ResourceGroup, diskResourceID.Name)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return mappingDiskInfo(disk), nil
+}
+
+// A disk can be resized only when it is not attached or its VM is deallocated.
+func (diskHandler *AzureDiskHandler) ResizeDisk(diskID string, sizeGiB int) (irs.DiskInfo, error) {
+	diskResourceID, err := ParseResourceID(diskID, DisksType, diskHandler.Client.SubscriptionID, diskHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	disk, err := diskHandler.Client.Get(diskHandler.Ctx, diskResourceID.ResourceGroup, diskResourceID.Name)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	currentSize := 0
+	if disk.DiskProperties != nil {
+		currentSize = int(to.Int32(disk.DiskSizeGB))
+	}
+	if sizeGiB <= currentSize {
+		return irs.DiskInfo{}, errors.New(fmt.Sprintf("disk %s can only grow, current: %d GiB, requested: %d GiB", diskResourceID.Name, currentSize, sizeGiB))
+	}
+
+	diskUpdate := compute.DiskUpdate{
+		DiskUpdateProperties: &compute.DiskUpdateProperties{
+			DiskSizeGB: to.Int32Ptr(int32(sizeGiB)),
+		},
+	}
+	future, err := diskHandler.Client.Update(diskHandler.Ctx, diskResourceID.ResourceGroup, diskResourceID.Name, diskUpdate)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	err = future.WaitForCompletionRef(diskHandler.Ctx, diskHandler.Client.Client)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(diskResourceID.String())
+}
+
+func (diskHandler *AzureDiskHandler) DeleteDisk(diskID string) (bool, error) {
+	diskResourceID, err := ParseResourceID(diskID, DisksType, diskHandler.Client.SubscriptionID, diskHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	future, err := diskHandler.Client.Delete(diskHandler.Ctx, diskResourceID.ResourceGroup, diskResourceID.Name)
+	if err != nil {
+		return false, err
+	}
+	err = future.WaitForCompletionRef(diskHandler.Ctx, diskHandler.Client.Client)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// The disk is attached at the first free LUN of the VM, Device of the result is "LUN {n}".
+func (diskHandler *AzureDiskHandler) AttachDisk(diskID string, vmID string) (irs.DiskInfo, error) {
+	diskResourceID, err := ParseResourceID(diskID, DisksType, diskHandler.Client.SubscriptionID, diskHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	vm, err := diskHandler.getVM(vmID)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	dataDisks := getVMDataDisks(vm)
+	usedLuns := map[int32]bool{}
+	for _, dataDisk := range dataDisks {
+		usedLuns[to.Int32(dataDisk.Lun)] = true
+	}
+	var lun int32
+	for usedLuns[lun] {
+		lun++
+	}
+	dataDisks = append(dataDisks, compute.DataDisk{
+		Lun:          to.Int32Ptr(lun),
+		Name:         to.StringPtr(diskResourceID.Name),
+		CreateOption: compute.DiskCreateOptionTypesAttach,
+		ManagedDisk: &compute.ManagedDiskParameters{
+			ID: to.StringPtr(diskResourceID.String()),
+		},
+	})
+
+	err = diskHandler.updateVMDataDisks(vm, dataDisks)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	diskInfo, err := diskHandler.GetDisk(diskResourceID.String())
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	diskInfo.Device = fmt.Sprintf("LUN %d", lun)
+	return diskInfo, nil
+}
+
+func (diskHandler *AzureDiskHandler) DetachDisk(diskID string, vmID string) (irs.DiskInfo, error) {
+	diskResourceID, err := ParseResourceID(diskID, DisksType, diskHandler.Client.SubscriptionID, diskHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	vm, err := diskHandler.getVM(vmID)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	var dataDisks []compute.DataDisk
+	found := false
+	for _, dataDisk := range getVMDataDisks(vm) {
+		if dataDisk.ManagedDisk != nil && strings.EqualFold(to.String(dataDisk.ManagedDisk.ID), diskResourceID.String()) {
+			found = true
+			continue
+		}
+		dataDisks = append(dataDisks, dataDisk)
+	}
+	if !found {
+		return irs.DiskInfo{}, errors.New(fmt.Sprintf("disk %s is not attached to VM %s", diskResourceID.Name, to.String(vm.Name)))
+	}
+
+	err = diskHandler.updateVMDataDisks(vm, dataDisks)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(diskResourceID.String())
+}
+
+func (diskHandler *AzureDiskHandler) getVM(vmID string) (compute.VirtualMachine, error) {
+	vmResourceID, err := ParseResourceID(vmID, VirtualMachinesType, diskHandler.VMClient.SubscriptionID, diskHandler.Region.ResourceGroup)
+	if err != nil {
+		return compute.VirtualMachine{}, err
+	}
+	return diskHandler.VMClient.Get(diskHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name, "")
+}
+
+// updateVMDataDisks replaces the data disks of a VM.
+func (diskHandler *AzureDiskHandler) updateVMDataDisks(vm compute.VirtualMachine, dataDisks []compute.DataDisk) error {
+	vmResourceID, err := ParseResourceID(to.String(vm.ID), VirtualMachinesType, diskHandler.VMClient.SubscriptionID, diskHandler.Region.ResourceGroup)
+	if err != nil {
+		return err
+	}
+	vm.StorageProfile.DataDisks = &dataDisks
+	vm.Resources = nil // extensions are not updated with the VM.
+
+	future, err := diskHandler.VMClient.CreateOrUpdate(diskHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name, vm)
+	if err != nil {
+		return err
+	}
+	return future.WaitForCompletionRef(diskHandler.Ctx, diskHandler.VMClient.Client)
+}
+
+func getVMDataDisks(vm compute.VirtualMachine) []compute.DataDisk {
+	if vm.VirtualMachineProperties == nil || vm.StorageProfile == nil || vm.StorageProfile.DataDisks == nil {
+		return nil
+	}
+	return *vm.StorageProfile.DataDisks
+}
+
+// getDataDisks returns the new data disks of a VM for the data disks of vmReqInfo(LUN: the index).
+// The name of a disk is DiskReqInfo.Name(default: {vm name}-disk-{LUN}), the disks are in the zone of the VM.
+// Managed disks are kept when the VM is deleted.
+func getDataDisks(vmName string, vmReqInfo irs.VMReqInfo) ([]compute.DataDisk, error) {
+	var dataDisks []compute.DataDisk
+	for i, diskReqInfo := range vmReqInfo.DataDisks {
+		if err := irs.CheckDiskReqInfo(diskReqInfo); err != nil {
+			return nil, err
+		}
+		if len(diskReqInfo.Tags) != 0 {
+			return nil, errors.New("the tags of data disks at launch are not supported for Azure, create the disk with DiskHandler: " + diskReqInfo.Name)
+		}
+		name := diskReqInfo.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-disk-%d", vmName, i)
+		}
+		dataDisk := compute.DataDisk{
+			Lun:          to.Int32Ptr(int32(i)),
+			Name:         to.StringPtr(name),
+			CreateOption: compute.DiskCreateOptionTypesEmpty,
+			DiskSizeGB:   to.Int32Ptr(int32(diskReqInfo.SizeGiB)),
+		}
+		if diskReqInfo.DiskType != "" {
+			dataDisk.ManagedDisk = &compute.ManagedDiskParameters{
+				StorageAccountType: compute.StorageAccountTypes(diskReqInfo.DiskType),
+			}
+		}
+		dataDisks = append(dataDisks, dataDisk)
+	}
+	return dataDisks, nil
+}
@@ -0,0 +1,226 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"golang.org/x/crypto/ssh"
+)
+
+const keyPairBits = 2048
+
+// Azure has no key pair resources, the public key is set to each VM.
+// AzureKeyPairHandler keeps the public keys in local files(KeyPath/{name}.json),
+// the private keys are not kept and returned only once by CreateKey.
+type AzureKeyPairHandler struct {
+	Region  idrv.RegionInfo
+	KeyPath string
+}
+
+// keyPairFile is the local file of a key pair.
+type keyPairFile struct {
+	Name        string
+	PublicKey   string
+	Fingerprint string
+	Tags        map[string]string
+	CreatedTime time.Time
+}
+
+// mappingKeyPairInfo maps a local key pair into irs.KeyPairInfo.
+// The ID of a local key pair is its name.
+func mappingKeyPairInfo(keyPair keyPairFile) irs.KeyPairInfo {
+	return irs.KeyPairInfo{
+		Id:          keyPair.Name,
+		Name:        keyPair.Name,
+		Tags:        keyPair.Tags,
+		PublicKey:   keyPair.PublicKey,
+		Fingerprint: keyPair.Fingerprint,
+		KeyValueList: []irs.KeyValue{
+			{Key: "CreatedTime", Value: keyPair.CreatedTime.Format(time.RFC3339)},
+		},
+	}
+}
+
+// CreateKey imports the PublicKey of keyPairReqInfo, or generates a RSA key pair and returns its private key only once.
+func (keyPairHandler *AzureKeyPairHandler) CreateKey(keyPairReqInfo irs.KeyPairReqInfo) (irs.KeyPairInfo, error) {
+	keyFilePath, err := keyPairHandler.getKeyFilePath(keyPairReqInfo.Name)
+	if err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+
+	var privateKey string
+	publicKey := keyPairReqInfo.PublicKey
+	if publicKey == "" {
+		privateKey, publicKey, err = generateKeyPair()
+		if err != nil {
+			return irs.KeyPairInfo{}, err
+		}
+	}
+	sshPublicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return irs.KeyPairInfo{}, errors.New(fmt.Sprintf("invalid public key of key pair %s, %v", keyPairReqInfo.Name, err))
+	}
+
+	keyPair := keyPairFile{
+		Name:        keyPairReqInfo.Name,
+		PublicKey:   strings.TrimSpace(publicKey),
+		Fingerprint: ssh.FingerprintLegacyMD5(sshPublicKey),
+		Tags:        keyPairReqInfo.Tags,
+		CreatedTime: time.Now(),
+	}
+	data, err := json.MarshalIndent(keyPair, "", "  ")
+	if err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+
+	err = os.MkdirAll(keyPairHandler.KeyPath, 0700)
+	if err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+	// O_EXCL: the existing key pair is not overwritten.
+	file, err := os.OpenFile(keyFilePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return irs.KeyPairInfo{}, errors.New(fmt.Sprintf("key pair %s already exists", keyPairReqInfo.Name))
+		}
+		return irs.KeyPairInfo{}, err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(keyFilePath)
+		return irs.KeyPairInfo{}, err
+	}
+
+	keyPairInfo := mappingKeyPairInfo(keyPair)
+	keyPairInfo.PrivateKey = privateKey
+	return keyPairInfo, nil
+}
+
+// local key pairs have no paging, so pages are cut from the filtered result in the order of the names.
+func (keyPairHandler *AzureKeyPairHandler) ListKey(listReqInfo irs.ListReqInfo) ([]*irs.KeyPairInfo, irs.ListPageInfo, error) {
+	var keyPairList []*irs.KeyPairInfo
+
+	files, err := ioutil.ReadDir(keyPairHandler.KeyPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, irs.ListPageInfo{}, err
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		keyPair, err := keyPairHandler.readKeyPair(strings.TrimSuffix(file.Name(), ".json"))
+		if err != nil {
+			return nil, irs.ListPageInfo{}, err
+		}
+		if !matchListFilter(listReqInfo, &keyPair.Name, getAzureTags(keyPair.Tags)) {
+			continue
+		}
+		keyPairInfo := mappingKeyPairInfo(keyPair)
+		keyPairList = append(keyPairList, &keyPairInfo)
+	}
+
+	start, end, nextToken, err := irs.GetPageRange(len(keyPairList), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	return keyPairList[start:end], irs.ListPageInfo{NextToken: nextToken}, nil
+}
+
+func (keyPairHandler *AzureKeyPairHandler) GetKey(keyPairID string) (irs.KeyPairInfo, error) {
+	keyPair, err := keyPairHandler.readKeyPair(keyPairID)
+	if err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+	return mappingKeyPairInfo(keyPair), nil
+}
+
+// The public keys already set to VMs are not removed from them.
+func (keyPairHandler *AzureKeyPairHandler) DeleteKey(keyPairID string) (bool, error) {
+	keyFilePath, err := keyPairHandler.getKeyFilePath(keyPairID)
+	if err != nil {
+		return false, err
+	}
+	err = os.Remove(keyFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, errors.New(fmt.Sprintf("key pair %s not found", keyPairID))
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// getKeyFilePath returns the local file of a key pair, the name must not be a path.
+func (keyPairHandler *AzureKeyPairHandler) getKeyFilePath(keyPairName string) (string, error) {
+	if keyPairName == "" || keyPairName == "." || keyPairName == ".." || strings.ContainsAny(keyPairName, `/\`) {
+		return "", errors.New(fmt.Sprintf("invalid key pair name %q", keyPairName))
+	}
+	if keyPairHandler.KeyPath == "" {
+		return "", errors.New("no local path for the key pairs of Azure")
+	}
+	return filepath.Join(keyPairHandler.KeyPath, keyPairName+".json"), nil
+}
+
+func (keyPairHandler *AzureKeyPairHandler) readKeyPair(keyPairName string) (keyPairFile, error) {
+	keyFilePath, err := keyPairHandler.getKeyFilePath(keyPairName)
+	if err != nil {
+		return keyPairFile{}, err
+	}
+	data, err := ioutil.ReadFile(keyFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return keyPairFile{}, errors.New(fmt.Sprintf("key pair %s not found", keyPairName))
+		}
+		return keyPairFile{}, err
+	}
+
+	var keyPair keyPairFile
+	err = json.Unmarshal(data, &keyPair)
+	if err != nil {
+		return keyPairFile{}, errors.New(fmt.Sprintf("invalid key pair file %s, %v", keyFilePath, err))
+	}
+	return keyPair, nil
+}
+
+// generateKeyPair generates a RSA key pair,
+// the private key is PEM encoded and the public key is OpenSSH authorized_keys format.
+func generateKeyPair() (string, string, error) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, keyPairBits)
+	if err != nil {
+		return "", "", err
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+	})
+
+	sshPublicKey, err := ssh.NewPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey)))
+	return string(privateKey), publicKey, nil
+}
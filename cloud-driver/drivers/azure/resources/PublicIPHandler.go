@@ -4,51 +4,67 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
-	"strings"
 )
 
 type AzurePublicIPHandler struct {
-	Region idrv.RegionInfo
-	Ctx    context.Context
-	Client *network.PublicIPAddressesClient
+	Region    idrv.RegionInfo
+	Ctx       context.Context
+	Client    *network.PublicIPAddressesClient
+	NicClient *network.InterfacesClient
+	VMClient  *compute.VirtualMachinesClient
 }
 
-// @TODO: PublicIP 리소스 프로퍼티 정의 필요
-type PublicIPInfo struct {
-	Id                       string
-	Name                     string
-	Location                 string
-	PublicIPAddressSku       string
-	PublicIPAddressVersion   string
-	PublicIPAllocationMethod string
-	IPAddress                string
-	IdleTimeoutInMinutes     int32
-}
-
-func (publicIP *PublicIPInfo) setter(address network.PublicIPAddress) *PublicIPInfo {
-	publicIP.Id = *address.ID
-	publicIP.Name = *address.Name
-	publicIP.Location = *address.Location
-	publicIP.PublicIPAddressSku = fmt.Sprint(address.Sku.Name)
-	publicIP.PublicIPAddressVersion = fmt.Sprint(address.PublicIPAddressVersion)
-	publicIP.PublicIPAllocationMethod = fmt.Sprint(address.PublicIPAllocationMethod)
-	if address.IPAddress != nil {
-		publicIP.IPAddress = *address.IPAddress
+// mappingPublicIPInfo maps a public IP address into irs.PublicIPInfo.
+// A public IP is associated with the IP configuration of a NIC, not a VM,
+// so the NIC is kept in the KeyValueList and OwnedVMId is empty.
+func mappingPublicIPInfo(address network.PublicIPAddress) irs.PublicIPInfo {
+	publicIPInfo := irs.PublicIPInfo{
+		Id:     to.String(address.ID),
+		Name:   to.String(address.Name),
+		Tags:   getTags(address.Tags),
+		Status: "available",
+		KeyValueList: []irs.KeyValue{
+			{Key: "Location", Value: to.String(address.Location)},
+		},
 	}
-	if address.IdleTimeoutInMinutes != nil {
-		publicIP.IdleTimeoutInMinutes = *address.IdleTimeoutInMinutes
+	if address.Sku != nil {
+		publicIPInfo.KeyValueList = append(publicIPInfo.KeyValueList, irs.KeyValue{Key: "Sku", Value: string(address.Sku.Name)})
 	}
 
-	return publicIP
+	if address.PublicIPAddressPropertiesFormat == nil {
+		return publicIPInfo
+	}
+	publicIPInfo.PublicIP = to.String(address.IPAddress)
+	publicIPInfo.KeyValueList = append(publicIPInfo.KeyValueList,
+		irs.KeyValue{Key: "PublicIPAddressVersion", Value: string(address.PublicIPAddressVersion)},
+		irs.KeyValue{Key: "PublicIPAllocationMethod", Value: string(address.PublicIPAllocationMethod)},
+		irs.KeyValue{Key: "IdleTimeoutInMinutes", Value: fmt.Sprint(to.Int32(address.IdleTimeoutInMinutes))},
+		irs.KeyValue{Key: "ProvisioningState", Value: to.String(address.ProvisioningState)},
+	)
+	if address.IPConfiguration != nil && address.IPConfiguration.ID != nil {
+		publicIPInfo.Status = "associated"
+		// /subscriptions/.../networkInterfaces/{nic}/ipConfigurations/{config}
+		nicID := strings.Split(*address.IPConfiguration.ID, "/ipConfigurations/")[0]
+		publicIPInfo.KeyValueList = append(publicIPInfo.KeyValueList, irs.KeyValue{Key: "NetworkInterfaceId", Value: nicID})
+	}
+	return publicIPInfo
 }
 
 func (publicIpHandler *AzurePublicIPHandler) CreatePublicIP(publicIPReqInfo irs.PublicIPReqInfo) (irs.PublicIPInfo, error) {
 
-	// @TODO: PublicIP 생성 요청 파라미터 정의 필요
+	if publicIPReqInfo.Pool != "" {
+		return irs.PublicIPInfo{}, errors.New("the pool of a public IP is not supported for Azure")
+	}
+
+	// Standard SKU의 Public IP는 Static만 가능함.
 	type PublicIPReqInfo struct {
 		PublicIPAddressSkuName       string
 		PublicIPAddressVersion       string
@@ -61,13 +77,22 @@ func (publicIpHandler *AzurePublicIPHandler) CreatePublicIP(publicIPReqInfo irs.
 		PublicIPAllocationMethod:     "Static",
 		PublicIPIdleTimeoutInMinutes: 4,
 	}
+	if publicIPReqInfo.Sku != "" {
+		if !strings.EqualFold(publicIPReqInfo.Sku, string(network.PublicIPAddressSkuNameBasic)) && !strings.EqualFold(publicIPReqInfo.Sku, string(network.PublicIPAddressSkuNameStandard)) {
+			return irs.PublicIPInfo{}, errors.New(fmt.Sprintf("invalid SKU %q of a public IP, ex) Basic, Standard", publicIPReqInfo.Sku))
+		}
+		reqInfo.PublicIPAddressSkuName = publicIPReqInfo.Sku
+	}
 
-	publicIPArr := strings.Split(publicIPReqInfo.Id, ":")
+	resourceID, err := ParseResourceID(publicIPReqInfo.Id, PublicIPAddressesType, publicIpHandler.Client.SubscriptionID, publicIpHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.PublicIPInfo{}, err
+	}
 
 	// Check PublicIP Exists
-	publicIP, err := publicIpHandler.Client.Get(publicIpHandler.Ctx, publicIPArr[0], publicIPArr[1], "")
+	publicIP, err := publicIpHandler.Client.Get(publicIpHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
 	if publicIP.ID != nil {
-		errMsg := fmt.Sprintf("Public IP with name %s already exist", publicIPArr[1])
+		errMsg := fmt.Sprintf("Public IP with name %s already exist", resourceID.Name)
 		createErr := errors.New(errMsg)
 		return irs.PublicIPInfo{}, createErr
 	}
@@ -82,9 +107,10 @@ func (publicIpHandler *AzurePublicIPHandler) CreatePublicIP(publicIPReqInfo irs.
 			IdleTimeoutInMinutes:     &reqInfo.PublicIPIdleTimeoutInMinutes,
 		},
 		Location: &publicIpHandler.Region.Region,
+		Tags:     getAzureTags(publicIPReqInfo.Tags),
 	}
 
-	future, err := publicIpHandler.Client.CreateOrUpdate(publicIpHandler.Ctx, publicIPArr[0], publicIPArr[1], createOpts)
+	future, err := publicIpHandler.Client.CreateOrUpdate(publicIpHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, createOpts)
 	if err != nil {
 		return irs.PublicIPInfo{}, err
 	}
@@ -101,39 +127,70 @@ func (publicIpHandler *AzurePublicIPHandler) CreatePublicIP(publicIPReqInfo irs.
 	return publicIPInfo, nil
 }
 
-func (publicIpHandler *AzurePublicIPHandler) ListPublicIP() ([]*irs.PublicIPInfo, error) {
-	//result, err := publicIpHandler.Client.ListAll(publicIpHandler.Ctx)
-	result, err := publicIpHandler.Client.List(publicIpHandler.Ctx, publicIpHandler.Region.ResourceGroup)
+// listPublicIPPage gets a page of public IPs in the resource group of the connection.
+func (publicIpHandler *AzurePublicIPHandler) listPublicIPPage(listReqInfo irs.ListReqInfo) (network.PublicIPAddressListResult, error) {
+	if listReqInfo.NextToken == "" {
+		result, err := publicIpHandler.Client.List(publicIpHandler.Ctx, publicIpHandler.Region.ResourceGroup)
+		if err != nil {
+			return network.PublicIPAddressListResult{}, err
+		}
+		return result.Response(), nil
+	}
+
+	req, err := getNextPageRequest(publicIpHandler.Ctx, publicIpHandler.Client.BaseURI, listReqInfo.NextToken)
+	if err != nil {
+		return network.PublicIPAddressListResult{}, err
+	}
+	resp, err := publicIpHandler.Client.ListSender(req)
+	if err != nil {
+		return network.PublicIPAddressListResult{}, err
+	}
+	return publicIpHandler.Client.ListResponder(resp)
+}
+
+func (publicIpHandler *AzurePublicIPHandler) ListPublicIP(listReqInfo irs.ListReqInfo) ([]*irs.PublicIPInfo, irs.ListPageInfo, error) {
+	result, err := publicIpHandler.listPublicIPPage(listReqInfo)
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
 	}
 
-	var publicIPList []*PublicIPInfo
-	for _, publicIP := range result.Values() {
-		publicIPInfo := new(PublicIPInfo).setter(publicIP)
-		publicIPList = append(publicIPList, publicIPInfo)
+	var publicIPList []*irs.PublicIPInfo
+	if result.Value == nil {
+		return publicIPList, getNextToken(result.NextLink), nil
+	}
+	for _, publicIP := range *result.Value {
+		if !matchListFilter(listReqInfo, publicIP.Name, publicIP.Tags) {
+			continue
+		}
+		publicIPInfo := mappingPublicIPInfo(publicIP)
+		publicIPList = append(publicIPList, &publicIPInfo)
 	}
 
-	spew.Dump(publicIPList)
-	return nil, nil
+	return publicIPList, getNextToken(result.NextLink), nil
 }
 
 func (publicIpHandler *AzurePublicIPHandler) GetPublicIP(publicIPID string) (irs.PublicIPInfo, error) {
-	publicIPArr := strings.Split(publicIPID, ":")
-	publicIP, err := publicIpHandler.Client.Get(publicIpHandler.Ctx, publicIPArr[0], publicIPArr[1], "")
+	resourceID, err := ParseResourceID(publicIPID, PublicIPAddressesType, publicIpHandler.Client.SubscriptionID, publicIpHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.PublicIPInfo{}, err
+	}
+	publicIP, err := publicIpHandler.Client.Get(publicIpHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
 	if err != nil {
 		return irs.PublicIPInfo{}, err
 	}
 
-	publicIPInfo := new(PublicIPInfo).setter(publicIP)
+	publicIPInfo := mappingPublicIPInfo(publicIP)
 
 	spew.Dump(publicIPInfo)
-	return irs.PublicIPInfo{}, nil
+	return publicIPInfo, nil
 }
 
 func (publicIpHandler *AzurePublicIPHandler) DeletePublicIP(publicIPID string) (bool, error) {
-	publicIPArr := strings.Split(publicIPID, ":")
-	future, err := publicIpHandler.Client.Delete(publicIpHandler.Ctx, publicIPArr[0], publicIPArr[1])
+	resourceID, err := ParseResourceID(publicIPID, PublicIPAddressesType, publicIpHandler.Client.SubscriptionID, publicIpHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	future, err := publicIpHandler.Client.Delete(publicIpHandler.Ctx, resourceID.ResourceGroup, resourceID.Name)
 	if err != nil {
 		return false, err
 	}
@@ -143,3 +200,127 @@ func (publicIpHandler *AzurePublicIPHandler) DeletePublicIP(publicIPID string) (
 	}
 	return true, nil
 }
+
+// getTargetNic returns the NIC of vmOrVNicID, the primary NIC of a VM.
+// A name is looked up as a VM first, then as a NIC.
+func (publicIpHandler *AzurePublicIPHandler) getTargetNic(vmOrVNicID string) (ResourceID, network.Interface, error) {
+	subscriptionID := publicIpHandler.Client.SubscriptionID
+	resourceGroup := publicIpHandler.Region.ResourceGroup
+
+	nicIDStr := vmOrVNicID
+	vmID, err := ParseResourceID(vmOrVNicID, VirtualMachinesType, subscriptionID, resourceGroup)
+	if err == nil {
+		vm, err := publicIpHandler.VMClient.Get(publicIpHandler.Ctx, vmID.ResourceGroup, vmID.Name, "")
+		if err == nil {
+			nicIDStr = mappingServerInfo(vm).VNIC
+			if nicIDStr == "" {
+				return ResourceID{}, network.Interface{}, errors.New("no NIC of VM " + vmOrVNicID)
+			}
+		} else if strings.HasPrefix(vmOrVNicID, "/") {
+			return ResourceID{}, network.Interface{}, err
+		}
+	}
+
+	nicID, err := ParseResourceID(nicIDStr, NetworkInterfacesType, subscriptionID, resourceGroup)
+	if err != nil {
+		return ResourceID{}, network.Interface{}, errors.New(fmt.Sprintf("invalid ID %q: not a VM nor a NIC", vmOrVNicID))
+	}
+	nic, err := publicIpHandler.NicClient.Get(publicIpHandler.Ctx, nicID.ResourceGroup, nicID.Name, "")
+	if err != nil {
+		return ResourceID{}, network.Interface{}, err
+	}
+	return nicID, nic, nil
+}
+
+// updateNic updates the IP configurations of a NIC.
+func (publicIpHandler *AzurePublicIPHandler) updateNic(nicID ResourceID, nic network.Interface) error {
+	future, err := publicIpHandler.NicClient.CreateOrUpdate(publicIpHandler.Ctx, nicID.ResourceGroup, nicID.Name, nic)
+	if err != nil {
+		return err
+	}
+	return future.WaitForCompletionRef(publicIpHandler.Ctx, publicIpHandler.NicClient.Client)
+}
+
+// AssociatePublicIP sets a public IP to the primary IP configuration of a NIC(or the primary NIC of a VM).
+// A NIC with another public IP is not changed.
+func (publicIpHandler *AzurePublicIPHandler) AssociatePublicIP(publicIPID string, vmOrVNicID string) (bool, error) {
+	resourceID, err := ParseResourceID(publicIPID, PublicIPAddressesType, publicIpHandler.Client.SubscriptionID, publicIpHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	publicIP, err := publicIpHandler.Client.Get(publicIpHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
+	if err != nil {
+		return false, err
+	}
+	if publicIP.PublicIPAddressPropertiesFormat != nil && publicIP.IPConfiguration != nil {
+		return false, errors.New(fmt.Sprintf("public IP %s is already associated with %s", resourceID.Name, to.String(publicIP.IPConfiguration.ID)))
+	}
+
+	nicID, nic, err := publicIpHandler.getTargetNic(vmOrVNicID)
+	if err != nil {
+		return false, err
+	}
+	if nic.InterfacePropertiesFormat == nil || nic.IPConfigurations == nil {
+		return false, errors.New("no IP configuration of NIC " + nicID.Name)
+	}
+
+	var ipConfig *network.InterfaceIPConfigurationPropertiesFormat
+	for _, c := range *nic.IPConfigurations {
+		if c.InterfaceIPConfigurationPropertiesFormat != nil && (ipConfig == nil || to.Bool(c.Primary)) {
+			ipConfig = c.InterfaceIPConfigurationPropertiesFormat
+		}
+	}
+	if ipConfig == nil {
+		return false, errors.New("no IP configuration of NIC " + nicID.Name)
+	}
+	if ipConfig.PublicIPAddress != nil {
+		return false, errors.New(fmt.Sprintf("NIC %s already has public IP %s", nicID.Name, to.String(ipConfig.PublicIPAddress.ID)))
+	}
+	ipConfig.PublicIPAddress = &network.PublicIPAddress{ID: publicIP.ID}
+
+	err = publicIpHandler.updateNic(nicID, nic)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DisassociatePublicIP removes a public IP from the IP configuration of its NIC.
+func (publicIpHandler *AzurePublicIPHandler) DisassociatePublicIP(publicIPID string) (bool, error) {
+	resourceID, err := ParseResourceID(publicIPID, PublicIPAddressesType, publicIpHandler.Client.SubscriptionID, publicIpHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	publicIP, err := publicIpHandler.Client.Get(publicIpHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
+	if err != nil {
+		return false, err
+	}
+	if publicIP.PublicIPAddressPropertiesFormat == nil || publicIP.IPConfiguration == nil || publicIP.IPConfiguration.ID == nil {
+		return false, errors.New("public IP is not associated: " + resourceID.Name)
+	}
+
+	// /subscriptions/.../networkInterfaces/{nic}/ipConfigurations/{config}
+	ipConfigID := *publicIP.IPConfiguration.ID
+	nicID, err := ParseResourceID(strings.Split(ipConfigID, "/ipConfigurations/")[0], NetworkInterfacesType, publicIpHandler.Client.SubscriptionID, publicIpHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("public IP %s is not associated with a NIC: %s", resourceID.Name, ipConfigID))
+	}
+	nic, err := publicIpHandler.NicClient.Get(publicIpHandler.Ctx, nicID.ResourceGroup, nicID.Name, "")
+	if err != nil {
+		return false, err
+	}
+	if nic.InterfacePropertiesFormat == nil || nic.IPConfigurations == nil {
+		return false, errors.New("no IP configuration of NIC " + nicID.Name)
+	}
+	for _, c := range *nic.IPConfigurations {
+		if c.InterfaceIPConfigurationPropertiesFormat != nil && strings.EqualFold(to.String(c.ID), ipConfigID) {
+			c.PublicIPAddress = nil
+		}
+	}
+
+	err = publicIpHandler.updateNic(nicID, nic)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,200 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-06-01/subscriptions"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// AzureRegionZoneHandler lists the locations of the subscription.
+// Locations have no availability zone API, the zones of a location are the zones of the VM SKUs in it.
+type AzureRegionZoneHandler struct {
+	Region         idrv.RegionInfo
+	Ctx            context.Context
+	Client         *subscriptions.Client
+	SkuClient      *compute.ResourceSkusClient
+	SubscriptionID string
+}
+
+// mappingRegionZoneInfo maps an Azure location into irs.RegionZoneInfo.
+// The locations listed for the subscription are available.
+func mappingRegionZoneInfo(location subscriptions.Location, zones []string) irs.RegionZoneInfo {
+	regionZoneInfo := irs.RegionZoneInfo{
+		Name:        to.String(location.Name),
+		DisplayName: to.String(location.DisplayName),
+		Status:      irs.RegionZoneAvailable,
+		KeyValueList: []irs.KeyValue{
+			{Key: "Latitude", Value: to.String(location.Latitude)},
+			{Key: "Longitude", Value: to.String(location.Longitude)},
+		},
+	}
+	for _, zone := range zones {
+		regionZoneInfo.ZoneList = append(regionZoneInfo.ZoneList, irs.ZoneInfo{Name: zone, Status: irs.RegionZoneAvailable})
+	}
+	return regionZoneInfo
+}
+
+func (regionZoneHandler *AzureRegionZoneHandler) ListRegionZone() ([]*irs.RegionZoneInfo, error) {
+	var regionZoneList []*irs.RegionZoneInfo
+
+	locationList, err := regionZoneHandler.listLocation()
+	if err != nil {
+		return nil, err
+	}
+	zoneMap, err := regionZoneHandler.getZoneMap("")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, location := range locationList {
+		regionZoneInfo := mappingRegionZoneInfo(location, zoneMap[strings.ToLower(to.String(location.Name))])
+		regionZoneList = append(regionZoneList, &regionZoneInfo)
+	}
+	return regionZoneList, nil
+}
+
+// Location names are case-insensitive. ex) koreacentral, KoreaCentral
+func (regionZoneHandler *AzureRegionZoneHandler) GetRegionZone(regionName string) (irs.RegionZoneInfo, error) {
+	location, err := regionZoneHandler.getLocation(regionName)
+	if err != nil {
+		return irs.RegionZoneInfo{}, err
+	}
+	zoneMap, err := regionZoneHandler.getZoneMap(regionName)
+	if err != nil {
+		return irs.RegionZoneInfo{}, err
+	}
+	return mappingRegionZoneInfo(location, zoneMap[strings.ToLower(regionName)]), nil
+}
+
+// ValidateRegionZone checks the location and zone of a connection like irs.ValidateRegionZone,
+// but the SKUs are listed only when a zone is given, ConnectCloud does not need them for a location.
+func (regionZoneHandler *AzureRegionZoneHandler) ValidateRegionZone(regionName string, zone string) error {
+	if regionName == "" {
+		return errors.New("no region of the connection")
+	}
+	if zone == "" {
+		_, err := regionZoneHandler.getLocation(regionName)
+		return err
+	}
+	regionZoneInfo, err := regionZoneHandler.GetRegionZone(regionName)
+	if err != nil {
+		return err
+	}
+	return irs.CheckRegionZone(regionZoneInfo, zone)
+}
+
+func (regionZoneHandler *AzureRegionZoneHandler) getLocation(regionName string) (subscriptions.Location, error) {
+	locationList, err := regionZoneHandler.listLocation()
+	if err != nil {
+		return subscriptions.Location{}, err
+	}
+	for _, location := range locationList {
+		if strings.EqualFold(to.String(location.Name), regionName) {
+			return location, nil
+		}
+	}
+	return subscriptions.Location{}, errors.New("location not found in the subscription: " + regionName)
+}
+
+func (regionZoneHandler *AzureRegionZoneHandler) listLocation() ([]subscriptions.Location, error) {
+	result, err := regionZoneHandler.Client.ListLocations(regionZoneHandler.Ctx, regionZoneHandler.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if result.Value == nil {
+		return nil, nil
+	}
+	return *result.Value, nil
+}
+
+// getZoneMap returns the sorted zones of the VM SKUs by the location(lower case).
+// With location, the SKUs are requested with the location filter, and the SKUs of other locations are skipped
+// in case the filter is not applied by the API version. "": all locations
+func (regionZoneHandler *AzureRegionZoneHandler) getZoneMap(location string) (map[string][]string, error) {
+	zoneSets := map[string]map[string]bool{}
+
+	req, err := regionZoneHandler.SkuClient.ListPreparer(regionZoneHandler.Ctx)
+	if err != nil {
+		return nil, err
+	}
+	if location != "" {
+		queryParameters := map[string]interface{}{
+			"$filter": autorest.Encode("query", fmt.Sprintf("location eq '%s'", location)),
+		}
+		req, err = autorest.Prepare(req, autorest.WithQueryParameters(queryParameters))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for req != nil {
+		result, err := regionZoneHandler.listSkuPage(req)
+		if err != nil {
+			return nil, err
+		}
+		if result.Value != nil {
+			for _, sku := range *result.Value {
+				if to.String(sku.ResourceType) != "virtualMachines" || sku.LocationInfo == nil {
+					continue
+				}
+				for _, locationInfo := range *sku.LocationInfo {
+					if locationInfo.Zones == nil {
+						continue
+					}
+					if location != "" && !strings.EqualFold(to.String(locationInfo.Location), location) {
+						continue
+					}
+					skuLocation := strings.ToLower(to.String(locationInfo.Location))
+					if zoneSets[skuLocation] == nil {
+						zoneSets[skuLocation] = map[string]bool{}
+					}
+					for _, zone := range *locationInfo.Zones {
+						zoneSets[skuLocation][zone] = true
+					}
+				}
+			}
+		}
+
+		req = nil
+		if to.String(result.NextLink) != "" {
+			req, err = getNextPageRequest(regionZoneHandler.Ctx, regionZoneHandler.SkuClient.BaseURI, *result.NextLink)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	zoneMap := map[string][]string{}
+	for location, zoneSet := range zoneSets {
+		for zone := range zoneSet {
+			zoneMap[location] = append(zoneMap[location], zone)
+		}
+		sort.Strings(zoneMap[location])
+	}
+	return zoneMap, nil
+}
+
+func (regionZoneHandler *AzureRegionZoneHandler) listSkuPage(req *http.Request) (compute.ResourceSkusResult, error) {
+	resp, err := regionZoneHandler.SkuClient.ListSender(req)
+	if err != nil {
+		return compute.ResourceSkusResult{}, err
+	}
+	return regionZoneHandler.SkuClient.ListResponder(resp)
+}
@@ -0,0 +1,137 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
+)
+
+// resource types(provider/type) of the ARM resource IDs used by this driver.
+const (
+	VirtualMachinesType   = "Microsoft.Compute/virtualMachines"
+	ImagesType            = "Microsoft.Compute/images"
+	DisksType             = "Microsoft.Compute/disks"
+	SnapshotsType         = "Microsoft.Compute/snapshots"
+	PublicIPAddressesType = "Microsoft.Network/publicIPAddresses"
+	SecurityGroupsType    = "Microsoft.Network/networkSecurityGroups"
+	VirtualNetworksType   = "Microsoft.Network/virtualNetworks"
+	NetworkInterfacesType = "Microsoft.Network/networkInterfaces"
+	RouteTablesType       = "Microsoft.Network/routeTables"
+	StorageAccountsType   = "Microsoft.Storage/storageAccounts"
+)
+
+// ResourceID is the ARM resource ID of an Azure resource.
+// ex) /subscriptions/{SubscriptionID}/resourceGroups/{ResourceGroup}/providers/Microsoft.Compute/virtualMachines/{Name}
+type ResourceID struct {
+	SubscriptionID string
+	ResourceGroup  string
+	ResourceType   string // ex) Microsoft.Compute/virtualMachines
+	Name           string
+}
+
+// String emits the full ARM resource ID.
+func (resourceID ResourceID) String() string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s",
+		resourceID.SubscriptionID, resourceID.ResourceGroup, resourceID.ResourceType, resourceID.Name)
+}
+
+// ParseResourceID parses the ID of a resource of resourceType.
+// id is a full ARM resource ID or the name of a resource,
+// the resource group of a name is resourceGroup(RegionInfo.ResourceGroup of the connection).
+func ParseResourceID(id string, resourceType string, subscriptionID string, resourceGroup string) (ResourceID, error) {
+	if id == "" {
+		return ResourceID{}, errors.New("empty ID of " + resourceType)
+	}
+
+	// name of a resource in the resource group of the connection
+	if !strings.HasPrefix(id, "/") {
+		if strings.ContainsAny(id, "/:") {
+			return ResourceID{}, errors.New(fmt.Sprintf("invalid ID %q of %s: not a name nor a full ARM resource ID", id, resourceType))
+		}
+		if resourceGroup == "" {
+			return ResourceID{}, errors.New(fmt.Sprintf("no resource group for %q of %s: set the ResourceGroup of the RegionInfo or use a full ARM resource ID", id, resourceType))
+		}
+		return ResourceID{SubscriptionID: subscriptionID, ResourceGroup: resourceGroup, ResourceType: resourceType, Name: id}, nil
+	}
+
+	// /subscriptions/{sub}/resourceGroups/{group}/providers/{namespace}/{type}/{name}
+	idArr := strings.Split(id, "/")
+	if len(idArr) != 9 || idArr[0] != "" ||
+		!strings.EqualFold(idArr[1], "subscriptions") || !strings.EqualFold(idArr[3], "resourceGroups") || !strings.EqualFold(idArr[5], "providers") ||
+		idArr[2] == "" || idArr[4] == "" || idArr[8] == "" {
+		return ResourceID{}, errors.New(fmt.Sprintf("invalid ARM resource ID %q of %s", id, resourceType))
+	}
+	if !strings.EqualFold(idArr[6]+"/"+idArr[7], resourceType) {
+		return ResourceID{}, errors.New(fmt.Sprintf("invalid ARM resource ID %q: not a resource of %s", id, resourceType))
+	}
+	if subscriptionID != "" && !strings.EqualFold(idArr[2], subscriptionID) {
+		return ResourceID{}, errors.New(fmt.Sprintf("invalid ARM resource ID %q: not in the subscription %s of the connection", id, subscriptionID))
+	}
+
+	return ResourceID{SubscriptionID: idArr[2], ResourceGroup: idArr[4], ResourceType: resourceType, Name: idArr[8]}, nil
+}
+
+// ParseSubnetID parses the ID of a subnet into the ID of its virtual network and the name of the subnet.
+// id is a full ARM resource ID(/subscriptions/.../virtualNetworks/{vnet}/subnets/{subnet}) or {vnet}/subnets/{subnet},
+// the virtual network of it is parsed by ParseResourceID.
+func ParseSubnetID(id string, subscriptionID string, resourceGroup string) (ResourceID, string, error) {
+	idArr := strings.SplitN(id, "/subnets/", 2)
+	if len(idArr) != 2 || idArr[1] == "" || strings.Contains(idArr[1], "/") {
+		return ResourceID{}, "", errors.New(fmt.Sprintf("invalid subnet ID %q: not a {virtual network ID}/subnets/{name}", id))
+	}
+	vNetworkID, err := ParseResourceID(idArr[0], VirtualNetworksType, subscriptionID, resourceGroup)
+	if err != nil {
+		return ResourceID{}, "", err
+	}
+	return vNetworkID, idArr[1], nil
+}
+
+// ParseImageReference parses the image ID of a VM.
+// imageID is a marketplace image(publisher:offer:sku:version) or the ID of a managed image(see ParseResourceID).
+func ParseImageReference(imageID string, subscriptionID string, resourceGroup string) (compute.ImageReference, error) {
+	if strings.Count(imageID, ":") != 3 {
+		imageResourceID, err := ParseResourceID(imageID, ImagesType, subscriptionID, resourceGroup)
+		if err != nil {
+			return compute.ImageReference{}, errors.New(fmt.Sprintf("invalid image ID %q: not a publisher:offer:sku:version nor an image ID, %v", imageID, err))
+		}
+		id := imageResourceID.String()
+		return compute.ImageReference{ID: &id}, nil
+	}
+
+	imageIdArr := strings.Split(imageID, ":")
+	for _, part := range imageIdArr {
+		if part == "" {
+			return compute.ImageReference{}, errors.New(fmt.Sprintf("invalid image ID %q: publisher, offer, sku and version are required", imageID))
+		}
+	}
+	return compute.ImageReference{
+		Publisher: &imageIdArr[0],
+		Offer:     &imageIdArr[1],
+		Sku:       &imageIdArr[2],
+		Version:   &imageIdArr[3],
+	}, nil
+}
+
+// GetImageID emits the image ID of an image reference(see ParseImageReference).
+func GetImageID(imageRef *compute.ImageReference) string {
+	if imageRef == nil {
+		return ""
+	}
+	if imageRef.ID != nil {
+		return *imageRef.ID
+	}
+	if imageRef.Publisher == nil || imageRef.Offer == nil || imageRef.Sku == nil || imageRef.Version == nil {
+		return ""
+	}
+	return *imageRef.Publisher + ":" + *imageRef.Offer + ":" + *imageRef.Sku + ":" + *imageRef.Version
+}
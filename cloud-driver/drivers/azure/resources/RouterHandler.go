@@ -0,0 +1,387 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// A router of Azure is a route table. The Internet is a next hop type of routes,
+// so a route table has no gateway and the routes to the gateway are the routes to the Internet.
+type AzureRouterHandler struct {
+	Region       idrv.RegionInfo
+	Ctx          context.Context
+	Client       *network.RouteTablesClient
+	SubnetClient *network.SubnetsClient
+}
+
+// mappingRouterInfo maps a route table into irs.RouterInfo.
+func mappingRouterInfo(routeTable network.RouteTable) irs.RouterInfo {
+	routerInfo := irs.RouterInfo{
+		Id:   to.String(routeTable.ID),
+		Name: to.String(routeTable.Name),
+		Tags: getTags(routeTable.Tags),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Location", Value: to.String(routeTable.Location)},
+		},
+	}
+
+	if routeTable.RouteTablePropertiesFormat == nil {
+		return routerInfo
+	}
+	routerInfo.Status = to.String(routeTable.ProvisioningState)
+	routerInfo.KeyValueList = append(routerInfo.KeyValueList, irs.KeyValue{
+		Key:   "DisableBgpRoutePropagation",
+		Value: strconv.FormatBool(to.Bool(routeTable.DisableBgpRoutePropagation)),
+	})
+	if routeTable.Routes != nil {
+		for _, route := range *routeTable.Routes {
+			if route.RoutePropertiesFormat == nil {
+				continue
+			}
+			routeInfo := irs.RouteInfo{
+				DestinationCIDR: to.String(route.AddressPrefix),
+				NextHop:         string(route.NextHopType),
+			}
+			switch route.NextHopType {
+			case network.RouteNextHopTypeInternet:
+				routeInfo.NextHop = irs.GatewayNextHop
+			case network.RouteNextHopTypeVirtualAppliance:
+				routeInfo.NextHop = to.String(route.NextHopIPAddress)
+			}
+			routerInfo.Routes = append(routerInfo.Routes, routeInfo)
+		}
+	}
+	if routeTable.Subnets != nil {
+		for _, subnet := range *routeTable.Subnets {
+			routerInfo.SubnetIds = append(routerInfo.SubnetIds, to.String(subnet.ID))
+		}
+	}
+	return routerInfo
+}
+
+// getRoute translates a route into an Azure route.
+// The NextHop is GatewayNextHop(Internet), the IP address of a virtual appliance,
+// or VnetLocal, VirtualNetworkGateway and None of Azure.
+// The name of a route is made from the destination. ex) route-10.0.0.0-16
+func getRoute(route irs.RouteInfo) (network.Route, error) {
+	if err := irs.CheckRoute(route); err != nil {
+		return network.Route{}, err
+	}
+
+	routeProperties := &network.RoutePropertiesFormat{
+		AddressPrefix: to.StringPtr(route.DestinationCIDR),
+	}
+	switch {
+	case route.NextHop == irs.GatewayNextHop:
+		routeProperties.NextHopType = network.RouteNextHopTypeInternet
+	case net.ParseIP(route.NextHop) != nil:
+		routeProperties.NextHopType = network.RouteNextHopTypeVirtualAppliance
+		routeProperties.NextHopIPAddress = to.StringPtr(route.NextHop)
+	case route.NextHop == string(network.RouteNextHopTypeVnetLocal),
+		route.NextHop == string(network.RouteNextHopTypeVirtualNetworkGateway),
+		route.NextHop == string(network.RouteNextHopTypeNone):
+		routeProperties.NextHopType = network.RouteNextHopType(route.NextHop)
+	default:
+		return network.Route{}, errors.New(fmt.Sprintf("invalid next hop %s of Azure routes: use gateway, an IP address, VnetLocal, VirtualNetworkGateway or None", route.NextHop))
+	}
+
+	name := "route-" + strings.NewReplacer("/", "-", ":", "-").Replace(route.DestinationCIDR)
+	return network.Route{
+		Name:                  to.StringPtr(name),
+		RoutePropertiesFormat: routeProperties,
+	}, nil
+}
+
+func getRouteList(routes []irs.RouteInfo) ([]network.Route, error) {
+	routeList := []network.Route{}
+	for _, route := range routes {
+		azureRoute, err := getRoute(route)
+		if err != nil {
+			return nil, err
+		}
+		routeList = append(routeList, azureRoute)
+	}
+	return routeList, nil
+}
+
+// Route tables have no gateway, so the GatewayId is rejected.
+func (routerHandler *AzureRouterHandler) CreateRouter(routerReqInfo irs.RouterReqInfo) (irs.RouterInfo, error) {
+	if routerReqInfo.GatewayId != "" {
+		return irs.RouterInfo{}, errors.New("gateways of route tables are not supported by Azure, use the routes to the gateway(Internet)")
+	}
+	routeList, err := getRouteList(routerReqInfo.Routes)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+
+	resourceID, err := ParseResourceID(routerReqInfo.Id, RouteTablesType, routerHandler.Client.SubscriptionID, routerHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+
+	// Check RouteTable Exists
+	routeTable, err := routerHandler.Client.Get(routerHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
+	if routeTable.ID != nil {
+		errMsg := fmt.Sprintf("Route Table with name %s already exist", resourceID.Name)
+		createErr := errors.New(errMsg)
+		return irs.RouterInfo{}, createErr
+	}
+
+	createOpts := network.RouteTable{
+		RouteTablePropertiesFormat: &network.RouteTablePropertiesFormat{
+			Routes: &routeList,
+		},
+		Location: &routerHandler.Region.Region,
+		Tags:     getAzureTags(routerReqInfo.Tags),
+	}
+	future, err := routerHandler.Client.CreateOrUpdate(routerHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, createOpts)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	err = future.WaitForCompletionRef(routerHandler.Ctx, routerHandler.Client.Client)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+
+	for _, subnetID := range routerReqInfo.SubnetIds {
+		if err := routerHandler.setSubnetRouteTable(subnetID, "", to.StringPtr(resourceID.String())); err != nil {
+			// the route table without the requested subnets is useless, so it is deleted.
+			if _, deleteErr := routerHandler.DeleteRouter(resourceID.String()); deleteErr != nil {
+				fmt.Println(deleteErr)
+			}
+			return irs.RouterInfo{}, err
+		}
+	}
+
+	return routerHandler.GetRouter(resourceID.String())
+}
+
+// setSubnetRouteTable sets the route table of a subnet, nil routeTableID detaches the route table.
+// setSubnetRouteTable sets the route table of a subnet, nil: no route table.
+// currentRouteTableID is the route table the subnet must be attached to, "": not checked.
+func (routerHandler *AzureRouterHandler) setSubnetRouteTable(subnetID string, currentRouteTableID string, routeTableID *string) error {
+	vNetworkID, subnetName, err := ParseSubnetID(subnetID, routerHandler.Client.SubscriptionID, routerHandler.Region.ResourceGroup)
+	if err != nil {
+		return err
+	}
+	subnet, err := routerHandler.SubnetClient.Get(routerHandler.Ctx, vNetworkID.ResourceGroup, vNetworkID.Name, subnetName, "")
+	if err != nil {
+		return err
+	}
+
+	if currentRouteTableID != "" {
+		if subnet.SubnetPropertiesFormat == nil || subnet.RouteTable == nil || !strings.EqualFold(to.String(subnet.RouteTable.ID), currentRouteTableID) {
+			return errors.New(fmt.Sprintf("subnet %s is not attached to route table %s", subnetName, currentRouteTableID))
+		}
+	}
+
+	if subnet.SubnetPropertiesFormat == nil {
+		subnet.SubnetPropertiesFormat = &network.SubnetPropertiesFormat{}
+	}
+	subnet.RouteTable = nil
+	if routeTableID != nil {
+		subnet.RouteTable = &network.RouteTable{ID: routeTableID}
+	}
+
+	future, err := routerHandler.SubnetClient.CreateOrUpdate(routerHandler.Ctx, vNetworkID.ResourceGroup, vNetworkID.Name, subnetName, subnet)
+	if err != nil {
+		return err
+	}
+	return future.WaitForCompletionRef(routerHandler.Ctx, routerHandler.SubnetClient.Client)
+}
+
+// listRouterPage gets a page of route tables in the resource group of the connection.
+func (routerHandler *AzureRouterHandler) listRouterPage(listReqInfo irs.ListReqInfo) (network.RouteTableListResult, error) {
+	if listReqInfo.NextToken == "" {
+		result, err := routerHandler.Client.List(routerHandler.Ctx, routerHandler.Region.ResourceGroup)
+		if err != nil {
+			return network.RouteTableListResult{}, err
+		}
+		return result.Response(), nil
+	}
+
+	req, err := getNextPageRequest(routerHandler.Ctx, routerHandler.Client.BaseURI, listReqInfo.NextToken)
+	if err != nil {
+		return network.RouteTableListResult{}, err
+	}
+	resp, err := routerHandler.Client.ListSender(req)
+	if err != nil {
+		return network.RouteTableListResult{}, err
+	}
+	return routerHandler.Client.ListResponder(resp)
+}
+
+func (routerHandler *AzureRouterHandler) ListRouter(listReqInfo irs.ListReqInfo) ([]*irs.RouterInfo, irs.ListPageInfo, error) {
+	result, err := routerHandler.listRouterPage(listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var routerList []*irs.RouterInfo
+	if result.Value == nil {
+		return routerList, getNextToken(result.NextLink), nil
+	}
+	for _, routeTable := range *result.Value {
+		if !matchListFilter(listReqInfo, routeTable.Name, routeTable.Tags) {
+			continue
+		}
+		routerInfo := mappingRouterInfo(routeTable)
+		routerList = append(routerList, &routerInfo)
+	}
+
+	return routerList, getNextToken(result.NextLink), nil
+}
+
+func (routerHandler *AzureRouterHandler) GetRouter(routerID string) (irs.RouterInfo, error) {
+	resourceID, err := ParseResourceID(routerID, RouteTablesType, routerHandler.Client.SubscriptionID, routerHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	routeTable, err := routerHandler.Client.Get(routerHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+
+	routerInfo := mappingRouterInfo(routeTable)
+
+	spew.Dump(routerInfo)
+	return routerInfo, nil
+}
+
+func (routerHandler *AzureRouterHandler) DeleteRouter(routerID string) (bool, error) {
+	resourceID, err := ParseResourceID(routerID, RouteTablesType, routerHandler.Client.SubscriptionID, routerHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	routeTable, err := routerHandler.Client.Get(routerHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
+	if err != nil {
+		return false, err
+	}
+
+	// a route table used by subnets can not be deleted.
+	for _, subnetID := range mappingRouterInfo(routeTable).SubnetIds {
+		if err := routerHandler.setSubnetRouteTable(subnetID, resourceID.String(), nil); err != nil {
+			return false, err
+		}
+	}
+
+	future, err := routerHandler.Client.Delete(routerHandler.Ctx, resourceID.ResourceGroup, resourceID.Name)
+	if err != nil {
+		return false, err
+	}
+	err = future.WaitForCompletionRef(routerHandler.Ctx, routerHandler.Client.Client)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (routerHandler *AzureRouterHandler) SetGateway(routerID string, gatewayID string) (irs.RouterInfo, error) {
+	return irs.RouterInfo{}, errors.New("gateways of route tables are not supported by Azure, use the routes to the gateway(Internet)")
+}
+
+func (routerHandler *AzureRouterHandler) RemoveGateway(routerID string) (irs.RouterInfo, error) {
+	return irs.RouterInfo{}, errors.New("gateways of route tables are not supported by Azure, remove the routes to the gateway(Internet)")
+}
+
+func (routerHandler *AzureRouterHandler) AddRoutes(routerID string, routes []irs.RouteInfo) (irs.RouterInfo, error) {
+	routeList, err := getRouteList(routes)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.updateRoutes(routerID, func(existingRoutes []network.Route) ([]network.Route, error) {
+		return append(existingRoutes, routeList...), nil
+	})
+}
+
+// A route is removed by the destination, a route table has only one route for a destination.
+func (routerHandler *AzureRouterHandler) RemoveRoutes(routerID string, routes []irs.RouteInfo) (irs.RouterInfo, error) {
+	return routerHandler.updateRoutes(routerID, func(existingRoutes []network.Route) ([]network.Route, error) {
+		removeRoutes := map[string]bool{}
+		for _, route := range routes {
+			removeRoutes[route.DestinationCIDR] = true
+		}
+
+		keptRoutes := []network.Route{}
+		for _, route := range existingRoutes {
+			if route.RoutePropertiesFormat != nil && removeRoutes[to.String(route.AddressPrefix)] {
+				delete(removeRoutes, to.String(route.AddressPrefix))
+				continue
+			}
+			keptRoutes = append(keptRoutes, route)
+		}
+		for destination := range removeRoutes {
+			return nil, errors.New(fmt.Sprintf("no route to %s in route table %s", destination, routerID))
+		}
+		return keptRoutes, nil
+	})
+}
+
+// updateRoutes replaces the routes of a route table with the routes made by updateFunc from the existing routes.
+func (routerHandler *AzureRouterHandler) updateRoutes(routerID string, updateFunc func(existingRoutes []network.Route) ([]network.Route, error)) (irs.RouterInfo, error) {
+	resourceID, err := ParseResourceID(routerID, RouteTablesType, routerHandler.Client.SubscriptionID, routerHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	routeTable, err := routerHandler.Client.Get(routerHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+
+	var existingRoutes []network.Route
+	if routeTable.RouteTablePropertiesFormat == nil {
+		routeTable.RouteTablePropertiesFormat = &network.RouteTablePropertiesFormat{}
+	}
+	if routeTable.Routes != nil {
+		existingRoutes = *routeTable.Routes
+	}
+	routeList, err := updateFunc(existingRoutes)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	routeTable.Routes = &routeList
+
+	future, err := routerHandler.Client.CreateOrUpdate(routerHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, routeTable)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	err = future.WaitForCompletionRef(routerHandler.Ctx, routerHandler.Client.Client)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+
+	return routerHandler.GetRouter(routerID)
+}
+
+func (routerHandler *AzureRouterHandler) AttachSubnet(routerID string, subnetID string) (irs.RouterInfo, error) {
+	resourceID, err := ParseResourceID(routerID, RouteTablesType, routerHandler.Client.SubscriptionID, routerHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	if err := routerHandler.setSubnetRouteTable(subnetID, "", to.StringPtr(resourceID.String())); err != nil {
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
+}
+
+func (routerHandler *AzureRouterHandler) DetachSubnet(routerID string, subnetID string) (irs.RouterInfo, error) {
+	resourceID, err := ParseResourceID(routerID, RouteTablesType, routerHandler.Client.SubscriptionID, routerHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	// the subnet attached to another route table is not detached.
+	if err := routerHandler.setSubnetRouteTable(subnetID, resourceID.String(), nil); err != nil {
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
+}
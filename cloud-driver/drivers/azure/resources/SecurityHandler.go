@@ -4,12 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
-	"github.com/davecgh/go-spew/spew"
-	"strings"
 )
 
 type AzureSecurityHandler struct {
@@ -18,121 +19,107 @@ type AzureSecurityHandler struct {
 	Client *network.SecurityGroupsClient
 }
 
-// @TODO: SecurityInfo 리소스 프로퍼티 정의 필요
-type SecurityInfo struct {
-	Id                   string
-	Name                 string
-	Location             string
-	SecurityRules        []SecurityRuleInfo
-	DefaultSecurityRules []SecurityRuleInfo
-}
+// mappingSecurityInfo maps a network security group into irs.SecurityInfo.
+// The DefaultSecurityRules(priority 65000~) are the fixed rules of every NSG, so they are not included.
+func mappingSecurityInfo(securityGroup network.SecurityGroup) irs.SecurityInfo {
+	securityInfo := irs.SecurityInfo{
+		Id:   to.String(securityGroup.ID),
+		Name: to.String(securityGroup.Name),
+		Tags: getTags(securityGroup.Tags),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Location", Value: to.String(securityGroup.Location)},
+		},
+	}
 
-type SecurityRuleInfo struct {
-	Name                     string
-	SourceAddressPrefix      string
-	SourcePortRange          string
-	DestinationAddressPrefix string
-	DestinationPortRange     string
-	Protocol                 string
-	Access                   string
-	Priority                 int32
-	Direction                string
+	if securityGroup.SecurityGroupPropertiesFormat == nil || securityGroup.SecurityRules == nil {
+		return securityInfo
+	}
+	securityInfo.SecurityRules = mappingSecurityRules(*securityGroup.SecurityRules)
+	return securityInfo
 }
 
-func (security *SecurityInfo) setter(securityGroup network.SecurityGroup) *SecurityInfo {
-	security.Id = *securityGroup.ID
-	security.Name = *securityGroup.Name
-	security.Location = *securityGroup.Location
+// mappingSecurityRuleInfo maps a NSG rule into rules.
+// A NSG rule has many port ranges and address prefixes, so it is split into a rule for each of them.
+// The Id of a rule is the name of the NSG rule.
+func mappingSecurityRuleInfo(sgRule network.SecurityRule) []irs.SecurityRuleInfo {
+	if sgRule.SecurityRulePropertiesFormat == nil {
+		return nil
+	}
+
+	rule := irs.SecurityRuleInfo{
+		Id:         to.String(sgRule.Name),
+		Direction:  strings.ToLower(string(sgRule.Direction)),
+		IPProtocol: strings.ToLower(string(sgRule.Protocol)),
+		Action:     strings.ToLower(string(sgRule.Access)),
+		Priority:   int(to.Int32(sgRule.Priority)),
+	}
+	if rule.IPProtocol == "*" {
+		rule.IPProtocol = "all"
+	}
 
-	var securityRuleArr []SecurityRuleInfo
-	var defaultSecurityRuleArr []SecurityRuleInfo
+	// the other side of the traffic: source of inbound, destination of outbound rules.
+	portRanges := append([]string{to.String(sgRule.DestinationPortRange)}, to.StringSlice(sgRule.DestinationPortRanges)...)
+	addressPrefixes := append([]string{to.String(sgRule.SourceAddressPrefix)}, to.StringSlice(sgRule.SourceAddressPrefixes)...)
+	peerGroups := sgRule.SourceApplicationSecurityGroups
+	if sgRule.Direction == network.SecurityRuleDirectionOutbound {
+		addressPrefixes = append([]string{to.String(sgRule.DestinationAddressPrefix)}, to.StringSlice(sgRule.DestinationAddressPrefixes)...)
+		peerGroups = sgRule.DestinationApplicationSecurityGroups
+	}
 
-	for _, sgRule := range *securityGroup.SecurityRules {
-		ruleInfo := SecurityRuleInfo{
-			Name:                     *sgRule.Name,
-			SourceAddressPrefix:      *sgRule.SourceAddressPrefix,
-			SourcePortRange:          *sgRule.SourcePortRange,
-			DestinationAddressPrefix: *sgRule.DestinationAddressPrefix,
-			DestinationPortRange:     *sgRule.DestinationPortRange,
-			Protocol:                 fmt.Sprint(sgRule.Protocol),
-			Access:                   fmt.Sprint(sgRule.Access),
-			Priority:                 *sgRule.Priority,
-			Direction:                fmt.Sprint(sgRule.Direction),
+	var securityRules []irs.SecurityRuleInfo
+	for _, portRange := range portRanges {
+		if portRange == "" {
+			continue
 		}
-		securityRuleArr = append(securityRuleArr, ruleInfo)
-	}
+		portRule := rule
+		portRule.FromPort, portRule.ToPort = parsePortRange(portRange)
 
-	for _, sgRule := range *securityGroup.DefaultSecurityRules {
-		ruleInfo := SecurityRuleInfo{
-			Name:                     *sgRule.Name,
-			SourceAddressPrefix:      *sgRule.SourceAddressPrefix,
-			SourcePortRange:          *sgRule.SourcePortRange,
-			DestinationAddressPrefix: *sgRule.DestinationAddressPrefix,
-			DestinationPortRange:     *sgRule.DestinationPortRange,
-			Protocol:                 fmt.Sprint(sgRule.Protocol),
-			Access:                   fmt.Sprint(sgRule.Access),
-			Priority:                 *sgRule.Priority,
-			Direction:                fmt.Sprint(sgRule.Direction),
+		for _, addressPrefix := range addressPrefixes {
+			if addressPrefix == "" {
+				continue
+			}
+			cidrRule := portRule
+			cidrRule.CIDR = addressPrefix
+			if addressPrefix == "*" {
+				cidrRule.CIDR = "0.0.0.0/0"
+			}
+			securityRules = append(securityRules, cidrRule)
+		}
+		if peerGroups != nil {
+			for _, peerGroup := range *peerGroups {
+				groupRule := portRule
+				groupRule.PeerGroup = to.String(peerGroup.ID)
+				securityRules = append(securityRules, groupRule)
+			}
 		}
-		defaultSecurityRuleArr = append(defaultSecurityRuleArr, ruleInfo)
 	}
+	return securityRules
+}
 
-	security.SecurityRules = securityRuleArr
-	security.DefaultSecurityRules = defaultSecurityRuleArr
-
-	return security
+// parsePortRange parses a port range of a NSG rule. ex) "*": -1, -1 / "22": 22, 22 / "8080-8090": 8080, 8090
+func parsePortRange(portRange string) (int, int) {
+	if portRange == "*" {
+		return -1, -1
+	}
+	portArr := strings.SplitN(portRange, "-", 2)
+	fromPort, err := strconv.Atoi(portArr[0])
+	if err != nil {
+		return -1, -1
+	}
+	toPort := fromPort
+	if len(portArr) == 2 {
+		if toPort, err = strconv.Atoi(portArr[1]); err != nil {
+			return -1, -1
+		}
+	}
+	return fromPort, toPort
 }
 
 func (securityHandler *AzureSecurityHandler) CreateSecurity(securityReqInfo irs.SecurityReqInfo) (irs.SecurityInfo, error) {
 
-	// @TODO: SecurityGroup 생성 요청 파라미터 정의 필요
-	type SecurityReqInfo struct {
-		SecurityRules *[]SecurityRuleInfo
-	}
-
-	reqInfo := SecurityReqInfo{
-		SecurityRules: &[]SecurityRuleInfo{
-			{
-				Name:                     "HTTP",
-				SourceAddressPrefix:      "*",
-				SourcePortRange:          "*",
-				DestinationAddressPrefix: "*",
-				DestinationPortRange:     "80",
-				Protocol:                 "TCP",
-				Access:                   "Allow",
-				Priority:                 300,
-				Direction:                "Inbound",
-			},
-			{
-				Name:                     "SSH",
-				SourceAddressPrefix:      "*",
-				SourcePortRange:          "*",
-				DestinationAddressPrefix: "*",
-				DestinationPortRange:     "22",
-				Protocol:                 "TCP",
-				Access:                   "Allow",
-				Priority:                 320,
-				Direction:                "Inbound",
-			},
-		},
-	}
-
-	var sgRuleList []network.SecurityRule
-	for _, rule := range *reqInfo.SecurityRules {
-		sgRuleInfo := network.SecurityRule{
-			Name: to.StringPtr(rule.Name),
-			SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-				SourceAddressPrefix:      to.StringPtr(rule.SourceAddressPrefix),
-				SourcePortRange:          to.StringPtr(rule.SourcePortRange),
-				DestinationAddressPrefix: to.StringPtr(rule.DestinationAddressPrefix),
-				DestinationPortRange:     to.StringPtr(rule.DestinationPortRange),
-				Protocol:                 network.SecurityRuleProtocol(rule.Protocol),
-				Access:                   network.SecurityRuleAccess(rule.Access),
-				Priority:                 to.Int32Ptr(rule.Priority),
-				Direction:                network.SecurityRuleDirection(rule.Direction),
-			},
-		}
-		sgRuleList = append(sgRuleList, sgRuleInfo)
+	sgRuleList, err := getSecurityRules(securityReqInfo.SecurityRules, nil)
+	if err != nil {
+		return irs.SecurityInfo{}, err
 	}
 
 	createOpts := network.SecurityGroup{
@@ -140,19 +127,23 @@ func (securityHandler *AzureSecurityHandler) CreateSecurity(securityReqInfo irs.
 			SecurityRules: &sgRuleList,
 		},
 		Location: &securityHandler.Region.Region,
+		Tags:     getAzureTags(securityReqInfo.Tags),
 	}
 
-	securityIdArr := strings.Split(securityReqInfo.Id, ":")
+	resourceID, err := ParseResourceID(securityReqInfo.Id, SecurityGroupsType, securityHandler.Client.SubscriptionID, securityHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
 
 	// Check SecurityGroup Exists
-	security, err := securityHandler.Client.Get(securityHandler.Ctx, securityIdArr[0], securityIdArr[1], "")
+	security, err := securityHandler.Client.Get(securityHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
 	if security.ID != nil {
-		errMsg := fmt.Sprintf("Security Group with name %s already exist", securityIdArr[1])
+		errMsg := fmt.Sprintf("Security Group with name %s already exist", resourceID.Name)
 		createErr := errors.New(errMsg)
 		return irs.SecurityInfo{}, createErr
 	}
 
-	future, err := securityHandler.Client.CreateOrUpdate(securityHandler.Ctx, securityIdArr[0], securityIdArr[1], createOpts)
+	future, err := securityHandler.Client.CreateOrUpdate(securityHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, createOpts)
 	if err != nil {
 		return irs.SecurityInfo{}, err
 	}
@@ -161,47 +152,206 @@ func (securityHandler *AzureSecurityHandler) CreateSecurity(securityReqInfo irs.
 		return irs.SecurityInfo{}, err
 	}
 
-	// @TODO: 생성된 SecurityGroup 정보 리턴
-	publicIPInfo, err := securityHandler.GetSecurity(securityReqInfo.Id)
+	securityInfo, err := securityHandler.GetSecurity(securityReqInfo.Id)
 	if err != nil {
 		return irs.SecurityInfo{}, err
 	}
-	return publicIPInfo, nil
+	return securityInfo, nil
+}
+
+// priorities of NSG rules, a lower number is applied first.
+const (
+	minRulePriority  = 100
+	maxRulePriority  = 4096
+	rulePriorityStep = 10
+)
+
+// getSecurityRules translates rules into NSG rules.
+// The priority of a rule without one is assigned in the order of the rules,
+// after the priorities of the existing rules of the same direction.
+// The name of a NSG rule is {direction}-{protocol}-{priority}. ex) inbound-tcp-100
+func getSecurityRules(rules []irs.SecurityRuleInfo, existingRules []network.SecurityRule) ([]network.SecurityRule, error) {
+	usedPriorities := map[string]map[int]bool{"inbound": {}, "outbound": {}}
+	nextPriorities := map[string]int{"inbound": minRulePriority, "outbound": minRulePriority}
+	for _, sgRule := range existingRules {
+		if sgRule.SecurityRulePropertiesFormat == nil {
+			continue
+		}
+		direction := strings.ToLower(string(sgRule.Direction))
+		priority := int(to.Int32(sgRule.Priority))
+		usedPriorities[direction][priority] = true
+		if priority >= nextPriorities[direction] {
+			nextPriorities[direction] = priority + rulePriorityStep
+		}
+	}
+
+	// requested priorities first, the others are assigned around them.
+	for _, rule := range rules {
+		if err := irs.CheckSecurityRule(rule); err != nil {
+			return nil, err
+		}
+		if rule.Priority == 0 {
+			continue
+		}
+		if rule.Priority < minRulePriority || rule.Priority > maxRulePriority {
+			return nil, errors.New(fmt.Sprintf("invalid priority %d of a security rule: Azure supports %d ~ %d", rule.Priority, minRulePriority, maxRulePriority))
+		}
+		if usedPriorities[rule.Direction][rule.Priority] {
+			return nil, errors.New(fmt.Sprintf("duplicated priority %d of %s security rules", rule.Priority, rule.Direction))
+		}
+		usedPriorities[rule.Direction][rule.Priority] = true
+	}
+
+	var sgRuleList []network.SecurityRule
+	for _, rule := range rules {
+		priority := rule.Priority
+		if priority == 0 {
+			for usedPriorities[rule.Direction][nextPriorities[rule.Direction]] {
+				nextPriorities[rule.Direction] += rulePriorityStep
+			}
+			priority = nextPriorities[rule.Direction]
+			if priority > maxRulePriority {
+				return nil, errors.New(fmt.Sprintf("no priority left for %s security rules", rule.Direction))
+			}
+			usedPriorities[rule.Direction][priority] = true
+		}
+
+		sgRule, err := getSecurityRule(rule, priority)
+		if err != nil {
+			return nil, err
+		}
+		sgRuleList = append(sgRuleList, sgRule)
+	}
+	return sgRuleList, nil
+}
+
+// getSecurityRule translates a rule into a NSG rule with the priority.
+// The PeerGroup of a rule is an application security group of Azure.
+func getSecurityRule(rule irs.SecurityRuleInfo, priority int) (network.SecurityRule, error) {
+	var protocol network.SecurityRuleProtocol
+	switch rule.IPProtocol {
+	case "tcp":
+		protocol = network.SecurityRuleProtocolTCP
+	case "udp":
+		protocol = network.SecurityRuleProtocolUDP
+	case "all":
+		protocol = network.SecurityRuleProtocolAsterisk
+	default:
+		return network.SecurityRule{}, errors.New(fmt.Sprintf("%s security rules are not supported by Azure NSGs of this driver, use tcp, udp or all", rule.IPProtocol))
+	}
+
+	portRange := "*"
+	if rule.FromPort != -1 {
+		portRange = strconv.Itoa(rule.FromPort)
+		if rule.ToPort != rule.FromPort {
+			portRange += "-" + strconv.Itoa(rule.ToPort)
+		}
+	}
+
+	access := network.SecurityRuleAccessAllow
+	if rule.Action == "deny" {
+		access = network.SecurityRuleAccessDeny
+	}
+
+	ruleProperties := &network.SecurityRulePropertiesFormat{
+		Protocol:             protocol,
+		SourcePortRange:      to.StringPtr("*"),
+		DestinationPortRange: to.StringPtr(portRange),
+		Access:               access,
+		Priority:             to.Int32Ptr(int32(priority)),
+	}
+
+	// the other side of the traffic: source of inbound, destination of outbound rules.
+	var peerGroups *[]network.ApplicationSecurityGroup
+	if rule.PeerGroup != "" {
+		peerGroups = &[]network.ApplicationSecurityGroup{{ID: to.StringPtr(rule.PeerGroup)}}
+	}
+	if rule.Direction == "inbound" {
+		ruleProperties.Direction = network.SecurityRuleDirectionInbound
+		ruleProperties.DestinationAddressPrefix = to.StringPtr("*")
+		if peerGroups != nil {
+			ruleProperties.SourceApplicationSecurityGroups = peerGroups
+		} else {
+			ruleProperties.SourceAddressPrefix = to.StringPtr(rule.CIDR)
+		}
+	} else {
+		ruleProperties.Direction = network.SecurityRuleDirectionOutbound
+		ruleProperties.SourceAddressPrefix = to.StringPtr("*")
+		if peerGroups != nil {
+			ruleProperties.DestinationApplicationSecurityGroups = peerGroups
+		} else {
+			ruleProperties.DestinationAddressPrefix = to.StringPtr(rule.CIDR)
+		}
+	}
+
+	return network.SecurityRule{
+		Name:                         to.StringPtr(fmt.Sprintf("%s-%s-%d", rule.Direction, rule.IPProtocol, priority)),
+		SecurityRulePropertiesFormat: ruleProperties,
+	}, nil
+}
+
+// listSecurityPage gets a page of security groups in the resource group of the connection.
+func (securityHandler *AzureSecurityHandler) listSecurityPage(listReqInfo irs.ListReqInfo) (network.SecurityGroupListResult, error) {
+	if listReqInfo.NextToken == "" {
+		result, err := securityHandler.Client.List(securityHandler.Ctx, securityHandler.Region.ResourceGroup)
+		if err != nil {
+			return network.SecurityGroupListResult{}, err
+		}
+		return result.Response(), nil
+	}
+
+	req, err := getNextPageRequest(securityHandler.Ctx, securityHandler.Client.BaseURI, listReqInfo.NextToken)
+	if err != nil {
+		return network.SecurityGroupListResult{}, err
+	}
+	resp, err := securityHandler.Client.ListSender(req)
+	if err != nil {
+		return network.SecurityGroupListResult{}, err
+	}
+	return securityHandler.Client.ListResponder(resp)
 }
 
-func (securityHandler *AzureSecurityHandler) ListSecurity() ([]*irs.SecurityInfo, error) {
-	//result, err := securityHandler.Client.ListAll(securityHandler.Ctx)
-	result, err := securityHandler.Client.List(securityHandler.Ctx, securityHandler.Region.ResourceGroup)
+func (securityHandler *AzureSecurityHandler) ListSecurity(listReqInfo irs.ListReqInfo) ([]*irs.SecurityInfo, irs.ListPageInfo, error) {
+	result, err := securityHandler.listSecurityPage(listReqInfo)
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
 	}
 
-	var securityList []*SecurityInfo
-	for _, security := range result.Values() {
-		securityInfo := new(SecurityInfo).setter(security)
-		securityList = append(securityList, securityInfo)
+	var securityList []*irs.SecurityInfo
+	if result.Value == nil {
+		return securityList, getNextToken(result.NextLink), nil
+	}
+	for _, security := range *result.Value {
+		if !matchListFilter(listReqInfo, security.Name, security.Tags) {
+			continue
+		}
+		securityInfo := mappingSecurityInfo(security)
+		securityList = append(securityList, &securityInfo)
 	}
 
-	spew.Dump(securityList)
-	return nil, nil
+	return securityList, getNextToken(result.NextLink), nil
 }
 
 func (securityHandler *AzureSecurityHandler) GetSecurity(securityID string) (irs.SecurityInfo, error) {
-	securityIdArr := strings.Split(securityID, ":")
-	security, err := securityHandler.Client.Get(securityHandler.Ctx, securityIdArr[0], securityIdArr[1], "")
+	resourceID, err := ParseResourceID(securityID, SecurityGroupsType, securityHandler.Client.SubscriptionID, securityHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	security, err := securityHandler.Client.Get(securityHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
 	if err != nil {
 		return irs.SecurityInfo{}, err
 	}
 
-	securityInfo := new(SecurityInfo).setter(security)
-
-	spew.Dump(securityInfo)
-	return irs.SecurityInfo{}, nil
+	securityInfo := mappingSecurityInfo(security)
+	return securityInfo, nil
 }
 
 func (securityHandler *AzureSecurityHandler) DeleteSecurity(securityID string) (bool, error) {
-	securityIDArr := strings.Split(securityID, ":")
-	future, err := securityHandler.Client.Delete(securityHandler.Ctx, securityIDArr[0], securityIDArr[1])
+	resourceID, err := ParseResourceID(securityID, SecurityGroupsType, securityHandler.Client.SubscriptionID, securityHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	future, err := securityHandler.Client.Delete(securityHandler.Ctx, resourceID.ResourceGroup, resourceID.Name)
 	if err != nil {
 		return false, err
 	}
@@ -211,3 +361,147 @@ func (securityHandler *AzureSecurityHandler) DeleteSecurity(securityID string) (
 	}
 	return true, nil
 }
+
+func (securityHandler *AzureSecurityHandler) AddRules(securityID string, securityRules []irs.SecurityRuleInfo) (irs.SecurityInfo, error) {
+	return securityHandler.updateSecurityRules(securityID, func(existingRules []network.SecurityRule) ([]network.SecurityRule, error) {
+		sgRuleList, err := getSecurityRules(securityRules, existingRules)
+		if err != nil {
+			return nil, err
+		}
+		return append(existingRules, sgRuleList...), nil
+	})
+}
+
+func (securityHandler *AzureSecurityHandler) RemoveRules(securityID string, securityRules []irs.SecurityRuleInfo) (irs.SecurityInfo, error) {
+	rules, err := normalizeRules(securityRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	return securityHandler.updateSecurityRules(securityID, func(existingRules []network.SecurityRule) ([]network.SecurityRule, error) {
+		removeRules, err := irs.FindSecurityRules(mappingSecurityRules(existingRules), rules)
+		if err != nil {
+			return nil, err
+		}
+		return removeSecurityRules(existingRules, removeRules)
+	})
+}
+
+// The rules to add and the rules to remove are applied by an update of the NSG.
+func (securityHandler *AzureSecurityHandler) SyncRules(securityID string, desiredRules []irs.SecurityRuleInfo) (irs.SecurityInfo, error) {
+	rules, err := normalizeRules(desiredRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	return securityHandler.updateSecurityRules(securityID, func(existingRules []network.SecurityRule) ([]network.SecurityRule, error) {
+		addRules, removeRules := irs.DiffSecurityRules(mappingSecurityRules(existingRules), rules)
+		keptRules, err := removeSecurityRules(existingRules, removeRules)
+		if err != nil {
+			return nil, err
+		}
+		sgRuleList, err := getSecurityRules(addRules, keptRules)
+		if err != nil {
+			return nil, err
+		}
+		return append(keptRules, sgRuleList...), nil
+	})
+}
+
+// updateSecurityRules replaces the rules of a NSG with the rules made by updateFunc from the existing rules.
+func (securityHandler *AzureSecurityHandler) updateSecurityRules(securityID string, updateFunc func(existingRules []network.SecurityRule) ([]network.SecurityRule, error)) (irs.SecurityInfo, error) {
+	resourceID, err := ParseResourceID(securityID, SecurityGroupsType, securityHandler.Client.SubscriptionID, securityHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	security, err := securityHandler.Client.Get(securityHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+
+	var existingRules []network.SecurityRule
+	if security.SecurityGroupPropertiesFormat == nil {
+		security.SecurityGroupPropertiesFormat = &network.SecurityGroupPropertiesFormat{}
+	}
+	if security.SecurityRules != nil {
+		existingRules = *security.SecurityRules
+	}
+	sgRuleList, err := updateFunc(existingRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	security.SecurityRules = &sgRuleList
+
+	future, err := securityHandler.Client.CreateOrUpdate(securityHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, security)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	err = future.WaitForCompletionRef(securityHandler.Ctx, securityHandler.Client.Client)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+
+	return securityHandler.GetSecurity(securityID)
+}
+
+func mappingSecurityRules(sgRuleList []network.SecurityRule) []irs.SecurityRuleInfo {
+	var securityRules []irs.SecurityRuleInfo
+	for _, sgRule := range sgRuleList {
+		securityRules = append(securityRules, mappingSecurityRuleInfo(sgRule)...)
+	}
+	return securityRules
+}
+
+// removeSecurityRules returns the NSG rules except the NSG rules of removeRules.
+// A NSG rule split into many rules is removed only when all of them are removed.
+func removeSecurityRules(sgRuleList []network.SecurityRule, removeRules []irs.SecurityRuleInfo) ([]network.SecurityRule, error) {
+	var keptRules []network.SecurityRule
+	for _, sgRule := range sgRuleList {
+		mappedRules := mappingSecurityRuleInfo(sgRule)
+		removeCount := 0
+		for _, mappedRule := range mappedRules {
+			for _, removeRule := range removeRules {
+				if removeRule.Id == mappedRule.Id && irs.MatchSecurityRule(removeRule, mappedRule) {
+					removeCount++
+					break
+				}
+			}
+		}
+
+		switch removeCount {
+		case 0:
+			keptRules = append(keptRules, sgRule)
+		case len(mappedRules):
+		default:
+			return nil, errors.New(fmt.Sprintf("NSG rule %s has other port ranges or address prefixes, all of them should be removed", to.String(sgRule.Name)))
+		}
+	}
+	return keptRules, nil
+}
+
+// normalizeRules translates rules in the same way as the NSG rules are mapped(ex: CIDR "*" is "0.0.0.0/0"),
+// so the requested rules can be compared with the current rules. A rule with an Id is kept as it is.
+func normalizeRules(rules []irs.SecurityRuleInfo) ([]irs.SecurityRuleInfo, error) {
+	var normalizedRules []irs.SecurityRuleInfo
+	for _, rule := range rules {
+		if rule.Id != "" {
+			normalizedRules = append(normalizedRules, rule)
+			continue
+		}
+		if err := irs.CheckSecurityRule(rule); err != nil {
+			return nil, err
+		}
+		// the priority is not changed by the translation, any valid one is used for a rule without one.
+		priority := rule.Priority
+		if priority == 0 {
+			priority = minRulePriority
+		}
+		sgRule, err := getSecurityRule(rule, priority)
+		if err != nil {
+			return nil, err
+		}
+		normalizedRule := mappingSecurityRuleInfo(sgRule)[0]
+		normalizedRule.Id = ""
+		normalizedRule.Priority = rule.Priority
+		normalizedRules = append(normalizedRules, normalizedRule)
+	}
+	return normalizedRules, nil
+}
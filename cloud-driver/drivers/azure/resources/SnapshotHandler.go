@@ -0,0 +1,259 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// AzureSnapshotHandler handles snapshots of managed disks in the resource group of the connection.
+type AzureSnapshotHandler struct {
+	Region     idrv.RegionInfo
+	Ctx        context.Context
+	Client     *compute.SnapshotsClient
+	DiskClient *compute.DisksClient
+	VMClient   *compute.VirtualMachinesClient
+}
+
+// mappingSnapshotInfo maps a snapshot into irs.SnapshotInfo.
+// Only the snapshots of OS disks have the OS type.
+func mappingSnapshotInfo(snapshot compute.Snapshot) irs.SnapshotInfo {
+	snapshotInfo := irs.SnapshotInfo{
+		Name: to.String(snapshot.Name),
+		Id:   to.String(snapshot.ID),
+		Tags: getTags(snapshot.Tags),
+	}
+	if snapshot.DiskProperties == nil {
+		return snapshotInfo
+	}
+	snapshotInfo.SizeGiB = int(to.Int32(snapshot.DiskSizeGB))
+	snapshotInfo.Status = to.String(snapshot.ProvisioningState)
+	snapshotInfo.RootDisk = snapshot.OsType != ""
+	if snapshot.CreationData != nil {
+		snapshotInfo.DiskId = to.String(snapshot.CreationData.SourceResourceID)
+	}
+	if snapshot.TimeCreated != nil {
+		snapshotInfo.CreatedTime = snapshot.TimeCreated.Local()
+	}
+	snapshotInfo.KeyValueList = []irs.KeyValue{
+		{Key: "OsType", Value: string(snapshot.OsType)},
+	}
+	return snapshotInfo
+}
+
+func (snapshotHandler *AzureSnapshotHandler) CreateSnapshot(snapshotReqInfo irs.SnapshotReqInfo) ([]*irs.SnapshotInfo, error) {
+	if err := irs.CheckSnapshotReqInfo(snapshotReqInfo); err != nil {
+		return nil, err
+	}
+
+	if snapshotReqInfo.DiskId != "" {
+		diskID, err := ParseResourceID(snapshotReqInfo.DiskId, DisksType, snapshotHandler.Client.SubscriptionID, snapshotHandler.Region.ResourceGroup)
+		if err != nil {
+			return nil, err
+		}
+		snapshotInfo, err := snapshotHandler.createSnapshot(snapshotReqInfo.Name, snapshotReqInfo.Tags, diskID.String())
+		if err != nil {
+			return nil, err
+		}
+		return []*irs.SnapshotInfo{&snapshotInfo}, nil
+	}
+
+	vmID, err := ParseResourceID(snapshotReqInfo.VMId, VirtualMachinesType, snapshotHandler.VMClient.SubscriptionID, snapshotHandler.Region.ResourceGroup)
+	if err != nil {
+		return nil, err
+	}
+	vm, err := snapshotHandler.VMClient.Get(snapshotHandler.Ctx, vmID.ResourceGroup, vmID.Name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the OS disk first
+	var diskIDs []string
+	if vm.VirtualMachineProperties != nil && vm.StorageProfile != nil && vm.StorageProfile.OsDisk != nil && vm.StorageProfile.OsDisk.ManagedDisk != nil {
+		diskIDs = append(diskIDs, to.String(vm.StorageProfile.OsDisk.ManagedDisk.ID))
+	}
+	for _, dataDisk := range getVMDataDisks(vm) {
+		if dataDisk.ManagedDisk != nil {
+			diskIDs = append(diskIDs, to.String(dataDisk.ManagedDisk.ID))
+		}
+	}
+	if len(diskIDs) == 0 {
+		return nil, errors.New("no managed disk of VM " + vmID.Name)
+	}
+
+	var snapshotList []*irs.SnapshotInfo
+	for i, diskID := range diskIDs {
+		name := fmt.Sprintf("%s-%d", snapshotReqInfo.Name, i)
+		snapshotInfo, err := snapshotHandler.createSnapshot(name, snapshotReqInfo.Tags, diskID)
+		if err != nil {
+			return snapshotList, err
+		}
+		snapshotInfo.VMId = to.String(vm.ID)
+		snapshotList = append(snapshotList, &snapshotInfo)
+	}
+	return snapshotList, nil
+}
+
+// createSnapshot copies a managed disk into a snapshot in the location of the connection.
+func (snapshotHandler *AzureSnapshotHandler) createSnapshot(name string, tags map[string]string, diskID string) (irs.SnapshotInfo, error) {
+	snapshotID, err := ParseResourceID(name, SnapshotsType, snapshotHandler.Client.SubscriptionID, snapshotHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.SnapshotInfo{}, err
+	}
+
+	snapshotOpts := compute.Snapshot{
+		Location: &snapshotHandler.Region.Region,
+		Tags:     getAzureTags(tags),
+		DiskProperties: &compute.DiskProperties{
+			CreationData: &compute.CreationData{
+				CreateOption:     compute.Copy,
+				SourceResourceID: to.StringPtr(diskID),
+			},
+		},
+	}
+	future, err := snapshotHandler.Client.CreateOrUpdate(snapshotHandler.Ctx, snapshotID.ResourceGroup, snapshotID.Name, snapshotOpts)
+	if err != nil {
+		return irs.SnapshotInfo{}, err
+	}
+	err = future.WaitForCompletionRef(snapshotHandler.Ctx, snapshotHandler.Client.Client)
+	if err != nil {
+		return irs.SnapshotInfo{}, err
+	}
+	return snapshotHandler.GetSnapshot(snapshotID.String())
+}
+
+// listSnapshotPage gets a page of snapshots in the resource group of the connection.
+func (snapshotHandler *AzureSnapshotHandler) listSnapshotPage(listReqInfo irs.ListReqInfo) (compute.SnapshotList, error) {
+	if listReqInfo.NextToken == "" {
+		snapshotList, err := snapshotHandler.Client.ListByResourceGroup(snapshotHandler.Ctx, snapshotHandler.Region.ResourceGroup)
+		if err != nil {
+			return compute.SnapshotList{}, err
+		}
+		return snapshotList.Response(), nil
+	}
+
+	req, err := getNextPageRequest(snapshotHandler.Ctx, snapshotHandler.Client.BaseURI, listReqInfo.NextToken)
+	if err != nil {
+		return compute.SnapshotList{}, err
+	}
+	resp, err := snapshotHandler.Client.ListByResourceGroupSender(req)
+	if err != nil {
+		return compute.SnapshotList{}, err
+	}
+	return snapshotHandler.Client.ListByResourceGroupResponder(resp)
+}
+
+func (snapshotHandler *AzureSnapshotHandler) ListSnapshot(listReqInfo irs.ListReqInfo) ([]*irs.SnapshotInfo, irs.ListPageInfo, error) {
+	var snapshotList []*irs.SnapshotInfo
+
+	result, err := snapshotHandler.listSnapshotPage(listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	if result.Value != nil {
+		for _, snapshot := range *result.Value {
+			if !matchListFilter(listReqInfo, snapshot.Name, snapshot.Tags) {
+				continue
+			}
+			snapshotInfo := mappingSnapshotInfo(snapshot)
+			snapshotList = append(snapshotList, &snapshotInfo)
+		}
+	}
+
+	return snapshotList, getNextToken(result.NextLink), nil
+}
+
+func (snapshotHandler *AzureSnapshotHandler) GetSnapshot(snapshotID string) (irs.SnapshotInfo, error) {
+	snapshotResourceID, err := ParseResourceID(snapshotID, SnapshotsType, snapshotHandler.Client.SubscriptionID, snapshotHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.SnapshotInfo{}, err
+	}
+	snapshot, err := snapshotHandler.Client.Get(snapshotHandler.Ctx, snapshotResourceID.ResourceGroup, snapshotResourceID.Name)
+	if err != nil {
+		return irs.SnapshotInfo{}, err
+	}
+	return mappingSnapshotInfo(snapshot), nil
+}
+
+func (snapshotHandler *AzureSnapshotHandler) DeleteSnapshot(snapshotID string) (bool, error) {
+	snapshotResourceID, err := ParseResourceID(snapshotID, SnapshotsType, snapshotHandler.Client.SubscriptionID, snapshotHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	future, err := snapshotHandler.Client.Delete(snapshotHandler.Ctx, snapshotResourceID.ResourceGroup, snapshotResourceID.Name)
+	if err != nil {
+		return false, err
+	}
+	err = future.WaitForCompletionRef(snapshotHandler.Ctx, snapshotHandler.Client.Client)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// The disk is created in the location of the connection, Zone is an availability zone of the location(ex: 1).
+func (snapshotHandler *AzureSnapshotHandler) RestoreSnapshot(snapshotID string, diskReqInfo irs.DiskReqInfo) (irs.DiskInfo, error) {
+	if diskReqInfo.SizeGiB < 0 {
+		return irs.DiskInfo{}, errors.New(fmt.Sprintf("invalid size of disk %s: %d GiB", diskReqInfo.Name, diskReqInfo.SizeGiB))
+	}
+	snapshotResourceID, err := ParseResourceID(snapshotID, SnapshotsType, snapshotHandler.Client.SubscriptionID, snapshotHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	diskID, err := ParseResourceID(diskReqInfo.Name, DisksType, snapshotHandler.DiskClient.SubscriptionID, snapshotHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	diskOpts := compute.Disk{
+		Location: &snapshotHandler.Region.Region,
+		Tags:     getAzureTags(diskReqInfo.Tags),
+		DiskProperties: &compute.DiskProperties{
+			CreationData: &compute.CreationData{
+				CreateOption:     compute.Copy,
+				SourceResourceID: to.StringPtr(snapshotResourceID.String()),
+			},
+		},
+	}
+	if diskReqInfo.SizeGiB > 0 {
+		diskOpts.DiskSizeGB = to.Int32Ptr(int32(diskReqInfo.SizeGiB))
+	}
+	if diskReqInfo.DiskType != "" {
+		diskOpts.Sku = &compute.DiskSku{Name: compute.DiskStorageAccountTypes(diskReqInfo.DiskType)}
+	}
+	zone := diskReqInfo.Zone
+	if zone == "" {
+		zone = snapshotHandler.Region.Zone
+	}
+	if zone != "" {
+		diskOpts.Zones = &[]string{zone}
+	}
+
+	future, err := snapshotHandler.DiskClient.CreateOrUpdate(snapshotHandler.Ctx, diskID.ResourceGroup, diskID.Name, diskOpts)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	err = future.WaitForCompletionRef(snapshotHandler.Ctx, snapshotHandler.DiskClient.Client)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	disk, err := snapshotHandler.DiskClient.Get(snapshotHandler.Ctx, diskID.ResourceGroup, diskID.Name)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return mappingDiskInfo(disk), nil
+}
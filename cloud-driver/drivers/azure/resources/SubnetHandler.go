@@ -0,0 +1,164 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/davecgh/go-spew/spew"
+)
+
+type AzureSubnetHandler struct {
+	Region idrv.RegionInfo
+	Ctx    context.Context
+	Client *network.SubnetsClient
+}
+
+// mappingSubnetInfo maps a subnet of a virtual network into irs.SubnetInfo.
+// Azure subnets are regional, so the Zone is empty.
+func mappingSubnetInfo(subnet network.Subnet) irs.SubnetInfo {
+	subnetInfo := irs.SubnetInfo{
+		Id:   to.String(subnet.ID),
+		Name: to.String(subnet.Name),
+	}
+	// /subscriptions/.../virtualNetworks/{vnet}/subnets/{subnet}
+	if strings.Contains(subnetInfo.Id, "/subnets/") {
+		subnetInfo.VNetworkId = strings.Split(subnetInfo.Id, "/subnets/")[0]
+	}
+
+	if subnet.SubnetPropertiesFormat == nil {
+		return subnetInfo
+	}
+	subnetInfo.CIDR = to.String(subnet.AddressPrefix)
+	subnetInfo.KeyValueList = []irs.KeyValue{
+		{Key: "ProvisioningState", Value: to.String(subnet.ProvisioningState)},
+	}
+	if subnet.NetworkSecurityGroup != nil {
+		subnetInfo.KeyValueList = append(subnetInfo.KeyValueList, irs.KeyValue{Key: "NetworkSecurityGroup", Value: to.String(subnet.NetworkSecurityGroup.ID)})
+	}
+	if subnet.RouteTable != nil {
+		subnetInfo.KeyValueList = append(subnetInfo.KeyValueList, irs.KeyValue{Key: "RouteTable", Value: to.String(subnet.RouteTable.ID)})
+	}
+	return subnetInfo
+}
+
+// The DNS servers are for all subnets of a virtual network, and subnets are regional and have no IP pools,
+// so DNS servers, zones and IP pools are rejected.
+func (subnetHandler *AzureSubnetHandler) CreateSubnet(vNetworkID string, subnetReqInfo irs.SubnetReqInfo) (irs.SubnetInfo, error) {
+	if len(subnetReqInfo.DNSServers) != 0 || subnetReqInfo.Zone != "" || len(subnetReqInfo.IPPools) != 0 {
+		return irs.SubnetInfo{}, errors.New("DNS servers, zones and IP pools of subnets are not supported by Azure, subnet: " + subnetReqInfo.Name)
+	}
+	// the CIDR in the address space is checked by Azure.
+	if err := irs.CheckSubnetReqInfo(subnetReqInfo, nil); err != nil {
+		return irs.SubnetInfo{}, err
+	}
+
+	resourceID, err := ParseResourceID(vNetworkID, VirtualNetworksType, subnetHandler.Client.SubscriptionID, subnetHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.SubnetInfo{}, err
+	}
+
+	createOpts := network.Subnet{
+		Name: to.StringPtr(subnetReqInfo.Name),
+		SubnetPropertiesFormat: &network.SubnetPropertiesFormat{
+			AddressPrefix: to.StringPtr(subnetReqInfo.CIDR),
+		},
+	}
+	future, err := subnetHandler.Client.CreateOrUpdate(subnetHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, subnetReqInfo.Name, createOpts)
+	if err != nil {
+		return irs.SubnetInfo{}, err
+	}
+	err = future.WaitForCompletionRef(subnetHandler.Ctx, subnetHandler.Client.Client)
+	if err != nil {
+		return irs.SubnetInfo{}, err
+	}
+
+	subnet, err := subnetHandler.Client.Get(subnetHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, subnetReqInfo.Name, "")
+	if err != nil {
+		return irs.SubnetInfo{}, err
+	}
+	return mappingSubnetInfo(subnet), nil
+}
+
+// listSubnetPage gets a page of subnets of a virtual network.
+func (subnetHandler *AzureSubnetHandler) listSubnetPage(resourceID ResourceID, listReqInfo irs.ListReqInfo) (network.SubnetListResult, error) {
+	if listReqInfo.NextToken == "" {
+		result, err := subnetHandler.Client.List(subnetHandler.Ctx, resourceID.ResourceGroup, resourceID.Name)
+		if err != nil {
+			return network.SubnetListResult{}, err
+		}
+		return result.Response(), nil
+	}
+
+	req, err := getNextPageRequest(subnetHandler.Ctx, subnetHandler.Client.BaseURI, listReqInfo.NextToken)
+	if err != nil {
+		return network.SubnetListResult{}, err
+	}
+	resp, err := subnetHandler.Client.ListSender(req)
+	if err != nil {
+		return network.SubnetListResult{}, err
+	}
+	return subnetHandler.Client.ListResponder(resp)
+}
+
+// subnets have no tags, so the TagFilter matches no subnet.
+func (subnetHandler *AzureSubnetHandler) ListSubnet(vNetworkID string, listReqInfo irs.ListReqInfo) ([]*irs.SubnetInfo, irs.ListPageInfo, error) {
+	resourceID, err := ParseResourceID(vNetworkID, VirtualNetworksType, subnetHandler.Client.SubscriptionID, subnetHandler.Region.ResourceGroup)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	result, err := subnetHandler.listSubnetPage(resourceID, listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var subnetList []*irs.SubnetInfo
+	if result.Value == nil {
+		return subnetList, getNextToken(result.NextLink), nil
+	}
+	for _, subnet := range *result.Value {
+		if !matchListFilter(listReqInfo, subnet.Name, nil) {
+			continue
+		}
+		subnetInfo := mappingSubnetInfo(subnet)
+		subnetList = append(subnetList, &subnetInfo)
+	}
+
+	return subnetList, getNextToken(result.NextLink), nil
+}
+
+func (subnetHandler *AzureSubnetHandler) GetSubnet(subnetID string) (irs.SubnetInfo, error) {
+	vNetworkID, subnetName, err := ParseSubnetID(subnetID, subnetHandler.Client.SubscriptionID, subnetHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.SubnetInfo{}, err
+	}
+	subnet, err := subnetHandler.Client.Get(subnetHandler.Ctx, vNetworkID.ResourceGroup, vNetworkID.Name, subnetName, "")
+	if err != nil {
+		return irs.SubnetInfo{}, err
+	}
+
+	subnetInfo := mappingSubnetInfo(subnet)
+
+	spew.Dump(subnetInfo)
+	return subnetInfo, nil
+}
+
+func (subnetHandler *AzureSubnetHandler) DeleteSubnet(subnetID string) (bool, error) {
+	vNetworkID, subnetName, err := ParseSubnetID(subnetID, subnetHandler.Client.SubscriptionID, subnetHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	future, err := subnetHandler.Client.Delete(subnetHandler.Ctx, vNetworkID.ResourceGroup, vNetworkID.Name, subnetName)
+	if err != nil {
+		return false, err
+	}
+	err = future.WaitForCompletionRef(subnetHandler.Ctx, subnetHandler.Client.Client)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
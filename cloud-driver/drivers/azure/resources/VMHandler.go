@@ -12,120 +12,502 @@ package resources
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2018-02-01/storage"
+	azstorage "github.com/Azure/azure-sdk-for-go/storage"
 	"github.com/Azure/go-autorest/autorest/to"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"io/ioutil"
-	"os"
+	"net/url"
 	"strings"
 )
 
 type AzureVMHandler struct {
-	Region idrv.RegionInfo
-	Ctx    context.Context
-	Client *compute.VirtualMachinesClient
+	Region               idrv.RegionInfo
+	Ctx                  context.Context
+	Client               *compute.VirtualMachinesClient
+	NicClient            *network.InterfacesClient
+	PublicIPClient       *network.PublicIPAddressesClient
+	SecurityGroupClient  *network.SecurityGroupsClient
+	SubnetClient         *network.SubnetsClient
+	KeyPairPath          string
+	DiskClient           *compute.DisksClient
+	StorageAccountClient *storage.AccountsClient
 }
 
+// the limit of the custom data of a VM before the base64 encoding.
+const maxCustomDataSize = 65535
+
+// StartVM creates a VM with its network resources.
+// With VNicInfo.Id the existing NIC is used, otherwise a NIC(VNicInfo.Name, default: {vm name}-nic) is created in
+// the subnet(VNetworkInfo.SubnetId, default: "default") of the virtual network(VNetworkInfo.Id).
+// The new NIC gets the existing public IP/NSG of PublicIPInfo.Id/SecurityInfo.Id, or a new public IP/NSG(rules: SecurityInfo.SecurityRules, default: SSH)
+// named PublicIPInfo.Name/SecurityInfo.Name, or none of them.
+// UserData is passed as the custom data of the VM.
+// The root disk is resized by RootDiskSizeGiB, the data disks(DataDisks) are created with the VM and kept after TerminateVM.
+// With NetworkInterfaces the NICs are created by them instead, the VM size must support the number of the NICs.
+// The resources created by StartVM are deleted when the VM creation fails.
 func (vmHandler *AzureVMHandler) StartVM(vmReqInfo irs.VMReqInfo) (irs.VMInfo, error) {
 	// Set VM Create Information
-	imageId := vmReqInfo.ImageInfo.Id
-	imageIdArr := strings.Split(imageId, ":")
-	
-	//sshKeyPath := "test"
-	//sshKeyData := ""
-	
-	rootPath := os.Getenv("CBSPIDER_PATH")
-	sshPublicKeyPath := rootPath + "/key/mcb-test-key.pub"
-	var sshKeyData string
-	if _, err := os.Stat(sshPublicKeyPath); err == nil {
-		sshBytes, err := ioutil.ReadFile(sshPublicKeyPath)
+	imageRef, err := ParseImageReference(vmReqInfo.ImageInfo.Id, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+
+	vmID, err := ParseResourceID(vmReqInfo.Name, VirtualMachinesType, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+
+	keyPairInfo, err := vmHandler.getKeyPair(vmReqInfo.KeyPairInfo)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	osProfile, err := getOSProfile(vmID.Name, vmReqInfo.LoginInfo, keyPairInfo)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	if vmReqInfo.UserData != "" {
+		err = irs.CheckUserData(vmReqInfo.UserData, maxCustomDataSize, false)
 		if err != nil {
-			//log.Fatalf("failed to read SSH key data: %v", err)
 			return irs.VMInfo{}, err
 		}
-		sshKeyData = string(sshBytes)
+		osProfile.CustomData = to.StringPtr(base64.StdEncoding.EncodeToString([]byte(vmReqInfo.UserData)))
 	}
-	
-	vmName := vmReqInfo.Name
-	vmNameArr := strings.Split(vmName, ":")
-	
-	// Check VM Exists
-	vm, err := vmHandler.Client.Get(vmHandler.Ctx, vmNameArr[0], vmNameArr[1], compute.InstanceView)
-	if vm.ID != nil {
-		errMsg := fmt.Sprintf("VirtualMachine with name %s already exist", vmNameArr[1])
+
+	// Check VM Exists, only 404 means that the VM does not exist.
+	vm, err := vmHandler.Client.Get(vmHandler.Ctx, vmID.ResourceGroup, vmID.Name, compute.InstanceView)
+	if err == nil {
+		errMsg := fmt.Sprintf("VirtualMachine with name %s already exist", vmID.Name)
 		createErr := errors.New(errMsg)
 		return irs.VMInfo{}, createErr
 	}
-	
+	if !isNotFound(vm.Response) {
+		return irs.VMInfo{}, errors.New(fmt.Sprintf("unable to check VirtualMachine %s exists, %v", vmID.Name, err))
+	}
+
+	// the managed OS disk is named to be deleted by the rollback. ex) {vm name}-osdisk
+	osDiskID := ResourceID{SubscriptionID: vmID.SubscriptionID, ResourceGroup: vmID.ResourceGroup, ResourceType: DisksType, Name: vmID.Name + "-osdisk"}
+	storageProfile := compute.StorageProfile{
+		ImageReference: &imageRef,
+		OsDisk: &compute.OSDisk{
+			Name:         to.StringPtr(osDiskID.Name),
+			CreateOption: compute.DiskCreateOptionTypesFromImage,
+		},
+	}
+	if vmReqInfo.RootDiskSizeGiB > 0 {
+		storageProfile.OsDisk.DiskSizeGB = to.Int32Ptr(int32(vmReqInfo.RootDiskSizeGiB))
+	}
+	dataDisks, err := getDataDisks(vmID.Name, vmReqInfo)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	if len(dataDisks) != 0 {
+		storageProfile.DataDisks = &dataDisks
+	}
+
+	// Create NIC, Public IP, SecurityGroup if needed
+	var createdIDs []ResourceID
+	var nicIDs []string
+	if len(vmReqInfo.NetworkInterfaces) != 0 {
+		nicIDs, err = vmHandler.prepareVNics(vmID, vmReqInfo, &createdIDs)
+	} else {
+		var nicID string
+		nicID, err = vmHandler.prepareVNic(vmID, vmReqInfo, &createdIDs)
+		nicIDs = []string{nicID}
+	}
+	if err != nil {
+		return irs.VMInfo{}, vmHandler.rollback(err, createdIDs)
+	}
+	var nicRefs []compute.NetworkInterfaceReference
+	for i, nicID := range nicIDs {
+		nicRefs = append(nicRefs, compute.NetworkInterfaceReference{
+			ID: to.StringPtr(nicID),
+			NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{
+				Primary: to.BoolPtr(i == 0),
+			},
+		})
+	}
+
 	vmOpts := compute.VirtualMachine{
 		Location: &vmHandler.Region.Region,
+		Tags:     getAzureTags(vmReqInfo.Tags),
 		VirtualMachineProperties: &compute.VirtualMachineProperties{
 			HardwareProfile: &compute.HardwareProfile{
 				VMSize: compute.VirtualMachineSizeTypes(vmReqInfo.SpecID),
 			},
-			StorageProfile: &compute.StorageProfile{
-				ImageReference: &compute.ImageReference{
-					Publisher: &imageIdArr[0],
-					Offer:     &imageIdArr[1],
-					Sku:       &imageIdArr[2],
-					Version:   &imageIdArr[3],
-				},
+			StorageProfile: &storageProfile,
+			OsProfile:      &osProfile,
+			NetworkProfile: &compute.NetworkProfile{
+				NetworkInterfaces: &nicRefs,
 			},
-			OsProfile: &compute.OSProfile{
-				ComputerName:  &vmNameArr[1],
-				AdminUsername: &vmReqInfo.LoginInfo.AdminUsername,
-				//AdminPassword: &vmReqInfo.LoginInfo.AdminPassword,
-				LinuxConfiguration: &compute.LinuxConfiguration{
-					SSH: &compute.SSHConfiguration{
-						PublicKeys: &[]compute.SSHPublicKey{
-							{
-								Path: to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", vmReqInfo.LoginInfo.AdminUsername)),
-								KeyData: &sshKeyData,
-							},
-						},
-					},
+		},
+	}
+
+	// the failed VM is deleted before its NIC, OS disk and data disks.
+	createdIDs = append(createdIDs, osDiskID)
+	for _, dataDisk := range dataDisks {
+		createdIDs = append(createdIDs, ResourceID{SubscriptionID: vmID.SubscriptionID, ResourceGroup: vmID.ResourceGroup, ResourceType: DisksType, Name: *dataDisk.Name})
+	}
+	createdIDs = append(createdIDs, vmID)
+	future, err := vmHandler.Client.CreateOrUpdate(vmHandler.Ctx, vmID.ResourceGroup, vmID.Name, vmOpts)
+	if err != nil {
+		return irs.VMInfo{}, vmHandler.rollback(err, createdIDs)
+	}
+	err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.Client.Client)
+	if err != nil {
+		return irs.VMInfo{}, vmHandler.rollback(err, createdIDs)
+	}
+
+	vm, err = vmHandler.Client.Get(vmHandler.Ctx, vmID.ResourceGroup, vmID.Name, compute.InstanceView)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	return vmHandler.getVMInfo(vm)
+}
+
+// getKeyPair gets the public key of the local key pair(KeyPairInfo.Name) when KeyPairInfo.PublicKey is not given.
+func (vmHandler *AzureVMHandler) getKeyPair(keyPairInfo irs.KeyPairInfo) (irs.KeyPairInfo, error) {
+	if keyPairInfo.PublicKey != "" || keyPairInfo.Name == "" {
+		return keyPairInfo, nil
+	}
+	keyPairHandler := AzureKeyPairHandler{Region: vmHandler.Region, KeyPath: vmHandler.KeyPairPath}
+	return keyPairHandler.GetKey(keyPairInfo.Name)
+}
+
+// getOSProfile sets the login of a Linux VM with the public key of keyPairInfo and/or the admin password.
+func getOSProfile(computerName string, loginInfo irs.LoginInfo, keyPairInfo irs.KeyPairInfo) (compute.OSProfile, error) {
+	if loginInfo.AdminUsername == "" {
+		return compute.OSProfile{}, errors.New("AdminUsername of LoginInfo is required to create an Azure VM")
+	}
+	if keyPairInfo.PublicKey == "" && loginInfo.AdminPassword == "" {
+		return compute.OSProfile{}, errors.New("PublicKey of KeyPairInfo or AdminPassword of LoginInfo is required to create an Azure VM")
+	}
+
+	osProfile := compute.OSProfile{
+		ComputerName:  to.StringPtr(computerName),
+		AdminUsername: to.StringPtr(loginInfo.AdminUsername),
+		LinuxConfiguration: &compute.LinuxConfiguration{
+			DisablePasswordAuthentication: to.BoolPtr(loginInfo.AdminPassword == ""),
+		},
+	}
+	if loginInfo.AdminPassword != "" {
+		osProfile.AdminPassword = to.StringPtr(loginInfo.AdminPassword)
+	}
+	if keyPairInfo.PublicKey != "" {
+		osProfile.LinuxConfiguration.SSH = &compute.SSHConfiguration{
+			PublicKeys: &[]compute.SSHPublicKey{
+				{
+					Path:    to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", loginInfo.AdminUsername)),
+					KeyData: to.StringPtr(keyPairInfo.PublicKey),
 				},
 			},
-			NetworkProfile: &compute.NetworkProfile{
-				NetworkInterfaces: &[]compute.NetworkInterfaceReference{
-					{
-						ID: &vmReqInfo.VNetworkInfo.Id,
-						NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{
-							Primary: to.BoolPtr(true),
-						},
-					},
+		}
+	}
+	return osProfile, nil
+}
+
+// prepareVNic returns the ID of the NIC of a new VM, the resources created here are added to createdIDs.
+func (vmHandler *AzureVMHandler) prepareVNic(vmID ResourceID, vmReqInfo irs.VMReqInfo, createdIDs *[]ResourceID) (string, error) {
+	subscriptionID := vmHandler.Client.SubscriptionID
+
+	// existing NIC
+	if vmReqInfo.VNicInfo.Id != "" {
+		nicID, err := ParseResourceID(vmReqInfo.VNicInfo.Id, NetworkInterfacesType, subscriptionID, vmID.ResourceGroup)
+		if err != nil {
+			return "", err
+		}
+		return nicID.String(), nil
+	}
+
+	if vmReqInfo.VNetworkInfo.Id == "" {
+		return "", errors.New("VNicInfo.Id or VNetworkInfo.Id is required to create an Azure VM")
+	}
+	subnet, err := vmHandler.getSubnet(vmID.ResourceGroup, vmReqInfo.VNetworkInfo)
+	if err != nil {
+		return "", err
+	}
+
+	nicName := vmReqInfo.VNicInfo.Name
+	if nicName == "" {
+		nicName = vmID.Name + "-nic"
+	}
+	nicID, err := ParseResourceID(nicName, NetworkInterfacesType, subscriptionID, vmID.ResourceGroup)
+	if err != nil {
+		return "", err
+	}
+
+	// Public IP
+	var publicIP *network.PublicIPAddress
+	if vmReqInfo.PublicIPInfo.Id != "" {
+		publicIPID, err := ParseResourceID(vmReqInfo.PublicIPInfo.Id, PublicIPAddressesType, subscriptionID, vmID.ResourceGroup)
+		if err != nil {
+			return "", err
+		}
+		publicIP = &network.PublicIPAddress{ID: to.StringPtr(publicIPID.String())}
+	} else if vmReqInfo.PublicIPInfo.Name != "" {
+		publicIPID, err := ParseResourceID(vmReqInfo.PublicIPInfo.Name, PublicIPAddressesType, subscriptionID, vmID.ResourceGroup)
+		if err != nil {
+			return "", err
+		}
+		publicIP, err = vmHandler.createPublicIP(publicIPID, vmReqInfo.Tags, createdIDs)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// SecurityGroup
+	var securityGroup *network.SecurityGroup
+	if vmReqInfo.SecurityInfo.Id != "" {
+		securityGroupID, err := ParseResourceID(vmReqInfo.SecurityInfo.Id, SecurityGroupsType, subscriptionID, vmID.ResourceGroup)
+		if err != nil {
+			return "", err
+		}
+		securityGroup = &network.SecurityGroup{ID: to.StringPtr(securityGroupID.String())}
+	} else if vmReqInfo.SecurityInfo.Name != "" {
+		securityGroupID, err := ParseResourceID(vmReqInfo.SecurityInfo.Name, SecurityGroupsType, subscriptionID, vmID.ResourceGroup)
+		if err != nil {
+			return "", err
+		}
+		securityGroup, err = vmHandler.createSecurityGroup(securityGroupID, vmReqInfo.SecurityInfo.SecurityRules, vmReqInfo.Tags, createdIDs)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// NIC
+	err = vmHandler.createNic(nicID, subnet, "", publicIP, securityGroup, vmReqInfo.Tags, createdIDs)
+	if err != nil {
+		return "", err
+	}
+	return nicID.String(), nil
+}
+
+// prepareVNics returns the IDs of the NICs({vm name}-nic-{i}) of NetworkInterfaces, the resources created here are added to createdIDs.
+// A NIC with PublicIP gets a new public IP({nic name}-ip), its security group is an existing NSG.
+func (vmHandler *AzureVMHandler) prepareVNics(vmID ResourceID, vmReqInfo irs.VMReqInfo, createdIDs *[]ResourceID) ([]string, error) {
+	// a NIC of Azure has a single NSG.
+	if err := irs.CheckVMNicReqInfo(vmReqInfo.NetworkInterfaces, 1); err != nil {
+		return nil, err
+	}
+	subscriptionID := vmHandler.Client.SubscriptionID
+
+	var nicIDs []string
+	for i, nic := range vmReqInfo.NetworkInterfaces {
+		vNetworkInfo := irs.VNetworkInfo{Id: nic.VNetworkId, SubnetId: nic.SubnetId}
+		if vNetworkInfo.Id == "" {
+			vNetworkInfo.Id = vmReqInfo.VNetworkInfo.Id
+		}
+		if vNetworkInfo.Id == "" && !strings.HasPrefix(nic.SubnetId, "/") {
+			return nil, errors.New(fmt.Sprintf("the virtual network or subnet ID of NIC %d is required to create an Azure VM", i))
+		}
+		subnet, err := vmHandler.getSubnet(vmID.ResourceGroup, vNetworkInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		nicID, err := ParseResourceID(fmt.Sprintf("%s-nic-%d", vmID.Name, i), NetworkInterfacesType, subscriptionID, vmID.ResourceGroup)
+		if err != nil {
+			return nil, err
+		}
+
+		var publicIP *network.PublicIPAddress
+		if nic.PublicIP {
+			publicIPID, err := ParseResourceID(nicID.Name+"-ip", PublicIPAddressesType, subscriptionID, vmID.ResourceGroup)
+			if err != nil {
+				return nil, err
+			}
+			publicIP, err = vmHandler.createPublicIP(publicIPID, vmReqInfo.Tags, createdIDs)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var securityGroup *network.SecurityGroup
+		if len(nic.SecurityGroupIds) != 0 {
+			securityGroupID, err := ParseResourceID(nic.SecurityGroupIds[0], SecurityGroupsType, subscriptionID, vmID.ResourceGroup)
+			if err != nil {
+				return nil, err
+			}
+			securityGroup = &network.SecurityGroup{ID: to.StringPtr(securityGroupID.String())}
+		}
+
+		err = vmHandler.createNic(nicID, subnet, nic.PrivateIP, publicIP, securityGroup, vmReqInfo.Tags, createdIDs)
+		if err != nil {
+			return nil, err
+		}
+		nicIDs = append(nicIDs, nicID.String())
+	}
+	return nicIDs, nil
+}
+
+// createNic creates a NIC with an IP configuration, privateIP: a static private IP("": dynamic).
+func (vmHandler *AzureVMHandler) createNic(nicID ResourceID, subnet network.Subnet, privateIP string, publicIP *network.PublicIPAddress, securityGroup *network.SecurityGroup, tags map[string]string, createdIDs *[]ResourceID) error {
+	ipConfig := network.InterfaceIPConfigurationPropertiesFormat{
+		Subnet:                    &subnet,
+		PrivateIPAllocationMethod: network.Dynamic,
+		PublicIPAddress:           publicIP,
+	}
+	if privateIP != "" {
+		ipConfig.PrivateIPAllocationMethod = network.Static
+		ipConfig.PrivateIPAddress = to.StringPtr(privateIP)
+	}
+
+	nicOpts := network.Interface{
+		Location: &vmHandler.Region.Region,
+		Tags:     getAzureTags(tags),
+		InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+			IPConfigurations: &[]network.InterfaceIPConfiguration{
+				{
+					Name:                                     to.StringPtr("ipConfig1"),
+					InterfaceIPConfigurationPropertiesFormat: &ipConfig,
 				},
 			},
+			NetworkSecurityGroup: securityGroup,
 		},
 	}
+	future, err := vmHandler.NicClient.CreateOrUpdate(vmHandler.Ctx, nicID.ResourceGroup, nicID.Name, nicOpts)
+	if err != nil {
+		return err
+	}
+	*createdIDs = append(*createdIDs, nicID)
+	return future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.NicClient.Client)
+}
 
-	future, err := vmHandler.Client.CreateOrUpdate(vmHandler.Ctx, vmNameArr[0], vmNameArr[1], vmOpts)
+// getSubnet gets the subnet(SubnetId: name or full ID, default: "default") of the virtual network(Id).
+func (vmHandler *AzureVMHandler) getSubnet(resourceGroup string, vNetworkInfo irs.VNetworkInfo) (network.Subnet, error) {
+	if strings.HasPrefix(vNetworkInfo.SubnetId, "/") {
+		return network.Subnet{ID: to.StringPtr(vNetworkInfo.SubnetId)}, nil
+	}
+
+	vNetworkID, err := ParseResourceID(vNetworkInfo.Id, VirtualNetworksType, vmHandler.Client.SubscriptionID, resourceGroup)
 	if err != nil {
-		panic(err)
-		return irs.VMInfo{}, err
+		return network.Subnet{}, err
 	}
-	err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.Client.Client)
+	subnetName := vNetworkInfo.SubnetId
+	if subnetName == "" {
+		subnetName = "default"
+	}
+	return vmHandler.SubnetClient.Get(vmHandler.Ctx, vNetworkID.ResourceGroup, vNetworkID.Name, subnetName, "")
+}
+
+// createPublicIP creates a static public IP, it is added to createdIDs once the creation is accepted.
+func (vmHandler *AzureVMHandler) createPublicIP(publicIPID ResourceID, tags map[string]string, createdIDs *[]ResourceID) (*network.PublicIPAddress, error) {
+	createOpts := network.PublicIPAddress{
+		Sku: &network.PublicIPAddressSku{
+			Name: network.PublicIPAddressSkuNameBasic,
+		},
+		PublicIPAddressPropertiesFormat: &network.PublicIPAddressPropertiesFormat{
+			PublicIPAddressVersion:   network.IPv4,
+			PublicIPAllocationMethod: network.Static,
+		},
+		Location: &vmHandler.Region.Region,
+		Tags:     getAzureTags(tags),
+	}
+
+	future, err := vmHandler.PublicIPClient.CreateOrUpdate(vmHandler.Ctx, publicIPID.ResourceGroup, publicIPID.Name, createOpts)
 	if err != nil {
-		panic(err)
-		return irs.VMInfo{}, err
+		return nil, err
 	}
-	
-	vm, err = vmHandler.Client.Get(vmHandler.Ctx, vmNameArr[0], vmNameArr[1], compute.InstanceView)
+	*createdIDs = append(*createdIDs, publicIPID)
+	err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.PublicIPClient.Client)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	vmInfo := mappingServerInfo(vm)
+	return &network.PublicIPAddress{ID: to.StringPtr(publicIPID.String())}, nil
+}
 
-	return vmInfo, nil
+// createSecurityGroup creates a NSG with the rules of the SecurityInfo of the request,
+// a NSG allowing SSH to login with the key pair without the rules. It is added to createdIDs once the creation is accepted.
+func (vmHandler *AzureVMHandler) createSecurityGroup(securityGroupID ResourceID, securityRules []irs.SecurityRuleInfo, tags map[string]string, createdIDs *[]ResourceID) (*network.SecurityGroup, error) {
+	if len(securityRules) == 0 {
+		securityRules = []irs.SecurityRuleInfo{
+			{Direction: "inbound", IPProtocol: "tcp", FromPort: 22, ToPort: 22, CIDR: "*", Action: "allow", Priority: 300},
+		}
+	}
+	sgRuleList, err := getSecurityRules(securityRules, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	createOpts := network.SecurityGroup{
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &sgRuleList,
+		},
+		Location: &vmHandler.Region.Region,
+		Tags:     getAzureTags(tags),
+	}
+
+	future, err := vmHandler.SecurityGroupClient.CreateOrUpdate(vmHandler.Ctx, securityGroupID.ResourceGroup, securityGroupID.Name, createOpts)
+	if err != nil {
+		return nil, err
+	}
+	*createdIDs = append(*createdIDs, securityGroupID)
+	err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.SecurityGroupClient.Client)
+	if err != nil {
+		return nil, err
+	}
+	return &network.SecurityGroup{ID: to.StringPtr(securityGroupID.String())}, nil
+}
+
+// rollback deletes the resources created by StartVM in reverse order and returns the cause of the rollback.
+func (vmHandler *AzureVMHandler) rollback(cause error, createdIDs []ResourceID) error {
+	var failedIDs []string
+	for i := len(createdIDs) - 1; i >= 0; i-- {
+		resourceID := createdIDs[i]
+		var err error
+		switch resourceID.ResourceType {
+		case VirtualMachinesType:
+			var future compute.VirtualMachinesDeleteFuture
+			if future, err = vmHandler.Client.Delete(vmHandler.Ctx, resourceID.ResourceGroup, resourceID.Name); err == nil {
+				err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.Client.Client)
+			}
+		case DisksType:
+			var future compute.DisksDeleteFuture
+			if future, err = vmHandler.DiskClient.Delete(vmHandler.Ctx, resourceID.ResourceGroup, resourceID.Name); err == nil {
+				err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.DiskClient.Client)
+			}
+		case NetworkInterfacesType:
+			var future network.InterfacesDeleteFuture
+			if future, err = vmHandler.NicClient.Delete(vmHandler.Ctx, resourceID.ResourceGroup, resourceID.Name); err == nil {
+				err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.NicClient.Client)
+			}
+		case PublicIPAddressesType:
+			var future network.PublicIPAddressesDeleteFuture
+			if future, err = vmHandler.PublicIPClient.Delete(vmHandler.Ctx, resourceID.ResourceGroup, resourceID.Name); err == nil {
+				err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.PublicIPClient.Client)
+			}
+		case SecurityGroupsType:
+			var future network.SecurityGroupsDeleteFuture
+			if future, err = vmHandler.SecurityGroupClient.Delete(vmHandler.Ctx, resourceID.ResourceGroup, resourceID.Name); err == nil {
+				err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.SecurityGroupClient.Client)
+			}
+		}
+		if err != nil {
+			fmt.Println("failed to roll back", resourceID.String(), err)
+			failedIDs = append(failedIDs, resourceID.String())
+		}
+	}
+
+	if len(failedIDs) != 0 {
+		return errors.New(fmt.Sprintf("%v (failed to roll back: %s)", cause, strings.Join(failedIDs, ", ")))
+	}
+	return cause
 }
 
 func (vmHandler *AzureVMHandler) SuspendVM(vmID string) {
-	vmIdArr := strings.Split(vmID, ":")
+	vmResourceID, err := ParseResourceID(vmID, VirtualMachinesType, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	future, err := vmHandler.Client.PowerOff(vmHandler.Ctx, vmIdArr[0], vmIdArr[1])
+	future, err := vmHandler.Client.PowerOff(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
 	if err != nil {
 		panic(err)
 	}
@@ -136,9 +518,13 @@ func (vmHandler *AzureVMHandler) SuspendVM(vmID string) {
 }
 
 func (vmHandler *AzureVMHandler) ResumeVM(vmID string) {
-	vmIdArr := strings.Split(vmID, ":")
+	vmResourceID, err := ParseResourceID(vmID, VirtualMachinesType, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	future, err := vmHandler.Client.Start(vmHandler.Ctx, vmIdArr[0], vmIdArr[1])
+	future, err := vmHandler.Client.Start(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
 	if err != nil {
 		panic(err)
 	}
@@ -149,9 +535,13 @@ func (vmHandler *AzureVMHandler) ResumeVM(vmID string) {
 }
 
 func (vmHandler *AzureVMHandler) RebootVM(vmID string) {
-	vmIdArr := strings.Split(vmID, ":")
+	vmResourceID, err := ParseResourceID(vmID, VirtualMachinesType, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	future, err := vmHandler.Client.Restart(vmHandler.Ctx, vmIdArr[0], vmIdArr[1])
+	future, err := vmHandler.Client.Restart(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
 	if err != nil {
 		panic(err)
 	}
@@ -162,10 +552,14 @@ func (vmHandler *AzureVMHandler) RebootVM(vmID string) {
 }
 
 func (vmHandler *AzureVMHandler) TerminateVM(vmID string) {
-	vmIdArr := strings.Split(vmID, ":")
+	vmResourceID, err := ParseResourceID(vmID, VirtualMachinesType, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	future, err := vmHandler.Client.Delete(vmHandler.Ctx, vmIdArr[0], vmIdArr[1])
-	//future, err := vmHandler.Client.Deallocate(vmHandler.Ctx, vmIdArr[0], vmIdArr[1])
+	future, err := vmHandler.Client.Delete(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
+	//future, err := vmHandler.Client.Deallocate(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
 	if err != nil {
 		panic(err)
 	}
@@ -175,15 +569,42 @@ func (vmHandler *AzureVMHandler) TerminateVM(vmID string) {
 	}
 }
 
-func (vmHandler *AzureVMHandler) ListVMStatus() []*irs.VMStatusInfo {
-	//serverList, err := vmHandler.Client.ListAll(vmHandler.Ctx)
-	serverList, err := vmHandler.Client.List(vmHandler.Ctx, vmHandler.Region.ResourceGroup)
+// listVMPage gets a page of VMs in the resource group of the connection.
+func (vmHandler *AzureVMHandler) listVMPage(listReqInfo irs.ListReqInfo) (compute.VirtualMachineListResult, error) {
+	if listReqInfo.NextToken == "" {
+		//serverList, err := vmHandler.Client.ListAll(vmHandler.Ctx)
+		serverList, err := vmHandler.Client.List(vmHandler.Ctx, vmHandler.Region.ResourceGroup)
+		if err != nil {
+			return compute.VirtualMachineListResult{}, err
+		}
+		return serverList.Response(), nil
+	}
+
+	req, err := getNextPageRequest(vmHandler.Ctx, vmHandler.Client.BaseURI, listReqInfo.NextToken)
 	if err != nil {
-		panic(err)
+		return compute.VirtualMachineListResult{}, err
+	}
+	resp, err := vmHandler.Client.ListSender(req)
+	if err != nil {
+		return compute.VirtualMachineListResult{}, err
+	}
+	return vmHandler.Client.ListResponder(resp)
+}
+
+func (vmHandler *AzureVMHandler) ListVMStatus(listReqInfo irs.ListReqInfo) ([]*irs.VMStatusInfo, irs.ListPageInfo, error) {
+	serverList, err := vmHandler.listVMPage(listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
 	}
 
 	var vmStatusList []*irs.VMStatusInfo
-	for _, s := range serverList.Values() {
+	if serverList.Value == nil {
+		return vmStatusList, getNextToken(serverList.NextLink), nil
+	}
+	for _, s := range *serverList.Value {
+		if !matchListFilter(listReqInfo, s.Name, s.Tags) {
+			continue
+		}
 		if s.InstanceView != nil {
 			statusStr := getVmStatus(*s.InstanceView)
 			status := irs.VMStatus(statusStr)
@@ -193,9 +614,7 @@ func (vmHandler *AzureVMHandler) ListVMStatus() []*irs.VMStatusInfo {
 			}
 			vmStatusList = append(vmStatusList, &vmStatusInfo)
 		} else {
-			vmIdArr := strings.Split(*s.ID, "/")
-			vmId := vmIdArr[4] + ":" + vmIdArr[8]
-			status := vmHandler.GetVMStatus(vmId)
+			status := vmHandler.GetVMStatus(*s.ID)
 			vmStatusInfo := irs.VMStatusInfo{
 				VmId:     *s.ID,
 				VmStatus: status,
@@ -204,12 +623,16 @@ func (vmHandler *AzureVMHandler) ListVMStatus() []*irs.VMStatusInfo {
 		}
 	}
 
-	return vmStatusList
+	return vmStatusList, getNextToken(serverList.NextLink), nil
 }
 
 func (vmHandler *AzureVMHandler) GetVMStatus(vmID string) irs.VMStatus {
-	vmIdArr := strings.Split(vmID, ":")
-	instanceView, err := vmHandler.Client.InstanceView(vmHandler.Ctx, vmIdArr[0], vmIdArr[1])
+	vmResourceID, err := ParseResourceID(vmID, VirtualMachinesType, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		fmt.Println(err)
+		return irs.VMStatus("")
+	}
+	instanceView, err := vmHandler.Client.InstanceView(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
 	if err != nil {
 		panic(err)
 	}
@@ -219,33 +642,228 @@ func (vmHandler *AzureVMHandler) GetVMStatus(vmID string) irs.VMStatus {
 	return irs.VMStatus(vmStatus)
 }
 
-func (vmHandler *AzureVMHandler) ListVM() []*irs.VMInfo {
-	//serverList, err := vmHandler.Client.ListAll(vmHandler.Ctx)
-	serverList, err := vmHandler.Client.List(vmHandler.Ctx, vmHandler.Region.ResourceGroup)
+func (vmHandler *AzureVMHandler) ListVM(listReqInfo irs.ListReqInfo) ([]*irs.VMInfo, irs.ListPageInfo, error) {
+	serverList, err := vmHandler.listVMPage(listReqInfo)
 	if err != nil {
-		panic(err)
+		return nil, irs.ListPageInfo{}, err
 	}
 
 	var vmList []*irs.VMInfo
-	for _, server := range serverList.Values() {
-		vmInfo := mappingServerInfo(server)
+	if serverList.Value == nil {
+		return vmList, getNextToken(serverList.NextLink), nil
+	}
+	for _, server := range *serverList.Value {
+		if !matchListFilter(listReqInfo, server.Name, server.Tags) {
+			continue
+		}
+		vmInfo, err := vmHandler.getVMInfo(server)
+		if err != nil {
+			return nil, irs.ListPageInfo{}, err
+		}
 		vmList = append(vmList, &vmInfo)
 	}
 
-	return vmList
+	return vmList, getNextToken(serverList.NextLink), nil
 }
 
 func (vmHandler *AzureVMHandler) GetVM(vmID string) irs.VMInfo {
-	vmIdArr := strings.Split(vmID, ":")
-	vm, err := vmHandler.Client.Get(vmHandler.Ctx, vmIdArr[0], vmIdArr[1], compute.InstanceView)
+	vmResourceID, err := ParseResourceID(vmID, VirtualMachinesType, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		fmt.Println(err)
+		return irs.VMInfo{}
+	}
+	vm, err := vmHandler.Client.Get(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name, compute.InstanceView)
 	if err != nil {
 		panic(err)
 	}
 
-	vmInfo := mappingServerInfo(vm)
+	vmInfo, err := vmHandler.getVMInfo(vm)
+	if err != nil {
+		fmt.Println(err)
+	}
 	return vmInfo
 }
 
+// ResizeVM changes the size of a VM, Azure restarts a running VM for the change.
+// A size not available on the hardware cluster of the VM needs the deallocation of the VM,
+// then the sizes of the zone of the VM are available and the VM is started again if it was running.
+func (vmHandler *AzureVMHandler) ResizeVM(vmID string, newSpec string) (irs.VMInfo, error) {
+	vmResourceID, err := ParseResourceID(vmID, VirtualMachinesType, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	vm, err := vmHandler.Client.Get(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name, compute.InstanceView)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	if vm.HardwareProfile == nil {
+		return irs.VMInfo{}, errors.New("no hardware profile of VM " + vmResourceID.Name)
+	}
+	if string(vm.HardwareProfile.VMSize) == newSpec {
+		return vmHandler.getVMInfo(vm)
+	}
+	running := vm.InstanceView != nil && vm.InstanceView.Statuses != nil && strings.HasPrefix(getVmStatus(*vm.InstanceView), "running")
+
+	available, err := vmHandler.isSizeAvailable(vmResourceID, newSpec)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	deallocated := false
+	if !available {
+		future, err := vmHandler.Client.Deallocate(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
+		if err != nil {
+			return irs.VMInfo{}, err
+		}
+		err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.Client.Client)
+		if err != nil {
+			return irs.VMInfo{}, err
+		}
+		deallocated = true
+
+		available, err = vmHandler.isSizeAvailable(vmResourceID, newSpec)
+		if err == nil && !available {
+			err = errors.New(fmt.Sprintf("VM size %s is not available in the zone of VM %s", newSpec, vmResourceID.Name))
+		}
+		if err != nil {
+			return irs.VMInfo{}, vmHandler.restartVM(err, vmResourceID, running)
+		}
+	}
+
+	vm.HardwareProfile.VMSize = compute.VirtualMachineSizeTypes(newSpec)
+	vm.InstanceView = nil
+	vm.Resources = nil // extensions are not updated with the VM.
+	future, err := vmHandler.Client.CreateOrUpdate(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name, vm)
+	if err == nil {
+		err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.Client.Client)
+	}
+	if err != nil {
+		return irs.VMInfo{}, vmHandler.restartVM(err, vmResourceID, deallocated && running)
+	}
+
+	if deallocated && running {
+		if err = vmHandler.restartVM(nil, vmResourceID, true); err != nil {
+			return irs.VMInfo{}, err
+		}
+	}
+	vm, err = vmHandler.Client.Get(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name, compute.InstanceView)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	return vmHandler.getVMInfo(vm)
+}
+
+// isSizeAvailable checks a size in the sizes a VM can be resized to.
+func (vmHandler *AzureVMHandler) isSizeAvailable(vmResourceID ResourceID, size string) (bool, error) {
+	sizeList, err := vmHandler.Client.ListAvailableSizes(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
+	if err != nil {
+		return false, err
+	}
+	if sizeList.Value == nil {
+		return false, nil
+	}
+	for _, vmSize := range *sizeList.Value {
+		if strings.EqualFold(to.String(vmSize.Name), size) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// restartVM starts the VM deallocated by ResizeVM if start is true, and returns the cause of the restart.
+func (vmHandler *AzureVMHandler) restartVM(cause error, vmResourceID ResourceID, start bool) error {
+	if !start {
+		return cause
+	}
+	future, err := vmHandler.Client.Start(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
+	if err == nil {
+		err = future.WaitForCompletionRef(vmHandler.Ctx, vmHandler.Client.Client)
+	}
+	if err != nil {
+		if cause == nil {
+			return err
+		}
+		return errors.New(fmt.Sprintf("%v (failed to start VM %s: %v)", cause, vmResourceID.Name, err))
+	}
+	return cause
+}
+
+// GetVMConsoleOutput reads the serial log of the boot diagnostics of a VM.
+// The boot diagnostics must be enabled on the VM with a storage account of the subscription,
+// the log blob is read with the key of the storage account.
+func (vmHandler *AzureVMHandler) GetVMConsoleOutput(vmID string, tailLines int) (string, error) {
+	vmResourceID, err := ParseResourceID(vmID, VirtualMachinesType, vmHandler.Client.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		return "", err
+	}
+	instanceView, err := vmHandler.Client.InstanceView(vmHandler.Ctx, vmResourceID.ResourceGroup, vmResourceID.Name)
+	if err != nil {
+		return "", err
+	}
+	if instanceView.BootDiagnostics == nil || instanceView.BootDiagnostics.SerialConsoleLogBlobURI == nil {
+		return "", errors.New("boot diagnostics of VM " + vmResourceID.Name + " is not enabled")
+	}
+
+	// ex) https://{storage account}.blob.core.windows.net/{container}/{blob}
+	logURI, err := url.Parse(to.String(instanceView.BootDiagnostics.SerialConsoleLogBlobURI))
+	if err != nil {
+		return "", err
+	}
+	accountName := strings.Split(logURI.Host, ".")[0]
+	blobPath := strings.SplitN(strings.TrimPrefix(logURI.Path, "/"), "/", 2)
+	if len(blobPath) != 2 {
+		return "", errors.New("invalid serial console log URI: " + logURI.String())
+	}
+
+	accountKey, err := vmHandler.getStorageAccountKey(accountName)
+	if err != nil {
+		return "", err
+	}
+	storageClient, err := azstorage.NewBasicClient(accountName, accountKey)
+	if err != nil {
+		return "", err
+	}
+	blobService := storageClient.GetBlobService()
+	reader, err := blobService.GetContainerReference(blobPath[0]).GetBlobReference(blobPath[1]).Get(nil)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	output, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return irs.TailLines(string(output), tailLines), nil
+}
+
+// getStorageAccountKey gets a key of a storage account of the subscription.
+func (vmHandler *AzureVMHandler) getStorageAccountKey(accountName string) (string, error) {
+	accountList, err := vmHandler.StorageAccountClient.List(vmHandler.Ctx)
+	if err != nil {
+		return "", err
+	}
+	if accountList.Value == nil {
+		return "", errors.New("storage account not found: " + accountName)
+	}
+	for _, account := range *accountList.Value {
+		if to.String(account.Name) != accountName {
+			continue
+		}
+		accountID, err := ParseResourceID(to.String(account.ID), StorageAccountsType, vmHandler.StorageAccountClient.SubscriptionID, vmHandler.Region.ResourceGroup)
+		if err != nil {
+			return "", err
+		}
+		keyList, err := vmHandler.StorageAccountClient.ListKeys(vmHandler.Ctx, accountID.ResourceGroup, accountID.Name)
+		if err != nil {
+			return "", err
+		}
+		if keyList.Keys == nil || len(*keyList.Keys) == 0 {
+			return "", errors.New("no key of storage account " + accountName)
+		}
+		return to.String((*keyList.Keys)[0].Value), nil
+	}
+	return "", errors.New("storage account not found: " + accountName)
+}
+
 func getVmStatus(instanceView compute.VirtualMachineInstanceView) string {
 	var powerState, provisioningState string
 
@@ -273,48 +891,175 @@ func getVmStatus(instanceView compute.VirtualMachineInstanceView) string {
 	return vmState
 }
 
+// mappingServerInfo maps a VM into irs.VMInfo without its network resources, see getVMInfo.
+// Azure does not return the admin password of a VM.
 func mappingServerInfo(server compute.VirtualMachine) irs.VMInfo {
 
 	// Get Default VM Info
 	vmInfo := irs.VMInfo{
-		Name: *server.Name,
-		Id:   *server.ID,
+		Name: to.String(server.Name),
+		Id:   to.String(server.ID),
 		Region: irs.RegionInfo{
-			Region: *server.Location,
+			Region: to.String(server.Location),
 		},
-		SpecID: string(server.VirtualMachineProperties.HardwareProfile.VMSize),
+		Tags: getTags(server.Tags),
 	}
 
 	// Set VM Zone
-	if server.Zones != nil {
+	if server.Zones != nil && len(*server.Zones) != 0 {
 		vmInfo.Region.Zone = (*server.Zones)[0]
 	}
+	if server.VirtualMachineProperties == nil {
+		return vmInfo
+	}
 
-	// Set VM Image Info
-	imageRef := server.VirtualMachineProperties.StorageProfile.ImageReference
-	imageId := *imageRef.Publisher + ":" + *imageRef.Offer + ":" + *imageRef.Sku + ":" + *imageRef.Version
-	vmInfo.ImageID = imageId
+	// Set VM Spec, Status
+	if server.HardwareProfile != nil {
+		vmInfo.SpecID = string(server.HardwareProfile.VMSize)
+	}
+	if server.InstanceView != nil && server.InstanceView.Statuses != nil {
+		vmInfo.KeyValueList = append(vmInfo.KeyValueList, irs.KeyValue{Key: "Status", Value: getVmStatus(*server.InstanceView)})
+	}
 
-	// Set VNic Info
-	niList := *server.NetworkProfile.NetworkInterfaces
-	for _, ni := range niList {
-		if ni.NetworkInterfaceReferenceProperties != nil {
-			vmInfo.VNIC = *ni.ID
+	// Set VNic Info, the primary NIC(or the only NIC)
+	if server.NetworkProfile != nil && server.NetworkProfile.NetworkInterfaces != nil {
+		for _, ni := range *server.NetworkProfile.NetworkInterfaces {
+			if vmInfo.VNIC == "" || (ni.NetworkInterfaceReferenceProperties != nil && to.Bool(ni.Primary)) {
+				vmInfo.VNIC = to.String(ni.ID)
+			}
 		}
 	}
 
-	// Set GuestUser Id/Pwd
-	if server.VirtualMachineProperties.OsProfile.AdminUsername != nil {
-		vmInfo.GuestUserID = *server.VirtualMachineProperties.OsProfile.AdminUsername
-	}
-	if server.VirtualMachineProperties.OsProfile.AdminPassword != nil {
-		vmInfo.GuestUserID = *server.VirtualMachineProperties.OsProfile.AdminPassword
+	// Set GuestUser Id
+	if server.OsProfile != nil {
+		vmInfo.GuestUserID = to.String(server.OsProfile.AdminUsername)
+		vmInfo.KeyValueList = append(vmInfo.KeyValueList, irs.KeyValue{Key: "ComputerName", Value: to.String(server.OsProfile.ComputerName)})
 	}
 
-	// Set BootDisk
-	if server.VirtualMachineProperties.StorageProfile.OsDisk.Name != nil {
-		vmInfo.GuestBootDisk = *server.VirtualMachineProperties.StorageProfile.OsDisk.Name
+	// Set VM Image, BootDisk, DataDisk Info
+	if server.StorageProfile != nil {
+		vmInfo.ImageID = GetImageID(server.StorageProfile.ImageReference)
+		if osDisk := server.StorageProfile.OsDisk; osDisk != nil {
+			vmInfo.GuestBootDisk = to.String(osDisk.Name)
+			if osDisk.ManagedDisk != nil {
+				vmInfo.RootDiskId = to.String(osDisk.ManagedDisk.ID)
+			}
+		}
+		for _, dataDisk := range getVMDataDisks(server) {
+			if vmInfo.GuestBlockDisk == "" {
+				vmInfo.GuestBlockDisk = fmt.Sprintf("LUN %d", to.Int32(dataDisk.Lun))
+			}
+			if dataDisk.ManagedDisk != nil {
+				vmInfo.DataDiskIds = append(vmInfo.DataDiskIds, to.String(dataDisk.ManagedDisk.ID))
+			}
+		}
 	}
 
 	return vmInfo
 }
+
+// getVMInfo maps a VM with its NICs, public IP and OS disk.
+// The addresses are of the primary IP configuration of the primary NIC, the security groups are the NSGs of all the NICs.
+// Azure has no launch time of a VM, so StartTime is the creation time of the managed OS disk.
+func (vmHandler *AzureVMHandler) getVMInfo(server compute.VirtualMachine) (irs.VMInfo, error) {
+	vmInfo := mappingServerInfo(server)
+	subscriptionID := vmHandler.Client.SubscriptionID
+
+	if server.VirtualMachineProperties != nil && server.NetworkProfile != nil && server.NetworkProfile.NetworkInterfaces != nil {
+		for _, ni := range *server.NetworkProfile.NetworkInterfaces {
+			nicID, err := ParseResourceID(to.String(ni.ID), NetworkInterfacesType, subscriptionID, vmHandler.Region.ResourceGroup)
+			if err != nil {
+				return vmInfo, err
+			}
+			nic, err := vmHandler.NicClient.Get(vmHandler.Ctx, nicID.ResourceGroup, nicID.Name, "")
+			if err != nil {
+				return vmInfo, err
+			}
+			if nic.InterfacePropertiesFormat == nil {
+				continue
+			}
+			if nic.NetworkSecurityGroup != nil && !containsString(vmInfo.SecurityGroupIds, to.String(nic.NetworkSecurityGroup.ID)) {
+				vmInfo.SecurityGroupIds = append(vmInfo.SecurityGroupIds, to.String(nic.NetworkSecurityGroup.ID))
+			}
+			if !strings.EqualFold(to.String(ni.ID), vmInfo.VNIC) {
+				continue
+			}
+			err = vmHandler.setNicAddress(&vmInfo, nic)
+			if err != nil {
+				return vmInfo, err
+			}
+		}
+	}
+	if len(vmInfo.SecurityGroupIds) != 0 {
+		vmInfo.SecurityID = vmInfo.SecurityGroupIds[0]
+	}
+
+	if vmInfo.RootDiskId != "" {
+		diskID, err := ParseResourceID(vmInfo.RootDiskId, DisksType, vmHandler.DiskClient.SubscriptionID, vmHandler.Region.ResourceGroup)
+		if err != nil {
+			return vmInfo, err
+		}
+		disk, err := vmHandler.DiskClient.Get(vmHandler.Ctx, diskID.ResourceGroup, diskID.Name)
+		if err != nil {
+			return vmInfo, err
+		}
+		if disk.DiskProperties != nil && disk.TimeCreated != nil {
+			vmInfo.StartTime = disk.TimeCreated.Local()
+		}
+	}
+
+	return vmInfo, nil
+}
+
+// setNicAddress sets the subnet, private/public IPs and DNS names of the primary IP configuration of a NIC.
+func (vmHandler *AzureVMHandler) setNicAddress(vmInfo *irs.VMInfo, nic network.Interface) error {
+	if nic.IPConfigurations == nil {
+		return nil
+	}
+	var ipConfig *network.InterfaceIPConfigurationPropertiesFormat
+	for _, c := range *nic.IPConfigurations {
+		if c.InterfaceIPConfigurationPropertiesFormat != nil && (ipConfig == nil || to.Bool(c.Primary)) {
+			ipConfig = c.InterfaceIPConfigurationPropertiesFormat
+		}
+	}
+	if ipConfig == nil {
+		return nil
+	}
+
+	vmInfo.PrivateIP = to.String(ipConfig.PrivateIPAddress)
+	if ipConfig.Subnet != nil {
+		// /subscriptions/{sub}/resourceGroups/{group}/providers/Microsoft.Network/virtualNetworks/{vnet}/subnets/{subnet}
+		vmInfo.SubNetworkID = to.String(ipConfig.Subnet.ID)
+		vmInfo.VNetworkID = strings.Split(vmInfo.SubNetworkID, "/subnets/")[0]
+	}
+	if nic.DNSSettings != nil {
+		if nic.DNSSettings.InternalFqdn != nil {
+			vmInfo.PrivateDNS = to.String(nic.DNSSettings.InternalFqdn)
+		} else if nic.DNSSettings.InternalDomainNameSuffix != nil {
+			for _, kv := range vmInfo.KeyValueList {
+				if kv.Key == "ComputerName" && kv.Value != "" {
+					vmInfo.PrivateDNS = kv.Value + "." + to.String(nic.DNSSettings.InternalDomainNameSuffix)
+				}
+			}
+		}
+	}
+
+	if ipConfig.PublicIPAddress == nil {
+		return nil
+	}
+	publicIPID, err := ParseResourceID(to.String(ipConfig.PublicIPAddress.ID), PublicIPAddressesType, vmHandler.PublicIPClient.SubscriptionID, vmHandler.Region.ResourceGroup)
+	if err != nil {
+		return err
+	}
+	publicIP, err := vmHandler.PublicIPClient.Get(vmHandler.Ctx, publicIPID.ResourceGroup, publicIPID.Name, "")
+	if err != nil {
+		return err
+	}
+	if publicIP.PublicIPAddressPropertiesFormat != nil {
+		vmInfo.PublicIP = to.String(publicIP.IPAddress)
+		if publicIP.DNSSettings != nil {
+			vmInfo.PublicDNS = to.String(publicIP.DNSSettings.Fqdn)
+		}
+	}
+	return nil
+}
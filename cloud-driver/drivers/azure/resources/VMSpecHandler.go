@@ -0,0 +1,95 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is a Cloud Driver Example for PoC Test.
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-06-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+)
+
+// AzureVMSpecHandler lists the VM sizes available in the location(Region) of the connection.
+type AzureVMSpecHandler struct {
+	Region idrv.RegionInfo
+	Ctx    context.Context
+	Client *compute.VirtualMachineSizesClient
+}
+
+// mappingVMSpecInfo maps an Azure VM size into irs.VMSpecInfo.
+// VM sizes have no GPU, network and architecture info, ex) GPUs are known only by the N-series names.
+func mappingVMSpecInfo(size compute.VirtualMachineSize) irs.VMSpecInfo {
+	return irs.VMSpecInfo{
+		Id:           to.String(size.Name),
+		Name:         to.String(size.Name),
+		VCpuCount:    int(to.Int32(size.NumberOfCores)),
+		MemoryMiB:    int64(to.Int32(size.MemoryInMB)),
+		LocalDiskGiB: int64(to.Int32(size.ResourceDiskSizeInMB)) / 1024,
+		KeyValueList: []irs.KeyValue{
+			{Key: "OsDiskSizeInMB", Value: strconv.Itoa(int(to.Int32(size.OsDiskSizeInMB)))},
+			{Key: "MaxDataDiskCount", Value: strconv.Itoa(int(to.Int32(size.MaxDataDiskCount)))},
+		},
+	}
+}
+
+func (vmSpecHandler *AzureVMSpecHandler) listVMSize() ([]compute.VirtualMachineSize, error) {
+	result, err := vmSpecHandler.Client.List(vmSpecHandler.Ctx, vmSpecHandler.Region.Region)
+	if err != nil {
+		return nil, err
+	}
+	if result.Value == nil {
+		return nil, nil
+	}
+	return *result.Value, nil
+}
+
+// VM sizes are listed at once, so pages are cut from the filtered result. VM sizes have no tags.
+func (vmSpecHandler *AzureVMSpecHandler) ListVMSpec(listReqInfo irs.ListReqInfo) ([]*irs.VMSpecInfo, irs.ListPageInfo, error) {
+	if len(listReqInfo.TagFilter) != 0 {
+		return nil, irs.ListPageInfo{}, errors.New("tag filter is not supported for Azure VM sizes")
+	}
+	var vmSpecList []*irs.VMSpecInfo
+
+	sizeList, err := vmSpecHandler.listVMSize()
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	for _, size := range sizeList {
+		if !matchListFilter(listReqInfo, size.Name, nil) {
+			continue
+		}
+		vmSpecInfo := mappingVMSpecInfo(size)
+		vmSpecList = append(vmSpecList, &vmSpecInfo)
+	}
+
+	start, end, nextToken, err := irs.GetPageRange(len(vmSpecList), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	return vmSpecList[start:end], irs.ListPageInfo{NextToken: nextToken}, nil
+}
+
+// There is no API to get a VM size, so it is found in the list(names are case-insensitive).
+func (vmSpecHandler *AzureVMSpecHandler) GetVMSpec(specID string) (irs.VMSpecInfo, error) {
+	sizeList, err := vmSpecHandler.listVMSize()
+	if err != nil {
+		return irs.VMSpecInfo{}, err
+	}
+	for _, size := range sizeList {
+		if strings.EqualFold(to.String(size.Name), specID) {
+			return mappingVMSpecInfo(size), nil
+		}
+	}
+	return irs.VMSpecInfo{}, errors.New("VM size not found in " + vmSpecHandler.Region.Region + ": " + specID)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
-	"strings"
 )
 
 type AzureVNetworkHandler struct {
@@ -17,94 +19,97 @@ type AzureVNetworkHandler struct {
 	Client *network.VirtualNetworksClient
 }
 
-// @TODO: VNetworkInfo 리소스 프로퍼티 정의 필요
-type VNetworkInfo struct {
-	Id              string
-	Name            string
-	AddressPrefixes []string
-	Subnets         []SubnetInfo
-	Location        string
-}
-
-type SubnetInfo struct {
-	Id            string
-	Name          string
-	AddressPrefix string
-}
+// mappingVNetworkInfo maps a virtual network into irs.VNetworkInfo.
+func mappingVNetworkInfo(vNetwork network.VirtualNetwork) irs.VNetworkInfo {
+	vNetInfo := irs.VNetworkInfo{
+		Id:   to.String(vNetwork.ID),
+		Name: to.String(vNetwork.Name),
+		Tags: getTags(vNetwork.Tags),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Location", Value: to.String(vNetwork.Location)},
+		},
+	}
 
-func (vNetInfo *VNetworkInfo) setter(network network.VirtualNetwork) *VNetworkInfo {
-	vNetInfo.Id = *network.ID
-	vNetInfo.Name = *network.Name
-	vNetInfo.AddressPrefixes = *network.AddressSpace.AddressPrefixes
-	var subnetArr []SubnetInfo
-	for _, subnet := range *network.Subnets {
-		subnetInfo := SubnetInfo{
-			Id:            *subnet.ID,
-			Name:          *subnet.Name,
-			AddressPrefix: *subnet.AddressPrefix,
+	if vNetwork.VirtualNetworkPropertiesFormat == nil {
+		return vNetInfo
+	}
+	vNetInfo.Status = to.String(vNetwork.ProvisioningState)
+	if vNetwork.AddressSpace != nil {
+		vNetInfo.AddressPrefixes = to.StringSlice(vNetwork.AddressSpace.AddressPrefixes)
+	}
+	if vNetwork.DhcpOptions != nil {
+		vNetInfo.KeyValueList = append(vNetInfo.KeyValueList, irs.KeyValue{Key: "DNSServers", Value: strings.Join(to.StringSlice(vNetwork.DhcpOptions.DNSServers), ",")})
+	}
+	if vNetwork.Subnets != nil {
+		for _, subnet := range *vNetwork.Subnets {
+			vNetInfo.Subnets = append(vNetInfo.Subnets, mappingSubnetInfo(subnet))
 		}
-		subnetArr = append(subnetArr, subnetInfo)
 	}
-	vNetInfo.Subnets = subnetArr
-
-	vNetInfo.Location = *network.Location
-
+	if len(vNetInfo.Subnets) != 0 {
+		vNetInfo.SubnetId = vNetInfo.Subnets[0].Id
+	}
 	return vNetInfo
 }
 
+// The DNS servers of a virtual network are for all of its subnets, so the subnets should have the same DNS servers.
+// Subnets are regional and have no IP pools, so zones and IP pools are rejected.
 func (vNetworkHandler *AzureVNetworkHandler) CreateVNetwork(vNetworkReqInfo irs.VNetworkReqInfo) (irs.VNetworkInfo, error) {
 
-	// @TODO: VNicInfo 생성 요청 파라미터 정의 필요
-	type VNetworkReqInfo struct {
-		Name            string
-		AddressPrefixes []string
-		Subnets         *[]SubnetInfo
+	if len(vNetworkReqInfo.AddressPrefixes) == 0 {
+		return irs.VNetworkInfo{}, errors.New("address prefixes are required by Azure virtual networks")
+	}
+	if err := irs.CheckVNetworkReqInfo(vNetworkReqInfo); err != nil {
+		return irs.VNetworkInfo{}, err
 	}
 
-	vNicIdArr := strings.Split(vNetworkReqInfo.Id, ":")
-
-	reqInfo := VNetworkReqInfo{
-		Name:            vNicIdArr[1],
-		AddressPrefixes: []string{"130.0.0.0/8"},
-		Subnets:         &[]SubnetInfo{
-			{
-				Name: "default",
-				AddressPrefix: "130.1.0.0/16",
-			},
-		},
+	resourceID, err := ParseResourceID(vNetworkReqInfo.Id, VirtualNetworksType, vNetworkHandler.Client.SubscriptionID, vNetworkHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.VNetworkInfo{}, err
 	}
 
 	var subnetArr []network.Subnet
-	for _, subnet := range *reqInfo.Subnets {
+	dnsServers := vNetworkReqInfo.Subnets[0].DNSServers
+	for _, subnet := range vNetworkReqInfo.Subnets {
+		if subnet.Zone != "" || len(subnet.IPPools) != 0 {
+			return irs.VNetworkInfo{}, errors.New("zones and IP pools of subnets are not supported by Azure, subnet: " + subnet.Name)
+		}
+		if strings.Join(subnet.DNSServers, ",") != strings.Join(dnsServers, ",") {
+			return irs.VNetworkInfo{}, errors.New("DNS servers of Azure subnets should be the same, subnet: " + subnet.Name)
+		}
 		subnetInfo := network.Subnet{
-			Name: &subnet.Name,
+			Name: to.StringPtr(subnet.Name),
 			SubnetPropertiesFormat: &network.SubnetPropertiesFormat{
-				AddressPrefix: &subnet.AddressPrefix,
+				AddressPrefix: to.StringPtr(subnet.CIDR),
 			},
 		}
 		subnetArr = append(subnetArr, subnetInfo)
 	}
-	
+
 	// Check vNetwork Exists
-	vNetwork, err := vNetworkHandler.Client.Get(vNetworkHandler.Ctx, vNicIdArr[0], vNicIdArr[1], "")
+	vNetwork, err := vNetworkHandler.Client.Get(vNetworkHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
 	if vNetwork.ID != nil {
-		errMsg := fmt.Sprintf("Virtual Network with name %s already exist", vNicIdArr[1])
+		errMsg := fmt.Sprintf("Virtual Network with name %s already exist", resourceID.Name)
 		createErr := errors.New(errMsg)
 		return irs.VNetworkInfo{}, createErr
 	}
-	
+
 	createOpts := network.VirtualNetwork{
-		Name: &reqInfo.Name,
+		Name: to.StringPtr(resourceID.Name),
 		VirtualNetworkPropertiesFormat: &network.VirtualNetworkPropertiesFormat{
 			AddressSpace: &network.AddressSpace{
-				AddressPrefixes: &reqInfo.AddressPrefixes,
+				AddressPrefixes: &vNetworkReqInfo.AddressPrefixes,
 			},
 			Subnets: &subnetArr,
 		},
 		Location: &vNetworkHandler.Region.Region,
+		Tags:     getAzureTags(vNetworkReqInfo.Tags),
+	}
+	// the DNS servers of Azure are used without them.
+	if len(dnsServers) != 0 {
+		createOpts.DhcpOptions = &network.DhcpOptions{DNSServers: &dnsServers}
 	}
 
-	future, err := vNetworkHandler.Client.CreateOrUpdate(vNetworkHandler.Ctx, vNicIdArr[0], vNicIdArr[1], createOpts)
+	future, err := vNetworkHandler.Client.CreateOrUpdate(vNetworkHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, createOpts)
 	if err != nil {
 		return irs.VNetworkInfo{}, err
 	}
@@ -113,42 +118,77 @@ func (vNetworkHandler *AzureVNetworkHandler) CreateVNetwork(vNetworkReqInfo irs.
 		return irs.VNetworkInfo{}, err
 	}
 
-	return irs.VNetworkInfo{}, nil
+	vNetwork, err = vNetworkHandler.Client.Get(vNetworkHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
+	if err != nil {
+		return irs.VNetworkInfo{}, err
+	}
+	return mappingVNetworkInfo(vNetwork), nil
 }
 
-func (vNetworkHandler *AzureVNetworkHandler) ListVNetwork() ([]*irs.VNetworkInfo, error) {
-	//vNetworkList, err := vNetworkHandler.Client.ListAll(vNetworkHandler.Ctx)
-	vNetworkList, err := vNetworkHandler.Client.List(vNetworkHandler.Ctx, vNetworkHandler.Region.ResourceGroup)
+// listVNetworkPage gets a page of virtual networks in the resource group of the connection.
+func (vNetworkHandler *AzureVNetworkHandler) listVNetworkPage(listReqInfo irs.ListReqInfo) (network.VirtualNetworkListResult, error) {
+	if listReqInfo.NextToken == "" {
+		result, err := vNetworkHandler.Client.List(vNetworkHandler.Ctx, vNetworkHandler.Region.ResourceGroup)
+		if err != nil {
+			return network.VirtualNetworkListResult{}, err
+		}
+		return result.Response(), nil
+	}
+
+	req, err := getNextPageRequest(vNetworkHandler.Ctx, vNetworkHandler.Client.BaseURI, listReqInfo.NextToken)
 	if err != nil {
-		return nil, err
+		return network.VirtualNetworkListResult{}, err
 	}
+	resp, err := vNetworkHandler.Client.ListSender(req)
+	if err != nil {
+		return network.VirtualNetworkListResult{}, err
+	}
+	return vNetworkHandler.Client.ListResponder(resp)
+}
 
-	var vNetList []*VNetworkInfo
-	for _, vNetwork := range vNetworkList.Values() {
-		vNetInfo := new(VNetworkInfo).setter(vNetwork)
-		vNetList = append(vNetList, vNetInfo)
+func (vNetworkHandler *AzureVNetworkHandler) ListVNetwork(listReqInfo irs.ListReqInfo) ([]*irs.VNetworkInfo, irs.ListPageInfo, error) {
+	result, err := vNetworkHandler.listVNetworkPage(listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var vNetList []*irs.VNetworkInfo
+	if result.Value == nil {
+		return vNetList, getNextToken(result.NextLink), nil
+	}
+	for _, vNetwork := range *result.Value {
+		if !matchListFilter(listReqInfo, vNetwork.Name, vNetwork.Tags) {
+			continue
+		}
+		vNetInfo := mappingVNetworkInfo(vNetwork)
+		vNetList = append(vNetList, &vNetInfo)
 	}
 
-	spew.Dump(vNetList)
-	return nil, nil
+	return vNetList, getNextToken(result.NextLink), nil
 }
 
 func (vNetworkHandler *AzureVNetworkHandler) GetVNetwork(vNetworkID string) (irs.VNetworkInfo, error) {
-	vNetworkIdArr := strings.Split(vNetworkID, ":")
-	vNetwork, err := vNetworkHandler.Client.Get(vNetworkHandler.Ctx, vNetworkIdArr[0], vNetworkIdArr[1], "")
+	resourceID, err := ParseResourceID(vNetworkID, VirtualNetworksType, vNetworkHandler.Client.SubscriptionID, vNetworkHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.VNetworkInfo{}, err
+	}
+	vNetwork, err := vNetworkHandler.Client.Get(vNetworkHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
 	if err != nil {
 		return irs.VNetworkInfo{}, err
 	}
 
-	vNetInfo := new(VNetworkInfo).setter(vNetwork)
+	vNetInfo := mappingVNetworkInfo(vNetwork)
 
 	spew.Dump(vNetInfo)
-	return irs.VNetworkInfo{}, nil
+	return vNetInfo, nil
 }
 
 func (vNetworkHandler *AzureVNetworkHandler) DeleteVNetwork(vNetworkID string) (bool, error) {
-	vNetworkIdArr := strings.Split(vNetworkID, ":")
-	future, err := vNetworkHandler.Client.Delete(vNetworkHandler.Ctx, vNetworkIdArr[0], vNetworkIdArr[1])
+	resourceID, err := ParseResourceID(vNetworkID, VirtualNetworksType, vNetworkHandler.Client.SubscriptionID, vNetworkHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	future, err := vNetworkHandler.Client.Delete(vNetworkHandler.Ctx, resourceID.ResourceGroup, resourceID.Name)
 	if err != nil {
 		return false, err
 	}
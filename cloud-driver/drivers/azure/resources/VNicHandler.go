@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
-	"strings"
 )
 
 type AzureVNicHandler struct {
@@ -18,54 +20,53 @@ type AzureVNicHandler struct {
 	SubnetClient *network.SubnetsClient
 }
 
-// @TODO: VNicInfo 리소스 프로퍼티 정의 필요
-type VNicInfo struct {
-	Id            string
-	Name          string
-	Location      string
-	Primary       bool
-	MacAddress    string
-	IP            []VNicIPConfig
-	SecurityGroup string
-}
-
-type VNicIPConfig struct {
-	Primary                   bool
-	PrivateIPAddress          string
-	PrivateIPAddressVersion   string
-	PrivateIPAllocationMethod string
-	PublicIP                  string
-	PublicIPAddressVersion    string
-	PublicIPAllocationMethod  string
-}
-
-func (nic *VNicInfo) setter(ni network.Interface) *VNicInfo {
-	nic.Id = *ni.ID
-	nic.Name = *ni.Name
-	nic.Location = *ni.Location
+// mappingVNicInfo maps a network interface into irs.VNicInfo.
+// The addresses are of the primary IP configuration, the public IP of it is kept as an ID in the KeyValueList.
+func mappingVNicInfo(ni network.Interface) irs.VNicInfo {
+	vNicInfo := irs.VNicInfo{
+		Id:   to.String(ni.ID),
+		Name: to.String(ni.Name),
+		Tags: getTags(ni.Tags),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Location", Value: to.String(ni.Location)},
+		},
+	}
 
+	if ni.InterfacePropertiesFormat == nil {
+		return vNicInfo
+	}
+	vNicInfo.MacAddress = to.String(ni.MacAddress)
+	vNicInfo.Status = to.String(ni.ProvisioningState)
 	if ni.NetworkSecurityGroup != nil {
-		nic.SecurityGroup = *ni.NetworkSecurityGroup.ID
+		vNicInfo.SecurityGroupIds = []string{to.String(ni.NetworkSecurityGroup.ID)}
+	}
+	if ni.VirtualMachine != nil {
+		vNicInfo.OwnedVMId = to.String(ni.VirtualMachine.ID)
 	}
 
-	var IPArr []VNicIPConfig
-	for _, ip := range *ni.IPConfigurations {
-		ipConfigInfo := VNicIPConfig{
-			Primary:                   *ip.Primary,
-			PrivateIPAddress:          *ip.PrivateIPAddress,
-			PrivateIPAddressVersion:   fmt.Sprint(ip.PrivateIPAddressVersion),
-			PrivateIPAllocationMethod: fmt.Sprint(ip.PrivateIPAllocationMethod),
+	if ni.IPConfigurations == nil {
+		return vNicInfo
+	}
+	for _, ipConfig := range *ni.IPConfigurations {
+		if ipConfig.InterfaceIPConfigurationPropertiesFormat == nil {
+			continue
 		}
-
-		if ip.PublicIPAddress != nil {
-			ipConfigInfo.PublicIP = *ip.PublicIPAddress.ID
+		// the first one when no primary is marked(a NIC with one IP configuration).
+		if vNicInfo.PrivateIP != "" && !to.Bool(ipConfig.Primary) {
+			continue
+		}
+		vNicInfo.PrivateIP = to.String(ipConfig.PrivateIPAddress)
+		if ipConfig.Subnet != nil {
+			// /subscriptions/.../virtualNetworks/{vnet}/subnets/{subnet}
+			vNicInfo.SubnetId = to.String(ipConfig.Subnet.ID)
+			vNicInfo.VNetworkId = strings.Split(vNicInfo.SubnetId, "/subnets/")[0]
+		}
+		if ipConfig.PublicIPAddress != nil {
+			vNicInfo.PublicIP = to.String(ipConfig.PublicIPAddress.IPAddress)
+			vNicInfo.KeyValueList = append(vNicInfo.KeyValueList, irs.KeyValue{Key: "PublicIPId", Value: to.String(ipConfig.PublicIPAddress.ID)})
 		}
-
-		IPArr = append(IPArr, ipConfigInfo)
 	}
-	nic.IP = IPArr
-
-	return nic
+	return vNicInfo
 }
 
 func (vNicHandler *AzureVNicHandler) CreateVNic(vNicReqInfo irs.VNicReqInfo) (irs.VNicInfo, error) {
@@ -105,17 +106,20 @@ func (vNicHandler *AzureVNicHandler) CreateVNic(vNicReqInfo irs.VNicReqInfo) (ir
 
 	}
 
-	vNicIdArr := strings.Split(vNicReqInfo.Id, ":")
+	resourceID, err := ParseResourceID(vNicReqInfo.Id, NetworkInterfacesType, vNicHandler.NicClient.SubscriptionID, vNicHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.VNicInfo{}, err
+	}
 	
 	// Check vNic Exists
-	vNic, err := vNicHandler.NicClient.Get(vNicHandler.Ctx, vNicIdArr[0], vNicIdArr[1], "")
+	vNic, err := vNicHandler.NicClient.Get(vNicHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
 	if vNic.ID != nil {
-		errMsg := fmt.Sprintf("Virtual Network Interface with name %s already exist", vNicIdArr[1])
+		errMsg := fmt.Sprintf("Virtual Network Interface with name %s already exist", resourceID.Name)
 		createErr := errors.New(errMsg)
 		return irs.VNicInfo{}, createErr
 	}
 
-	subnet, err := vNicHandler.getSubnet(vNicIdArr[0], reqInfo.VNetworkName, reqInfo.SubnetName)
+	subnet, err := vNicHandler.getSubnet(resourceID.ResourceGroup, reqInfo.VNetworkName, reqInfo.SubnetName)
 
 	var ipConfigArr []network.InterfaceIPConfiguration
 	for _, ipReqInfo := range reqInfo.IP {
@@ -140,10 +144,11 @@ func (vNicHandler *AzureVNicHandler) CreateVNic(vNicReqInfo irs.VNicReqInfo) (ir
 			},
 		},
 		Location: &vNicHandler.Region.Region,
+		Tags:     getAzureTags(vNicReqInfo.Tags),
 		//NetworkSecurityGroup:
 	}
 	
-	future, err := vNicHandler.NicClient.CreateOrUpdate(vNicHandler.Ctx, vNicIdArr[0], vNicIdArr[1], createOpts)
+	future, err := vNicHandler.NicClient.CreateOrUpdate(vNicHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, createOpts)
 	if err != nil {
 		return irs.VNicInfo{}, err
 	}
@@ -152,42 +157,77 @@ func (vNicHandler *AzureVNicHandler) CreateVNic(vNicReqInfo irs.VNicReqInfo) (ir
 		return irs.VNicInfo{}, err
 	}
 
-	return irs.VNicInfo{}, nil
+	vNic, err = vNicHandler.NicClient.Get(vNicHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
+	if err != nil {
+		return irs.VNicInfo{}, err
+	}
+	return mappingVNicInfo(vNic), nil
+}
+
+// listVNicPage gets a page of network interfaces in the resource group of the connection.
+func (vNicHandler *AzureVNicHandler) listVNicPage(listReqInfo irs.ListReqInfo) (network.InterfaceListResult, error) {
+	if listReqInfo.NextToken == "" {
+		result, err := vNicHandler.NicClient.List(vNicHandler.Ctx, vNicHandler.Region.ResourceGroup)
+		if err != nil {
+			return network.InterfaceListResult{}, err
+		}
+		return result.Response(), nil
+	}
+
+	req, err := getNextPageRequest(vNicHandler.Ctx, vNicHandler.NicClient.BaseURI, listReqInfo.NextToken)
+	if err != nil {
+		return network.InterfaceListResult{}, err
+	}
+	resp, err := vNicHandler.NicClient.ListSender(req)
+	if err != nil {
+		return network.InterfaceListResult{}, err
+	}
+	return vNicHandler.NicClient.ListResponder(resp)
 }
 
-func (vNicHandler *AzureVNicHandler) ListVNic() ([]*irs.VNicInfo, error) {
-	//result, err := vNicHandler.NicClient.ListAll(vNicHandler.Ctx)
-	result, err := vNicHandler.NicClient.List(vNicHandler.Ctx, vNicHandler.Region.ResourceGroup)
+func (vNicHandler *AzureVNicHandler) ListVNic(listReqInfo irs.ListReqInfo) ([]*irs.VNicInfo, irs.ListPageInfo, error) {
+	result, err := vNicHandler.listVNicPage(listReqInfo)
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
 	}
 
-	var vNicList []*VNicInfo
-	for _, vNic := range result.Values() {
-		vNicInfo := new(VNicInfo).setter(vNic)
-		vNicList = append(vNicList, vNicInfo)
+	var vNicList []*irs.VNicInfo
+	if result.Value == nil {
+		return vNicList, getNextToken(result.NextLink), nil
+	}
+	for _, vNic := range *result.Value {
+		if !matchListFilter(listReqInfo, vNic.Name, vNic.Tags) {
+			continue
+		}
+		vNicInfo := mappingVNicInfo(vNic)
+		vNicList = append(vNicList, &vNicInfo)
 	}
 
-	spew.Dump(vNicList)
-	return nil, nil
+	return vNicList, getNextToken(result.NextLink), nil
 }
 
 func (vNicHandler *AzureVNicHandler) GetVNic(vNicID string) (irs.VNicInfo, error) {
-	vNicIDArr := strings.Split(vNicID, ":")
-	vNic, err := vNicHandler.NicClient.Get(vNicHandler.Ctx, vNicIDArr[0], vNicIDArr[1], "")
+	resourceID, err := ParseResourceID(vNicID, NetworkInterfacesType, vNicHandler.NicClient.SubscriptionID, vNicHandler.Region.ResourceGroup)
+	if err != nil {
+		return irs.VNicInfo{}, err
+	}
+	vNic, err := vNicHandler.NicClient.Get(vNicHandler.Ctx, resourceID.ResourceGroup, resourceID.Name, "")
 	if err != nil {
 		return irs.VNicInfo{}, err
 	}
 
-	vNicInfo := new(VNicInfo).setter(vNic)
+	vNicInfo := mappingVNicInfo(vNic)
 
 	spew.Dump(vNicInfo)
-	return irs.VNicInfo{}, nil
+	return vNicInfo, nil
 }
 
 func (vNicHandler *AzureVNicHandler) DeleteVNic(vNicID string) (bool, error) {
-	vNicIDArr := strings.Split(vNicID, ":")
-	future, err := vNicHandler.NicClient.Delete(vNicHandler.Ctx, vNicIDArr[0], vNicIDArr[1])
+	resourceID, err := ParseResourceID(vNicID, NetworkInterfacesType, vNicHandler.NicClient.SubscriptionID, vNicHandler.Region.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	future, err := vNicHandler.NicClient.Delete(vNicHandler.Ctx, resourceID.ResourceGroup, resourceID.Name)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,62 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is the golden file check of the test programs of the drivers(ex: main/Test_VMInfo.go).
+// A result is compared in JSON with {name}.golden.json of the test data of a driver,
+// the golden files are rewritten with the -update flag.
+
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current results")
+
+// GetDataPath returns the test data path of a driver, ex) GetDataPath("aws", "vminfo")
+func GetDataPath(driverName string, dataName string) string {
+	rootPath := os.Getenv("CBSPIDER_PATH")
+	return filepath.Join(rootPath, "cloud-driver/drivers", driverName, "main/testdata", dataName)
+}
+
+// Check compares a result with {dataPath}/{name}.golden.json and prints the result of the check.
+// With the -update flag, the golden file is rewritten with the result.
+func Check(dataPath string, name string, result interface{}) bool {
+	got, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		fmt.Printf("[FAIL] %s: %v\n", name, err)
+		return false
+	}
+	got = append(got, '\n')
+
+	goldenFile := filepath.Join(dataPath, name+".golden.json")
+	if *update {
+		if err := ioutil.WriteFile(goldenFile, got, 0644); err != nil {
+			fmt.Printf("[FAIL] %s: %v\n", name, err)
+			return false
+		}
+		fmt.Printf("[UPDATED] %s\n", goldenFile)
+		return true
+	}
+
+	want, err := ioutil.ReadFile(goldenFile)
+	if err != nil {
+		fmt.Printf("[FAIL] %s: %v\n", name, err)
+		return false
+	}
+	if !bytes.Equal(got, want) {
+		fmt.Printf("[FAIL] %s\n--- want\n%s--- got\n%s", name, want, got)
+		return false
+	}
+	fmt.Printf("[OK] %s\n", name)
+	return true
+}
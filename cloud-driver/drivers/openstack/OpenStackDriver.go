@@ -14,6 +14,7 @@ import (
 	oscon "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/openstack/connect"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	icon "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/connect"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/rackspace/gophercloud"
 	"github.com/rackspace/gophercloud/openstack"
 )
@@ -75,7 +76,17 @@ func (driver *OpenStackDriver) ConnectCloud(connectionInfo idrv.ConnectionInfo)
 		panic(err)
 	}
 
-	iConn := oscon.OpenStackCloudConnection{Client, ImageClient, NetworkClient}
+	iConn := oscon.OpenStackCloudConnection{Client, ImageClient, NetworkClient, connectionInfo.RegionInfo.Region}
+
+	// the region(and zone) typed in the connection must be available.
+	regionZoneHandler, err := iConn.CreateRegionZoneHandler()
+	if err != nil {
+		return nil, err
+	}
+	err = irs.ValidateRegionZone(regionZoneHandler, connectionInfo.RegionInfo.Region, connectionInfo.RegionInfo.Zone)
+	if err != nil {
+		return nil, err
+	}
 
 	return &iConn, nil // return type: (icon.CloudConnection, error)
 }
@@ -11,10 +11,12 @@
 package connect
 
 import (
+	"errors"
 	"fmt"
 	osrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/openstack/resources"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack"
 )
 
 // modified by powerkim, 2019.07.29
@@ -22,6 +24,19 @@ type OpenStackCloudConnection struct {
 	Client        *gophercloud.ServiceClient
 	ImageClient   *gophercloud.ServiceClient
 	NetworkClient *gophercloud.ServiceClient
+	Region        string
+}
+
+// getVolumeClient returns the cinder v1 client, the block storage API of gophercloud.
+// It is created by the handlers of volumes only, some clouds have no endpoint of the "volume" service(ex: volumev3 only).
+func (cloudConn *OpenStackCloudConnection) getVolumeClient() (*gophercloud.ServiceClient, error) {
+	client, err := openstack.NewBlockStorageV1(cloudConn.Client.ProviderClient, gophercloud.EndpointOpts{
+		Region: cloudConn.Region,
+	})
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("no cinder v1(volume) endpoint in region %s: %v", cloudConn.Region, err))
+	}
+	return client, nil
 }
 
 func (cloudConn *OpenStackCloudConnection) CreateVNetworkHandler() (irs.VNetworkHandler, error) {
@@ -30,15 +45,25 @@ func (cloudConn *OpenStackCloudConnection) CreateVNetworkHandler() (irs.VNetwork
 	return &vNetworkHandler, nil
 }
 
+func (cloudConn *OpenStackCloudConnection) CreateSubnetHandler() (irs.SubnetHandler, error) {
+	fmt.Println("OpenStack Cloud Driver: called CreateSubnetHandler()!")
+	subnetHandler := osrs.OpenStackSubnetHandler{cloudConn.NetworkClient}
+	return &subnetHandler, nil
+}
+
 func (cloudConn *OpenStackCloudConnection) CreateImageHandler() (irs.ImageHandler, error) {
 	fmt.Println("OpenStack Cloud Driver: called CreateImageHandler()!")
-	imageHandler := osrs.OpenStackImageHandler{cloudConn.Client, cloudConn.ImageClient}
+	volumeClient, err := cloudConn.getVolumeClient()
+	if err != nil {
+		return nil, err
+	}
+	imageHandler := osrs.OpenStackImageHandler{cloudConn.Client, cloudConn.ImageClient, volumeClient}
 	return &imageHandler, nil
 }
 
 func (cloudConn OpenStackCloudConnection) CreateSecurityHandler() (irs.SecurityHandler, error) {
 	fmt.Println("OpenStack Cloud Driver: called CreateSecurityHandler()!")
-	securityHandler := osrs.OpenStackSecurityHandler{cloudConn.Client}
+	securityHandler := osrs.OpenStackSecurityHandler{cloudConn.Client, cloudConn.NetworkClient}
 	return &securityHandler, nil
 }
 func (cloudConn *OpenStackCloudConnection) CreateKeyPairHandler() (irs.KeyPairHandler, error) {
@@ -53,9 +78,42 @@ func (cloudConn *OpenStackCloudConnection) CreateVNicHandler() (irs.VNicHandler,
 }
 func (cloudConn OpenStackCloudConnection) CreatePublicIPHandler() (irs.PublicIPHandler, error) {
 	fmt.Println("OpenStack Cloud Driver: called CreatePublicIPHandler()!")
-	publicIPHandler := osrs.OpenStackPublicIPHandler{cloudConn.Client}
+	publicIPHandler := osrs.OpenStackPublicIPHandler{cloudConn.Client, cloudConn.NetworkClient}
 	return &publicIPHandler, nil
 }
+func (cloudConn *OpenStackCloudConnection) CreateRouterHandler() (irs.RouterHandler, error) {
+	fmt.Println("OpenStack Cloud Driver: called CreateRouterHandler()!")
+	routerHandler := osrs.OpenStackRouterHandler{cloudConn.NetworkClient}
+	return &routerHandler, nil
+}
+func (cloudConn *OpenStackCloudConnection) CreateVMSpecHandler() (irs.VMSpecHandler, error) {
+	fmt.Println("OpenStack Cloud Driver: called CreateVMSpecHandler()!")
+	vmSpecHandler := osrs.OpenStackVMSpecHandler{cloudConn.Client}
+	return &vmSpecHandler, nil
+}
+func (cloudConn *OpenStackCloudConnection) CreateRegionZoneHandler() (irs.RegionZoneHandler, error) {
+	fmt.Println("OpenStack Cloud Driver: called CreateRegionZoneHandler()!")
+	regionZoneHandler := osrs.OpenStackRegionZoneHandler{cloudConn.Client}
+	return &regionZoneHandler, nil
+}
+func (cloudConn *OpenStackCloudConnection) CreateDiskHandler() (irs.DiskHandler, error) {
+	fmt.Println("OpenStack Cloud Driver: called CreateDiskHandler()!")
+	volumeClient, err := cloudConn.getVolumeClient()
+	if err != nil {
+		return nil, err
+	}
+	diskHandler := osrs.OpenStackDiskHandler{cloudConn.Client, volumeClient}
+	return &diskHandler, nil
+}
+func (cloudConn *OpenStackCloudConnection) CreateSnapshotHandler() (irs.SnapshotHandler, error) {
+	fmt.Println("OpenStack Cloud Driver: called CreateSnapshotHandler()!")
+	volumeClient, err := cloudConn.getVolumeClient()
+	if err != nil {
+		return nil, err
+	}
+	snapshotHandler := osrs.OpenStackSnapshotHandler{cloudConn.Client, volumeClient}
+	return &snapshotHandler, nil
+}
 
 // modified by powerkim, 2019.07.29
 func (cloudConn *OpenStackCloudConnection) CreateVMHandler() (irs.VMHandler, error) {
@@ -71,7 +129,7 @@ func (cloudConn *OpenStackCloudConnection) CreateVMHandler() (irs.VMHandler, err
 	//     }
 
 	fmt.Println("OpenStack Cloud Driver: called CreateVMHandler()!")
-	vmHandler := osrs.OpenStackVMHandler{cloudConn.Client}
+	vmHandler := osrs.OpenStackVMHandler{cloudConn.Client, cloudConn.NetworkClient}
 	return &vmHandler, nil
 }
 
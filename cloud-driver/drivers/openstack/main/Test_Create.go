@@ -2,8 +2,6 @@ package main
 
 import (
 	osdrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/openstack"
-	osconn "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/openstack/connect"
-	osrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/openstack/resources"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
@@ -45,38 +43,44 @@ func testCreateVM() {
 	//keyPairHandler, _ := cloudConnection.CreateKeyPairHandler()
 	vmHandler, _ := cloudConnection.CreateVMHandler()
 	publicIPHandler, _ := cloudConnection.CreatePublicIPHandler()
-
-	// TODO: RouterHandler 인터페이스 추가
-	osConnection := cloudConnection.(*osconn.OpenStackCloudConnection)
-	routerHandler := osrs.OpenStackRouterHandler{Client: osConnection.NetworkClient}
+	routerHandler, _ := cloudConnection.CreateRouterHandler()
 
 	// 1. Virtual Network, Subnet 생성
-	vNetReqInfo := irs.VNetworkReqInfo{Name: config.Openstack.VirtualNetwork.Name}
+	vNetReqInfo := irs.VNetworkReqInfo{
+		Name: config.Openstack.VirtualNetwork.Name,
+		Subnets: []irs.SubnetReqInfo{
+			{
+				Name:       "default",
+				CIDR:       "30.0.0.0/24",
+				DNSServers: []string{"8.8.8.8"},
+				IPPools:    []irs.IPPool{{Start: "30.0.0.2", End: "30.0.0.254"}},
+			},
+		},
+	}
 	vNet, err := vNetworkHandler.CreateVNetwork(vNetReqInfo)
 	if err != nil {
 		panic(err)
 	}
 
-	// 2. Router 생성 및 인터페이스 등록
-	// Router 생성
-	routerReqInfo := osrs.RouterReqInfo{
-		Name:         config.Openstack.Router.Name,
-		GateWayId:    config.Openstack.Router.GateWayId,
-		AdminStateUp: config.Openstack.Router.AdminStateUp,
-	}
-	router, err := routerHandler.CreateRouter(routerReqInfo)
-	if err != nil {
-		panic(err)
+	// 2. Router 생성 및 서브넷 연결
+	routerReqInfo := irs.RouterReqInfo{
+		Name:      config.Openstack.Router.Name,
+		GatewayId: config.Openstack.Router.GateWayId,
+		SubnetIds: []string{vNet.SubnetId},
 	}
-	// 인터페이스 등록(연결)
-	irReqInfo := osrs.InterfaceReqInfo{RouterId: router.Id, SubnetId: vNet.SubnetId}
-	_, err = routerHandler.AddInterface(irReqInfo)
+	_, err = routerHandler.CreateRouter(routerReqInfo)
 	if err != nil {
 		panic(err)
 	}
 
 	// 3. Security Group 생성
-	sgReqInfo := irs.SecurityReqInfo{Name: config.Openstack.SecurityGroup.Name}
+	sgReqInfo := irs.SecurityReqInfo{
+		Name: config.Openstack.SecurityGroup.Name,
+		SecurityRules: []irs.SecurityRuleInfo{
+			{Direction: "inbound", IPProtocol: "tcp", FromPort: 22, ToPort: 22, CIDR: "0.0.0.0/0"},
+			{Direction: "inbound", IPProtocol: "icmp", FromPort: -1, ToPort: -1, CIDR: "0.0.0.0/0"},
+		},
+	}
 	sg, err := securityHandler.CreateSecurity(sgReqInfo)
 	if err != nil {
 		panic(err)
@@ -125,9 +129,7 @@ func testCreateVM() {
 	time.Sleep(time.Second * 10)
 
 	// PublicIP 할당
-	IP, err := publicIPHandler.GetPublicIP(publicIP.Id)
-	openStackPublicIPHandler := publicIPHandler.(*osrs.OpenStackPublicIPHandler)
-	_, err = openStackPublicIPHandler.AssociatePublicIP(vm.Id, IP.Id)
+	_, err = publicIPHandler.AssociatePublicIP(publicIP.Id, vm.Id)
 	if err != nil {
 		panic(err)
 	}
@@ -168,9 +170,8 @@ type Config struct {
 		} `yaml:"vnet_info"`
 
 		Router struct {
-			Name         string `yaml:"name"`
-			GateWayId    string `yaml:"gateway_id"`
-			AdminStateUp bool   `yaml:"adminstatup"`
+			Name      string `yaml:"name"`
+			GateWayId string `yaml:"gateway_id"`
 		} `yaml:"router_info"`
 	} `yaml:"openstack"`
 }
@@ -3,10 +3,9 @@ package main
 import (
 	"fmt"
 	osdrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/openstack"
-	"github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/openstack/connect"
-	osrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/openstack/resources"
 	idrv "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/davecgh/go-spew/spew"
 	"gopkg.in/yaml.v3"
 	"io/ioutil"
 	"os"
@@ -41,7 +40,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListImage() ...")
-				imageHandler.ListImage()
+				imageHandler.ListImage(irs.ListReqInfo{})
 				fmt.Println("Finish ListImage()")
 			case 2:
 				fmt.Println("Start GetImage() ...")
@@ -95,7 +94,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListKey() ...")
-				keyPairHandler.ListKey()
+				keyPairHandler.ListKey(irs.ListReqInfo{})
 				fmt.Println("Finish ListKey()")
 			case 2:
 				fmt.Println("Start GetKey() ...")
@@ -150,7 +149,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListPublicIP() ...")
-				publicIPHandler.ListPublicIP()
+				publicIPHandler.ListPublicIP(irs.ListReqInfo{})
 				fmt.Println("Finish ListPublicIP()")
 			case 2:
 				fmt.Println("Start GetPublicIP() ...")
@@ -190,7 +189,8 @@ func testSecurityHandler(config Config) {
 	fmt.Println("2. GetSecurity()")
 	fmt.Println("3. CreateSecurity()")
 	fmt.Println("4. DeleteSecurity()")
-	fmt.Println("5. Exit")
+	fmt.Println("5. SyncRules()")
+	fmt.Println("6. Exit")
 
 	var securityGroupId string
 
@@ -207,7 +207,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListSecurity() ...")
-				securityHandler.ListSecurity()
+				securityHandler.ListSecurity(irs.ListReqInfo{})
 				fmt.Println("Finish ListSecurity()")
 			case 2:
 				fmt.Println("Start GetSecurity() ...")
@@ -215,7 +215,13 @@ Loop:
 				fmt.Println("Finish GetSecurity()")
 			case 3:
 				fmt.Println("Start CreateSecurity() ...")
-				reqInfo := irs.SecurityReqInfo{Name: config.Openstack.SecurityGroup.Name}
+				reqInfo := irs.SecurityReqInfo{
+					Name: config.Openstack.SecurityGroup.Name,
+					SecurityRules: []irs.SecurityRuleInfo{
+						{Direction: "inbound", IPProtocol: "tcp", FromPort: 22, ToPort: 22, CIDR: "0.0.0.0/0"},
+						{Direction: "inbound", IPProtocol: "icmp", FromPort: -1, ToPort: -1, CIDR: "0.0.0.0/0"},
+					},
+				}
 				securityGroup, err := securityHandler.CreateSecurity(reqInfo)
 				if err != nil {
 					panic(err)
@@ -227,6 +233,14 @@ Loop:
 				securityHandler.DeleteSecurity(securityGroupId)
 				fmt.Println("Finish DeleteSecurity()")
 			case 5:
+				fmt.Println("Start SyncRules() ...")
+				// SSH is changed to port 2222 and icmp is removed.
+				desiredRules := []irs.SecurityRuleInfo{
+					{Direction: "inbound", IPProtocol: "tcp", FromPort: 2222, ToPort: 2222, CIDR: "0.0.0.0/0"},
+				}
+				securityHandler.SyncRules(securityGroupId, desiredRules)
+				fmt.Println("Finish SyncRules()")
+			case 6:
 				fmt.Println("Exit")
 				break Loop
 			}
@@ -264,7 +278,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListVNetwork() ...")
-				vNetworkHandler.ListVNetwork()
+				vNetworkHandler.ListVNetwork(irs.ListReqInfo{})
 				fmt.Println("Finish ListVNetwork()")
 			case 2:
 				fmt.Println("Start GetVNetwork() ...")
@@ -272,7 +286,17 @@ Loop:
 				fmt.Println("Finish GetVNetwork()")
 			case 3:
 				fmt.Println("Start CreateVNetwork() ...")
-				reqInfo := irs.VNetworkReqInfo{Name: config.Openstack.VirtualNetwork.Name}
+				reqInfo := irs.VNetworkReqInfo{
+					Name: config.Openstack.VirtualNetwork.Name,
+					Subnets: []irs.SubnetReqInfo{
+						{
+							Name:       "default",
+							CIDR:       "30.0.0.0/24",
+							DNSServers: []string{"8.8.8.8"},
+							IPPools:    []irs.IPPool{{Start: "30.0.0.2", End: "30.0.0.254"}},
+						},
+					},
+				}
 				vNetwork, err := vNetworkHandler.CreateVNetwork(reqInfo)
 				if err != nil {
 					panic(err)
@@ -321,7 +345,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListVNic() ...")
-				vNicHandler.ListVNic()
+				vNicHandler.ListVNic(irs.ListReqInfo{})
 				fmt.Println("Finish ListVNic()")
 			case 2:
 				fmt.Println("Start GetVNic() ...")
@@ -354,15 +378,15 @@ func testRouterHandler(config Config) {
 		panic(err)
 	}
 
-	routerHandler := resourceHandler.(osrs.OpenStackRouterHandler)
+	routerHandler := resourceHandler.(irs.RouterHandler)
 
 	fmt.Println("Test RouterHandler")
 	fmt.Println("1. ListRouter()")
 	fmt.Println("2. GetRouter()")
 	fmt.Println("3. CreateRouter()")
 	fmt.Println("4. DeleteRouter()")
-	fmt.Println("5. AddInterface()")
-	fmt.Println("6. DeleteInterface()")
+	fmt.Println("5. AttachSubnet()")
+	fmt.Println("6. DetachSubnet()")
 	fmt.Println("7. Exit")
 
 	var routerId string
@@ -380,7 +404,7 @@ Loop:
 			switch commandNum {
 			case 1:
 				fmt.Println("Start ListRouter() ...")
-				routerHandler.ListRouter()
+				routerHandler.ListRouter(irs.ListReqInfo{})
 				fmt.Println("Finish ListRouter()")
 			case 2:
 				fmt.Println("Start GetRouter() ...")
@@ -388,10 +412,9 @@ Loop:
 				fmt.Println("Finish GetRouter()")
 			case 3:
 				fmt.Println("Start CreateRouter() ...")
-				reqInfo := osrs.RouterReqInfo{
-					Name:         config.Openstack.Router.Name,
-					GateWayId:    config.Openstack.Router.GateWayId,
-					AdminStateUp: config.Openstack.Router.AdminStateUp,
+				reqInfo := irs.RouterReqInfo{
+					Name:      config.Openstack.Router.Name,
+					GatewayId: config.Openstack.Router.GateWayId,
 				}
 				router, err := routerHandler.CreateRouter(reqInfo)
 				if err != nil {
@@ -404,24 +427,161 @@ Loop:
 				routerHandler.DeleteRouter(routerId)
 				fmt.Println("Finish DeleteRouter()")
 			case 5:
-				fmt.Println("Start AddInterface() ...")
-				reqInfo := osrs.InterfaceReqInfo{
-					SubnetId: config.Openstack.Subnet.Id,
-					RouterId: routerId,
+				fmt.Println("Start AttachSubnet() ...")
+				_, err := routerHandler.AttachSubnet(routerId, config.Openstack.Subnet.Id)
+				if err != nil {
+					panic(err)
+				}
+				fmt.Println("Finish AttachSubnet()")
+			case 6:
+				fmt.Println("Start DetachSubnet() ...")
+				_, err := routerHandler.DetachSubnet(routerId, config.Openstack.Subnet.Id)
+				if err != nil {
+					panic(err)
+				}
+				fmt.Println("Finish DetachSubnet()")
+			case 7:
+				fmt.Println("Exit")
+				break Loop
+			}
+		}
+	}
+}
+
+func testVMSpecHandler(config Config) {
+	resourceHandler, err := getResourceHandler("vmspec")
+	if err != nil {
+		panic(err)
+	}
+
+	vmSpecHandler := resourceHandler.(irs.VMSpecHandler)
+
+	fmt.Println("Test VMSpecHandler")
+	fmt.Println("1. ListVMSpec()")
+	fmt.Println("2. GetVMSpec()")
+	fmt.Println("3. Exit")
+
+Loop:
+	for {
+		var commandNum int
+		inputCnt, err := fmt.Scan(&commandNum)
+		if err != nil {
+			panic(err)
+		}
+
+		if inputCnt == 1 {
+			switch commandNum {
+			case 1:
+				fmt.Println("Start ListVMSpec() ...")
+				vmSpecList, _, err := vmSpecHandler.ListVMSpec(irs.ListReqInfo{})
+				if err != nil {
+					panic(err)
 				}
-				_, err := routerHandler.AddInterface(reqInfo)
+				spew.Dump(vmSpecList)
+				fmt.Println("Finish ListVMSpec()")
+			case 2:
+				fmt.Println("Start GetVMSpec() ...")
+				vmSpec, err := vmSpecHandler.GetVMSpec(config.Openstack.FlavorId)
 				if err != nil {
 					panic(err)
 				}
-				fmt.Println("Finish AddInterface()")
+				spew.Dump(vmSpec)
+				fmt.Println("Finish GetVMSpec()")
+			case 3:
+				fmt.Println("Exit")
+				break Loop
+			}
+		}
+	}
+}
+
+func testDiskHandler(config Config) {
+	resourceHandler, err := getResourceHandler("disk")
+	if err != nil {
+		panic(err)
+	}
+
+	diskHandler := resourceHandler.(irs.DiskHandler)
+
+	fmt.Println("Test DiskHandler")
+	fmt.Println("1. ListDisk()")
+	fmt.Println("2. GetDisk()")
+	fmt.Println("3. CreateDisk()")
+	fmt.Println("4. AttachDisk()")
+	fmt.Println("5. DetachDisk()")
+	fmt.Println("6. ResizeDisk()")
+	fmt.Println("7. DeleteDisk()")
+	fmt.Println("8. Exit")
+
+	var diskId string
+
+Loop:
+	for {
+		var commandNum int
+		inputCnt, err := fmt.Scan(&commandNum)
+		if err != nil {
+			panic(err)
+		}
+
+		if inputCnt == 1 {
+			switch commandNum {
+			case 1:
+				fmt.Println("Start ListDisk() ...")
+				diskList, _, err := diskHandler.ListDisk(irs.ListReqInfo{})
+				if err != nil {
+					panic(err)
+				}
+				spew.Dump(diskList)
+				fmt.Println("Finish ListDisk()")
+			case 2:
+				fmt.Println("Start GetDisk() ...")
+				disk, err := diskHandler.GetDisk(diskId)
+				if err != nil {
+					panic(err)
+				}
+				spew.Dump(disk)
+				fmt.Println("Finish GetDisk()")
+			case 3:
+				fmt.Println("Start CreateDisk() ...")
+				reqInfo := irs.DiskReqInfo{
+					Name:    config.Openstack.Disk.Name,
+					SizeGiB: config.Openstack.Disk.Size,
+				}
+				disk, err := diskHandler.CreateDisk(reqInfo)
+				if err != nil {
+					panic(err)
+				}
+				diskId = disk.Id
+				fmt.Println("Finish CreateDisk()")
+			case 4:
+				fmt.Println("Start AttachDisk() ...")
+				_, err := diskHandler.AttachDisk(diskId, config.Openstack.ServerId)
+				if err != nil {
+					panic(err)
+				}
+				fmt.Println("Finish AttachDisk()")
+			case 5:
+				fmt.Println("Start DetachDisk() ...")
+				_, err := diskHandler.DetachDisk(diskId, config.Openstack.ServerId)
+				if err != nil {
+					panic(err)
+				}
+				fmt.Println("Finish DetachDisk()")
 			case 6:
-				fmt.Println("Start DeleteInterface() ...")
-				_, err := routerHandler.DeleteInterface(routerId, config.Openstack.Subnet.Id)
+				fmt.Println("Start ResizeDisk() ...")
+				_, err := diskHandler.ResizeDisk(diskId, config.Openstack.Disk.Size*2)
 				if err != nil {
 					panic(err)
 				}
-				fmt.Println("Finish DeleteInterface()")
+				fmt.Println("Finish ResizeDisk()")
 			case 7:
+				fmt.Println("Start DeleteDisk() ...")
+				_, err := diskHandler.DeleteDisk(diskId)
+				if err != nil {
+					panic(err)
+				}
+				fmt.Println("Finish DeleteDisk()")
+			case 8:
 				fmt.Println("Exit")
 				break Loop
 			}
@@ -466,13 +626,11 @@ func getResourceHandler(resourceType string) (interface{}, error) {
 	case "vnic":
 		resourceHandler, err = cloudConnection.CreateVNicHandler()
 	case "router":
-		osDriver := osdrv.OpenStackDriver{}
-		cloudConn, err := osDriver.ConnectCloud(connectionInfo)
-		if err != nil {
-			panic(err)
-		}
-		osCloudConn := cloudConn.(*connect.OpenStackCloudConnection)
-		resourceHandler = osrs.OpenStackRouterHandler{Client: osCloudConn.NetworkClient}
+		resourceHandler, err = cloudConnection.CreateRouterHandler()
+	case "vmspec":
+		resourceHandler, err = cloudConnection.CreateVMSpecHandler()
+	case "disk":
+		resourceHandler, err = cloudConnection.CreateDiskHandler()
 	}
 
 	if err != nil {
@@ -491,7 +649,9 @@ func showTestHandlerInfo() {
 	fmt.Println("5. VNetworkHandler")
 	fmt.Println("6. VNicHandler")
 	fmt.Println("7. RouterHandler")
-	fmt.Println("8. Exit")
+	fmt.Println("8. VMSpecHandler")
+	fmt.Println("9. DiskHandler")
+	fmt.Println("10. Exit")
 	fmt.Println("==========================================================")
 }
 
@@ -533,6 +693,12 @@ Loop:
 				testRouterHandler(config)
 				showTestHandlerInfo()
 			case 8:
+				testVMSpecHandler(config)
+				showTestHandlerInfo()
+			case 9:
+				testDiskHandler(config)
+				showTestHandlerInfo()
+			case 10:
 				fmt.Println("Exit Test ResourceHandler Program")
 				break Loop
 			}
@@ -579,10 +745,14 @@ type Config struct {
 		} `yaml:"subnet_info"`
 
 		Router struct {
-			Name         string `yaml:"name"`
-			GateWayId    string `yaml:"gateway_id"`
-			AdminStateUp bool   `yaml:"adminstatup"`
+			Name      string `yaml:"name"`
+			GateWayId string `yaml:"gateway_id"`
 		} `yaml:"router_info"`
+
+		Disk struct {
+			Name string `yaml:"name"`
+			Size int    `yaml:"size"`
+		} `yaml:"disk_info"`
 	} `yaml:"openstack"`
 }
 
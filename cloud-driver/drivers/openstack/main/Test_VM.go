@@ -70,7 +70,10 @@ func testVMHandler() {
 			switch commandNum {
 			case 1:
 				fmt.Println("Start List VM ...")
-				vmList := vmHandler.ListVM()
+				vmList, _, err := vmHandler.ListVM(irs.ListReqInfo{})
+				if err != nil {
+					panic(err)
+				}
 				for i, vm := range vmList {
 					fmt.Println("[", i, "] ")
 					spew.Dump(vm)
@@ -83,7 +86,10 @@ func testVMHandler() {
 				fmt.Println("Finish Get VM")
 			case 3:
 				fmt.Println("Start List VMStatus ...")
-				vmStatusList := vmHandler.ListVMStatus()
+				vmStatusList, _, err := vmHandler.ListVMStatus(irs.ListReqInfo{})
+				if err != nil {
+					panic(err)
+				}
 				for i, vmStatus := range vmStatusList {
 					fmt.Println("[", i, "] ", *vmStatus)
 				}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/golden"
+	osrs "github.com/cloud-barista/poc-cb-spider/cloud-driver/drivers/openstack/resources"
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/rackspace/gophercloud"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Golden file check of the VMInfo of the OpenStack Driver.
+// The recorded nova and neutron responses of testdata/vminfo are replayed by a local server,
+// the results of GetVM and ListVM are compared with testdata/vminfo/*.golden.json.
+//   $ go run Test_VMInfo.go           # check
+//   $ go run Test_VMInfo.go -update   # rewrite the golden files
+
+// replays testdata/vminfo/{path}.json, ex) compute/servers/{id} -> compute_servers_{id}.json
+// the ports of a server are filtered by device_id, ex) network_v2.0_ports_{server id}.json
+func newOpenStackServer(dataPath string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.Replace(strings.Trim(r.URL.Path, "/"), "/", "_", -1)
+		if deviceID := r.URL.Query().Get("device_id"); deviceID != "" {
+			name += "_" + deviceID
+		}
+		body, err := ioutil.ReadFile(filepath.Join(dataPath, name+".json"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+}
+
+func main() {
+	flag.Parse()
+	// StartTime is the local time of the server, so it is fixed to UTC.
+	time.Local = time.UTC
+
+	dataPath := golden.GetDataPath("openstack", "vminfo")
+	server := newOpenStackServer(dataPath)
+	defer server.Close()
+
+	provider := &gophercloud.ProviderClient{TokenID: "token"}
+	vmHandler := osrs.OpenStackVMHandler{
+		Client: &gophercloud.ServiceClient{
+			ProviderClient: provider,
+			Endpoint:       server.URL + "/compute/",
+		},
+		NetworkClient: &gophercloud.ServiceClient{
+			ProviderClient: provider,
+			Endpoint:       server.URL + "/network/",
+			ResourceBase:   server.URL + "/network/v2.0/",
+		},
+	}
+
+	ok := golden.Check(dataPath, "GetVM", vmHandler.GetVM("6a1c2b3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"))
+
+	vmList, _, err := vmHandler.ListVM(irs.ListReqInfo{})
+	if err != nil {
+		fmt.Printf("[FAIL] ListVM: %v\n", err)
+		ok = false
+	} else {
+		ok = golden.Check(dataPath, "ListVM", vmList) && ok
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
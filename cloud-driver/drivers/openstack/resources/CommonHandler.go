@@ -0,0 +1,214 @@
+// Proof of Concepts of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is common functions of OpenStack resource handlers.
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/rackspace/gophercloud"
+)
+
+// page size of marker based paging when ListReqInfo.PageSize is 0.
+const defaultPageSize = 100
+
+// getLimit returns the limit of marker based paging(servers, images, networks, ports).
+// The continuation token is the ID of the last item of the previous page(marker).
+func getLimit(listReqInfo irs.ListReqInfo) int {
+	if listReqInfo.PageSize <= 0 {
+		return defaultPageSize
+	}
+	return listReqInfo.PageSize
+}
+
+// getMarkerPageInfo returns the next token of marker based paging.
+// A full page means that there can be more items after lastID.
+func getMarkerPageInfo(count int, limit int, lastID string) irs.ListPageInfo {
+	if count < limit {
+		return irs.ListPageInfo{}
+	}
+	return irs.ListPageInfo{NextToken: lastID}
+}
+
+// matchListFilter checks the name/tag filters of listReqInfo on the driver side.
+// Tags of OpenStack resources are kept in the metadata(servers, volumes) or in the string tags(neutron, glance).
+func matchListFilter(listReqInfo irs.ListReqInfo, name string, metadata map[string]string) bool {
+	if listReqInfo.NameFilter != "" && name != listReqInfo.NameFilter {
+		return false
+	}
+	for key, value := range listReqInfo.TagFilter {
+		if metaValue, ok := metadata[key]; !ok || metaValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTagFilter rejects tag filters for resources without metadata nor tags(key pairs, flavors).
+func checkTagFilter(listReqInfo irs.ListReqInfo, resourceName string) error {
+	if len(listReqInfo.TagFilter) != 0 {
+		return errors.New(fmt.Sprintf("tag filter is not supported for OpenStack %s", resourceName))
+	}
+	return nil
+}
+
+// checkTags rejects tags for resources without metadata nor tags(key pairs).
+func checkTags(tags map[string]string, resourceName string) error {
+	if len(tags) != 0 {
+		return errors.New(fmt.Sprintf("tags are not supported for OpenStack %s", resourceName))
+	}
+	return nil
+}
+
+// the max length of a string tag of neutron and glance.
+const maxTagLength = 60
+
+// getTagList converts tags into the string tags of neutron resources and glance images.
+// A tag is kept as "key=value". ex) {"owner": "powerkim"} -> ["owner=powerkim"]
+func getTagList(tags map[string]string) ([]string, error) {
+	var tagList []string
+	for key, value := range tags {
+		if key == "" || strings.Contains(key, "=") {
+			return nil, errors.New(fmt.Sprintf("invalid tag key %q: empty or with \"=\"", key))
+		}
+		tag := key + "=" + value
+		// "," is the separator of the tag filters of neutron.
+		if len(tag) > maxTagLength || strings.ContainsAny(tag, ",/") {
+			return nil, errors.New(fmt.Sprintf("invalid tag %q: longer than %d or with \",\" or \"/\"", tag, maxTagLength))
+		}
+		tagList = append(tagList, tag)
+	}
+	sort.Strings(tagList)
+	return tagList, nil
+}
+
+// getTagMap converts string tags into tags, the value of a tag without "=" is "".
+func getTagMap(tagList []string) map[string]string {
+	tags := make(map[string]string, len(tagList))
+	for _, tag := range tagList {
+		tagArr := strings.SplitN(tag, "=", 2)
+		if len(tagArr) == 2 {
+			tags[tagArr[0]] = tagArr[1]
+		} else {
+			tags[tag] = ""
+		}
+	}
+	return tags
+}
+
+// getBodyTags converts the "tags" of a resource in a response body into tags.
+func getBodyTags(value interface{}) map[string]string {
+	list, _ := value.([]interface{})
+	var tagList []string
+	for _, v := range list {
+		if tag, ok := v.(string); ok {
+			tagList = append(tagList, tag)
+		}
+	}
+	return getTagMap(tagList)
+}
+
+// setNeutronTags replaces all tags of a neutron resource. ex) resourceType: networks, ports, routers, security-groups, floatingips
+func setNeutronTags(client *gophercloud.ServiceClient, resourceType string, resourceID string, tags map[string]string) error {
+	tagList, err := getTagList(tags)
+	if err != nil {
+		return err
+	}
+	if len(tagList) == 0 {
+		return nil
+	}
+	reqBody := map[string]interface{}{"tags": tagList}
+	_, err = client.Put(client.ServiceURL(resourceType, resourceID, "tags"), reqBody, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	})
+	return err
+}
+
+// extractNeutronTags returns the tags in the body of a Get result of a neutron resource. ex) resourceKey: network
+// The tags are not extracted by gophercloud.
+func extractNeutronTags(body interface{}, resourceKey string) map[string]string {
+	bodyMap, _ := body.(map[string]interface{})
+	resource, _ := bodyMap[resourceKey].(map[string]interface{})
+	return getBodyTags(resource["tags"])
+}
+
+// extractNeutronTagMap returns the tags of the resources in the body of a list page by ID. ex) collectionKey: networks
+func extractNeutronTagMap(body interface{}, collectionKey string) map[string]map[string]string {
+	tagMap := map[string]map[string]string{}
+	bodyMap, _ := body.(map[string]interface{})
+	list, _ := bodyMap[collectionKey].([]interface{})
+	for _, item := range list {
+		resource, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := resource["id"].(string)
+		tagMap[id] = getBodyTags(resource["tags"])
+	}
+	return tagMap
+}
+
+// getNeutronTags gets the tags of a neutron resource for the resources got with nova(security groups, floating IPs).
+// The IDs of the resources are the same in nova and neutron. ex) resourceType: security-groups, resourceKey: security_group
+func getNeutronTags(client *gophercloud.ServiceClient, resourceType string, resourceKey string, resourceID string) (map[string]string, error) {
+	var body interface{}
+	_, err := client.Get(client.ServiceURL(resourceType, resourceID), &body, nil)
+	if err != nil {
+		return nil, err
+	}
+	return extractNeutronTags(body, resourceKey), nil
+}
+
+// listNeutronTagMap gets the tags of all neutron resources of a type by ID. ex) collectionKey: security_groups
+func listNeutronTagMap(client *gophercloud.ServiceClient, resourceType string, collectionKey string) (map[string]map[string]string, error) {
+	var body interface{}
+	_, err := client.Get(client.ServiceURL(resourceType)+"?fields=id&fields=tags", &body, nil)
+	if err != nil {
+		return nil, err
+	}
+	return extractNeutronTagMap(body, collectionKey), nil
+}
+
+// getServerMetadata converts the metadata of a server(map[string]interface{}) into string values.
+func getServerMetadata(metadata map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		result[key] = fmt.Sprint(value)
+	}
+	return result
+}
+
+// time layout of cinder without the timezone(UTC). ex) created_at of a volume
+const cinderTimeLayout = "2006-01-02T15:04:05.999999"
+
+// getTime parses a RFC3339 time string of OpenStack(ex: Created of an image) into the local time.
+// The zero time is returned for an empty or unknown string.
+func getTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse(cinderTimeLayout, value)
+		if err != nil {
+			return time.Time{}
+		}
+	}
+	return t.Local()
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack/blockstorage/v1/volumes"
+	"github.com/rackspace/gophercloud/openstack/compute/v2/extensions/volumeattach"
+	"github.com/rackspace/gophercloud/openstack/compute/v2/servers"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+// seconds to wait for the status of a volume.
+const volumeWaitSeconds = 300
+
+// OpenStackDiskHandler handles cinder volumes with the volume client,
+// volumes are attached to servers with the compute client(os-volume_attachments).
+type OpenStackDiskHandler struct {
+	Client       *gophercloud.ServiceClient
+	VolumeClient *gophercloud.ServiceClient
+}
+
+// mappingDiskInfo maps a cinder volume into irs.DiskInfo, Tags are kept in the metadata of the volume.
+func mappingDiskInfo(volume volumes.Volume) irs.DiskInfo {
+	diskInfo := irs.DiskInfo{
+		Name:        volume.Name,
+		Id:          volume.ID,
+		Tags:        volume.Metadata,
+		SizeGiB:     volume.Size,
+		DiskType:    volume.VolumeType,
+		Zone:        volume.AvailabilityZone,
+		Status:      volume.Status,
+		CreatedTime: getTime(volume.CreatedAt),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Bootable", Value: volume.Bootable},
+			{Key: "SnapshotId", Value: volume.SnapshotID},
+		},
+	}
+	if len(volume.Attachments) != 0 {
+		diskInfo.VMId = fmt.Sprint(volume.Attachments[0]["server_id"])
+		diskInfo.Device = fmt.Sprint(volume.Attachments[0]["device"])
+	}
+	return diskInfo
+}
+
+func (diskHandler *OpenStackDiskHandler) CreateDisk(diskReqInfo irs.DiskReqInfo) (irs.DiskInfo, error) {
+	if err := irs.CheckDiskReqInfo(diskReqInfo); err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	createOpts := volumes.CreateOpts{
+		Name:         diskReqInfo.Name,
+		Size:         diskReqInfo.SizeGiB,
+		VolumeType:   diskReqInfo.DiskType,
+		Availability: diskReqInfo.Zone,
+		Metadata:     diskReqInfo.Tags,
+	}
+	volume, err := volumes.Create(diskHandler.VolumeClient, createOpts).Extract()
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	err = volumes.WaitForStatus(diskHandler.VolumeClient, volume.ID, "available", volumeWaitSeconds)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(volume.ID)
+}
+
+// cinder v1 lists all volumes at once, so the page is cut on the driver side.
+func (diskHandler *OpenStackDiskHandler) ListDisk(listReqInfo irs.ListReqInfo) ([]*irs.DiskInfo, irs.ListPageInfo, error) {
+	var volumeList []volumes.Volume
+
+	listOpts := volumes.ListOpts{
+		Name:     listReqInfo.NameFilter,
+		Metadata: listReqInfo.TagFilter,
+	}
+	pager := volumes.List(diskHandler.VolumeClient, listOpts)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := volumes.ExtractVolumes(page)
+		if err != nil {
+			return false, err
+		}
+		for _, v := range list {
+			if matchListFilter(listReqInfo, v.Name, v.Metadata) {
+				volumeList = append(volumeList, v)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	start, end, nextToken, err := irs.GetPageRange(len(volumeList), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var diskList []*irs.DiskInfo
+	for _, v := range volumeList[start:end] {
+		diskInfo := mappingDiskInfo(v)
+		diskList = append(diskList, &diskInfo)
+	}
+	return diskList, irs.ListPageInfo{NextToken: nextToken}, nil
+}
+
+func (diskHandler *OpenStackDiskHandler) GetDisk(diskID string) (irs.DiskInfo, error) {
+	volume, err := volumes.Get(diskHandler.VolumeClient, diskID).Extract()
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return mappingDiskInfo(*volume), nil
+}
+
+// gophercloud has no extend API of volumes, so the os-extend action is requested directly.
+// Only volumes not attached(available) can be extended by most cinder backends.
+func (diskHandler *OpenStackDiskHandler) ResizeDisk(diskID string, sizeGiB int) (irs.DiskInfo, error) {
+	volume, err := volumes.Get(diskHandler.VolumeClient, diskID).Extract()
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	if sizeGiB <= volume.Size {
+		return irs.DiskInfo{}, errors.New(fmt.Sprintf("volume %s can only grow, current: %d GiB, requested: %d GiB", diskID, volume.Size, sizeGiB))
+	}
+
+	reqBody := map[string]interface{}{
+		"os-extend": map[string]interface{}{
+			"new_size": sizeGiB,
+		},
+	}
+	_, err = diskHandler.VolumeClient.Post(diskHandler.VolumeClient.ServiceURL("volumes", diskID, "action"), reqBody, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{202},
+	})
+	if err != nil {
+		return irs.DiskInfo{}, errors.New(fmt.Sprintf("unable to extend volume %s, %v", diskID, err))
+	}
+
+	// the volume keeps its status until cinder starts extending it, so the new size is waited too.
+	originalStatus := volume.Status
+	err = gophercloud.WaitFor(volumeWaitSeconds, func() (bool, error) {
+		current, err := volumes.Get(diskHandler.VolumeClient, diskID).Extract()
+		if err != nil {
+			return false, err
+		}
+		if current.Status == "error_extending" {
+			return false, errors.New(fmt.Sprintf("failed to extend volume %s to %d GiB", diskID, sizeGiB))
+		}
+		return current.Size == sizeGiB && current.Status == originalStatus, nil
+	})
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(diskID)
+}
+
+func (diskHandler *OpenStackDiskHandler) DeleteDisk(diskID string) (bool, error) {
+	err := volumes.Delete(diskHandler.VolumeClient, diskID).ExtractErr()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// The device name is chosen by nova.
+func (diskHandler *OpenStackDiskHandler) AttachDisk(diskID string, vmID string) (irs.DiskInfo, error) {
+	createOpts := volumeattach.CreateOpts{
+		VolumeID: diskID,
+	}
+	_, err := volumeattach.Create(diskHandler.Client, vmID, createOpts).Extract()
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	err = volumes.WaitForStatus(diskHandler.VolumeClient, diskID, "in-use", volumeWaitSeconds)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(diskID)
+}
+
+// The ID of the attachment of a volume is the ID of the volume.
+func (diskHandler *OpenStackDiskHandler) DetachDisk(diskID string, vmID string) (irs.DiskInfo, error) {
+	err := volumeattach.Delete(diskHandler.Client, vmID, diskID).ExtractErr()
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	err = volumes.WaitForStatus(diskHandler.VolumeClient, diskID, "available", volumeWaitSeconds)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	return diskHandler.GetDisk(diskID)
+}
+
+// getBlockDevices returns the block devices of a server for the root disk size and the data disks of vmReqInfo.
+// The root disk of a given size is a volume from the image, data disks are blank volumes.
+// All of them are deleted with the server, the name, type and tags of data disks are not supported.
+func getBlockDevices(vmReqInfo irs.VMReqInfo) ([]blockDevice, error) {
+	var blockDevices []blockDevice
+
+	if vmReqInfo.RootDiskSizeGiB > 0 {
+		blockDevices = append(blockDevices, blockDevice{
+			SourceType:          "image",
+			UUID:                vmReqInfo.ImageInfo.Id,
+			DestinationType:     "volume",
+			VolumeSize:          vmReqInfo.RootDiskSizeGiB,
+			BootIndex:           0,
+			DeleteOnTermination: true,
+		})
+	}
+	for _, diskReqInfo := range vmReqInfo.DataDisks {
+		if err := irs.CheckDiskReqInfo(diskReqInfo); err != nil {
+			return nil, err
+		}
+		if diskReqInfo.DiskType != "" || len(diskReqInfo.Tags) != 0 {
+			return nil, errors.New("the type and tags of data disks at launch are not supported for OpenStack, create the disk with DiskHandler: " + diskReqInfo.Name)
+		}
+		blockDevices = append(blockDevices, blockDevice{
+			SourceType:          "blank",
+			DestinationType:     "volume",
+			VolumeSize:          diskReqInfo.SizeGiB,
+			BootIndex:           -1,
+			DeleteOnTermination: true,
+		})
+	}
+	return blockDevices, nil
+}
+
+// blockDevice is an item of block_device_mapping_v2.
+// bootfromvolume of gophercloud always sends boot_index, and has no blank source type.
+type blockDevice struct {
+	SourceType          string
+	UUID                string
+	DestinationType     string
+	VolumeSize          int
+	BootIndex           int
+	DeleteOnTermination bool
+}
+
+// blockDeviceCreateOpts adds block_device_mapping_v2 to the server create options.
+type blockDeviceCreateOpts struct {
+	CreateOptsBuilder servers.CreateOptsBuilder
+	BlockDevices      []blockDevice
+}
+
+func (opts blockDeviceCreateOpts) ToServerCreateMap() (map[string]interface{}, error) {
+	base, err := opts.CreateOptsBuilder.ToServerCreateMap()
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.BlockDevices) == 0 {
+		return base, nil
+	}
+
+	serverMap := base["server"].(map[string]interface{})
+	var mappings []map[string]interface{}
+	for _, device := range opts.BlockDevices {
+		mapping := map[string]interface{}{
+			"source_type":           device.SourceType,
+			"destination_type":      device.DestinationType,
+			"volume_size":           device.VolumeSize,
+			"delete_on_termination": device.DeleteOnTermination,
+		}
+		if device.UUID != "" {
+			mapping["uuid"] = device.UUID
+		}
+		if device.BootIndex >= 0 {
+			mapping["boot_index"] = strconv.Itoa(device.BootIndex)
+		}
+		mappings = append(mappings, mapping)
+	}
+	serverMap["block_device_mapping_v2"] = mappings
+	return base, nil
+}
@@ -6,47 +6,87 @@ import (
 	"fmt"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
+	"github.com/mitchellh/mapstructure"
 	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack/blockstorage/v1/volumes"
 	"github.com/rackspace/gophercloud/openstack/compute/v2/images"
 	imgsvc "github.com/rackspace/gophercloud/openstack/imageservice/v2/images"
 	"github.com/rackspace/gophercloud/pagination"
 	"io/ioutil"
 	"os"
+	"strconv"
 )
 
 type OpenStackImageHandler struct {
-	Client      *gophercloud.ServiceClient
-	ImageClient *gophercloud.ServiceClient
+	Client       *gophercloud.ServiceClient
+	ImageClient  *gophercloud.ServiceClient
+	VolumeClient *gophercloud.ServiceClient
 }
 
-// @TODO: ImageInfo 리소스 프로퍼티 정의 필요
-type ImageInfo struct {
-	ID       string
-	Created  string
-	MinDisk  int
-	MinRAM   int
-	Name     string
-	Progress int
-	Status   string
-	Updated  string
-	Metadata map[string]string
+// mappingImageInfo maps a nova image and its glance tags into irs.ImageInfo.
+// The OS type is the "os_type" property of the image if it is set.
+func mappingImageInfo(image images.Image, tags map[string]string) irs.ImageInfo {
+	return irs.ImageInfo{
+		Id:          image.ID,
+		Name:        image.Name,
+		Tags:        tags,
+		OSType:      image.Metadata["os_type"],
+		Status:      image.Status,
+		CreatedTime: getTime(image.Created),
+		KeyValueList: []irs.KeyValue{
+			{Key: "MinDisk", Value: strconv.Itoa(image.MinDisk)},
+			{Key: "MinRAM", Value: strconv.Itoa(image.MinRAM)},
+			{Key: "Progress", Value: strconv.Itoa(image.Progress)},
+			{Key: "Updated", Value: image.Updated},
+		},
+	}
+}
+
+// setImageTags adds the tags to a glance image as "key=value" string tags.
+func (imageHandler *OpenStackImageHandler) setImageTags(imageID string, tags map[string]string) error {
+	tagList, err := getTagList(tags)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tagList {
+		_, err := imageHandler.ImageClient.Put(imageHandler.ImageClient.ServiceURL("images", imageID, "tags", tag), nil, nil, &gophercloud.RequestOpts{
+			OkCodes: []int{204},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (imageInfo *ImageInfo) setter(results images.Image) *ImageInfo {
-	imageInfo.ID = results.ID
-	imageInfo.Created = results.Created
-	imageInfo.MinDisk = results.MinDisk
-	imageInfo.MinRAM = results.MinRAM
-	imageInfo.Name = results.Name
-	imageInfo.Progress = results.Progress
-	imageInfo.Status = results.Status
-	imageInfo.Updated = results.Updated
-	imageInfo.Metadata = results.Metadata
-
-	return imageInfo
+// listImageTagMap gets the glance tags of all images by ID, the nova images have no tags.
+func (imageHandler *OpenStackImageHandler) listImageTagMap() (map[string]map[string]string, error) {
+	tagMap := map[string]map[string]string{}
+	pager := imgsvc.List(imageHandler.ImageClient, imgsvc.ListOpts{})
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := imgsvc.ExtractImages(page)
+		if err != nil {
+			return false, err
+		}
+		for _, img := range list {
+			tagMap[img.ID] = getTagMap(img.Tags)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tagMap, nil
 }
 
 func (imageHandler *OpenStackImageHandler) CreateImage(imageReqInfo irs.ImageReqInfo) (irs.ImageInfo, error) {
+	tagList, err := getTagList(imageReqInfo.Tags)
+	if err != nil {
+		return irs.ImageInfo{}, err
+	}
+	if imageReqInfo.SnapshotId != "" {
+		return imageHandler.createImageFromSnapshot(imageReqInfo)
+	}
 
 	// @TODO: Image 생성 요청 파라미터 정의 필요
 	type ImageReqInfo struct {
@@ -64,6 +104,7 @@ func (imageHandler *OpenStackImageHandler) CreateImage(imageReqInfo irs.ImageReq
 		Name:            reqInfo.Name,
 		ContainerFormat: reqInfo.ContainerFormat,
 		DiskFormat:      reqInfo.DiskFormat,
+		Tags:            tagList, // Tags are kept in the glance tags of the image.
 	}
 
 	rootPath := os.Getenv("CBSPIDER_PATH")
@@ -97,33 +138,101 @@ func (imageHandler *OpenStackImageHandler) CreateImage(imageReqInfo irs.ImageReq
 	imageInfo := irs.ImageInfo{
 		Id:   image.ID,
 		Name: image.Name,
+		Tags: imageReqInfo.Tags,
 	}
 	return imageInfo, nil
 }
 
-func (imageHandler *OpenStackImageHandler) ListImage() ([]*irs.ImageInfo, error) {
-	var imageList []*ImageInfo
+// createImageFromSnapshot uploads a temporary volume from the snapshot to a qcow2 image(os-volume_upload_image),
+// the temporary volume is deleted after the upload. The tags are added to the uploaded image.
+func (imageHandler *OpenStackImageHandler) createImageFromSnapshot(imageReqInfo irs.ImageReqInfo) (irs.ImageInfo, error) {
+	snapshotHandler := OpenStackSnapshotHandler{imageHandler.Client, imageHandler.VolumeClient}
+	diskInfo, err := snapshotHandler.RestoreSnapshot(imageReqInfo.SnapshotId, irs.DiskReqInfo{Name: imageReqInfo.Name + "-upload"})
+	if err != nil {
+		return irs.ImageInfo{}, err
+	}
+	defer func() {
+		if deleteErr := volumes.Delete(imageHandler.VolumeClient, diskInfo.Id).ExtractErr(); deleteErr != nil {
+			fmt.Println("failed to delete the temporary volume", diskInfo.Id, deleteErr)
+		}
+	}()
 
-	pager := images.ListDetail(imageHandler.Client, images.ListOpts{})
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+	reqBody := map[string]interface{}{
+		"os-volume_upload_image": map[string]interface{}{
+			"image_name":       imageReqInfo.Name,
+			"disk_format":      "qcow2",
+			"container_format": "bare",
+			"force":            true,
+		},
+	}
+	var body interface{}
+	_, err = imageHandler.VolumeClient.Post(imageHandler.VolumeClient.ServiceURL("volumes", diskInfo.Id, "action"), reqBody, &body, &gophercloud.RequestOpts{
+		OkCodes: []int{202},
+	})
+	if err != nil {
+		return irs.ImageInfo{}, errors.New(fmt.Sprintf("unable to upload volume %s to image %s, %v", diskInfo.Id, imageReqInfo.Name, err))
+	}
+	var result struct {
+		UploadImage struct {
+			ImageID string `mapstructure:"image_id"`
+		} `mapstructure:"os-volume_upload_image"`
+	}
+	if err := mapstructure.Decode(body, &result); err != nil {
+		return irs.ImageInfo{}, err
+	}
+
+	// the volume is available again when the upload is finished.
+	err = volumes.WaitForStatus(imageHandler.VolumeClient, diskInfo.Id, "available", volumeWaitSeconds)
+	if err != nil {
+		return irs.ImageInfo{}, err
+	}
+	if err := imageHandler.setImageTags(result.UploadImage.ImageID, imageReqInfo.Tags); err != nil {
+		return irs.ImageInfo{}, errors.New(fmt.Sprintf("unable to add the tags to image %s, %v", result.UploadImage.ImageID, err))
+	}
+	return imageHandler.GetImage(result.UploadImage.ImageID)
+}
+
+func (imageHandler *OpenStackImageHandler) ListImage(listReqInfo irs.ListReqInfo) ([]*irs.ImageInfo, irs.ListPageInfo, error) {
+	tagMap, err := imageHandler.listImageTagMap()
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var imageList []*irs.ImageInfo
+
+	listOpts := images.ListOpts{
+		Name:   listReqInfo.NameFilter,
+		Limit:  getLimit(listReqInfo),
+		Marker: listReqInfo.NextToken,
+	}
+
+	var pageInfo irs.ListPageInfo
+	pager := images.ListDetail(imageHandler.Client, listOpts)
+	err = pager.EachPage(func(page pagination.Page) (bool, error) {
 		// Get Image
 		list, err := images.ExtractImages(page)
 		if err != nil {
 			return false, err
 		}
+		if len(list) != 0 {
+			pageInfo = getMarkerPageInfo(len(list), listOpts.Limit, list[len(list)-1].ID)
+		}
 		// Add to List
 		for _, img := range list {
-			imageInfo := new(ImageInfo).setter(img)
-			imageList = append(imageList, imageInfo)
+			if !matchListFilter(listReqInfo, img.Name, tagMap[img.ID]) {
+				continue
+			}
+			imageInfo := mappingImageInfo(img, tagMap[img.ID])
+			imageList = append(imageList, &imageInfo)
 		}
-		return true, nil
+		// only the first page, the next page starts from the marker.
+		return false, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
 	}
 
-	spew.Dump(imageList)
-	return nil, nil
+	return imageList, pageInfo, nil
 }
 
 func (imageHandler *OpenStackImageHandler) GetImage(imageID string) (irs.ImageInfo, error) {
@@ -132,10 +241,15 @@ func (imageHandler *OpenStackImageHandler) GetImage(imageID string) (irs.ImageIn
 		return irs.ImageInfo{}, err
 	}
 
-	imageInfo := new(ImageInfo).setter(*image)
+	glanceImage, err := imgsvc.Get(imageHandler.ImageClient, imageID).Extract()
+	if err != nil {
+		return irs.ImageInfo{}, err
+	}
+
+	imageInfo := mappingImageInfo(*image, getTagMap(glanceImage.Tags))
 
 	spew.Dump(imageInfo)
-	return irs.ImageInfo{}, nil
+	return imageInfo, nil
 }
 
 func (imageHandler *OpenStackImageHandler) DeleteImage(imageID string) (bool, error) {
@@ -12,41 +12,46 @@ type OpenStackKeyPairHandler struct {
 	Client *gophercloud.ServiceClient
 }
 
-// @TODO: KeyPairInfo 리소스 프로퍼티 정의 필요
-type KeyPairInfo struct {
-	Name        string
-	Fingerprint string
-	PublicKey   string
-	PrivateKey  string
-	UserID      string
-}
-
-func (keyPairInfo *KeyPairInfo) setter(keypair keypairs.KeyPair) *KeyPairInfo {
-	keyPairInfo.Name = keypair.Name
-	keyPairInfo.Fingerprint = keypair.Fingerprint
-	keyPairInfo.PublicKey = keypair.PublicKey
-	keyPairInfo.PrivateKey = keypair.PrivateKey
-	keyPairInfo.UserID = keypair.UserID
-
-	return keyPairInfo
+// mappingKeyPairInfo maps a nova key pair into irs.KeyPairInfo.
+// The ID of a nova key pair is its name.
+func mappingKeyPairInfo(keypair keypairs.KeyPair) irs.KeyPairInfo {
+	return irs.KeyPairInfo{
+		Id:          keypair.Name,
+		Name:        keypair.Name,
+		PublicKey:   keypair.PublicKey,
+		Fingerprint: keypair.Fingerprint,
+		Owner:       keypair.UserID,
+	}
 }
 
+// Nova imports the PublicKey of keyPairReqInfo, or generates a new key pair and returns its private key only once.
 func (keyPairHandler *OpenStackKeyPairHandler) CreateKey(keyPairReqInfo irs.KeyPairReqInfo) (irs.KeyPairInfo, error) {
 
+	if err := checkTags(keyPairReqInfo.Tags, "key pair"); err != nil {
+		return irs.KeyPairInfo{}, err
+	}
+
 	create0pts := keypairs.CreateOpts{
-		Name: keyPairReqInfo.Name,
+		Name:      keyPairReqInfo.Name,
+		PublicKey: keyPairReqInfo.PublicKey,
 	}
-	keyPairInfo, err := keypairs.Create(keyPairHandler.Client, create0pts).Extract()
+	keyPair, err := keypairs.Create(keyPairHandler.Client, create0pts).Extract()
 	if err != nil {
 		return irs.KeyPairInfo{}, err
 	}
 
-	spew.Dump(keyPairInfo)
-	return irs.KeyPairInfo{Name: keyPairInfo.Name}, nil
+	keyPairInfo := mappingKeyPairInfo(*keyPair)
+	keyPairInfo.PrivateKey = keyPair.PrivateKey
+	return keyPairInfo, nil
 }
 
-func (keyPairHandler *OpenStackKeyPairHandler) ListKey() ([]*irs.KeyPairInfo, error) {
-	var keyPairList []*KeyPairInfo
+// key pairs have no paging, so pages are cut from the filtered result.
+func (keyPairHandler *OpenStackKeyPairHandler) ListKey(listReqInfo irs.ListReqInfo) ([]*irs.KeyPairInfo, irs.ListPageInfo, error) {
+	if err := checkTagFilter(listReqInfo, "key pair"); err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var keyPairList []*irs.KeyPairInfo
 
 	pager := keypairs.List(keyPairHandler.Client)
 	err := pager.EachPage(func(page pagination.Page) (bool, error) {
@@ -57,29 +62,35 @@ func (keyPairHandler *OpenStackKeyPairHandler) ListKey() ([]*irs.KeyPairInfo, er
 		}
 		// Add to List
 		for _, k := range list {
-			keyPairInfo := new(KeyPairInfo).setter(k)
-			keyPairList = append(keyPairList, keyPairInfo)
+			if !matchListFilter(listReqInfo, k.Name, nil) {
+				continue
+			}
+			keyPairInfo := mappingKeyPairInfo(k)
+			keyPairList = append(keyPairList, &keyPairInfo)
 		}
 		return true, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
 	}
 
-	spew.Dump(keyPairList)
-	return nil, nil
+	start, end, nextToken, err := irs.GetPageRange(len(keyPairList), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	return keyPairList[start:end], irs.ListPageInfo{NextToken: nextToken}, nil
 }
 
 func (keyPairHandler *OpenStackKeyPairHandler) GetKey(keyPairID string) (irs.KeyPairInfo, error) {
 	keyPair, err := keypairs.Get(keyPairHandler.Client, keyPairID).Extract()
 	if err != nil {
-		return irs.KeyPairInfo{}, nil
+		return irs.KeyPairInfo{}, err
 	}
 
-	keyPairInfo := new(KeyPairInfo).setter(*keyPair)
+	keyPairInfo := mappingKeyPairInfo(*keyPair)
 
 	spew.Dump(keyPairInfo)
-	return irs.KeyPairInfo{}, nil
+	return keyPairInfo, nil
 }
 
 func (keyPairHandler *OpenStackKeyPairHandler) DeleteKey(keyPairID string) (bool, error) {
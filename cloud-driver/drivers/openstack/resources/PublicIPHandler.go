@@ -1,63 +1,94 @@
 package resources
 
 import (
+	"errors"
+	"fmt"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/rackspace/gophercloud"
 	"github.com/rackspace/gophercloud/openstack/compute/v2/extensions/floatingip"
+	"github.com/rackspace/gophercloud/openstack/compute/v2/servers"
+	"github.com/rackspace/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/rackspace/gophercloud/openstack/networking/v2/ports"
 	"github.com/rackspace/gophercloud/pagination"
 )
 
 type OpenStackPublicIPHandler struct {
-	Client *gophercloud.ServiceClient
+	Client        *gophercloud.ServiceClient
+	NetworkClient *gophercloud.ServiceClient
 }
 
-// @TODO: PublicIP 리소스 프로퍼티 정의 필요
-type PublicIPInfo struct {
-	ID         string
-	FixedIP    string
-	InstanceID string
-	IP         string
-	Pool       string
-}
-
-func (publicIPInfo *PublicIPInfo) setter(floatingIp floatingip.FloatingIP) *PublicIPInfo {
-	publicIPInfo.ID = floatingIp.ID
-	publicIPInfo.FixedIP = floatingIp.FixedIP
-	publicIPInfo.InstanceID = floatingIp.InstanceID
-	publicIPInfo.IP = floatingIp.IP
-	publicIPInfo.Pool = floatingIp.Pool
+// mappingPublicIPInfo maps a floating IP into irs.PublicIPInfo.
+// A floating IP has no name, so the name is its address.
+func mappingPublicIPInfo(floatingIp floatingip.FloatingIP, tags map[string]string) irs.PublicIPInfo {
+	status := "available"
+	if floatingIp.InstanceID != "" {
+		status = "associated"
+	}
 
-	return publicIPInfo
+	return irs.PublicIPInfo{
+		Id:        floatingIp.ID,
+		Name:      floatingIp.IP,
+		PublicIP:  floatingIp.IP,
+		Status:    status,
+		OwnedVMId: floatingIp.InstanceID,
+		Tags:      tags,
+		KeyValueList: []irs.KeyValue{
+			{Key: "Pool", Value: floatingIp.Pool},
+			{Key: "FixedIP", Value: floatingIp.FixedIP},
+		},
+	}
 }
 
 func (publicIPHandler *OpenStackPublicIPHandler) CreatePublicIP(publicIPReqInfo irs.PublicIPReqInfo) (irs.PublicIPInfo, error) {
 
-	// @TODO: PublicIP 생성 요청 파라미터 정의 필요
-	type PublicIPReqInfo struct {
-		Pool string
+	if _, err := getTagList(publicIPReqInfo.Tags); err != nil {
+		return irs.PublicIPInfo{}, err
+	}
+
+	if publicIPReqInfo.Sku != "" {
+		return irs.PublicIPInfo{}, errors.New("the SKU of a floating IP is not supported for OpenStack")
 	}
-	reqInfo := PublicIPReqInfo{
-		Pool: "public1", // Floating IP가 할당되는 IP Pool 정보
+
+	// Floating IP가 할당되는 IP Pool(외부 네트워크의 이름), 없으면 첫번째 외부 네트워크
+	pool := publicIPReqInfo.Pool
+	if pool == "" {
+		externalNetwork, err := getExternalNetwork(publicIPHandler.NetworkClient)
+		if err != nil {
+			return irs.PublicIPInfo{}, err
+		}
+		pool = externalNetwork.Name
 	}
 
 	createOpts := floatingip.CreateOpts{
-		Pool: reqInfo.Pool,
+		Pool: pool,
 	}
 	publicIPInfo, err := floatingip.Create(publicIPHandler.Client, createOpts).Extract()
 	if err != nil {
 		return irs.PublicIPInfo{}, err
 	}
+	// the tags are the neutron tags of the floating IP, the floating IP without them is released.
+	err = setNeutronTags(publicIPHandler.NetworkClient, "floatingips", publicIPInfo.ID, publicIPReqInfo.Tags)
+	if err != nil {
+		floatingip.Delete(publicIPHandler.Client, publicIPInfo.ID)
+		return irs.PublicIPInfo{}, err
+	}
 
 	spew.Dump(publicIPInfo)
-	return irs.PublicIPInfo{Id: publicIPInfo.ID}, nil
+	return mappingPublicIPInfo(*publicIPInfo, publicIPReqInfo.Tags), nil
 }
 
-func (publicIPHandler *OpenStackPublicIPHandler) ListPublicIP() ([]*irs.PublicIPInfo, error) {
-	var publicIPList []*PublicIPInfo
+// floating IPs have no paging, so pages are cut from the filtered result.
+func (publicIPHandler *OpenStackPublicIPHandler) ListPublicIP(listReqInfo irs.ListReqInfo) ([]*irs.PublicIPInfo, irs.ListPageInfo, error) {
+	tagMap, err := listNeutronTagMap(publicIPHandler.NetworkClient, "floatingips", "floatingips")
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var publicIPList []*irs.PublicIPInfo
 
 	pager := floatingip.List(publicIPHandler.Client)
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+	err = pager.EachPage(func(page pagination.Page) (bool, error) {
 		// Get PublicIP
 		list, err := floatingip.ExtractFloatingIPs(page)
 		if err != nil {
@@ -65,17 +96,23 @@ func (publicIPHandler *OpenStackPublicIPHandler) ListPublicIP() ([]*irs.PublicIP
 		}
 		// Add to List
 		for _, p := range list {
-			publicIPInfo := new(PublicIPInfo).setter(p)
-			publicIPList = append(publicIPList, publicIPInfo)
+			if !matchListFilter(listReqInfo, p.IP, tagMap[p.ID]) {
+				continue
+			}
+			publicIPInfo := mappingPublicIPInfo(p, tagMap[p.ID])
+			publicIPList = append(publicIPList, &publicIPInfo)
 		}
 		return true, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
 	}
 
-	spew.Dump(publicIPList)
-	return nil, nil
+	start, end, nextToken, err := irs.GetPageRange(len(publicIPList), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	return publicIPList[start:end], irs.ListPageInfo{NextToken: nextToken}, nil
 }
 
 func (publicIPHandler *OpenStackPublicIPHandler) GetPublicIP(publicIPID string) (irs.PublicIPInfo, error) {
@@ -84,10 +121,15 @@ func (publicIPHandler *OpenStackPublicIPHandler) GetPublicIP(publicIPID string)
 		return irs.PublicIPInfo{}, err
 	}
 
-	publicIPInfo := new(PublicIPInfo).setter(*floatingIP)
+	tags, err := getNeutronTags(publicIPHandler.NetworkClient, "floatingips", "floatingip", publicIPID)
+	if err != nil {
+		return irs.PublicIPInfo{}, err
+	}
+
+	publicIPInfo := mappingPublicIPInfo(*floatingIP, tags)
 
 	spew.Dump(publicIPInfo)
-	return irs.PublicIPInfo{Id: publicIPInfo.IP}, nil
+	return publicIPInfo, nil
 }
 
 func (publicIPHandler *OpenStackPublicIPHandler) DeletePublicIP(publicIPID string) (bool, error) {
@@ -98,12 +140,57 @@ func (publicIPHandler *OpenStackPublicIPHandler) DeletePublicIP(publicIPID strin
 	return true, nil
 }
 
-func (publicIPHandler *OpenStackPublicIPHandler) AssociatePublicIP(serverID string, publicIPID string) (bool, error) {
-	associateOpts := floatingip.AssociateOpts{
-		ServerID:   serverID,
-		FloatingIP: publicIPID,
+// AssociatePublicIP associates a floating IP with a server(its first fixed IP) or a port.
+func (publicIPHandler *OpenStackPublicIPHandler) AssociatePublicIP(publicIPID string, vmOrVNicID string) (bool, error) {
+	floatingIP, err := floatingip.Get(publicIPHandler.Client, publicIPID).Extract()
+	if err != nil {
+		return false, err
+	}
+	if floatingIP.InstanceID != "" || floatingIP.FixedIP != "" {
+		return false, errors.New(fmt.Sprintf("floating IP %s is already associated with %s", floatingIP.IP, floatingIP.FixedIP))
+	}
+
+	// Server
+	_, err = servers.Get(publicIPHandler.Client, vmOrVNicID).Extract()
+	if err == nil {
+		associateOpts := floatingip.AssociateOpts{
+			ServerID:   vmOrVNicID,
+			FloatingIP: floatingIP.IP,
+		}
+		err = floatingip.AssociateInstance(publicIPHandler.Client, associateOpts).ExtractErr()
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	// Port
+	_, portErr := ports.Get(publicIPHandler.NetworkClient, vmOrVNicID).Extract()
+	if portErr != nil {
+		return false, errors.New(fmt.Sprintf("no server or port %s: %v, %v", vmOrVNicID, err, portErr))
+	}
+	updateOpts := floatingips.UpdateOpts{
+		PortID: vmOrVNicID,
 	}
-	err := floatingip.AssociateInstance(publicIPHandler.Client, associateOpts).ExtractErr()
+	_, err = floatingips.Update(publicIPHandler.NetworkClient, publicIPID, updateOpts).Extract()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DisassociatePublicIP removes the port of a floating IP, the IDs of floating IPs are the same in nova and neutron.
+func (publicIPHandler *OpenStackPublicIPHandler) DisassociatePublicIP(publicIPID string) (bool, error) {
+	floatingIP, err := floatingips.Get(publicIPHandler.NetworkClient, publicIPID).Extract()
+	if err != nil {
+		return false, err
+	}
+	if floatingIP.PortID == "" {
+		return false, errors.New("floating IP is not associated: " + floatingIP.FloatingIP)
+	}
+
+	// an empty PortID is sent as null.
+	_, err = floatingips.Update(publicIPHandler.NetworkClient, publicIPID, floatingips.UpdateOpts{}).Extract()
 	if err != nil {
 		return false, err
 	}
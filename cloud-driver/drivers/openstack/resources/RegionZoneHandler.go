@@ -0,0 +1,136 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack"
+)
+
+// gophercloud has no APIs of keystone regions and nova availability zones,
+// so they are requested directly with the clients.
+type OpenStackRegionZoneHandler struct {
+	Client *gophercloud.ServiceClient
+}
+
+type keystoneRegion struct {
+	ID             string `mapstructure:"id"`
+	Description    string `mapstructure:"description"`
+	ParentRegionID string `mapstructure:"parent_region_id"`
+}
+
+type novaAvailabilityZone struct {
+	ZoneName  string `mapstructure:"zoneName"`
+	ZoneState struct {
+		Available bool `mapstructure:"available"`
+	} `mapstructure:"zoneState"`
+}
+
+// mappingRegionZoneInfo maps a keystone region and the nova availability zones in it into irs.RegionZoneInfo.
+// A region without the compute service can not run VMs, so it is unavailable.
+func mappingRegionZoneInfo(region keystoneRegion, zones []novaAvailabilityZone, hasCompute bool) irs.RegionZoneInfo {
+	regionZoneInfo := irs.RegionZoneInfo{
+		Name:        region.ID,
+		DisplayName: region.Description,
+		Status:      irs.RegionZoneAvailable,
+		KeyValueList: []irs.KeyValue{
+			{Key: "ParentRegionId", Value: region.ParentRegionID},
+		},
+	}
+	if !hasCompute {
+		regionZoneInfo.Status = irs.RegionZoneUnavailable
+	}
+	for _, zone := range zones {
+		zoneInfo := irs.ZoneInfo{
+			Name:   zone.ZoneName,
+			Status: irs.RegionZoneUnavailable,
+		}
+		if zone.ZoneState.Available {
+			zoneInfo.Status = irs.RegionZoneAvailable
+		}
+		regionZoneInfo.ZoneList = append(regionZoneInfo.ZoneList, zoneInfo)
+	}
+	return regionZoneInfo
+}
+
+func (regionZoneHandler *OpenStackRegionZoneHandler) ListRegionZone() ([]*irs.RegionZoneInfo, error) {
+	var regionZoneList []*irs.RegionZoneInfo
+
+	identityClient := openstack.NewIdentityV3(regionZoneHandler.Client.ProviderClient)
+	var body interface{}
+	_, err := identityClient.Get(identityClient.ServiceURL("regions"), &body, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Regions []keystoneRegion `mapstructure:"regions"`
+	}
+	if err := mapstructure.Decode(body, &result); err != nil {
+		return nil, err
+	}
+
+	for _, region := range result.Regions {
+		regionZoneInfo, err := regionZoneHandler.getRegionZone(region)
+		if err != nil {
+			return nil, err
+		}
+		regionZoneList = append(regionZoneList, &regionZoneInfo)
+	}
+	return regionZoneList, nil
+}
+
+func (regionZoneHandler *OpenStackRegionZoneHandler) GetRegionZone(regionName string) (irs.RegionZoneInfo, error) {
+	if regionName == "" {
+		return irs.RegionZoneInfo{}, errors.New("no region name")
+	}
+
+	identityClient := openstack.NewIdentityV3(regionZoneHandler.Client.ProviderClient)
+	var body interface{}
+	_, err := identityClient.Get(identityClient.ServiceURL("regions", regionName), &body, nil)
+	if err != nil {
+		// keystone v2 or a policy without GET /regions/{id}: the region is checked with the compute endpoint
+		// of the service catalog, and it has only the ID.
+		_, catalogErr := openstack.NewComputeV2(regionZoneHandler.Client.ProviderClient, gophercloud.EndpointOpts{
+			Region: regionName,
+		})
+		if catalogErr != nil {
+			return irs.RegionZoneInfo{}, errors.New(fmt.Sprintf("unable to get region %s, %v, %v", regionName, err, catalogErr))
+		}
+		return regionZoneHandler.getRegionZone(keystoneRegion{ID: regionName})
+	}
+	var result struct {
+		Region keystoneRegion `mapstructure:"region"`
+	}
+	if err := mapstructure.Decode(body, &result); err != nil {
+		return irs.RegionZoneInfo{}, err
+	}
+
+	return regionZoneHandler.getRegionZone(result.Region)
+}
+
+// getRegionZone gets the availability zones of a region with the compute endpoint of that region.
+func (regionZoneHandler *OpenStackRegionZoneHandler) getRegionZone(region keystoneRegion) (irs.RegionZoneInfo, error) {
+	computeClient, err := openstack.NewComputeV2(regionZoneHandler.Client.ProviderClient, gophercloud.EndpointOpts{
+		Region: region.ID,
+	})
+	if err != nil {
+		// no compute endpoint in the region.
+		return mappingRegionZoneInfo(region, nil, false), nil
+	}
+
+	var body interface{}
+	_, err = computeClient.Get(computeClient.ServiceURL("os-availability-zone"), &body, nil)
+	if err != nil {
+		return irs.RegionZoneInfo{}, err
+	}
+	var result struct {
+		AvailabilityZones []novaAvailabilityZone `mapstructure:"availabilityZoneInfo"`
+	}
+	if err := mapstructure.Decode(body, &result); err != nil {
+		return irs.RegionZoneInfo{}, err
+	}
+	return mappingRegionZoneInfo(region, result.AvailabilityZones, true), nil
+}
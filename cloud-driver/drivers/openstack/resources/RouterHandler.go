@@ -1,10 +1,15 @@
 package resources
 
 import (
-	//irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"errors"
+	"fmt"
+	"strconv"
+
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/rackspace/gophercloud"
 	"github.com/rackspace/gophercloud/openstack/networking/v2/extensions/layer3/routers"
+	"github.com/rackspace/gophercloud/openstack/networking/v2/ports"
 	"github.com/rackspace/gophercloud/pagination"
 )
 
@@ -12,151 +17,319 @@ type OpenStackRouterHandler struct {
 	Client *gophercloud.ServiceClient
 }
 
-// @TODO: Router 생성 요청 파라미터 정의 필요
-type RouterReqInfo struct {
-	Name         string
-	GateWayId    string
-	AdminStateUp bool
-}
-
-// @TODO: Router 리소스 프로퍼티 정의 필요
-type RouteInfo struct {
-	NextHop         string
-	DestinationCIDR string
-}
-type RouterInfo struct {
-	Id           string
-	Name         string
-	TenantId     string
-	AdminStateUp bool
-	Distributed  bool
-	Routes       []RouteInfo
+// mappingRouterInfo maps a neutron router and the subnets of its interfaces into irs.RouterInfo.
+// The GatewayId of a router is the external network of it.
+func mappingRouterInfo(router routers.Router, tags map[string]string, subnetIDs []string) irs.RouterInfo {
+	routerInfo := irs.RouterInfo{
+		Id:        router.ID,
+		Name:      router.Name,
+		Tags:      tags,
+		GatewayId: router.GatewayInfo.NetworkID,
+		SubnetIds: subnetIDs,
+		Status:    router.Status,
+		Owner:     router.TenantID,
+		KeyValueList: []irs.KeyValue{
+			{Key: "AdminStateUp", Value: strconv.FormatBool(router.AdminStateUp)},
+			{Key: "Distributed", Value: strconv.FormatBool(router.Distributed)},
+		},
+	}
+	for _, route := range router.Routes {
+		routerInfo.Routes = append(routerInfo.Routes, irs.RouteInfo{
+			DestinationCIDR: route.DestinationCIDR,
+			NextHop:         route.NextHop,
+		})
+	}
+	return routerInfo
 }
 
-// @TODO: Interface 생성 요청 파라미터 정의 필요
-type InterfaceReqInfo struct {
-	RouterId string
-	SubnetId string
-}
+// getSubnetIDs gets the subnets attached to a router from the ports of its interfaces.
+func (routerHandler *OpenStackRouterHandler) getSubnetIDs(routerID string) ([]string, error) {
+	var subnetIDs []string
 
-// @TODO: Interface 리소스 프로퍼티 정의 필요
-type InterfaceInfo struct {
-	Id   string
-	Name string
+	listOpts := ports.ListOpts{
+		DeviceID:    routerID,
+		DeviceOwner: "network:router_interface",
+	}
+	pager := ports.List(routerHandler.Client, listOpts)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := ports.ExtractPorts(page)
+		if err != nil {
+			return false, err
+		}
+		for _, port := range list {
+			for _, fixedIP := range port.FixedIPs {
+				subnetIDs = append(subnetIDs, fixedIP.SubnetID)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subnetIDs, nil
 }
 
-func (routerInfo *RouterInfo) setter(router routers.Router) *RouterInfo {
-	routerInfo.Id = router.ID
-	routerInfo.Name = router.Name
-	router.TenantID = router.TenantID
-	router.AdminStateUp = router.AdminStateUp
-	router.Distributed = router.Distributed
-
-	var routeArr []RouteInfo
-
-	for _, route := range router.Routes {
-		routeInfo := RouteInfo{
-			NextHop:         route.NextHop,
-			DestinationCIDR: route.DestinationCIDR,
+// getRoutes translates routes into neutron extra routes.
+// The default route of the external gateway is made by neutron, so routes to the gateway are rejected.
+func getRoutes(routes []irs.RouteInfo) ([]routers.Route, error) {
+	routeList := []routers.Route{}
+	for _, route := range routes {
+		if err := irs.CheckRoute(route); err != nil {
+			return nil, err
+		}
+		if route.NextHop == irs.GatewayNextHop {
+			return nil, errors.New("routes to the gateway are not supported by OpenStack(neutron), the external gateway of a router is its default route")
 		}
-		routeArr = append(routeArr, routeInfo)
+		routeList = append(routeList, routers.Route{
+			DestinationCIDR: route.DestinationCIDR,
+			NextHop:         route.NextHop,
+		})
 	}
-
-	routerInfo.Routes = routeArr
-
-	return routerInfo
+	return routeList, nil
 }
 
-func (routerHandler *OpenStackRouterHandler) CreateRouter(routerReqInfo RouterReqInfo) (RouterInfo, error) {
+// The routes are added after the subnets are attached, the next hops of the routes should be in the subnets.
+func (routerHandler *OpenStackRouterHandler) CreateRouter(routerReqInfo irs.RouterReqInfo) (irs.RouterInfo, error) {
+
+	if _, err := getTagList(routerReqInfo.Tags); err != nil {
+		return irs.RouterInfo{}, err
+	}
+	routeList, err := getRoutes(routerReqInfo.Routes)
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
 
 	createOpts := routers.CreateOpts{
 		Name:         routerReqInfo.Name,
-		AdminStateUp: &routerReqInfo.AdminStateUp,
-		GatewayInfo: &routers.GatewayInfo{
-			NetworkID: routerReqInfo.GateWayId,
-		},
+		AdminStateUp: gophercloud.Enabled,
+	}
+	if routerReqInfo.GatewayId != "" {
+		createOpts.GatewayInfo = &routers.GatewayInfo{NetworkID: routerReqInfo.GatewayId}
 	}
 
 	// Create Router
 	router, err := routers.Create(routerHandler.Client, createOpts).Extract()
 	if err != nil {
-		return RouterInfo{}, err
+		return irs.RouterInfo{}, err
 	}
-
 	spew.Dump(router)
-	return RouterInfo{Id: router.ID, Name: router.Name}, nil
+
+	err = routerHandler.setupRouter(router.ID, routerReqInfo.Tags, routerReqInfo.SubnetIds, routeList)
+	if err != nil {
+		// the router without the requested tags, subnets and routes is useless, so it is deleted.
+		if _, deleteErr := routerHandler.DeleteRouter(router.ID); deleteErr != nil {
+			fmt.Println(deleteErr)
+		}
+		return irs.RouterInfo{}, err
+	}
+
+	return routerHandler.GetRouter(router.ID)
+}
+
+func (routerHandler *OpenStackRouterHandler) setupRouter(routerID string, tags map[string]string, subnetIDs []string, routeList []routers.Route) error {
+	if err := setNeutronTags(routerHandler.Client, "routers", routerID, tags); err != nil {
+		return err
+	}
+	for _, subnetID := range subnetIDs {
+		_, err := routers.AddInterface(routerHandler.Client, routerID, routers.InterfaceOpts{SubnetID: subnetID}).Extract()
+		if err != nil {
+			return err
+		}
+	}
+	if len(routeList) == 0 {
+		return nil
+	}
+	_, err := routers.Update(routerHandler.Client, routerID, routers.UpdateOpts{Routes: routeList}).Extract()
+	return err
 }
 
-func (routerHandler *OpenStackRouterHandler) ListRouter() ([]*RouterInfo, error) {
-	var routerInfoList []*RouterInfo
+func (routerHandler *OpenStackRouterHandler) ListRouter(listReqInfo irs.ListReqInfo) ([]*irs.RouterInfo, irs.ListPageInfo, error) {
+	var routerList []*irs.RouterInfo
+
+	listOpts := routers.ListOpts{
+		Name:   listReqInfo.NameFilter,
+		Limit:  getLimit(listReqInfo),
+		Marker: listReqInfo.NextToken,
+	}
 
-	pager := routers.List(routerHandler.Client, routers.ListOpts{})
-	err := pager.EachPage(func(page pagination.Page) (b bool, e error) {
+	var routerArr []routers.Router
+	var tagMap map[string]map[string]string
+	var pageInfo irs.ListPageInfo
+	pager := routers.List(routerHandler.Client, listOpts)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
 		// Get Router
 		list, err := routers.ExtractRouters(page)
 		if err != nil {
 			return false, err
 		}
-		for _, r := range list {
-			routerInfo := new(RouterInfo).setter(r)
-			routerInfoList = append(routerInfoList, routerInfo)
+		if len(list) != 0 {
+			pageInfo = getMarkerPageInfo(len(list), listOpts.Limit, list[len(list)-1].ID)
 		}
-		return true, nil
+		routerArr = append(routerArr, list...)
+		tagMap = extractNeutronTagMap(page.(routers.RouterPage).Body, "routers")
+		// only the first page, the next page starts from the marker.
+		return false, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
 	}
 
-	spew.Dump(routerInfoList)
-	return nil, nil
+	for _, r := range routerArr {
+		if !matchListFilter(listReqInfo, r.Name, tagMap[r.ID]) {
+			continue
+		}
+		subnetIDs, err := routerHandler.getSubnetIDs(r.ID)
+		if err != nil {
+			return nil, irs.ListPageInfo{}, err
+		}
+		routerInfo := mappingRouterInfo(r, tagMap[r.ID], subnetIDs)
+		routerList = append(routerList, &routerInfo)
+	}
+
+	return routerList, pageInfo, nil
 }
 
-func (routerHandler *OpenStackRouterHandler) GetRouter(routerID string) (RouterInfo, error) {
-	router, err := routers.Get(routerHandler.Client, routerID).Extract()
+func (routerHandler *OpenStackRouterHandler) GetRouter(routerID string) (irs.RouterInfo, error) {
+	result := routers.Get(routerHandler.Client, routerID)
+	router, err := result.Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	subnetIDs, err := routerHandler.getSubnetIDs(routerID)
 	if err != nil {
-		return RouterInfo{}, err
+		return irs.RouterInfo{}, err
 	}
 
-	routerInfo := new(RouterInfo).setter(*router)
+	routerInfo := mappingRouterInfo(*router, extractNeutronTags(result.Body, "router"), subnetIDs)
 
 	spew.Dump(routerInfo)
-	return RouterInfo{}, nil
+	return routerInfo, nil
 }
 
 func (routerHandler *OpenStackRouterHandler) DeleteRouter(routerID string) (bool, error) {
-	err := routers.Delete(routerHandler.Client, routerID).ExtractErr()
+	// a router with interfaces can not be deleted.
+	subnetIDs, err := routerHandler.getSubnetIDs(routerID)
+	if err != nil {
+		return false, err
+	}
+	for _, subnetID := range subnetIDs {
+		_, err := routers.RemoveInterface(routerHandler.Client, routerID, routers.InterfaceOpts{SubnetID: subnetID}).Extract()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = routers.Delete(routerHandler.Client, routerID).ExtractErr()
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func (routerHandler *OpenStackRouterHandler) AddInterface(interfaceReqInfo InterfaceReqInfo) (InterfaceInfo, error) {
-	createOpts := routers.InterfaceOpts{
-		SubnetID: interfaceReqInfo.SubnetId,
+// The gateway of a router is an external network.
+func (routerHandler *OpenStackRouterHandler) SetGateway(routerID string, gatewayID string) (irs.RouterInfo, error) {
+	if gatewayID == "" {
+		return irs.RouterInfo{}, errors.New("no external network for the gateway of router " + routerID)
+	}
+	router, err := routers.Get(routerHandler.Client, routerID).Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	// the routes are sent with the gateway, nil routes would clear them.
+	updateOpts := routers.UpdateOpts{
+		GatewayInfo: &routers.GatewayInfo{NetworkID: gatewayID},
+		Routes:      router.Routes,
+	}
+	_, err = routers.Update(routerHandler.Client, routerID, updateOpts).Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
 	}
+	return routerHandler.GetRouter(routerID)
+}
 
-	// Add Interface
-	ir, err := routers.AddInterface(routerHandler.Client, interfaceReqInfo.RouterId, createOpts).Extract()
+func (routerHandler *OpenStackRouterHandler) RemoveGateway(routerID string) (irs.RouterInfo, error) {
+	router, err := routers.Get(routerHandler.Client, routerID).Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	// an empty gateway info clears the gateway, the routes are kept.
+	updateOpts := routers.UpdateOpts{
+		GatewayInfo: &routers.GatewayInfo{},
+		Routes:      router.Routes,
+	}
+	_, err = routers.Update(routerHandler.Client, routerID, updateOpts).Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
+}
+
+// Neutron updates the whole routes of a router, so the routes are merged with the current routes.
+func (routerHandler *OpenStackRouterHandler) AddRoutes(routerID string, routes []irs.RouteInfo) (irs.RouterInfo, error) {
+	routeList, err := getRoutes(routes)
 	if err != nil {
-		return InterfaceInfo{}, err
+		return irs.RouterInfo{}, err
+	}
+	result := routers.Get(routerHandler.Client, routerID)
+	router, err := result.Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
 	}
 
-	spew.Dump(ir)
-	return InterfaceInfo{}, nil
+	routeList = append(router.Routes, routeList...)
+	_, err = routers.Update(routerHandler.Client, routerID, routers.UpdateOpts{Routes: routeList}).Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
 }
 
-func (routerHandler *OpenStackRouterHandler) DeleteInterface(routerID string, subnetID string) (bool, error) {
-	deleteOpts := routers.InterfaceOpts{
-		SubnetID: subnetID,
+func (routerHandler *OpenStackRouterHandler) RemoveRoutes(routerID string, routes []irs.RouteInfo) (irs.RouterInfo, error) {
+	result := routers.Get(routerHandler.Client, routerID)
+	router, err := result.Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
 	}
 
-	// Delete Interface
-	ir, err := routers.RemoveInterface(routerHandler.Client, routerID, deleteOpts).Extract()
+	removeRoutes := map[routers.Route]bool{}
+	for _, route := range routes {
+		removeRoutes[routers.Route{DestinationCIDR: route.DestinationCIDR, NextHop: route.NextHop}] = true
+	}
+	routeList := []routers.Route{}
+	for _, route := range router.Routes {
+		if removeRoutes[route] {
+			delete(removeRoutes, route)
+			continue
+		}
+		routeList = append(routeList, route)
+	}
+	for route := range removeRoutes {
+		return irs.RouterInfo{}, errors.New(fmt.Sprintf("no route to %s via %s in router %s", route.DestinationCIDR, route.NextHop, routerID))
+	}
+
+	_, err = routers.Update(routerHandler.Client, routerID, routers.UpdateOpts{Routes: routeList}).Extract()
 	if err != nil {
-		return false, err
+		return irs.RouterInfo{}, err
+	}
+	return routerHandler.GetRouter(routerID)
+}
+
+func (routerHandler *OpenStackRouterHandler) AttachSubnet(routerID string, subnetID string) (irs.RouterInfo, error) {
+	// Add Interface
+	ir, err := routers.AddInterface(routerHandler.Client, routerID, routers.InterfaceOpts{SubnetID: subnetID}).Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
 	}
+	spew.Dump(ir)
+	return routerHandler.GetRouter(routerID)
+}
 
+func (routerHandler *OpenStackRouterHandler) DetachSubnet(routerID string, subnetID string) (irs.RouterInfo, error) {
+	// Delete Interface
+	ir, err := routers.RemoveInterface(routerHandler.Client, routerID, routers.InterfaceOpts{SubnetID: subnetID}).Extract()
+	if err != nil {
+		return irs.RouterInfo{}, err
+	}
 	spew.Dump(ir)
-	return true, nil
+	return routerHandler.GetRouter(routerID)
 }
@@ -1,6 +1,10 @@
 package resources
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/rackspace/gophercloud"
@@ -9,172 +13,205 @@ import (
 )
 
 type OpenStackSecurityHandler struct {
-	Client *gophercloud.ServiceClient
+	Client        *gophercloud.ServiceClient
+	NetworkClient *gophercloud.ServiceClient
 }
 
-// @TODO: SecurityInfo 리소스 프로퍼티 정의 필요
-type SecurityInfo struct {
-	ID          string
-	Name        string
-	Description string
-	Rules       []SecurityRuleInfo
-	TenantID    string
-}
+// mappingSecurityInfo maps a nova security group into irs.SecurityInfo.
+// Nova rules are always inbound "allow" rules, and the peer group of a rule is given as
+// the name of the group, so it is converted into the ID with groupIDs(see getGroupIDs).
+func mappingSecurityInfo(securityGroup secgroups.SecurityGroup, tags map[string]string, groupIDs map[string]string) irs.SecurityInfo {
+	securityInfo := irs.SecurityInfo{
+		Id:          securityGroup.ID,
+		Name:        securityGroup.Name,
+		Description: securityGroup.Description,
+		Tags:        tags,
+		Owner:       securityGroup.TenantID,
+	}
 
-type SecurityRuleInfo struct {
-	ID            string
-	FromPort      int
-	ToPort        int
-	IPProtocol    string
-	ParentGroupID string
-	CIDR          string
-	Group         Group
-}
+	for _, sgRule := range securityGroup.Rules {
+		ruleInfo := irs.SecurityRuleInfo{
+			Id:         sgRule.ID,
+			Direction:  "inbound",
+			IPProtocol: strings.ToLower(sgRule.IPProtocol),
+			FromPort:   sgRule.FromPort,
+			ToPort:     sgRule.ToPort,
+			CIDR:       sgRule.IPRange.CIDR,
+			Action:     "allow",
+		}
+		if sgRule.Group.Name != "" {
+			ruleInfo.PeerGroup = groupIDs[sgRule.Group.TenantID+"/"+sgRule.Group.Name]
+		}
+		if ruleInfo.IPProtocol == "" {
+			ruleInfo.IPProtocol = "all"
+		}
+		// all ports of tcp/udp are -1 like the requested rules.
+		if (ruleInfo.IPProtocol == "tcp" || ruleInfo.IPProtocol == "udp") && ruleInfo.FromPort == 1 && ruleInfo.ToPort == 65535 {
+			ruleInfo.FromPort, ruleInfo.ToPort = -1, -1
+		}
+		securityInfo.SecurityRules = append(securityInfo.SecurityRules, ruleInfo)
+	}
 
-type Group struct {
-	TenantId string
-	Name     string
+	return securityInfo
 }
 
-func (securityInfo *SecurityInfo) setter(results secgroups.SecurityGroup) *SecurityInfo {
-	securityInfo.ID = results.ID
-	securityInfo.Name = results.Name
-	securityInfo.Description = results.Description
-
-	var securityRuleArr []SecurityRuleInfo
+// listSecurityGroups gets all security groups of the project.
+func (securityHandler *OpenStackSecurityHandler) listSecurityGroups() ([]secgroups.SecurityGroup, error) {
+	var groupList []secgroups.SecurityGroup
 
-	for _, sgRule := range results.Rules {
-		ruleInfo := SecurityRuleInfo{
-			ID:            sgRule.ID,
-			FromPort:      sgRule.FromPort,
-			ToPort:        sgRule.ToPort,
-			IPProtocol:    sgRule.IPProtocol,
-			CIDR:          sgRule.IPRange.CIDR,
-			ParentGroupID: sgRule.ParentGroupID,
-			Group: Group{
-				TenantId: sgRule.Group.TenantID,
-				Name:     sgRule.Group.Name,
-			},
+	pager := secgroups.List(securityHandler.Client)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		// Get SecurityGroup
+		list, err := secgroups.ExtractSecurityGroups(page)
+		if err != nil {
+			return false, err
 		}
-
-		securityRuleArr = append(securityRuleArr, ruleInfo)
+		groupList = append(groupList, list...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return groupList, nil
+}
 
-	securityInfo.Rules = securityRuleArr
-	securityInfo.TenantID = results.TenantID
-
-	return securityInfo
+// getGroupIDs returns the IDs of security groups keyed by {tenant ID}/{name}.
+func getGroupIDs(groupList []secgroups.SecurityGroup) map[string]string {
+	groupIDs := map[string]string{}
+	for _, group := range groupList {
+		groupIDs[group.TenantID+"/"+group.Name] = group.ID
+	}
+	return groupIDs
 }
 
+// Nova security groups have only inbound "allow" rules(the outbound traffic is all allowed),
+// so outbound and deny rules are rejected. Priorities are ignored.
+// The tags are the neutron tags of the group.
 func (securityHandler *OpenStackSecurityHandler) CreateSecurity(securityReqInfo irs.SecurityReqInfo) (irs.SecurityInfo, error) {
 
-	// @TODO: SecurityGroup 생성 요청 파라미터 정의 필요
-	type SecurityRuleReqInfo struct {
-		ParentGroupID string
-		FromPort      int
-		ToPort        int
-		IPProtocol    string
-		CIDR          string // 방식 1) 특정 CIDR 범위의 IP 기준으로 룰 적용
-		FromGroupID   string // 방식 2) SecurityGroup 기준으로 룰 적용
-	}
-	type SecurityReqInfo struct {
-		Name          string
-		Description   string
-		SecurityRules *[]SecurityRuleReqInfo
+	if _, err := getTagList(securityReqInfo.Tags); err != nil {
+		return irs.SecurityInfo{}, err
 	}
 
-	reqInfo := SecurityReqInfo{
-		Name:        securityReqInfo.Name,
-		Description: "Temp securityGroup for test",
+	// check all rules before creating the group.
+	createRuleOptsList, err := getCreateRuleOptsList(securityReqInfo.SecurityRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
 	}
 
 	// Create SecurityGroup
 	createOpts := secgroups.CreateOpts{
-		Name:        reqInfo.Name,
-		Description: reqInfo.Description,
+		Name:        securityReqInfo.Name,
+		Description: securityReqInfo.Description,
 	}
 	group, err := secgroups.Create(securityHandler.Client, createOpts).Extract()
 	if err != nil {
 		return irs.SecurityInfo{}, err
 	}
 
-	reqInfo.SecurityRules = &[]SecurityRuleReqInfo{
-		{
-			//ParentGroupID: group.ID,
-			FromPort:   22,
-			ToPort:     22,
-			IPProtocol: "TCP",
-			CIDR:       "0.0.0.0/0", // 방식 1) CIDR 기준 룰 적용
-		},
-		{
-			//ParentGroupID: group.ID,
-			FromPort:    3306,
-			ToPort:      3306,
-			IPProtocol:  "TCP",
-			FromGroupID: group.ID, // 방식 2) 보안그룹 기준 룰 적용
-		},
-		{
-			//ParentGroupID: group.ID,
-			FromPort:   -1,
-			ToPort:     -1,
-			IPProtocol: "ICMP",
-			CIDR:       "0.0.0.0/0",
-		},
+	// Set Tags and Create SecurityGroup Rules
+	err = setNeutronTags(securityHandler.NetworkClient, "security-groups", group.ID, securityReqInfo.Tags)
+	if err == nil {
+		err = securityHandler.createRules(group.ID, createRuleOptsList)
 	}
-
-	// Create SecurityGroup Rules
-	for _, rule := range *reqInfo.SecurityRules {
-		createRuleOpts := secgroups.CreateRuleOpts{
-			ParentGroupID: group.ID,
-			FromPort:      rule.FromPort,
-			ToPort:        rule.ToPort,
-			IPProtocol:    rule.IPProtocol,
-		}
-
-		if rule.CIDR != "" {
-			createRuleOpts.CIDR = rule.CIDR
-		} else {
-			createRuleOpts.FromGroupID = group.ID
-		}
-
-		_, err := secgroups.CreateRule(securityHandler.Client, createRuleOpts).Extract()
-		if err != nil {
-			return irs.SecurityInfo{}, err
+	if err != nil {
+		// the group without the requested tags and rules is useless, so it is deleted.
+		if deleteErr := secgroups.Delete(securityHandler.Client, group.ID).ExtractErr(); deleteErr != nil {
+			fmt.Println(deleteErr)
 		}
+		return irs.SecurityInfo{}, err
 	}
 
 	securityInfo, err := securityHandler.GetSecurity(group.ID)
 	if err != nil {
-		return irs.SecurityInfo{}, nil
+		return irs.SecurityInfo{}, err
 	}
+	return securityInfo, nil
+}
 
-	spew.Dump(securityInfo)
-	return irs.SecurityInfo{Id: group.ID, Name: group.Name}, nil
+func getCreateRuleOptsList(rules []irs.SecurityRuleInfo) ([]secgroups.CreateRuleOpts, error) {
+	var createRuleOptsList []secgroups.CreateRuleOpts
+	for _, rule := range rules {
+		createRuleOpts, err := getCreateRuleOpts(rule)
+		if err != nil {
+			return nil, err
+		}
+		createRuleOptsList = append(createRuleOptsList, createRuleOpts...)
+	}
+	return createRuleOptsList, nil
 }
 
-func (securityHandler *OpenStackSecurityHandler) ListSecurity() ([]*irs.SecurityInfo, error) {
-	var securityList []*SecurityInfo
+// getCreateRuleOpts translates a rule into the options of nova rules without the ParentGroupID.
+// A rule for all protocols is translated into tcp, udp and icmp rules.
+// -1 ports of tcp/udp are 1 ~ 65535, -1 type/code of icmp are all types/codes.
+func getCreateRuleOpts(rule irs.SecurityRuleInfo) ([]secgroups.CreateRuleOpts, error) {
+	if err := irs.CheckSecurityRule(rule); err != nil {
+		return nil, err
+	}
+	if rule.Direction == "outbound" {
+		return nil, errors.New("outbound rules are not supported by OpenStack(nova) security groups, the outbound traffic is all allowed")
+	}
+	if rule.Action == "deny" {
+		return nil, errors.New("deny rules are not supported by OpenStack(nova) security groups")
+	}
+
+	ruleOpts := secgroups.CreateRuleOpts{
+		IPProtocol:  rule.IPProtocol,
+		FromPort:    rule.FromPort,
+		ToPort:      rule.ToPort,
+		CIDR:        rule.CIDR,
+		FromGroupID: rule.PeerGroup,
+	}
 
-	pager := secgroups.List(securityHandler.Client)
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
-		// Get SecurityGroup
-		list, err := secgroups.ExtractSecurityGroups(page)
-		if err != nil {
-			return false, err
+	var ruleOptsList []secgroups.CreateRuleOpts
+	switch rule.IPProtocol {
+	case "all":
+		for _, protocol := range []string{"tcp", "udp"} {
+			protocolOpts := ruleOpts
+			protocolOpts.IPProtocol = protocol
+			protocolOpts.FromPort, protocolOpts.ToPort = 1, 65535
+			ruleOptsList = append(ruleOptsList, protocolOpts)
 		}
-		// Add to List
-		for _, s := range list {
-			securityInfo := new(SecurityInfo).setter(s)
-			securityList = append(securityList, securityInfo)
+		icmpOpts := ruleOpts
+		icmpOpts.IPProtocol = "icmp"
+		ruleOptsList = append(ruleOptsList, icmpOpts)
+	case "tcp", "udp":
+		if ruleOpts.FromPort == -1 {
+			ruleOpts.FromPort, ruleOpts.ToPort = 1, 65535
 		}
-		return true, nil
-	})
+		ruleOptsList = append(ruleOptsList, ruleOpts)
+	default:
+		ruleOptsList = append(ruleOptsList, ruleOpts)
+	}
+	return ruleOptsList, nil
+}
+
+// security groups have no paging, so pages are cut from the filtered result.
+func (securityHandler *OpenStackSecurityHandler) ListSecurity(listReqInfo irs.ListReqInfo) ([]*irs.SecurityInfo, irs.ListPageInfo, error) {
+	groupList, err := securityHandler.listSecurityGroups()
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
+	}
+	groupIDs := getGroupIDs(groupList)
+	tagMap, err := listNeutronTagMap(securityHandler.NetworkClient, "security-groups", "security_groups")
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var securityList []*irs.SecurityInfo
+	for _, s := range groupList {
+		if !matchListFilter(listReqInfo, s.Name, tagMap[s.ID]) {
+			continue
+		}
+		securityInfo := mappingSecurityInfo(s, tagMap[s.ID], groupIDs)
+		securityList = append(securityList, &securityInfo)
 	}
 
-	spew.Dump(securityList)
-	return nil, nil
+	start, end, nextToken, err := irs.GetPageRange(len(securityList), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+	return securityList[start:end], irs.ListPageInfo{NextToken: nextToken}, nil
 }
 
 func (securityHandler *OpenStackSecurityHandler) GetSecurity(securityID string) (irs.SecurityInfo, error) {
@@ -183,10 +220,29 @@ func (securityHandler *OpenStackSecurityHandler) GetSecurity(securityID string)
 		return irs.SecurityInfo{}, err
 	}
 
-	securityInfo := new(SecurityInfo).setter(*securityGroup)
+	// the groups are got only for the rules with peer groups.
+	var groupIDs map[string]string
+	for _, sgRule := range securityGroup.Rules {
+		if sgRule.Group.Name == "" {
+			continue
+		}
+		groupList, err := securityHandler.listSecurityGroups()
+		if err != nil {
+			return irs.SecurityInfo{}, err
+		}
+		groupIDs = getGroupIDs(groupList)
+		break
+	}
+
+	tags, err := getNeutronTags(securityHandler.NetworkClient, "security-groups", "security_group", securityID)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+
+	securityInfo := mappingSecurityInfo(*securityGroup, tags, groupIDs)
 
 	spew.Dump(securityInfo)
-	return irs.SecurityInfo{}, nil
+	return securityInfo, nil
 }
 
 func (securityHandler *OpenStackSecurityHandler) DeleteSecurity(securityID string) (bool, error) {
@@ -196,3 +252,113 @@ func (securityHandler *OpenStackSecurityHandler) DeleteSecurity(securityID strin
 	}
 	return true, nil
 }
+
+func (securityHandler *OpenStackSecurityHandler) AddRules(securityID string, securityRules []irs.SecurityRuleInfo) (irs.SecurityInfo, error) {
+	createRuleOptsList, err := getCreateRuleOptsList(securityRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	if err := securityHandler.createRules(securityID, createRuleOptsList); err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	return securityHandler.GetSecurity(securityID)
+}
+
+// A rule for all protocols is removed with its tcp, udp and icmp rules.
+func (securityHandler *OpenStackSecurityHandler) RemoveRules(securityID string, securityRules []irs.SecurityRuleInfo) (irs.SecurityInfo, error) {
+	securityInfo, err := securityHandler.GetSecurity(securityID)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	rules, err := normalizeRules(securityRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	removeRules, err := irs.FindSecurityRules(securityInfo.SecurityRules, rules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+
+	if err := securityHandler.deleteRules(removeRules); err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	return securityHandler.GetSecurity(securityID)
+}
+
+// New rules are added before the old ones are removed, so the allowed traffic is not cut during the sync.
+func (securityHandler *OpenStackSecurityHandler) SyncRules(securityID string, desiredRules []irs.SecurityRuleInfo) (irs.SecurityInfo, error) {
+	securityInfo, err := securityHandler.GetSecurity(securityID)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	rules, err := normalizeRules(desiredRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	addRules, removeRules := irs.DiffSecurityRules(securityInfo.SecurityRules, rules)
+
+	createRuleOptsList, err := getCreateRuleOptsList(addRules)
+	if err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	if err := securityHandler.createRules(securityID, createRuleOptsList); err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	if err := securityHandler.deleteRules(removeRules); err != nil {
+		return irs.SecurityInfo{}, err
+	}
+	return securityHandler.GetSecurity(securityID)
+}
+
+func (securityHandler *OpenStackSecurityHandler) createRules(securityID string, createRuleOptsList []secgroups.CreateRuleOpts) error {
+	for _, createRuleOpts := range createRuleOptsList {
+		createRuleOpts.ParentGroupID = securityID
+		_, err := secgroups.CreateRule(securityHandler.Client, createRuleOpts).Extract()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (securityHandler *OpenStackSecurityHandler) deleteRules(rules []irs.SecurityRuleInfo) error {
+	for _, rule := range rules {
+		err := secgroups.DeleteRule(securityHandler.Client, rule.Id).ExtractErr()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeRules translates rules in the same way as the nova rules are mapped(ex: all is tcp, udp and icmp),
+// so the requested rules can be compared with the current rules. A rule with an Id is kept as it is.
+func normalizeRules(rules []irs.SecurityRuleInfo) ([]irs.SecurityRuleInfo, error) {
+	var normalizedRules []irs.SecurityRuleInfo
+	for _, rule := range rules {
+		if rule.Id != "" {
+			normalizedRules = append(normalizedRules, rule)
+			continue
+		}
+		createRuleOptsList, err := getCreateRuleOpts(rule)
+		if err != nil {
+			return nil, err
+		}
+		for _, createRuleOpts := range createRuleOptsList {
+			normalizedRule := irs.SecurityRuleInfo{
+				Direction:  "inbound",
+				IPProtocol: createRuleOpts.IPProtocol,
+				FromPort:   createRuleOpts.FromPort,
+				ToPort:     createRuleOpts.ToPort,
+				CIDR:       createRuleOpts.CIDR,
+				PeerGroup:  createRuleOpts.FromGroupID,
+				Action:     "allow",
+			}
+			if (normalizedRule.IPProtocol == "tcp" || normalizedRule.IPProtocol == "udp") && normalizedRule.FromPort == 1 && normalizedRule.ToPort == 65535 {
+				normalizedRule.FromPort, normalizedRule.ToPort = -1, -1
+			}
+			normalizedRules = append(normalizedRules, normalizedRule)
+		}
+	}
+	return normalizedRules, nil
+}
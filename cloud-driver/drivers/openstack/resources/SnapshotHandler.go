@@ -0,0 +1,214 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack/blockstorage/v1/snapshots"
+	"github.com/rackspace/gophercloud/openstack/blockstorage/v1/volumes"
+	"github.com/rackspace/gophercloud/openstack/compute/v2/extensions/volumeattach"
+	"github.com/rackspace/gophercloud/openstack/compute/v2/servers"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+// OpenStackSnapshotHandler handles cinder snapshots.
+// Only volumes have snapshots, the ephemeral root disk of a server booted from an image has none.
+type OpenStackSnapshotHandler struct {
+	Client       *gophercloud.ServiceClient
+	VolumeClient *gophercloud.ServiceClient
+}
+
+// mappingSnapshotInfo maps a cinder snapshot into irs.SnapshotInfo, Tags are kept in the metadata of the snapshot.
+func mappingSnapshotInfo(snapshot snapshots.Snapshot) irs.SnapshotInfo {
+	return irs.SnapshotInfo{
+		Name:        snapshot.Name,
+		Id:          snapshot.ID,
+		Tags:        snapshot.Metadata,
+		DiskId:      snapshot.VolumeID,
+		SizeGiB:     snapshot.Size,
+		Status:      snapshot.Status,
+		CreatedTime: getTime(snapshot.CreatedAt),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Description", Value: snapshot.Description},
+		},
+	}
+}
+
+// The snapshots of the volumes in use are forced, so they are crash consistent.
+func (snapshotHandler *OpenStackSnapshotHandler) CreateSnapshot(snapshotReqInfo irs.SnapshotReqInfo) ([]*irs.SnapshotInfo, error) {
+	if err := irs.CheckSnapshotReqInfo(snapshotReqInfo); err != nil {
+		return nil, err
+	}
+
+	if snapshotReqInfo.DiskId != "" {
+		snapshotInfo, err := snapshotHandler.createSnapshot(snapshotReqInfo.Name, snapshotReqInfo.Tags, snapshotReqInfo.DiskId)
+		if err != nil {
+			return nil, err
+		}
+		return []*irs.SnapshotInfo{&snapshotInfo}, nil
+	}
+
+	volumeIDs, rootVolume, err := snapshotHandler.getServerVolumes(snapshotReqInfo.VMId)
+	if err != nil {
+		return nil, err
+	}
+	if len(volumeIDs) == 0 {
+		return nil, errors.New("no volume of server " + snapshotReqInfo.VMId)
+	}
+
+	var snapshotList []*irs.SnapshotInfo
+	for i, volumeID := range volumeIDs {
+		name := fmt.Sprintf("%s-%d", snapshotReqInfo.Name, i)
+		snapshotInfo, err := snapshotHandler.createSnapshot(name, snapshotReqInfo.Tags, volumeID)
+		if err != nil {
+			return snapshotList, err
+		}
+		snapshotInfo.VMId = snapshotReqInfo.VMId
+		snapshotInfo.RootDisk = i == 0 && rootVolume
+		snapshotList = append(snapshotList, &snapshotInfo)
+	}
+	return snapshotList, nil
+}
+
+// getServerVolumes returns the volumes attached to a server, the root volume(boot from volume) first.
+func (snapshotHandler *OpenStackSnapshotHandler) getServerVolumes(serverID string) ([]string, bool, error) {
+	server, err := servers.Get(snapshotHandler.Client, serverID).Extract()
+	if err != nil {
+		return nil, false, err
+	}
+	// the image of a server booted from a volume is empty.
+	bootFromVolume := len(server.Image) == 0
+
+	var volumeIDs []string
+	rootVolume := false
+	pager := volumeattach.List(snapshotHandler.Client, serverID)
+	err = pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := volumeattach.ExtractVolumeAttachments(page)
+		if err != nil {
+			return false, err
+		}
+		for _, attachment := range list {
+			if bootFromVolume && (attachment.Device == "/dev/vda" || attachment.Device == "/dev/sda") {
+				volumeIDs = append([]string{attachment.VolumeID}, volumeIDs...)
+				rootVolume = true
+			} else {
+				volumeIDs = append(volumeIDs, attachment.VolumeID)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return volumeIDs, rootVolume, nil
+}
+
+func (snapshotHandler *OpenStackSnapshotHandler) createSnapshot(name string, tags map[string]string, volumeID string) (irs.SnapshotInfo, error) {
+	createOpts := snapshots.CreateOpts{
+		Name:     name,
+		VolumeID: volumeID,
+		Force:    true,
+		Metadata: tags,
+	}
+	snapshot, err := snapshots.Create(snapshotHandler.VolumeClient, createOpts).Extract()
+	if err != nil {
+		return irs.SnapshotInfo{}, err
+	}
+	return mappingSnapshotInfo(*snapshot), nil
+}
+
+// cinder v1 lists all snapshots at once, so the page is cut on the driver side.
+func (snapshotHandler *OpenStackSnapshotHandler) ListSnapshot(listReqInfo irs.ListReqInfo) ([]*irs.SnapshotInfo, irs.ListPageInfo, error) {
+	var snapshotList []snapshots.Snapshot
+
+	listOpts := snapshots.ListOpts{
+		Name: listReqInfo.NameFilter,
+	}
+	pager := snapshots.List(snapshotHandler.VolumeClient, listOpts)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := snapshots.ExtractSnapshots(page)
+		if err != nil {
+			return false, err
+		}
+		for _, s := range list {
+			if matchListFilter(listReqInfo, s.Name, s.Metadata) {
+				snapshotList = append(snapshotList, s)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	start, end, nextToken, err := irs.GetPageRange(len(snapshotList), listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var snapshotInfoList []*irs.SnapshotInfo
+	for _, s := range snapshotList[start:end] {
+		snapshotInfo := mappingSnapshotInfo(s)
+		snapshotInfoList = append(snapshotInfoList, &snapshotInfo)
+	}
+	return snapshotInfoList, irs.ListPageInfo{NextToken: nextToken}, nil
+}
+
+func (snapshotHandler *OpenStackSnapshotHandler) GetSnapshot(snapshotID string) (irs.SnapshotInfo, error) {
+	snapshot, err := snapshots.Get(snapshotHandler.VolumeClient, snapshotID).Extract()
+	if err != nil {
+		return irs.SnapshotInfo{}, err
+	}
+	return mappingSnapshotInfo(*snapshot), nil
+}
+
+// The snapshots with volumes created from them can not be deleted.
+func (snapshotHandler *OpenStackSnapshotHandler) DeleteSnapshot(snapshotID string) (bool, error) {
+	err := snapshots.Delete(snapshotHandler.VolumeClient, snapshotID).ExtractErr()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// The snapshot must be available before it is restored.
+func (snapshotHandler *OpenStackSnapshotHandler) RestoreSnapshot(snapshotID string, diskReqInfo irs.DiskReqInfo) (irs.DiskInfo, error) {
+	snapshot, err := snapshots.Get(snapshotHandler.VolumeClient, snapshotID).Extract()
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	size := diskReqInfo.SizeGiB
+	if size == 0 {
+		size = snapshot.Size
+	}
+	if size < snapshot.Size {
+		return irs.DiskInfo{}, errors.New(fmt.Sprintf("invalid size of disk %s: %d GiB, the snapshot is %d GiB", diskReqInfo.Name, size, snapshot.Size))
+	}
+
+	err = snapshots.WaitForStatus(snapshotHandler.VolumeClient, snapshotID, "available", volumeWaitSeconds)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	createOpts := volumes.CreateOpts{
+		Name:         diskReqInfo.Name,
+		Size:         size,
+		SnapshotID:   snapshotID,
+		VolumeType:   diskReqInfo.DiskType,
+		Availability: diskReqInfo.Zone,
+		Metadata:     diskReqInfo.Tags,
+	}
+	volume, err := volumes.Create(snapshotHandler.VolumeClient, createOpts).Extract()
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+
+	err = volumes.WaitForStatus(snapshotHandler.VolumeClient, volume.ID, "available", volumeWaitSeconds)
+	if err != nil {
+		return irs.DiskInfo{}, err
+	}
+	diskHandler := OpenStackDiskHandler{snapshotHandler.Client, snapshotHandler.VolumeClient}
+	return diskHandler.GetDisk(volume.ID)
+}
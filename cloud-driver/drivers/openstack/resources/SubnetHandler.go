@@ -0,0 +1,145 @@
+package resources
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack/networking/v2/subnets"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+type OpenStackSubnetHandler struct {
+	Client *gophercloud.ServiceClient
+}
+
+// mappingSubnetInfo maps a neutron subnet into irs.SubnetInfo.
+// Neutron subnets are regional, so the Zone is empty.
+func mappingSubnetInfo(subnet subnets.Subnet) irs.SubnetInfo {
+	var pools []string
+	for _, pool := range subnet.AllocationPools {
+		pools = append(pools, pool.Start+"-"+pool.End)
+	}
+	return irs.SubnetInfo{
+		Id:         subnet.ID,
+		Name:       subnet.Name,
+		VNetworkId: subnet.NetworkID,
+		CIDR:       subnet.CIDR,
+		KeyValueList: []irs.KeyValue{
+			{Key: "GatewayIP", Value: subnet.GatewayIP},
+			{Key: "EnableDHCP", Value: strconv.FormatBool(subnet.EnableDHCP)},
+			{Key: "DNSNameservers", Value: strings.Join(subnet.DNSNameservers, ",")},
+			{Key: "AllocationPools", Value: strings.Join(pools, ",")},
+		},
+	}
+}
+
+// Neutron subnets are regional, so zones are rejected.
+func (subnetHandler *OpenStackSubnetHandler) CreateSubnet(vNetworkID string, subnetReqInfo irs.SubnetReqInfo) (irs.SubnetInfo, error) {
+	if subnetReqInfo.Zone != "" {
+		return irs.SubnetInfo{}, errors.New("zones of subnets are not supported by OpenStack(neutron), subnet: " + subnetReqInfo.Name)
+	}
+	// a network has no address space, the CIDR is checked with the other subnets by neutron.
+	if err := irs.CheckSubnetReqInfo(subnetReqInfo, nil); err != nil {
+		return irs.SubnetInfo{}, err
+	}
+
+	subnet, err := createSubnet(subnetHandler.Client, vNetworkID, subnetReqInfo)
+	if err != nil {
+		return irs.SubnetInfo{}, err
+	}
+	return mappingSubnetInfo(*subnet), nil
+}
+
+// createSubnet creates a subnet of a network, the IP version is decided by the CIDR.
+func createSubnet(client *gophercloud.ServiceClient, networkID string, subnetReqInfo irs.SubnetReqInfo) (*subnets.Subnet, error) {
+	// Set IPPool
+	var allocationPools []subnets.AllocationPool
+	for _, ipPool := range subnetReqInfo.IPPools {
+		allocationPools = append(allocationPools, subnets.AllocationPool{
+			Start: ipPool.Start,
+			End:   ipPool.End,
+		})
+	}
+
+	ipVersion := subnets.IPv4
+	if strings.Contains(subnetReqInfo.CIDR, ":") {
+		ipVersion = subnets.IPv6
+	}
+
+	subnetCreateOpts := subnets.CreateOpts{
+		NetworkID:       networkID,
+		CIDR:            subnetReqInfo.CIDR,
+		IPVersion:       ipVersion,
+		Name:            subnetReqInfo.Name,
+		AllocationPools: allocationPools,
+		DNSNameservers:  subnetReqInfo.DNSServers,
+	}
+	subnet, err := subnets.Create(client, subnetCreateOpts).Extract()
+	if err != nil {
+		return nil, err
+	}
+	spew.Dump(subnet)
+	return subnet, nil
+}
+
+// The tag filter is checked with the string tags of the subnets.
+func (subnetHandler *OpenStackSubnetHandler) ListSubnet(vNetworkID string, listReqInfo irs.ListReqInfo) ([]*irs.SubnetInfo, irs.ListPageInfo, error) {
+	var subnetList []*irs.SubnetInfo
+
+	listOpts := subnets.ListOpts{
+		NetworkID: vNetworkID,
+		Name:      listReqInfo.NameFilter,
+		Limit:     getLimit(listReqInfo),
+		Marker:    listReqInfo.NextToken,
+	}
+
+	var pageInfo irs.ListPageInfo
+	pager := subnets.List(subnetHandler.Client, listOpts)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := subnets.ExtractSubnets(page)
+		if err != nil {
+			return false, err
+		}
+		if len(list) != 0 {
+			pageInfo = getMarkerPageInfo(len(list), listOpts.Limit, list[len(list)-1].ID)
+		}
+		tagMap := extractNeutronTagMap(page.(subnets.SubnetPage).Body, "subnets")
+		for _, s := range list {
+			if !matchListFilter(listReqInfo, s.Name, tagMap[s.ID]) {
+				continue
+			}
+			subnetInfo := mappingSubnetInfo(s)
+			subnetList = append(subnetList, &subnetInfo)
+		}
+		// only the first page, the next page starts from the marker.
+		return false, nil
+	})
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	return subnetList, pageInfo, nil
+}
+
+func (subnetHandler *OpenStackSubnetHandler) GetSubnet(subnetID string) (irs.SubnetInfo, error) {
+	subnet, err := subnets.Get(subnetHandler.Client, subnetID).Extract()
+	if err != nil {
+		return irs.SubnetInfo{}, err
+	}
+
+	subnetInfo := mappingSubnetInfo(*subnet)
+	spew.Dump(subnetInfo)
+	return subnetInfo, nil
+}
+
+func (subnetHandler *OpenStackSubnetHandler) DeleteSubnet(subnetID string) (bool, error) {
+	err := subnets.Delete(subnetHandler.Client, subnetID).ExtractErr()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
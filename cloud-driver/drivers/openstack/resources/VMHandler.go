@@ -11,22 +11,47 @@
 package resources
 
 import (
+	"errors"
 	"fmt"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/mitchellh/mapstructure"
 	"github.com/rackspace/gophercloud"
 	"github.com/rackspace/gophercloud/openstack/compute/v2/extensions/keypairs"
 	"github.com/rackspace/gophercloud/openstack/compute/v2/extensions/startstop"
+	"github.com/rackspace/gophercloud/openstack/compute/v2/extensions/volumeattach"
+	"github.com/rackspace/gophercloud/openstack/compute/v2/flavors"
 	"github.com/rackspace/gophercloud/openstack/compute/v2/servers"
+	"github.com/rackspace/gophercloud/openstack/networking/v2/extensions/external"
+	"github.com/rackspace/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/rackspace/gophercloud/openstack/networking/v2/networks"
+	"github.com/rackspace/gophercloud/openstack/networking/v2/ports"
+	"github.com/rackspace/gophercloud/openstack/networking/v2/subnets"
 	"github.com/rackspace/gophercloud/pagination"
+	"net/http"
+	"sort"
 )
 
 // modified by powerkim, 2019.07.29
 type OpenStackVMHandler struct {
-	Client *gophercloud.ServiceClient
+	Client        *gophercloud.ServiceClient
+	NetworkClient *gophercloud.ServiceClient
 }
 
+// the limit of the base64 encoded user_data of a server.
+const maxUserDataSize = 65535
+
+// seconds to wait for the resize of a server.
+const resizeWaitSeconds = 600
+
+// seconds to wait for the deletion of a server on the rollback of StartVM.
+const deleteWaitSeconds = 300
+
 // modified by powerkim, 2019.07.29
 func (vmHandler *OpenStackVMHandler) StartVM(vmReqInfo irs.VMReqInfo) (irs.VMInfo, error) {
+	err := irs.CheckUserData(vmReqInfo.UserData, maxUserDataSize, true)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
 
 	// Add Server Create Options
 	serverCreateOpts := servers.CreateOpts{
@@ -39,24 +64,207 @@ func (vmHandler *OpenStackVMHandler) StartVM(vmReqInfo irs.VMReqInfo) (irs.VMInf
 		SecurityGroups: []string{
 			vmReqInfo.SecurityInfo.Name,
 		},
+		Metadata: vmReqInfo.Tags, // Tags are kept in the metadata of the server.
 		//ServiceClient: vmHandler.Client,
 	}
 
+	// Add NICs, NetworkInterfaces are the ports created here with their own security groups.
+	var portIDs []string
+	if len(vmReqInfo.NetworkInterfaces) != 0 {
+		portIDs, err = vmHandler.createPorts(vmReqInfo.Name, vmReqInfo.NetworkInterfaces)
+		if err != nil {
+			return irs.VMInfo{}, vmHandler.deletePorts(err, portIDs)
+		}
+		serverCreateOpts.Networks = nil
+		for _, portID := range portIDs {
+			serverCreateOpts.Networks = append(serverCreateOpts.Networks, servers.Network{Port: portID})
+		}
+		serverCreateOpts.SecurityGroups = nil
+	}
+
+	// Add User Data, it is also written to the config drive for the clouds without the metadata service.
+	if vmReqInfo.UserData != "" {
+		serverCreateOpts.UserData = []byte(vmReqInfo.UserData)
+		serverCreateOpts.ConfigDrive = true
+	}
+
+	// Add Root Disk, Data Disks
+	blockDevices, err := getBlockDevices(vmReqInfo)
+	if err != nil {
+		return irs.VMInfo{}, vmHandler.deletePorts(err, portIDs)
+	}
+	blockDeviceOpts := blockDeviceCreateOpts{
+		CreateOptsBuilder: serverCreateOpts,
+		BlockDevices:      blockDevices,
+	}
+
 	// Add KeyPair
 	createOpts := keypairs.CreateOptsExt{
-		CreateOptsBuilder: serverCreateOpts,
+		CreateOptsBuilder: blockDeviceOpts,
 		KeyName:           vmReqInfo.KeyPairInfo.Name,
 	}
 
 	server, err := servers.Create(vmHandler.Client, createOpts).Extract()
 	if err != nil {
-		return irs.VMInfo{}, err
+		return irs.VMInfo{}, vmHandler.deletePorts(err, portIDs)
+	}
+
+	// Add Floating IPs to the ports of NetworkInterfaces with PublicIP,
+	// the server without them is deleted with its ports like the failures before.
+	var floatingIPIDs []string
+	for i, nic := range vmReqInfo.NetworkInterfaces {
+		if !nic.PublicIP {
+			continue
+		}
+		floatingIPID, err := vmHandler.createFloatingIP(portIDs[i])
+		if err != nil {
+			cause := errors.New(fmt.Sprintf("failed to create the floating IP of NIC %d: %v", i, err))
+			return irs.VMInfo{}, vmHandler.deleteServer(cause, server.ID, floatingIPIDs, portIDs)
+		}
+		floatingIPIDs = append(floatingIPIDs, floatingIPID)
 	}
 
 	vmInfo := mappingServerInfo(*server)
 	return vmInfo, nil
 }
 
+// createPorts creates the ports({vm name}-nic-{i}) of NetworkInterfaces in order, the IDs of the created ports are returned even on error.
+// The ports are kept after TerminateVM, delete them with VNicHandler.
+func (vmHandler *OpenStackVMHandler) createPorts(vmName string, nics []irs.VMNicReqInfo) ([]string, error) {
+	if err := irs.CheckVMNicReqInfo(nics, 0); err != nil {
+		return nil, err
+	}
+
+	var portIDs []string
+	for i, nic := range nics {
+		networkID := nic.VNetworkId
+		if networkID == "" {
+			if nic.SubnetId == "" {
+				return portIDs, errors.New(fmt.Sprintf("the network or subnet of NIC %d is required", i))
+			}
+			subnet, err := subnets.Get(vmHandler.NetworkClient, nic.SubnetId).Extract()
+			if err != nil {
+				return portIDs, err
+			}
+			networkID = subnet.NetworkID
+		}
+
+		createOpts := ports.CreateOpts{
+			NetworkID: networkID,
+			Name:      fmt.Sprintf("%s-nic-%d", vmName, i),
+		}
+		if nic.SubnetId != "" || nic.PrivateIP != "" {
+			createOpts.FixedIPs = []ports.IP{
+				{SubnetID: nic.SubnetId, IPAddress: nic.PrivateIP},
+			}
+		}
+		// neutron needs the IDs of the security groups, nil: the "default" security group.
+		if len(nic.SecurityGroupIds) != 0 {
+			createOpts.SecurityGroups = nic.SecurityGroupIds
+		}
+		port, err := ports.Create(vmHandler.NetworkClient, createOpts).Extract()
+		if err != nil {
+			return portIDs, err
+		}
+		portIDs = append(portIDs, port.ID)
+	}
+	return portIDs, nil
+}
+
+// deletePorts deletes the ports created by StartVM and returns the cause of the deletion.
+func (vmHandler *OpenStackVMHandler) deletePorts(cause error, portIDs []string) error {
+	for _, portID := range portIDs {
+		err := ports.Delete(vmHandler.NetworkClient, portID).ExtractErr()
+		if err != nil {
+			return errors.New(fmt.Sprintf("%v (failed to delete port %s: %v)", cause, portID, err))
+		}
+	}
+	return cause
+}
+
+// deleteServer deletes the server and the floating IPs created by StartVM, and then the ports after the server is gone.
+// It returns the cause of the deletion.
+func (vmHandler *OpenStackVMHandler) deleteServer(cause error, serverID string, floatingIPIDs []string, portIDs []string) error {
+	for _, floatingIPID := range floatingIPIDs {
+		err := floatingips.Delete(vmHandler.NetworkClient, floatingIPID).ExtractErr()
+		if err != nil {
+			return errors.New(fmt.Sprintf("%v (failed to delete floating IP %s: %v)", cause, floatingIPID, err))
+		}
+	}
+
+	err := servers.Delete(vmHandler.Client, serverID).ExtractErr()
+	if err == nil {
+		err = gophercloud.WaitFor(deleteWaitSeconds, func() (bool, error) {
+			err := servers.Get(vmHandler.Client, serverID).Err
+			if err == nil {
+				return false, nil
+			}
+			if responseErr, ok := err.(*gophercloud.UnexpectedResponseCodeError); ok && responseErr.Actual == http.StatusNotFound {
+				return true, nil
+			}
+			return false, err
+		})
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf("%v (failed to delete server %s: %v)", cause, serverID, err))
+	}
+	return vmHandler.deletePorts(cause, portIDs)
+}
+
+// createFloatingIP creates a floating IP of the first external network for a port and returns its ID.
+func (vmHandler *OpenStackVMHandler) createFloatingIP(portID string) (string, error) {
+	externalNetwork, err := getExternalNetwork(vmHandler.NetworkClient)
+	if err != nil {
+		return "", err
+	}
+	createOpts := floatingips.CreateOpts{
+		FloatingNetworkID: externalNetwork.ID,
+		PortID:            portID,
+	}
+	floatingIP, err := floatingips.Create(vmHandler.NetworkClient, createOpts).Extract()
+	if err != nil {
+		return "", err
+	}
+	return floatingIP.ID, nil
+}
+
+// getServerState returns the task state and the fault message in the body of a Get result of a server,
+// they are not extracted by gophercloud. "": none
+func getServerState(body interface{}) (string, string) {
+	bodyMap, _ := body.(map[string]interface{})
+	server, _ := bodyMap["server"].(map[string]interface{})
+	taskState, _ := server["OS-EXT-STS:task_state"].(string)
+	fault, _ := server["fault"].(map[string]interface{})
+	faultMessage, _ := fault["message"].(string)
+	return taskState, faultMessage
+}
+
+// getExternalNetwork returns the first external network, the default pool of floating IPs.
+func getExternalNetwork(networkClient *gophercloud.ServiceClient) (external.NetworkExternal, error) {
+	var externalNetwork external.NetworkExternal
+	pager := networks.List(networkClient, networks.ListOpts{})
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := external.ExtractList(page)
+		if err != nil {
+			return false, err
+		}
+		for _, n := range list {
+			if n.External {
+				externalNetwork = n
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return external.NetworkExternal{}, err
+	}
+	if externalNetwork.ID == "" {
+		return external.NetworkExternal{}, errors.New("no external network for floating IPs")
+	}
+	return externalNetwork, nil
+}
+
 func (vmHandler *OpenStackVMHandler) SuspendVM(vmID string) {
 	err := startstop.Stop(vmHandler.Client, vmID).Err
 	if err != nil {
@@ -90,64 +298,183 @@ func (vmHandler *OpenStackVMHandler) TerminateVM(vmID string) {
 	}
 }
 
-func (vmHandler *OpenStackVMHandler) ListVMStatus() []*irs.VMStatusInfo {
-	var vmStatusList []*irs.VMStatusInfo
+// ResizeVM resizes a server(ACTIVE or SHUTOFF) to a flavor and confirms the resize, the server keeps its power state.
+// The server keeps its status with a task state(ex: resize_prep) until it is RESIZE and then VERIFY_RESIZE.
+// When no host of the zone of the server has the flavor, nova cancels the resize:
+// the task state is cleared without the new flavor, or the server is ERROR with the fault.
+func (vmHandler *OpenStackVMHandler) ResizeVM(vmID string, newSpec string) (irs.VMInfo, error) {
+	server, err := servers.Get(vmHandler.Client, vmID).Extract()
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	if server.Flavor["id"] == newSpec {
+		return mappingServerInfo(*server), nil
+	}
+	if server.Status != "ACTIVE" && server.Status != "SHUTOFF" {
+		return irs.VMInfo{}, errors.New(fmt.Sprintf("server %s can not be resized in status %s", vmID, server.Status))
+	}
+	_, err = flavors.Get(vmHandler.Client, newSpec).Extract()
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
 
-	pager := servers.List(vmHandler.Client, nil)
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
-		// Get VM Status
-		list, err := servers.ExtractServers(page)
+	err = servers.Resize(vmHandler.Client, vmID, servers.ResizeOpts{FlavorRef: newSpec}).ExtractErr()
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+
+	originalStatus := server.Status
+	resizeStarted := false
+	err = gophercloud.WaitFor(resizeWaitSeconds, func() (bool, error) {
+		result := servers.Get(vmHandler.Client, vmID)
+		current, err := result.Extract()
 		if err != nil {
 			return false, err
 		}
-		// Add to List
-		for _, s := range list {
-			vmStatus := irs.VMStatus(s.Status)
-			vmStatusInfo := irs.VMStatusInfo{
-				VmId:     s.ID,
-				VmStatus: vmStatus,
-			}
-			vmStatusList = append(vmStatusList, &vmStatusInfo)
+		taskState, faultMessage := getServerState(result.Body)
+		switch {
+		case current.Status == "VERIFY_RESIZE":
+			return true, nil
+		case current.Status == "ERROR":
+			return false, errors.New(fmt.Sprintf("failed to resize server %s to flavor %s: %s", vmID, newSpec, faultMessage))
+		case current.Status == "RESIZE" || taskState != "":
+			resizeStarted = true
+		case resizeStarted && current.Flavor["id"] != newSpec:
+			return false, errors.New(fmt.Sprintf("flavor %s is not available in the zone of server %s", newSpec, vmID))
 		}
-		return true, nil
+		return false, nil
 	})
 	if err != nil {
-		panic(err)
+		return irs.VMInfo{}, err
 	}
 
-	return vmStatusList
+	err = servers.ConfirmResize(vmHandler.Client, vmID).ExtractErr()
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	err = servers.WaitForStatus(vmHandler.Client, vmID, originalStatus, resizeWaitSeconds)
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	server, err = servers.Get(vmHandler.Client, vmID).Extract()
+	if err != nil {
+		return irs.VMInfo{}, err
+	}
+	return vmHandler.getServerInfo(*server)
 }
 
-func (vmHandler *OpenStackVMHandler) GetVMStatus(vmID string) irs.VMStatus {
-	serverResult, err := servers.Get(vmHandler.Client, vmID).Extract()
+// gophercloud has no console output API of servers, so the os-getConsoleOutput action is requested directly.
+// nova cuts the last lines of the log by the length.
+func (vmHandler *OpenStackVMHandler) GetVMConsoleOutput(vmID string, tailLines int) (string, error) {
+	getOpts := map[string]interface{}{}
+	if tailLines > 0 {
+		getOpts["length"] = tailLines
+	}
+	reqBody := map[string]interface{}{
+		"os-getConsoleOutput": getOpts,
+	}
+	var body interface{}
+	_, err := vmHandler.Client.Post(vmHandler.Client.ServiceURL("servers", vmID, "action"), reqBody, &body, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	})
 	if err != nil {
-		panic(err)
+		return "", errors.New(fmt.Sprintf("unable to get the console output of server %s, %v", vmID, err))
 	}
-	return irs.VMStatus(serverResult.Status)
+	var result struct {
+		Output string `mapstructure:"output"`
+	}
+	if err := mapstructure.Decode(body, &result); err != nil {
+		return "", err
+	}
+	return result.Output, nil
 }
 
-func (vmHandler *OpenStackVMHandler) ListVM() []*irs.VMInfo {
-	var vmList []*irs.VMInfo
+// listServerPage gets a page of servers, only the first page of the pager is read.
+func (vmHandler *OpenStackVMHandler) listServerPage(listReqInfo irs.ListReqInfo) ([]servers.Server, irs.ListPageInfo, error) {
+	listOpts := servers.ListOpts{
+		Name:   listReqInfo.NameFilter, // regular expression on the nova side, exact match is checked below.
+		Limit:  getLimit(listReqInfo),
+		Marker: listReqInfo.NextToken,
+	}
 
-	pager := servers.List(vmHandler.Client, nil)
+	var serverList []servers.Server
+	pager := servers.List(vmHandler.Client, listOpts)
 	err := pager.EachPage(func(page pagination.Page) (bool, error) {
 		// Get Servers
 		list, err := servers.ExtractServers(page)
 		if err != nil {
 			return false, err
 		}
-		// Add to List
-		for _, s := range list {
-			vmInfo := mappingServerInfo(s)
-			vmList = append(vmList, &vmInfo)
-		}
-		return true, nil
+		serverList = list
+		return false, nil
 	})
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	pageInfo := irs.ListPageInfo{}
+	if len(serverList) != 0 {
+		pageInfo = getMarkerPageInfo(len(serverList), listOpts.Limit, serverList[len(serverList)-1].ID)
+	}
+
+	// Filter by name, metadata
+	var filteredList []servers.Server
+	for _, s := range serverList {
+		if matchListFilter(listReqInfo, s.Name, getServerMetadata(s.Metadata)) {
+			filteredList = append(filteredList, s)
+		}
+	}
+
+	return filteredList, pageInfo, nil
+}
+
+func (vmHandler *OpenStackVMHandler) ListVMStatus(listReqInfo irs.ListReqInfo) ([]*irs.VMStatusInfo, irs.ListPageInfo, error) {
+	var vmStatusList []*irs.VMStatusInfo
+
+	serverList, pageInfo, err := vmHandler.listServerPage(listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	// Add to List
+	for _, s := range serverList {
+		vmStatus := irs.VMStatus(s.Status)
+		vmStatusInfo := irs.VMStatusInfo{
+			VmId:     s.ID,
+			VmStatus: vmStatus,
+		}
+		vmStatusList = append(vmStatusList, &vmStatusInfo)
+	}
+
+	return vmStatusList, pageInfo, nil
+}
+
+func (vmHandler *OpenStackVMHandler) GetVMStatus(vmID string) irs.VMStatus {
+	serverResult, err := servers.Get(vmHandler.Client, vmID).Extract()
 	if err != nil {
 		panic(err)
 	}
+	return irs.VMStatus(serverResult.Status)
+}
+
+func (vmHandler *OpenStackVMHandler) ListVM(listReqInfo irs.ListReqInfo) ([]*irs.VMInfo, irs.ListPageInfo, error) {
+	var vmList []*irs.VMInfo
+
+	serverList, pageInfo, err := vmHandler.listServerPage(listReqInfo)
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	// Add to List
+	for _, s := range serverList {
+		vmInfo, err := vmHandler.getServerInfo(s)
+		if err != nil {
+			return nil, irs.ListPageInfo{}, err
+		}
+		vmList = append(vmList, &vmInfo)
+	}
 
-	return vmList
+	return vmList, pageInfo, nil
 }
 
 func (vmHandler *OpenStackVMHandler) GetVM(vmID string) irs.VMInfo {
@@ -157,39 +484,129 @@ func (vmHandler *OpenStackVMHandler) GetVM(vmID string) irs.VMInfo {
 		return irs.VMInfo{}
 	}
 
-	vmInfo := mappingServerInfo(*serverResult)
+	vmInfo, err := vmHandler.getServerInfo(*serverResult)
+	if err != nil {
+		fmt.Println(err)
+	}
 	return vmInfo
 }
 
+// mappingServerInfo maps a server into irs.VMInfo with the addresses of the server,
+// the first fixed and floating IPv4 addresses of the networks(sorted by name) are the private and public IPs.
+// The NICs, security groups and volumes of the server are added by getServerInfo.
 func mappingServerInfo(server servers.Server) irs.VMInfo {
 
 	// Get Default VM Info
 	vmInfo := irs.VMInfo{
-		Name: server.Name,
-		Id:   server.ID,
-		//StartTime: server.Created,
+		Name:      server.Name,
+		Id:        server.ID,
+		StartTime: getTime(server.Created),
 		KeyPairID: server.KeyName,
+		Tags:      getServerMetadata(server.Metadata),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Status", Value: server.Status},
+		},
 	}
 
-	if len(server.Image) != 0 {
-		vmInfo.ImageID = server.Image["id"].(string)
+	// the image of a server booted from a volume is empty.
+	if imageID, ok := server.Image["id"].(string); ok {
+		vmInfo.ImageID = imageID
 	}
-	if len(server.Flavor) != 0 {
-		vmInfo.SpecID = server.Flavor["id"].(string)
+	if flavorID, ok := server.Flavor["id"].(string); ok {
+		vmInfo.SpecID = flavorID
+	}
+	for _, securityGroup := range server.SecurityGroups {
+		vmInfo.KeyValueList = append(vmInfo.KeyValueList, irs.KeyValue{Key: "SecurityGroupName", Value: fmt.Sprint(securityGroup["name"])})
 	}
 
-	// Get VM Subnet, Address Info
-	for k, subnet := range server.Addresses {
-		vmInfo.SubNetworkID = k
-		for _, addr := range subnet.([]interface{}) {
-			addrMap := addr.(map[string]interface{})
-			if addrMap["OS-EXT-IPS:type"] == "floating" {
-				vmInfo.PrivateIP = addrMap["addr"].(string)
-			} else if addrMap["OS-EXT-IPS:type"] == "fixed" {
-				vmInfo.PublicIP = addrMap["addr"].(string)
+	// Get VM Address Info, the keys of the addresses are the names of the networks.
+	var networkNames []string
+	for networkName := range server.Addresses {
+		networkNames = append(networkNames, networkName)
+	}
+	sort.Strings(networkNames)
+	for _, networkName := range networkNames {
+		addrList, ok := server.Addresses[networkName].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, addr := range addrList {
+			addrMap, ok := addr.(map[string]interface{})
+			if !ok || fmt.Sprint(addrMap["version"]) != "4" {
+				continue
+			}
+			ip := fmt.Sprint(addrMap["addr"])
+			if addrMap["OS-EXT-IPS:type"] == "floating" && vmInfo.PublicIP == "" {
+				vmInfo.PublicIP = ip
+			} else if addrMap["OS-EXT-IPS:type"] == "fixed" && vmInfo.PrivateIP == "" {
+				vmInfo.PrivateIP = ip
+				vmInfo.KeyValueList = append(vmInfo.KeyValueList, irs.KeyValue{Key: "NetworkName", Value: networkName})
 			}
 		}
 	}
 
 	return vmInfo
 }
+
+// getServerInfo maps a server with its ports and volumes.
+// The primary NIC is the port of the private IP, the security groups are of all the ports of the server.
+func (vmHandler *OpenStackVMHandler) getServerInfo(server servers.Server) (irs.VMInfo, error) {
+	vmInfo := mappingServerInfo(server)
+
+	// Get VM Network, Subnet, SecurityGroup Info of the ports
+	pager := ports.List(vmHandler.NetworkClient, ports.ListOpts{DeviceID: server.ID})
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := ports.ExtractPorts(page)
+		if err != nil {
+			return false, err
+		}
+		for _, port := range list {
+			for _, fixedIP := range port.FixedIPs {
+				if fixedIP.IPAddress == vmInfo.PrivateIP {
+					vmInfo.VNIC = port.ID
+					vmInfo.VNetworkID = port.NetworkID
+					vmInfo.SubNetworkID = fixedIP.SubnetID
+				}
+			}
+			for _, securityGroupID := range port.SecurityGroups {
+				if !containsString(vmInfo.SecurityGroupIds, securityGroupID) {
+					vmInfo.SecurityGroupIds = append(vmInfo.SecurityGroupIds, securityGroupID)
+				}
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return vmInfo, err
+	}
+	if len(vmInfo.SecurityGroupIds) != 0 {
+		vmInfo.SecurityID = vmInfo.SecurityGroupIds[0]
+	}
+
+	// Get VM Disk Info, the root volume of a server booted from a volume is /dev/vda(or /dev/sda).
+	bootFromVolume := vmInfo.ImageID == ""
+	pager = volumeattach.List(vmHandler.Client, server.ID)
+	err = pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := volumeattach.ExtractVolumeAttachments(page)
+		if err != nil {
+			return false, err
+		}
+		for _, attachment := range list {
+			if bootFromVolume && (attachment.Device == "/dev/vda" || attachment.Device == "/dev/sda") {
+				vmInfo.RootDiskId = attachment.VolumeID
+				vmInfo.GuestBootDisk = attachment.Device
+				continue
+			}
+			if vmInfo.GuestBlockDisk == "" {
+				vmInfo.GuestBlockDisk = attachment.Device
+			}
+			vmInfo.DataDiskIds = append(vmInfo.DataDiskIds, attachment.VolumeID)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return vmInfo, err
+	}
+
+	return vmInfo, nil
+}
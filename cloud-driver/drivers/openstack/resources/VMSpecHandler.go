@@ -0,0 +1,87 @@
+package resources
+
+import (
+	"errors"
+	"strconv"
+
+	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack/compute/v2/flavors"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+type OpenStackVMSpecHandler struct {
+	Client *gophercloud.ServiceClient
+}
+
+// mappingVMSpecInfo maps a nova flavor into irs.VMSpecInfo.
+// The GPUs of a flavor are in its extra specs(ex: pci_passthrough:alias), so GpuCount is not known.
+func mappingVMSpecInfo(flavor flavors.Flavor) irs.VMSpecInfo {
+	return irs.VMSpecInfo{
+		Id:           flavor.ID,
+		Name:         flavor.Name,
+		VCpuCount:    flavor.VCPUs,
+		MemoryMiB:    int64(flavor.RAM),
+		LocalDiskGiB: int64(flavor.Disk),
+		KeyValueList: []irs.KeyValue{
+			{Key: "Swap", Value: strconv.Itoa(flavor.Swap)},
+			{Key: "RxTxFactor", Value: strconv.FormatFloat(flavor.RxTxFactor, 'f', -1, 64)},
+		},
+	}
+}
+
+// Flavors have no name filter, so all flavors are searched for NameFilter without paging.
+func (vmSpecHandler *OpenStackVMSpecHandler) ListVMSpec(listReqInfo irs.ListReqInfo) ([]*irs.VMSpecInfo, irs.ListPageInfo, error) {
+	if err := checkTagFilter(listReqInfo, "flavor"); err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var vmSpecList []*irs.VMSpecInfo
+
+	listOpts := flavors.ListOpts{}
+	if listReqInfo.NameFilter == "" {
+		listOpts.Limit = getLimit(listReqInfo)
+		listOpts.Marker = listReqInfo.NextToken
+	}
+
+	var pageInfo irs.ListPageInfo
+	pager := flavors.ListDetail(vmSpecHandler.Client, listOpts)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		// Get Flavor
+		list, err := flavors.ExtractFlavors(page)
+		if err != nil {
+			return false, err
+		}
+		for _, f := range list {
+			if !matchListFilter(listReqInfo, f.Name, nil) {
+				continue
+			}
+			vmSpecInfo := mappingVMSpecInfo(f)
+			vmSpecList = append(vmSpecList, &vmSpecInfo)
+		}
+		if listReqInfo.NameFilter != "" {
+			return true, nil
+		}
+		if len(list) != 0 {
+			pageInfo = getMarkerPageInfo(len(list), listOpts.Limit, list[len(list)-1].ID)
+		}
+		// only the first page, the next page starts from the marker.
+		return false, nil
+	})
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	return vmSpecList, pageInfo, nil
+}
+
+func (vmSpecHandler *OpenStackVMSpecHandler) GetVMSpec(specID string) (irs.VMSpecInfo, error) {
+	if specID == "" {
+		return irs.VMSpecInfo{}, errors.New("no flavor ID")
+	}
+	flavor, err := flavors.Get(vmSpecHandler.Client, specID).Extract()
+	if err != nil {
+		return irs.VMSpecInfo{}, err
+	}
+	return mappingVMSpecInfo(*flavor), nil
+}
@@ -1,6 +1,10 @@
 package resources
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/rackspace/gophercloud"
@@ -13,66 +17,78 @@ type OpenStackVNetworkHandler struct {
 	Client *gophercloud.ServiceClient
 }
 
-type VNetworkInfo struct {
-	ID           string
-	Name         string
-	AdminStateUp bool
-	Status       string
-	Subnets      []string
-	TenantID     string
-	Shared       bool
+// getSubnets gets the subnets of a network, all subnets of the project for "" networkID.
+// The result is keyed by subnet ID.
+func (vNetworkHandler *OpenStackVNetworkHandler) getSubnets(networkID string) (map[string]subnets.Subnet, error) {
+	subnetMap := map[string]subnets.Subnet{}
+
+	pager := subnets.List(vNetworkHandler.Client, subnets.ListOpts{NetworkID: networkID})
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		list, err := subnets.ExtractSubnets(page)
+		if err != nil {
+			return false, err
+		}
+		for _, s := range list {
+			subnetMap[s.ID] = s
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subnetMap, nil
 }
 
-func (vNetworkInfo *VNetworkInfo) setter(network networks.Network) *VNetworkInfo {
-	vNetworkInfo.ID = network.ID
-	vNetworkInfo.Name = network.Name
-	vNetworkInfo.AdminStateUp = network.AdminStateUp
-	vNetworkInfo.Status = network.Status
-	vNetworkInfo.Subnets = network.Subnets
-	vNetworkInfo.TenantID = network.TenantID
-	vNetworkInfo.Shared = network.Shared
+// mappingVNetworkInfo maps a neutron network and its subnets into irs.VNetworkInfo.
+// A network has no address space of its own, so AddressPrefixes are the CIDRs of the subnets.
+func mappingVNetworkInfo(network networks.Network, tags map[string]string, subnetMap map[string]subnets.Subnet) irs.VNetworkInfo {
+	vNetworkInfo := irs.VNetworkInfo{
+		Id:     network.ID,
+		Name:   network.Name,
+		Tags:   tags,
+		Status: network.Status,
+		Owner:  network.TenantID,
+		KeyValueList: []irs.KeyValue{
+			{Key: "AdminStateUp", Value: strconv.FormatBool(network.AdminStateUp)},
+			{Key: "Shared", Value: strconv.FormatBool(network.Shared)},
+		},
+	}
+
+	for _, subnetID := range network.Subnets {
+		subnetInfo := irs.SubnetInfo{Id: subnetID, VNetworkId: network.ID}
+		if subnet, ok := subnetMap[subnetID]; ok {
+			subnetInfo = mappingSubnetInfo(subnet)
+			vNetworkInfo.AddressPrefixes = append(vNetworkInfo.AddressPrefixes, subnet.CIDR)
+		}
+		vNetworkInfo.Subnets = append(vNetworkInfo.Subnets, subnetInfo)
+	}
+	if len(network.Subnets) != 0 {
+		vNetworkInfo.SubnetId = network.Subnets[0]
+	}
 
 	return vNetworkInfo
 }
 
+// A neutron network has no address space, so the AddressPrefixes are only used to check the subnets.
+// Subnets are regional, so the zones of subnets are rejected.
 func (vNetworkHandler *OpenStackVNetworkHandler) CreateVNetwork(vNetworkReqInfo irs.VNetworkReqInfo) (irs.VNetworkInfo, error) {
 
-	// @TODO: vNetwork 생성 요청 파라미터 정의 필요
-	type IPPool struct {
-		Start string
-		End   string
+	if _, err := getTagList(vNetworkReqInfo.Tags); err != nil {
+		return irs.VNetworkInfo{}, err
 	}
-	type VNetworkReqInfo struct {
-		Name          string
-		AdminStateUp  bool
-		CIDR          string
-		IPVersion     int
-		SubnetName    string
-		IPPool        []IPPool
-		DNSNameServer []string
+	if err := irs.CheckVNetworkReqInfo(vNetworkReqInfo); err != nil {
+		return irs.VNetworkInfo{}, err
 	}
-
-	reqInfo := VNetworkReqInfo{
-		Name:         vNetworkReqInfo.Name,
-		AdminStateUp: *networks.Up,
-		CIDR:         "30.0.0.0/24",
-		IPVersion:    subnets.IPv4,
-		SubnetName:   "default",
-		IPPool: []IPPool{
-			{
-				Start: "30.0.0.2",
-				End:   "30.0.0.254",
-			},
-		},
-		DNSNameServer: []string{
-			"8.8.8.8",
-		},
+	for _, subnet := range vNetworkReqInfo.Subnets {
+		if subnet.Zone != "" {
+			return irs.VNetworkInfo{}, errors.New("zones of subnets are not supported by OpenStack(neutron), subnet: " + subnet.Name)
+		}
 	}
 
 	// Create vNetwork
 	createOpts := networks.CreateOpts{
-		Name:         reqInfo.Name,
-		AdminStateUp: &reqInfo.AdminStateUp,
+		Name:         vNetworkReqInfo.Name,
+		AdminStateUp: networks.Up,
 	}
 	network, err := networks.Create(vNetworkHandler.Client, createOpts).Extract()
 	if err != nil {
@@ -80,73 +96,90 @@ func (vNetworkHandler *OpenStackVNetworkHandler) CreateVNetwork(vNetworkReqInfo
 	}
 	spew.Dump(network)
 
-	// Set IPPool
-	var AllocationPool []subnets.AllocationPool
-	for _, IPPool := range reqInfo.IPPool {
-		pool := subnets.AllocationPool{
-			Start: IPPool.Start,
-			End:   IPPool.End,
+	// the network without the requested tags and subnets is useless, so it is deleted with the created subnets.
+	deleteNetwork := func() {
+		if deleteErr := networks.Delete(vNetworkHandler.Client, network.ID).ExtractErr(); deleteErr != nil {
+			fmt.Println(deleteErr)
 		}
-		AllocationPool = append(AllocationPool, pool)
 	}
 
-	// Create Subnet
-	subnetCreateOpts := subnets.CreateOpts{
-		NetworkID:       network.ID,
-		CIDR:            reqInfo.CIDR,
-		IPVersion:       reqInfo.IPVersion,
-		Name:            reqInfo.SubnetName,
-		AllocationPools: AllocationPool,
-		DNSNameservers:  reqInfo.DNSNameServer,
+	// Set Tags
+	if err := setNeutronTags(vNetworkHandler.Client, "networks", network.ID, vNetworkReqInfo.Tags); err != nil {
+		deleteNetwork()
+		return irs.VNetworkInfo{}, err
 	}
 
-	subnet, err := subnets.Create(vNetworkHandler.Client, subnetCreateOpts).Extract()
-	if err != nil {
-		return irs.VNetworkInfo{}, err
+	// Create Subnets
+	for _, subnet := range vNetworkReqInfo.Subnets {
+		if _, err := createSubnet(vNetworkHandler.Client, network.ID, subnet); err != nil {
+			deleteNetwork()
+			return irs.VNetworkInfo{}, err
+		}
 	}
-	spew.Dump(subnet)
 
-	// @TODO: 생성된 vNetwork 정보 리턴
-	return irs.VNetworkInfo{Id: network.ID, SubnetId: subnet.ID}, nil
+	return vNetworkHandler.GetVNetwork(network.ID)
 }
 
-func (vNetworkHandler *OpenStackVNetworkHandler) ListVNetwork() ([]*irs.VNetworkInfo, error) {
-	var vNetworkIList []*VNetworkInfo
+func (vNetworkHandler *OpenStackVNetworkHandler) ListVNetwork(listReqInfo irs.ListReqInfo) ([]*irs.VNetworkInfo, irs.ListPageInfo, error) {
+	var vNetworkList []*irs.VNetworkInfo
 
-	pager := networks.List(vNetworkHandler.Client, nil)
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+	listOpts := networks.ListOpts{
+		Name:   listReqInfo.NameFilter,
+		Limit:  getLimit(listReqInfo),
+		Marker: listReqInfo.NextToken,
+	}
+
+	// subnets of the networks in the page are got with one call.
+	subnetMap, err := vNetworkHandler.getSubnets("")
+	if err != nil {
+		return nil, irs.ListPageInfo{}, err
+	}
+
+	var pageInfo irs.ListPageInfo
+	pager := networks.List(vNetworkHandler.Client, listOpts)
+	err = pager.EachPage(func(page pagination.Page) (bool, error) {
 		// Get vNetwork
 		list, err := networks.ExtractNetworks(page)
 		if err != nil {
 			return false, err
 		}
+		if len(list) != 0 {
+			pageInfo = getMarkerPageInfo(len(list), listOpts.Limit, list[len(list)-1].ID)
+		}
+		tagMap := extractNeutronTagMap(page.(networks.NetworkPage).Body, "networks")
 		// Add to List
 		for _, n := range list {
-			vNetworkInfo := new(VNetworkInfo).setter(n)
-			vNetworkIList = append(vNetworkIList, vNetworkInfo)
+			if !matchListFilter(listReqInfo, n.Name, tagMap[n.ID]) {
+				continue
+			}
+			vNetworkInfo := mappingVNetworkInfo(n, tagMap[n.ID], subnetMap)
+			vNetworkList = append(vNetworkList, &vNetworkInfo)
 		}
-		return true, nil
+		// only the first page, the next page starts from the marker.
+		return false, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
 	}
 
-	spew.Dump(vNetworkIList)
-	return nil, nil
+	return vNetworkList, pageInfo, nil
 }
 
 func (vNetworkHandler *OpenStackVNetworkHandler) GetVNetwork(vNetworkID string) (irs.VNetworkInfo, error) {
-	network, err := networks.Get(vNetworkHandler.Client, vNetworkID).Extract()
+	result := networks.Get(vNetworkHandler.Client, vNetworkID)
+	network, err := result.Extract()
 	if err != nil {
 		return irs.VNetworkInfo{}, err
 	}
 
-	if network != nil {
-		vNetworkInfo := new(VNetworkInfo).setter(*network)
-		spew.Dump(vNetworkInfo)
+	subnetMap, err := vNetworkHandler.getSubnets(vNetworkID)
+	if err != nil {
+		return irs.VNetworkInfo{}, err
 	}
 
-	return irs.VNetworkInfo{}, nil
+	vNetworkInfo := mappingVNetworkInfo(*network, extractNeutronTags(result.Body, "network"), subnetMap)
+	spew.Dump(vNetworkInfo)
+	return vNetworkInfo, nil
 }
 
 func (vNetworkHandler *OpenStackVNetworkHandler) DeleteVNetwork(vNetworkID string) (bool, error) {
@@ -1,6 +1,9 @@
 package resources
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/Azure/go-autorest/autorest/to"
 	irs "github.com/cloud-barista/poc-cb-spider/cloud-driver/interfaces/resources"
 	"github.com/davecgh/go-spew/spew"
@@ -13,68 +16,46 @@ type OpenStackVNicworkHandler struct {
 	Client *gophercloud.ServiceClient
 }
 
-// @TODO: KeyPairInfo 리소스 프로퍼티 정의 필요
-type FixedIPInfo struct {
-	SubnetId  string
-	IPAddress string
-}
-type AddressPairInfo struct {
-	IPAddress  string
-	MACAddress string
-}
-type PortInfo struct {
-	Id                  string
-	NetworkId           string
-	Name                string
-	AdminStateUp        bool
-	Status              string
-	MACAddress          string
-	FixedIPs            []FixedIPInfo
-	TenantID            string
-	DeviceOwner         string
-	SecurityGroups      []string
-	DeviceID            string
-	AllowedAddressPairs []AddressPairInfo
-}
-
-func (portInfo *PortInfo) setter(port ports.Port) *PortInfo {
-	portInfo.Id = port.ID
-	portInfo.NetworkId = port.NetworkID
-	portInfo.Name = port.Name
-	portInfo.AdminStateUp = port.AdminStateUp
-	portInfo.MACAddress = port.MACAddress
-	portInfo.TenantID = port.TenantID
-	portInfo.DeviceOwner = port.DeviceOwner
-	portInfo.SecurityGroups = port.SecurityGroups
-	portInfo.DeviceID = port.DeviceID
-
-	var fixedIPArr []FixedIPInfo
-	var allowedAddressPairArr []AddressPairInfo
-
-	for _, ip := range port.FixedIPs {
-		IPInfo := FixedIPInfo{
-			SubnetId:  ip.SubnetID,
-			IPAddress: ip.IPAddress,
-		}
-		fixedIPArr = append(fixedIPArr, IPInfo)
+// mappingVNicInfo maps a neutron port into irs.VNicInfo.
+// The addresses are of the first fixed IP of the port.
+func mappingVNicInfo(port ports.Port, tags map[string]string) irs.VNicInfo {
+	vNicInfo := irs.VNicInfo{
+		Id:               port.ID,
+		Name:             port.Name,
+		Tags:             tags,
+		VNetworkId:       port.NetworkID,
+		MacAddress:       port.MACAddress,
+		SecurityGroupIds: port.SecurityGroups,
+		Status:           port.Status,
+		Owner:            port.TenantID,
+		KeyValueList: []irs.KeyValue{
+			{Key: "DeviceOwner", Value: port.DeviceOwner},
+			{Key: "DeviceID", Value: port.DeviceID},
+			{Key: "AdminStateUp", Value: strconv.FormatBool(port.AdminStateUp)},
+		},
 	}
 
+	// the device of a VM port is the server. ex) compute:nova
+	if strings.HasPrefix(port.DeviceOwner, "compute:") {
+		vNicInfo.OwnedVMId = port.DeviceID
+	}
+	if len(port.FixedIPs) != 0 {
+		vNicInfo.SubnetId = port.FixedIPs[0].SubnetID
+		vNicInfo.PrivateIP = port.FixedIPs[0].IPAddress
+	}
 	for _, addressPair := range port.AllowedAddressPairs {
-		addressPairInfo := AddressPairInfo{
-			IPAddress:  addressPair.IPAddress,
-			MACAddress: addressPair.MACAddress,
-		}
-		allowedAddressPairArr = append(allowedAddressPairArr, addressPairInfo)
+		vNicInfo.KeyValueList = append(vNicInfo.KeyValueList, irs.KeyValue{Key: "AllowedAddressPair", Value: addressPair.IPAddress + "," + addressPair.MACAddress})
 	}
 
-	portInfo.FixedIPs = fixedIPArr
-	portInfo.AllowedAddressPairs = allowedAddressPairArr
-
-	return portInfo
+	return vNicInfo
 }
 
 func (vNicHandler *OpenStackVNicworkHandler) CreateVNic(vNicReqInfo irs.VNicReqInfo) (irs.VNicInfo, error) {
 
+	if _, err := getTagList(vNicReqInfo.Tags); err != nil {
+		return irs.VNicInfo{}, err
+	}
+
 	// @TODO: Port 생성 요청 파라미터 정의 필요
 	type PortReqInfo struct {
 		NetworkId    string
@@ -100,46 +81,66 @@ func (vNicHandler *OpenStackVNicworkHandler) CreateVNic(vNicReqInfo irs.VNicReqI
 	if err != nil {
 		return irs.VNicInfo{}, err
 	}
+	// the port without the requested tags is deleted.
+	if err := setNeutronTags(vNicHandler.Client, "ports", port.ID, vNicReqInfo.Tags); err != nil {
+		ports.Delete(vNicHandler.Client, port.ID)
+		return irs.VNicInfo{}, err
+	}
 
 	spew.Dump(port)
-	return irs.VNicInfo{Id: port.ID, Name: port.Name}, nil
+	return mappingVNicInfo(*port, vNicReqInfo.Tags), nil
 }
 
-func (vNicHandler *OpenStackVNicworkHandler) ListVNic() ([]*irs.VNicInfo, error) {
-	var portList []PortInfo
+func (vNicHandler *OpenStackVNicworkHandler) ListVNic(listReqInfo irs.ListReqInfo) ([]*irs.VNicInfo, irs.ListPageInfo, error) {
+	var vNicList []*irs.VNicInfo
+
+	listOpts := ports.ListOpts{
+		Name:   listReqInfo.NameFilter,
+		Limit:  getLimit(listReqInfo),
+		Marker: listReqInfo.NextToken,
+	}
 
-	pager := ports.List(vNicHandler.Client, nil)
+	var pageInfo irs.ListPageInfo
+	pager := ports.List(vNicHandler.Client, listOpts)
 	err := pager.EachPage(func(page pagination.Page) (bool, error) {
 		// Get Port
 		list, err := ports.ExtractPorts(page)
 		if err != nil {
 			return false, err
 		}
+		if len(list) != 0 {
+			pageInfo = getMarkerPageInfo(len(list), listOpts.Limit, list[len(list)-1].ID)
+		}
 		// Add to Port
+		tagMap := extractNeutronTagMap(page.(ports.PortPage).Body, "ports")
 		for _, p := range list {
-			PortInfo := new(PortInfo).setter(p)
-			portList = append(portList, *PortInfo)
+			if !matchListFilter(listReqInfo, p.Name, tagMap[p.ID]) {
+				continue
+			}
+			vNicInfo := mappingVNicInfo(p, tagMap[p.ID])
+			vNicList = append(vNicList, &vNicInfo)
 		}
-		return true, nil
+		// only the first page, the next page starts from the marker.
+		return false, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, irs.ListPageInfo{}, err
 	}
 
-	spew.Dump(portList)
-	return nil, nil
+	return vNicList, pageInfo, nil
 }
 
 func (vNicHandler *OpenStackVNicworkHandler) GetVNic(vNicID string) (irs.VNicInfo, error) {
-	port, err := ports.Get(vNicHandler.Client, vNicID).Extract()
+	result := ports.Get(vNicHandler.Client, vNicID)
+	port, err := result.Extract()
 	if err != nil {
 		return irs.VNicInfo{}, err
 	}
 
-	portInfo := new(PortInfo).setter(*port)
+	vNicInfo := mappingVNicInfo(*port, extractNeutronTags(result.Body, "port"))
 
-	spew.Dump(portInfo)
-	return irs.VNicInfo{}, nil
+	spew.Dump(vNicInfo)
+	return vNicInfo, nil
 }
 
 func (vNicHandler *OpenStackVNicworkHandler) DeleteVNic(vNicID string) (bool, error) {
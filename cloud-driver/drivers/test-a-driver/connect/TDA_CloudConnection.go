@@ -24,6 +24,10 @@ func (TADCloudConnection) CreateVNetworkHandler() (irs.VNetworkHandler, error) {
 }
 
 
+func (TADCloudConnection) CreateSubnetHandler() (irs.SubnetHandler, error) {
+	return nil, nil
+}
+
 func (TADCloudConnection) CreateImageHandler() (irs.ImageHandler, error) {
 	return nil, nil
 }
@@ -40,6 +44,21 @@ func (TADCloudConnection) CreateVNicHandler() (irs.VNicHandler, error) {
 func (TADCloudConnection) CreatePublicIPHandler() (irs.PublicIPHandler, error) {
 	return nil, nil
 }
+func (TADCloudConnection) CreateRouterHandler() (irs.RouterHandler, error) {
+	return nil, nil
+}
+func (TADCloudConnection) CreateVMSpecHandler() (irs.VMSpecHandler, error) {
+	return nil, nil
+}
+func (TADCloudConnection) CreateRegionZoneHandler() (irs.RegionZoneHandler, error) {
+	return nil, nil
+}
+func (TADCloudConnection) CreateDiskHandler() (irs.DiskHandler, error) {
+	return nil, nil
+}
+func (TADCloudConnection) CreateSnapshotHandler() (irs.SnapshotHandler, error) {
+	return nil, nil
+}
 
 func (TADCloudConnection) CreateVMHandler() (irs.VMHandler, error) {
 	return nil, nil
@@ -24,6 +24,10 @@ func (TBDCloudConnection) CreateVNetworkHandler() (irs.VNetworkHandler, error) {
 }
 
 
+func (TBDCloudConnection) CreateSubnetHandler() (irs.SubnetHandler, error) {
+	return nil, nil
+}
+
 func (TBDCloudConnection) CreateImageHandler() (irs.ImageHandler, error) {
 	return nil, nil
 }
@@ -40,6 +44,21 @@ func (TBDCloudConnection) CreateVNicHandler() (irs.VNicHandler, error) {
 func (TBDCloudConnection) CreatePublicIPHandler() (irs.PublicIPHandler, error) {
 	return nil, nil
 }
+func (TBDCloudConnection) CreateRouterHandler() (irs.RouterHandler, error) {
+	return nil, nil
+}
+func (TBDCloudConnection) CreateVMSpecHandler() (irs.VMSpecHandler, error) {
+	return nil, nil
+}
+func (TBDCloudConnection) CreateRegionZoneHandler() (irs.RegionZoneHandler, error) {
+	return nil, nil
+}
+func (TBDCloudConnection) CreateDiskHandler() (irs.DiskHandler, error) {
+	return nil, nil
+}
+func (TBDCloudConnection) CreateSnapshotHandler() (irs.SnapshotHandler, error) {
+	return nil, nil
+}
 
 func (TBDCloudConnection) CreateVMHandler() (irs.VMHandler, error) {
 	return nil, nil
@@ -27,8 +27,8 @@ type DriverCapabilityInfo struct {
 type CredentialInfo struct {
 	// @todo TBD
 	// key-value pairs
-	ClientId         string // Azure Credential
-	ClientSecret     string // Azure Credential
+	ClientId         string // Azure Credential, AWS Credential(Access Key ID)
+	ClientSecret     string // Azure Credential, AWS Credential(Secret Access Key)
 	TenantId         string // Azure Credential
 	SubscriptionId   string // Azure Credential
 	IdentityEndpoint string // OpenStack Credential
@@ -36,6 +36,9 @@ type CredentialInfo struct {
 	Password         string // OpenStack Credential
 	DomainName       string // OpenStack Credential
 	ProjectID        string // OpenStack Credential
+	SessionToken     string // AWS Credential(temporary credentials only)
+	Profile          string // AWS Credential(named profile of the shared credentials file)
+	RoleArn          string // AWS Credential(role to assume with the credential above)
 }
 
 type RegionInfo struct {
@@ -17,10 +17,16 @@ import (
 type CloudConnection interface {
 	CreateImageHandler() (irs.ImageHandler, error)
 	CreateVNetworkHandler() (irs.VNetworkHandler, error)
+	CreateSubnetHandler() (irs.SubnetHandler, error)
 	CreateSecurityHandler() (irs.SecurityHandler, error)
 	CreateKeyPairHandler() (irs.KeyPairHandler, error)
 	CreateVNicHandler() (irs.VNicHandler, error)
 	CreatePublicIPHandler() (irs.PublicIPHandler, error)
+	CreateRouterHandler() (irs.RouterHandler, error)
+	CreateVMSpecHandler() (irs.VMSpecHandler, error)
+	CreateRegionZoneHandler() (irs.RegionZoneHandler, error)
+	CreateDiskHandler() (irs.DiskHandler, error)
+	CreateSnapshotHandler() (irs.SnapshotHandler, error)
 
 	CreateVMHandler() (irs.VMHandler, error)
 
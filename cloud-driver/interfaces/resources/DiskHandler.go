@@ -0,0 +1,63 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DiskReqInfo is a data disk(AWS EBS volume, OpenStack Cinder volume, Azure managed disk).
+type DiskReqInfo struct {
+	Name string
+	Id   string
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	SizeGiB  int
+	DiskType string // "": default of the cloud. ex) gp2, io1, Cinder volume type, Premium_LRS
+	Zone     string // "": Zone of the connection. ex) ap-northeast-2a, nova, 1
+}
+
+type DiskInfo struct {
+	Name string
+	Id   string
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	SizeGiB     int
+	DiskType    string
+	Zone        string
+	Status      string    // ex) available, in-use, Unattached, Attached
+	VMId        string    // the VM attached to, "": not attached
+	Device      string    // the device in the VM. ex) /dev/sdf, LUN 0(Azure)
+	CreatedTime time.Time // Timezone: based on cloud-barista server location.
+
+	KeyValueList []KeyValue // provider-specific extras. ex) {"Encrypted", "false"}
+}
+
+// CheckDiskReqInfo checks the size of a disk.
+func CheckDiskReqInfo(diskReqInfo DiskReqInfo) error {
+	if diskReqInfo.SizeGiB <= 0 {
+		return errors.New(fmt.Sprintf("invalid size of disk %s: %d GiB", diskReqInfo.Name, diskReqInfo.SizeGiB))
+	}
+	return nil
+}
+
+// Disks can only grow by ResizeDisk.
+// A disk is attached to one VM in the same zone.
+type DiskHandler interface {
+	CreateDisk(diskReqInfo DiskReqInfo) (DiskInfo, error)
+	ListDisk(listReqInfo ListReqInfo) ([]*DiskInfo, ListPageInfo, error)
+	GetDisk(diskID string) (DiskInfo, error)
+	ResizeDisk(diskID string, sizeGiB int) (DiskInfo, error)
+	DeleteDisk(diskID string) (bool, error)
+
+	AttachDisk(diskID string, vmID string) (DiskInfo, error)
+	DetachDisk(diskID string, vmID string) (DiskInfo, error)
+}
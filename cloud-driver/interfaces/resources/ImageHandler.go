@@ -10,23 +10,38 @@
 
 package resources
 
+import (
+	"time"
+)
+
 //package image
 
 type ImageReqInfo struct {
 	Name string
 	Id   string
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	SnapshotId   string // the snapshot of a root disk the image is created from(see SnapshotHandler)
+	Architecture string // ex) x86_64, arm64, "": the architecture of the source of the snapshot(if known by the cloud)
 	// @todo
 }
 
 type ImageInfo struct {
 	Name string
 	Id   string
-	// @todo
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	OSType      string    // ex) Linux, Windows
+	Status      string    // ex) available, ACTIVE, Succeeded
+	CreatedTime time.Time // Timezone: based on cloud-barista server location.
+	Owner       string    // ex) AWS account ID, OpenStack project ID
+
+	KeyValueList []KeyValue // provider-specific extras. ex) {"MinDisk", "10"}
 }
 
 type ImageHandler interface {
 	CreateImage(imageReqInfo ImageReqInfo) (ImageInfo, error)
-	ListImage() ([]*ImageInfo, error)
+	ListImage(listReqInfo ListReqInfo) ([]*ImageInfo, ListPageInfo, error)
 	GetImage(imageID string) (ImageInfo, error)
 	DeleteImage(imageID string) (bool, error)
 }
@@ -0,0 +1,16 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+// KeyValue is a provider-specific information which has no field in the shared Info types.
+// ex) {"MinRAM", "512"}, {"VirtualizationType", "hvm"}
+type KeyValue struct {
+	Key   string
+	Value string
+}
@@ -0,0 +1,58 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ListReqInfo is the option of all List* methods of resource handlers.
+type ListReqInfo struct {
+	PageSize  int    // max count of items in a page, 0: driver default. ex) Azure decides the page size by itself.
+	NextToken string // ListPageInfo.NextToken of the previous page, "": first page
+
+	NameFilter string            // only resources with this name, "": all
+	TagFilter  map[string]string // only resources with all of these tags(key: value), nil: all
+}
+
+// ListPageInfo is the paging result of all List* methods.
+// A page can hold less items than PageSize(ex: filtered on the driver side),
+// so only an empty NextToken means the last page.
+type ListPageInfo struct {
+	NextToken string // pass to ListReqInfo.NextToken to get the next page, "": last page
+}
+
+// GetPageRange returns the range [start:end) of a page in total items for the APIs without paging
+// (ex: key pairs, security groups, floating IPs, VM sizes), cut on the driver side.
+// The continuation token is the offset of the next page.
+func GetPageRange(total int, listReqInfo ListReqInfo) (int, int, string, error) {
+	start := 0
+	if listReqInfo.NextToken != "" {
+		offset, err := strconv.Atoi(listReqInfo.NextToken)
+		if err != nil || offset < 0 {
+			return 0, 0, "", errors.New("invalid NextToken: " + listReqInfo.NextToken)
+		}
+		start = offset
+	}
+	if start > total {
+		start = total
+	}
+
+	end := total
+	if listReqInfo.PageSize > 0 && start+listReqInfo.PageSize < total {
+		end = start + listReqInfo.PageSize
+	}
+
+	nextToken := ""
+	if end < total {
+		nextToken = strconv.Itoa(end)
+	}
+	return start, end, nextToken, nil
+}
@@ -13,18 +13,31 @@ package resources
 type PublicIPReqInfo struct {
 	Name string
 	Id   string
-	// @todo
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	Pool string // AWS: public IPv4 pool, OpenStack: floating IP pool(external network), "": default pool. ex) public
+	Sku  string // Azure only, "": Basic. ex) Basic, Standard
 }
 
 type PublicIPInfo struct {
 	Name string
 	Id   string
-	// @todo
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	PublicIP  string // ex) 13.125.43.21
+	Status    string // ex) associated, available, Succeeded
+	OwnedVMId string // VM which the public IP is associated with, "": not associated or unknown
+
+	KeyValueList []KeyValue // provider-specific extras. ex) {"Pool", "public"}
 }
 
 type PublicIPHandler interface {
 	CreatePublicIP(publicIPReqInfo PublicIPReqInfo) (PublicIPInfo, error)
-	ListPublicIP() ([]*PublicIPInfo, error)
+	ListPublicIP(listReqInfo ListReqInfo) ([]*PublicIPInfo, ListPageInfo, error)
 	GetPublicIP(publicIPID string) (PublicIPInfo, error)
 	DeletePublicIP(publicIPID string) (bool, error)
+
+	// vmOrVNicID: the ID of a VM(its primary NIC) or a NIC(AWS: ENI, OpenStack: port).
+	AssociatePublicIP(publicIPID string, vmOrVNicID string) (bool, error)
+	DisassociatePublicIP(publicIPID string) (bool, error)
 }
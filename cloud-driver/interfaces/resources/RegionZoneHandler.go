@@ -0,0 +1,72 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+)
+
+// status of regions and zones.
+const (
+	RegionZoneAvailable   = "Available"
+	RegionZoneUnavailable = "Unavailable" // ex) AWS regions not opted in, impaired zones
+	RegionZoneUnknown     = "Unknown"     // ex) the zones of other OpenStack regions
+)
+
+type RegionZoneInfo struct {
+	Name        string // Region of RegionInfo. ex) ap-northeast-2, RegionOne, koreacentral
+	DisplayName string // ex) Korea Central
+	Status      string // RegionZoneAvailable, RegionZoneUnavailable, RegionZoneUnknown
+	ZoneList    []ZoneInfo
+
+	KeyValueList []KeyValue // provider-specific extras. ex) {"OptInStatus", "opt-in-not-required"}
+}
+
+type ZoneInfo struct {
+	Name   string // Zone of RegionInfo. ex) ap-northeast-2a, nova, 1
+	Status string
+}
+
+// CheckRegionZone checks that a region and the zone in it are available, "": no zone.
+func CheckRegionZone(regionZoneInfo RegionZoneInfo, zone string) error {
+	if regionZoneInfo.Status != RegionZoneAvailable {
+		return errors.New(fmt.Sprintf("region %s is not available, status: %s", regionZoneInfo.Name, regionZoneInfo.Status))
+	}
+	if zone == "" {
+		return nil
+	}
+	for _, zoneInfo := range regionZoneInfo.ZoneList {
+		if zoneInfo.Name != zone {
+			continue
+		}
+		if zoneInfo.Status != RegionZoneAvailable {
+			return errors.New(fmt.Sprintf("zone %s of region %s is not available, status: %s", zone, regionZoneInfo.Name, zoneInfo.Status))
+		}
+		return nil
+	}
+	return errors.New(fmt.Sprintf("no zone %s in region %s", zone, regionZoneInfo.Name))
+}
+
+// ValidateRegionZone checks the region and zone of a connection with its RegionZoneHandler.
+func ValidateRegionZone(regionZoneHandler RegionZoneHandler, region string, zone string) error {
+	if region == "" {
+		return errors.New("no region of the connection")
+	}
+	regionZoneInfo, err := regionZoneHandler.GetRegionZone(region)
+	if err != nil {
+		return err
+	}
+	return CheckRegionZone(regionZoneInfo, zone)
+}
+
+type RegionZoneHandler interface {
+	ListRegionZone() ([]*RegionZoneInfo, error)
+	GetRegionZone(regionName string) (RegionZoneInfo, error)
+}
@@ -0,0 +1,79 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// GatewayNextHop is the NextHop of a route to the gateway of a router(the Internet).
+const GatewayNextHop = "gateway"
+
+// A router is an OpenStack router, an AWS route table(with the internet gateway of its VPC)
+// or an Azure route table.
+type RouterReqInfo struct {
+	Name       string
+	Id         string            // Azure: name or ARM ID of the route table
+	Tags       map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+	VNetworkId string            // AWS: VPC of the route table
+	GatewayId  string            // OpenStack: external network, AWS: internet gateway(see SetGateway), Azure: not supported
+	Routes     []RouteInfo
+	SubnetIds  []string // subnets attached to the router
+}
+
+type RouteInfo struct {
+	DestinationCIDR string // ex) 0.0.0.0/0
+	// GatewayNextHop, an IP address(OpenStack, Azure) or the ID of a next hop resource(AWS: igw-, nat-, eni-, i-, pcx-, vgw-)
+	NextHop string
+}
+
+type RouterInfo struct {
+	Name       string
+	Id         string
+	Tags       map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+	VNetworkId string            // AWS: VPC of the route table
+	GatewayId  string
+	Routes     []RouteInfo // only the routes added by users, not the local routes of a network
+	SubnetIds  []string
+
+	Status string // ex) ACTIVE, Succeeded
+	Owner  string // ex) AWS account ID, OpenStack project ID
+
+	KeyValueList []KeyValue // provider-specific extras.
+}
+
+// CheckRoute checks the destination CIDR and the next hop of a route.
+func CheckRoute(route RouteInfo) error {
+	if _, _, err := net.ParseCIDR(route.DestinationCIDR); err != nil {
+		return errors.New(fmt.Sprintf("invalid destination CIDR %s of a route", route.DestinationCIDR))
+	}
+	if route.NextHop == "" {
+		return errors.New(fmt.Sprintf("no next hop of the route to %s", route.DestinationCIDR))
+	}
+	return nil
+}
+
+type RouterHandler interface {
+	CreateRouter(routerReqInfo RouterReqInfo) (RouterInfo, error)
+	ListRouter(listReqInfo ListReqInfo) ([]*RouterInfo, ListPageInfo, error)
+	GetRouter(routerID string) (RouterInfo, error)
+	DeleteRouter(routerID string) (bool, error) // the attached subnets are detached first
+
+	// AWS: "" gatewayID is the internet gateway of the VPC, created when the VPC has none.
+	SetGateway(routerID string, gatewayID string) (RouterInfo, error)
+	RemoveGateway(routerID string) (RouterInfo, error)
+
+	AddRoutes(routerID string, routes []RouteInfo) (RouterInfo, error)
+	RemoveRoutes(routerID string, routes []RouteInfo) (RouterInfo, error)
+
+	AttachSubnet(routerID string, subnetID string) (RouterInfo, error)
+	DetachSubnet(routerID string, subnetID string) (RouterInfo, error)
+}
@@ -10,21 +10,173 @@
 
 package resources
 
+import (
+	"errors"
+	"fmt"
+)
+
+// SecurityReqInfo is a security group with its rules.
+// Without outbound rules, the outbound traffic follows the default of the cloud(all allowed).
 type SecurityReqInfo struct {
 	Name string
 	Id   string
-	// @todo
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	Description   string
+	VNetworkId    string             // ex) vpc-23ed0a4b (AWS only, "": default VPC)
+	SecurityRules []SecurityRuleInfo // Id is ignored, see CheckSecurityRule()
 }
 
 type SecurityInfo struct {
 	Name string
 	Id   string
-	// @todo
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	Description   string
+	VNetworkId    string // ex) vpc-23ed0a4b, "": not bound to a virtual network
+	SecurityRules []SecurityRuleInfo
+	Owner         string // ex) AWS account ID, OpenStack project ID
+
+	KeyValueList []KeyValue // provider-specific extras.
+}
+
+// SecurityRuleInfo is a rule of a security group.
+// A rule has a CIDR or a PeerGroup as the other side of the traffic.
+type SecurityRuleInfo struct {
+	Id         string
+	Direction  string // inbound, outbound
+	IPProtocol string // tcp, udp, icmp, all
+	FromPort   int    // -1: all ports(or all types of icmp)
+	ToPort     int    // -1: all ports(or all codes of icmp)
+	CIDR       string // ex) 0.0.0.0/0
+	PeerGroup  string // ID of the security group of the other side, ex) sg-0b7452563e1121bb6
+	Action     string // allow, deny ("": allow)
+	Priority   int    // 0: no priority(or assigned by the driver), ex) Azure: 100 ~ 4096
+}
+
+// CheckSecurityRule checks the cloud-neutral constraints of a requested rule.
+// Drivers check what their clouds can not express(ex: deny rules on AWS) by themselves.
+func CheckSecurityRule(rule SecurityRuleInfo) error {
+	if rule.Direction != "inbound" && rule.Direction != "outbound" {
+		return errors.New(fmt.Sprintf("invalid direction %q of a security rule: inbound or outbound", rule.Direction))
+	}
+	if rule.Action != "" && rule.Action != "allow" && rule.Action != "deny" {
+		return errors.New(fmt.Sprintf("invalid action %q of a security rule: allow or deny", rule.Action))
+	}
+	if (rule.CIDR == "") == (rule.PeerGroup == "") {
+		return errors.New("a security rule needs one of CIDR or PeerGroup")
+	}
+	if rule.Priority < 0 {
+		return errors.New(fmt.Sprintf("invalid priority %d of a security rule", rule.Priority))
+	}
+
+	switch rule.IPProtocol {
+	case "all":
+		if rule.FromPort != -1 || rule.ToPort != -1 {
+			return errors.New("the ports of a security rule for all protocols must be -1")
+		}
+	case "tcp", "udp":
+		if rule.FromPort == -1 && rule.ToPort == -1 {
+			return nil
+		}
+		if rule.FromPort < 0 || rule.ToPort > 65535 || rule.FromPort > rule.ToPort {
+			return errors.New(fmt.Sprintf("invalid port range %d ~ %d of a %s security rule", rule.FromPort, rule.ToPort, rule.IPProtocol))
+		}
+	case "icmp":
+		// FromPort is the type, ToPort is the code of icmp.
+		if rule.FromPort < -1 || rule.FromPort > 255 || rule.ToPort < -1 || rule.ToPort > 255 {
+			return errors.New(fmt.Sprintf("invalid icmp type %d, code %d of a security rule", rule.FromPort, rule.ToPort))
+		}
+	default:
+		return errors.New(fmt.Sprintf("invalid protocol %q of a security rule: tcp, udp, icmp or all", rule.IPProtocol))
+	}
+	return nil
+}
+
+// MatchSecurityRule checks whether rule is the same rule as target regardless of the Id.
+// The priorities are compared only when both rules have one.
+func MatchSecurityRule(rule SecurityRuleInfo, target SecurityRuleInfo) bool {
+	if rule.Direction != target.Direction || rule.IPProtocol != target.IPProtocol ||
+		rule.FromPort != target.FromPort || rule.ToPort != target.ToPort ||
+		rule.CIDR != target.CIDR || rule.PeerGroup != target.PeerGroup {
+		return false
+	}
+	if getRuleAction(rule) != getRuleAction(target) {
+		return false
+	}
+	return rule.Priority == 0 || target.Priority == 0 || rule.Priority == target.Priority
+}
+
+func getRuleAction(rule SecurityRuleInfo) string {
+	if rule.Action == "" {
+		return "allow"
+	}
+	return rule.Action
+}
+
+// FindSecurityRules returns the rules of currentRules for rules to be removed.
+// A rule with an Id is found by the Id, the others by MatchSecurityRule().
+func FindSecurityRules(currentRules []SecurityRuleInfo, rules []SecurityRuleInfo) ([]SecurityRuleInfo, error) {
+	var foundRules []SecurityRuleInfo
+	found := make([]bool, len(currentRules))
+
+	for _, rule := range rules {
+		matched := false
+		for i, currentRule := range currentRules {
+			if rule.Id != "" && rule.Id != currentRule.Id {
+				continue
+			}
+			if rule.Id == "" && !MatchSecurityRule(rule, currentRule) {
+				continue
+			}
+			matched = true
+			if !found[i] {
+				found[i] = true
+				foundRules = append(foundRules, currentRule)
+			}
+		}
+		if !matched {
+			return nil, errors.New(fmt.Sprintf("no security rule %+v", rule))
+		}
+	}
+	return foundRules, nil
+}
+
+// DiffSecurityRules returns the rules of desiredRules not in currentRules(to be added)
+// and the rules of currentRules not in desiredRules(to be removed).
+func DiffSecurityRules(currentRules []SecurityRuleInfo, desiredRules []SecurityRuleInfo) ([]SecurityRuleInfo, []SecurityRuleInfo) {
+	var addRules, removeRules []SecurityRuleInfo
+
+	for _, desiredRule := range desiredRules {
+		if !containsSecurityRule(currentRules, desiredRule) {
+			addRules = append(addRules, desiredRule)
+		}
+	}
+	for _, currentRule := range currentRules {
+		if !containsSecurityRule(desiredRules, currentRule) {
+			removeRules = append(removeRules, currentRule)
+		}
+	}
+	return addRules, removeRules
+}
+
+func containsSecurityRule(rules []SecurityRuleInfo, target SecurityRuleInfo) bool {
+	for _, rule := range rules {
+		if MatchSecurityRule(rule, target) {
+			return true
+		}
+	}
+	return false
 }
 
 type SecurityHandler interface {
 	CreateSecurity(securityReqInfo SecurityReqInfo) (SecurityInfo, error)
-	ListSecurity() ([]*SecurityInfo, error)
+	ListSecurity(listReqInfo ListReqInfo) ([]*SecurityInfo, ListPageInfo, error)
 	GetSecurity(securityID string) (SecurityInfo, error)
 	DeleteSecurity(securityID string) (bool, error)
+
+	// Rules of a security group are changed in place, so the group can be in use by VMs.
+	AddRules(securityID string, securityRules []SecurityRuleInfo) (SecurityInfo, error)
+	RemoveRules(securityID string, securityRules []SecurityRuleInfo) (SecurityInfo, error) // by Id or by the same rule
+	SyncRules(securityID string, desiredRules []SecurityRuleInfo) (SecurityInfo, error)    // only the differences are applied
 }
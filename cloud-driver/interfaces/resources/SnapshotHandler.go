@@ -0,0 +1,62 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+import (
+	"errors"
+	"time"
+)
+
+// SnapshotReqInfo is a snapshot of a disk, or the snapshots of all the disks of a VM.
+type SnapshotReqInfo struct {
+	Name string // the snapshots of a VM are named {Name}-{n}, n: 0 for the root disk
+	Id   string
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	VMId   string // snapshots of all the disks of the VM
+	DiskId string // a snapshot of the disk, VMId is ignored
+}
+
+type SnapshotInfo struct {
+	Name string
+	Id   string
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	DiskId      string // the source disk
+	VMId        string // the VM of the source disk, set by CreateSnapshot
+	RootDisk    bool   // a snapshot of the root disk(images are created from it), set by CreateSnapshot(Azure: always)
+	SizeGiB     int
+	Status      string    // ex) pending, completed, available, Succeeded
+	CreatedTime time.Time // Timezone: based on cloud-barista server location.
+
+	KeyValueList []KeyValue // provider-specific extras. ex) {"Progress", "100%"}
+}
+
+// CheckSnapshotReqInfo checks the name and the source of snapshots.
+func CheckSnapshotReqInfo(snapshotReqInfo SnapshotReqInfo) error {
+	if snapshotReqInfo.Name == "" {
+		return errors.New("no name of snapshot")
+	}
+	if snapshotReqInfo.VMId == "" && snapshotReqInfo.DiskId == "" {
+		return errors.New("no VM nor disk of snapshot " + snapshotReqInfo.Name)
+	}
+	return nil
+}
+
+// CreateSnapshot returns the snapshots in the order of the disks of the VM(root disk first).
+// RestoreSnapshot creates a new disk(DiskReqInfo.SizeGiB 0: the size of the snapshot) from a snapshot,
+// images are created from the snapshot of a root disk by ImageHandler(ImageReqInfo.SnapshotId).
+type SnapshotHandler interface {
+	CreateSnapshot(snapshotReqInfo SnapshotReqInfo) ([]*SnapshotInfo, error)
+	ListSnapshot(listReqInfo ListReqInfo) ([]*SnapshotInfo, ListPageInfo, error)
+	GetSnapshot(snapshotID string) (SnapshotInfo, error)
+	DeleteSnapshot(snapshotID string) (bool, error)
+
+	RestoreSnapshot(snapshotID string, diskReqInfo DiskReqInfo) (DiskInfo, error)
+}
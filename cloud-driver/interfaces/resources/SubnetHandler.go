@@ -0,0 +1,83 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+type SubnetReqInfo struct {
+	Name       string
+	CIDR       string   // ex) 10.0.1.0/24, in one of the address prefixes of the VNetwork
+	Zone       string   // ex) ap-northeast-2a (AWS only, Azure and OpenStack subnets are regional)
+	DNSServers []string // ex) ["8.8.8.8"] (Azure: the same for all subnets of a VNetwork, AWS: not supported)
+	IPPools    []IPPool // allocation pools of the subnet (OpenStack only)
+}
+
+type IPPool struct {
+	Start string // ex) 10.0.1.10
+	End   string // ex) 10.0.1.200
+}
+
+type SubnetInfo struct {
+	Name       string
+	Id         string
+	VNetworkId string
+	CIDR       string // ex) 10.0.1.0/24
+	Zone       string // ex) ap-northeast-2a, "": regional subnet
+
+	KeyValueList []KeyValue // provider-specific extras.
+}
+
+// CheckSubnetReqInfo checks the CIDR, the DNS servers and the IP pools of a subnet.
+// The CIDR should be in one of addressPrefixes, it is not checked for empty addressPrefixes.
+func CheckSubnetReqInfo(subnet SubnetReqInfo, addressPrefixes []string) error {
+	if subnet.Name == "" {
+		return errors.New("no name of a subnet")
+	}
+	subnetIP, subnetNet, err := net.ParseCIDR(subnet.CIDR)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid CIDR %s of subnet %s", subnet.CIDR, subnet.Name))
+	}
+
+	inAddressSpace := len(addressPrefixes) == 0
+	for _, prefix := range addressPrefixes {
+		_, ipNet, err := net.ParseCIDR(prefix)
+		if err == nil && ipNet.Contains(subnetIP) {
+			inAddressSpace = true
+			break
+		}
+	}
+	if !inAddressSpace {
+		return errors.New(fmt.Sprintf("CIDR %s of subnet %s is not in the address prefixes %v", subnet.CIDR, subnet.Name, addressPrefixes))
+	}
+
+	for _, dnsServer := range subnet.DNSServers {
+		if net.ParseIP(dnsServer) == nil {
+			return errors.New(fmt.Sprintf("invalid DNS server %s of subnet %s", dnsServer, subnet.Name))
+		}
+	}
+	for _, pool := range subnet.IPPools {
+		start, end := net.ParseIP(pool.Start), net.ParseIP(pool.End)
+		if start == nil || end == nil || !subnetNet.Contains(start) || !subnetNet.Contains(end) {
+			return errors.New(fmt.Sprintf("IP pool %s ~ %s is not in CIDR %s of subnet %s", pool.Start, pool.End, subnet.CIDR, subnet.Name))
+		}
+	}
+	return nil
+}
+
+// Subnets are added to or deleted from an existing VNetwork without recreating it.
+type SubnetHandler interface {
+	CreateSubnet(vNetworkID string, subnetReqInfo SubnetReqInfo) (SubnetInfo, error)
+	ListSubnet(vNetworkID string, listReqInfo ListReqInfo) ([]*SubnetInfo, ListPageInfo, error)
+	GetSubnet(subnetID string) (SubnetInfo, error)
+	DeleteSubnet(subnetID string) (bool, error)
+}
@@ -11,6 +11,11 @@
 package resources
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
 	"time"
 )
 
@@ -18,15 +23,34 @@ type VMReqInfo struct {
 	// region/zone: Do not specify, this driver already knew these in Connection.
 
 	Name string
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
 
 	ImageInfo    ImageInfo
 	VNetworkInfo VNetworkInfo
 	SecurityInfo SecurityInfo
 	KeyPairInfo  KeyPairInfo
 	SpecID       string // instance type or flavour, etc...
-	vNicInfo     VNicInfo
+	VNicInfo     VNicInfo
 	PublicIPInfo PublicIPInfo
 	LoginInfo    LoginInfo
+
+	RootDiskSizeGiB int           // 0: the size of the image
+	DataDisks       []DiskReqInfo // new data disks attached at launch, deleted with the VM(Azure: kept)
+
+	UserData string // bootstrap script(ex: #!/bin/bash) or cloud-config(#cloud-config), not encoded. run by cloud-init at the first boot
+
+	// the NICs of the VM, the first one is the primary.
+	// empty: a single NIC by VNetworkInfo, SecurityInfo, VNicInfo and PublicIPInfo.
+	NetworkInterfaces []VMNicReqInfo
+}
+
+// VMNicReqInfo is a NIC created with a VM.
+type VMNicReqInfo struct {
+	VNetworkId       string   // Azure: virtual network name or ID(default: VNetworkInfo.Id), OpenStack: network ID("": the network of SubnetId), AWS: not used
+	SubnetId         string   // ex) subnet-8c4a53e4, Azure: subnet name or ID(default: "default")
+	PrivateIP        string   // ex) 172.31.4.60, "": assigned by the cloud
+	SecurityGroupIds []string // ex) ["sg-0b7452563e1121bb6"], Azure: a NSG per NIC
+	PublicIP         bool     // a new public IP for the NIC, AWS: only with a single NIC
 }
 
 type VMStatusInfo struct {
@@ -58,29 +82,76 @@ type RegionInfo struct {
 type VMInfo struct {
 	Name      string
 	Id        string
-	StartTime time.Time // Timezone: based on cloud-barista server location.
+	StartTime time.Time         // Timezone: based on cloud-barista server location.
+	Tags      map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
 
 	Region       RegionInfo // ex) {us-east1, us-east1-c} or {ap-northeast-2}
 	ImageID      string     // ex) ami-047f7b46bd6dd5d84 or projects/gce-uefi-images/global/images/centos-7-v20190326
 	SpecID       string     // instance type or flavour, etc... ex) t2.micro or f1-micro
 	VNetworkID   string     // ex) vpc-23ed0a4b
 	SubNetworkID string     // ex) subnet-8c4a53e4
-	SecurityID   string     // ex) sg-0b7452563e1121bb6
+	SecurityID   string     // ex) sg-0b7452563e1121bb6, the first one of SecurityGroupIds
+
+	SecurityGroupIds []string // ex) ["sg-0b7452563e1121bb6"], OpenStack: the IDs of the security groups of the ports
 
-	VNIC       string // ex) eth0
+	VNIC       string // ex) eni-0f6e4b4d9a2c1b3e5, the ID of the primary NIC
 	PublicIP   string // ex) 13.125.43.21
-	PublicDNS  string // ex) ec2-13-125-43-0.ap-northeast-2.compute.amazonaws.com
-	PrivateIP  string // ex) ip-172-31-4-60.ap-northeast-2.compute.internal
-	PrivateDNS string // ex) 172.31.4.60
+	PublicDNS  string // ex) ec2-13-125-43-0.ap-northeast-2.compute.amazonaws.com, "": no public DNS name(OpenStack)
+	PrivateIP  string // ex) 172.31.4.60
+	PrivateDNS string // ex) ip-172-31-4-60.ap-northeast-2.compute.internal, "": no private DNS name(OpenStack)
 
 	KeyPairID    string // ex) powerkimKeyPair
 	GuestUserID  string // ex) user1
 	GuestUserPwd string
 
 	GuestBootDisk  string // ex) /dev/sda1
-	GuestBlockDisk string // ex)
+	GuestBlockDisk string // ex) /dev/sdf, the device of the first data disk
+
+	RootDiskId  string   // ex) vol-0c5fb0a1d9a4e8f12, "": the root disk is not a volume(OpenStack: booted from an image)
+	DataDiskIds []string // ex) ["vol-0a3b1e6f2c8d7e9a1"]
 
 	AdditionalInfo string // Any information to be good for users and developers.
+
+	KeyValueList []KeyValue // provider-specific extras.
+}
+
+// CheckUserData checks the size of the user data of a VM by the limit of a cloud.
+// encoded: the limit is on the base64 encoded user data. ex) Nova user_data
+func CheckUserData(userData string, maxSize int, encoded bool) error {
+	size := len(userData)
+	if encoded {
+		size = base64.StdEncoding.EncodedLen(size)
+	}
+	if size > maxSize {
+		return errors.New(fmt.Sprintf("user data is too large: %d bytes, the limit is %d bytes", size, maxSize))
+	}
+	return nil
+}
+
+// CheckVMNicReqInfo checks the NICs of a VM request, maxSecurityGroups: the limit of security groups per NIC(0: no limit).
+func CheckVMNicReqInfo(nics []VMNicReqInfo, maxSecurityGroups int) error {
+	for i, nic := range nics {
+		if nic.PrivateIP != "" && net.ParseIP(nic.PrivateIP) == nil {
+			return errors.New(fmt.Sprintf("invalid private IP of NIC %d: %s", i, nic.PrivateIP))
+		}
+		if maxSecurityGroups > 0 && len(nic.SecurityGroupIds) > maxSecurityGroups {
+			return errors.New(fmt.Sprintf("too many security groups of NIC %d: %d, the limit is %d", i, len(nic.SecurityGroupIds), maxSecurityGroups))
+		}
+	}
+	return nil
+}
+
+// TailLines returns the last lines of a text, lines <= 0: the whole text.
+func TailLines(text string, lines int) string {
+	if lines <= 0 {
+		return text
+	}
+	// a newline at the end of the text does not start a line.
+	lastLine := strings.LastIndex(strings.TrimSuffix(text, "\n"), "\n")
+	for i := 1; i < lines && lastLine >= 0; i++ {
+		lastLine = strings.LastIndex(text[:lastLine], "\n")
+	}
+	return text[lastLine+1:]
 }
 
 type LoginInfo struct {
@@ -95,9 +166,17 @@ type VMHandler interface {
 	RebootVM(vmID string)
 	TerminateVM(vmID string)
 
-	ListVMStatus() []*VMStatusInfo
+	ListVMStatus(listReqInfo ListReqInfo) ([]*VMStatusInfo, ListPageInfo, error)
 	GetVMStatus(vmID string) VMStatus
 
-	ListVM() []*VMInfo
+	ListVM(listReqInfo ListReqInfo) ([]*VMInfo, ListPageInfo, error)
 	GetVM(vmID string) VMInfo
+
+	// ResizeVM changes the spec(SpecID) of a VM, a running VM is stopped for the change and started again.
+	// An error is returned when the new spec is not available in the zone of the VM.
+	ResizeVM(vmID string, newSpec string) (VMInfo, error)
+
+	// GetVMConsoleOutput returns the serial console log of a VM to see the boot of the VM.
+	// tailLines: the number of the last lines, 0: all the log kept by the cloud(ex: AWS keeps the last 64KB).
+	GetVMConsoleOutput(vmID string, tailLines int) (string, error)
 }
@@ -0,0 +1,30 @@
+// Cloud Driver Interface of CB-Spider.
+// The CB-Spider is a sub-Framework of the Cloud-Barista Multi-Cloud Project.
+// The CB-Spider Mission is to connect all the clouds with a single interface.
+//
+//      * Cloud-Barista: https://github.com/cloud-barista
+//
+// This is Resouces interfaces of Cloud Driver.
+
+package resources
+
+// VMSpecInfo is an instance type(AWS), a flavor(OpenStack) or a VM size(Azure).
+// 0 or "": unknown in the cloud. ex) no GPU info of Azure VM sizes
+type VMSpecInfo struct {
+	Name string // ex) t2.micro, m1.small, Standard_B1ls
+	Id   string // SpecID of VMReqInfo. ex) t2.micro, OpenStack flavor ID, Standard_B1ls
+
+	VCpuCount          int
+	MemoryMiB          int64
+	GpuCount           int
+	LocalDiskGiB       int64    // instance store(AWS), root disk(OpenStack), temporary disk(Azure)
+	NetworkPerformance string   // ex) Low to Moderate, Up to 5 Gigabit
+	Architectures      []string // ex) x86_64, arm64
+
+	KeyValueList []KeyValue // provider-specific extras. ex) {"MaxDataDiskCount", "2"}
+}
+
+type VMSpecHandler interface {
+	ListVMSpec(listReqInfo ListReqInfo) ([]*VMSpecInfo, ListPageInfo, error)
+	GetVMSpec(specID string) (VMSpecInfo, error)
+}
@@ -10,22 +10,58 @@
 
 package resources
 
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
 type VNetworkReqInfo struct {
 	Name string
 	Id   string
-	// @todo
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	AddressPrefixes []string // ex) ["10.0.0.0/16"] (AWS: the first is the primary CIDR, OpenStack: not used)
+	Subnets         []SubnetReqInfo
+}
+
+// CheckVNetworkReqInfo checks the CIDRs and the IP pools of a request.
+// The features supported by each cloud are checked by the drivers.
+func CheckVNetworkReqInfo(reqInfo VNetworkReqInfo) error {
+	for _, prefix := range reqInfo.AddressPrefixes {
+		if _, _, err := net.ParseCIDR(prefix); err != nil {
+			return errors.New(fmt.Sprintf("invalid address prefix %s of a VNetwork", prefix))
+		}
+	}
+
+	if len(reqInfo.Subnets) == 0 {
+		return errors.New("no subnet of a VNetwork")
+	}
+	for _, subnet := range reqInfo.Subnets {
+		if err := CheckSubnetReqInfo(subnet, reqInfo.AddressPrefixes); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type VNetworkInfo struct {
 	Name     string
 	Id       string
-	SubnetId string
-	// @todo
+	SubnetId string            // the first subnet of Subnets
+	Tags     map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	AddressPrefixes []string // ex) ["10.0.0.0/16"] (OpenStack: CIDRs of the subnets)
+	Subnets         []SubnetInfo
+	Status          string // ex) available, ACTIVE, Succeeded
+	Owner           string // ex) AWS account ID, OpenStack project ID
+
+	KeyValueList []KeyValue // provider-specific extras. ex) {"IsDefault", "true"}
 }
 
 type VNetworkHandler interface {
 	CreateVNetwork(vNetworkReqInfo VNetworkReqInfo) (VNetworkInfo, error)
-	ListVNetwork() ([]*VNetworkInfo, error)
+	ListVNetwork(listReqInfo ListReqInfo) ([]*VNetworkInfo, ListPageInfo, error)
 	GetVNetwork(vNetworkID string) (VNetworkInfo, error)
 	DeleteVNetwork(vNetworkID string) (bool, error)
 }
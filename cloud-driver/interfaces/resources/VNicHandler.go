@@ -13,18 +13,31 @@ package resources
 type VNicReqInfo struct {
 	Name string
 	Id   string
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
 	// @todo
 }
 
 type VNicInfo struct {
 	Name string
 	Id   string
-	// @todo
+	Tags map[string]string // ex) {"owner": "powerkim", "cost-center": "cb-spider"}
+
+	VNetworkId       string   // ex) vpc-23ed0a4b
+	SubnetId         string   // ex) subnet-8c4a53e4
+	MacAddress       string   // ex) 02:3a:6b:1c:5e:7a
+	PrivateIP        string   // ex) 172.31.4.60
+	PublicIP         string   // ex) 13.125.43.21, "": no public IP or unknown
+	SecurityGroupIds []string // ex) ["sg-0b7452563e1121bb6"]
+	OwnedVMId        string   // VM which the NIC is attached to, "": not attached
+	Status           string   // ex) in-use, ACTIVE, Succeeded
+	Owner            string   // ex) AWS account ID, OpenStack project ID
+
+	KeyValueList []KeyValue // provider-specific extras.
 }
 
 type VNicHandler interface {
 	CreateVNic(vNicReqInfo VNicReqInfo) (VNicInfo, error)
-	ListVNic() ([]*VNicInfo, error)
+	ListVNic(listReqInfo ListReqInfo) ([]*VNicInfo, ListPageInfo, error)
 	GetVNic(vNicID string) (VNicInfo, error)
 	DeleteVNic(vNicID string) (bool, error)
 }